@@ -16,6 +16,12 @@ limitations under the License.
 
 package v1beta1
 
+const (
+	// DeployWindowClosedCondition is True when deployments are currently not allowed due to the deploy windows
+	// or an active DeployFreeze.
+	DeployWindowClosedCondition string = "DeployWindowClosed"
+)
+
 const (
 	// DiffFailedReason represents the fact that the
 	// kluctl diff command failed.
@@ -40,6 +46,18 @@ const (
 	// the reconciliation succeeded.
 	ReconciliationSucceededReason string = "ReconciliationSucceeded"
 
+	// DependencyNotReadyReason represents the fact that
+	// one of the dependencies of the KluctlDeployment is not ready.
+	DependencyNotReadyReason string = "DependencyNotReady"
+
+	// DeployWindowClosedReason represents the fact that
+	// the current time is outside the allowed deploy windows.
+	DeployWindowClosedReason string = "DeployWindowClosed"
+
+	// DeployFreezeActiveReason represents the fact that
+	// a referenced DeployFreeze is active.
+	DeployFreezeActiveReason string = "DeployFreezeActive"
+
 	// WaitingForLegacyMigrationReason means that the controller is waiting for the legacy controller to set `readyForMigration=true`
 	WaitingForLegacyMigrationReason string = "WaitingForLegacyMigration"
 )
@@ -0,0 +1,79 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	DeployFreezeKind = "DeployFreeze"
+)
+
+// DeployFreezeSpec defines when a change freeze is active
+type DeployFreezeSpec struct {
+	// Reason is a human-readable description of the freeze, which is shown in the DeployWindowClosed condition
+	// of affected KluctlDeployments.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Start specifies when the freeze starts. If omitted, the freeze is active immediately.
+	// +optional
+	Start *metav1.Time `json:"start,omitempty"`
+
+	// End specifies when the freeze ends. If omitted, the freeze is active until the DeployFreeze is deleted.
+	// +optional
+	End *metav1.Time `json:"end,omitempty"`
+
+	// TimeZone is the IANA time zone used to interpret the schedules of Windows. Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Windows specifies recurring windows in which the freeze is active, e.g. every weekend. If omitted, the
+	// freeze is active for the whole time between Start and End.
+	// +optional
+	Windows []DeployWindow `json:"windows,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Start",type="date",JSONPath=".spec.start",description=""
+//+kubebuilder:printcolumn:name="End",type="date",JSONPath=".spec.end",description=""
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".spec.reason",description=""
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// DeployFreeze is the Schema for the deployfreezes API. It is referenced by KluctlDeployments via
+// spec.deployWindows.freezeRefs to prevent deployments while the freeze is active.
+type DeployFreeze struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DeployFreezeSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DeployFreezeList contains a list of DeployFreeze
+type DeployFreezeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeployFreeze `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DeployFreeze{}, &DeployFreezeList{})
+}
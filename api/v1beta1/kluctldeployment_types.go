@@ -31,6 +31,17 @@ const (
 	KluctlDeploymentFinalizer = "finalizers.gitops.kluctl.io"
 	MaxConditionMessageLength = 20000
 
+	// KluctlDeploymentShardKeyLabel assigns a KluctlDeployment to the controller shard started with the same
+	// --shard-key
+	KluctlDeploymentShardKeyLabel = "kluctl.io/shard-key"
+
+	// MaxDeploymentItemStatuses limits the number of entries in status.deploymentItems to keep the object size small
+	MaxDeploymentItemStatuses = 250
+
+	DriftRemediationReport          = "report"
+	DriftRemediationRedeployDrifted = "redeploy-drifted"
+	DriftRemediationRedeployAll     = "redeploy-all"
+
 	KluctlDeployModeFull   = "full-deploy"
 	KluctlDeployPokeImages = "poke-images"
 )
@@ -242,6 +253,153 @@ type KluctlDeploymentSpec struct {
 	// 2. Use the Kluctl Webui to manually approve a deployment, which will set this field appropriately.
 	// +optional
 	ManualObjectsHash *string `json:"manualObjectsHash,omitempty"`
+
+	// DependsOn specifies a list of KluctlDeployments that must be ready before this KluctlDeployment is reconciled.
+	// If a dependency is not ready, the reconciliation is skipped and retried later.
+	// Manual requests (e.g. manual deployments triggered via the CLI or the Webui) ignore dependencies.
+	// +optional
+	DependsOn []KluctlDeploymentDependency `json:"dependsOn,omitempty"`
+
+	// DeployWindows restricts when the controller is allowed to deploy and prune. Outside of the windows, the
+	// controller still diffs and performs drift detection, but postpones deployments until the next window opens.
+	// Manual requests are also rejected unless they explicitly override the deploy windows.
+	// +optional
+	DeployWindows *DeployWindows `json:"deployWindows,omitempty"`
+
+	// DriftRemediation specifies how the controller reacts to drift found by drift detection. By default, drift
+	// is only reported.
+	// +optional
+	DriftRemediation *DriftRemediation `json:"driftRemediation,omitempty"`
+
+	// ResultRetention specifies retention policies for the command and validate results of this KluctlDeployment.
+	// These take precedence over the policies configured in the controller.
+	// +optional
+	ResultRetention []ResultRetentionPolicy `json:"resultRetention,omitempty"`
+}
+
+// KluctlDeploymentDependency references another KluctlDeployment that must be ready before the dependant
+// KluctlDeployment is reconciled.
+type KluctlDeploymentDependency struct {
+	// Name of the KluctlDeployment.
+	// +required
+	Name string `json:"name"`
+
+	// Namespace of the KluctlDeployment. Defaults to the namespace of the dependant KluctlDeployment.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Strict additionally requires that the last deployment of the dependency succeeded without errors and that
+	// no drift was detected since then.
+	// +optional
+	Strict bool `json:"strict,omitempty"`
+}
+
+// DeployWindows specifies when deployments are allowed.
+type DeployWindows struct {
+	// TimeZone is the IANA time zone used to interpret the schedules of Allow and Deny, e.g. 'Europe/Berlin'.
+	// Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Allow specifies the windows in which deployments are allowed. If empty, deployments are allowed at any time
+	// that is not matched by Deny or an active DeployFreeze.
+	// +optional
+	Allow []DeployWindow `json:"allow,omitempty"`
+
+	// Deny specifies the windows in which deployments are not allowed. Deny takes precedence over Allow.
+	// +optional
+	Deny []DeployWindow `json:"deny,omitempty"`
+
+	// FreezeRefs specifies the names of cluster-scoped DeployFreeze objects. Deployments are not allowed while
+	// any of the referenced freezes is active. Missing DeployFreeze objects are ignored.
+	// +optional
+	FreezeRefs []string `json:"freezeRefs,omitempty"`
+}
+
+// DeployWindow specifies a recurring time window.
+type DeployWindow struct {
+	// Schedule is a cron expression (minute, hour, day of month, month, day of week) that specifies when the
+	// window opens, e.g. '0 8 * * 1-5'.
+	// +required
+	Schedule string `json:"schedule"`
+
+	// Duration specifies how long the window stays open after it was opened.
+	// +required
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	Duration metav1.Duration `json:"duration"`
+}
+
+// DriftRemediation specifies how drift is remediated.
+type DriftRemediation struct {
+	// Mode specifies what to do when drift is detected. 'report' only reports the drift, 'redeploy-drifted'
+	// re-applies only the drifted objects and 'redeploy-all' performs a full deployment.
+	// +kubebuilder:default:=report
+	// +kubebuilder:validation:Enum=report;redeploy-drifted;redeploy-all
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// Exclude specifies kinds of objects that are never remediated. Drift on these objects is still reported.
+	// +optional
+	Exclude []DriftRemediationExclude `json:"exclude,omitempty"`
+
+	// MaxRemediationsPerHour limits how often drift is remediated, which avoids fighting with other controllers
+	// that modify the same objects. Defaults to 6.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxRemediationsPerHour *int `json:"maxRemediationsPerHour,omitempty"`
+}
+
+// DriftRemediationExclude specifies a group and kind to exclude from drift remediation.
+type DriftRemediationExclude struct {
+	// Group is the API group of the object. Use an empty string for the core API group.
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Kind is the kind of the object.
+	// +required
+	Kind string `json:"kind"`
+}
+
+func (d *DriftRemediation) GetMode() string {
+	if d == nil || d.Mode == "" {
+		return DriftRemediationReport
+	}
+	return d.Mode
+}
+
+func (d *DriftRemediation) GetMaxRemediationsPerHour() int {
+	if d == nil || d.MaxRemediationsPerHour == nil {
+		return 6
+	}
+	return *d.MaxRemediationsPerHour
+}
+
+// ResultRetentionPolicy specifies which old results are deleted from the result store.
+type ResultRetentionPolicy struct {
+	// Command restricts the policy to results of the given command, e.g. 'deploy' or 'diff'. Use 'validate' for
+	// validate results. If omitted, the policy applies to all results not matched by a more specific policy.
+	// +optional
+	Command string `json:"command,omitempty"`
+
+	// KeepCount specifies how many results to keep per target.
+	// +optional
+	KeepCount *int `json:"keepCount,omitempty"`
+
+	// MaxAge specifies how long results are kept.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+
+	// KeepLastSuccessful ensures that the newest successful result is never deleted, independent of KeepCount
+	// and MaxAge.
+	// +optional
+	KeepLastSuccessful bool `json:"keepLastSuccessful,omitempty"`
+
+	// KeepLastFailed ensures that the newest failed result is never deleted, independent of KeepCount and MaxAge.
+	// +optional
+	KeepLastFailed bool `json:"keepLastFailed,omitempty"`
 }
 
 // GetRetryInterval returns the retry interval
@@ -261,6 +419,11 @@ type ProjectSource struct {
 	// +optional
 	Oci *ProjectSourceOci `json:"oci,omitempty"`
 
+	// SourceRef specifies a Flux source object (GitRepository, OCIRepository or Bucket) to be used as project source.
+	// The artifact produced by the Flux source-controller is downloaded and used as project source.
+	// +optional
+	SourceRef *ProjectSourceFluxRef `json:"sourceRef,omitempty"`
+
 	// Url specifies the Git url where the project source is located
 	// DEPRECATED this field is deprecated and will be removed in the next API version bump. Use spec.git.url instead.
 	// +optional
@@ -320,6 +483,29 @@ type ProjectSourceOci struct {
 	Path string `json:"path,omitempty"`
 }
 
+type ProjectSourceFluxRef struct {
+	// APIVersion of the Flux source object. If omitted, the default version for the given kind is used.
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind of the Flux source object.
+	// +kubebuilder:validation:Enum=GitRepository;OCIRepository;Bucket
+	// +required
+	Kind string `json:"kind"`
+
+	// Name of the Flux source object.
+	// +required
+	Name string `json:"name"`
+
+	// Namespace of the Flux source object. If omitted, the namespace of the KluctlDeployment is used.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Path specifies the sub-directory inside the artifact to be used as project directory
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
 type SourceOverride struct {
 	// +required
 	RepoKey gittypes.RepoKey `json:"repoKey"`
@@ -539,6 +725,69 @@ type KluctlDeploymentStatus struct {
 	// LastDriftDetectionResultMessage contains a short message that describes the drift
 	// optional
 	LastDriftDetectionResultMessage string `json:"lastDriftDetectionResultMessage,omitempty"`
+
+	// DeployPostponedUntil is set when a deployment was postponed because the deploy window was closed. The
+	// postponed deployment is performed in the next open deploy window.
+	// +optional
+	DeployPostponedUntil *metav1.Time `json:"deployPostponedUntil,omitempty"`
+
+	// DriftRemediationTimes contains the times of the drift remediations performed within the last hour.
+	// +optional
+	DriftRemediationTimes []metav1.Time `json:"driftRemediationTimes,omitempty"`
+
+	// DeploymentItems contains a compact status of each deployment item, derived from the last deploy and validate
+	// results. If the project has more than MaxDeploymentItemStatuses items, items with errors or which are not
+	// ready are preferred and DeploymentItemsTruncated is set.
+	// +optional
+	DeploymentItems []DeploymentItemStatus `json:"deploymentItems,omitempty"`
+
+	// DeploymentItemsTruncated is true when DeploymentItems does not contain all deployment items.
+	// +optional
+	DeploymentItemsTruncated bool `json:"deploymentItemsTruncated,omitempty"`
+
+	// DeploymentItemsMessage contains a short message that describes which deployment items have problems
+	// +optional
+	DeploymentItemsMessage string `json:"deploymentItemsMessage,omitempty"`
+}
+
+// DeploymentItemStatus is the compact status of a single deployment item
+type DeploymentItemStatus struct {
+	// Path is the path of the deployment item, relative to the project root.
+	// +required
+	Path string `json:"path"`
+
+	// Tags are the tags of the deployment item.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+
+	// AppliedObjects is the number of objects applied by the last deployment.
+	// +optional
+	AppliedObjects int `json:"appliedObjects,omitempty"`
+
+	// ChangedObjects is the number of new or changed objects in the last deployment.
+	// +optional
+	ChangedObjects int `json:"changedObjects,omitempty"`
+
+	// Errors is the number of errors of the last deployment.
+	// +optional
+	Errors int `json:"errors,omitempty"`
+
+	// Warnings is the number of warnings of the last deployment.
+	// +optional
+	Warnings int `json:"warnings,omitempty"`
+
+	// Ready is true if the last validation found no errors for the objects of this deployment item. It is not set if
+	// the KluctlDeployment was never validated.
+	// +optional
+	Ready *bool `json:"ready,omitempty"`
+
+	// LastChangeTime is the time of the last deployment that created or changed objects of this deployment item.
+	// +optional
+	LastChangeTime *metav1.Time `json:"lastChangeTime,omitempty"`
+}
+
+func (s *DeploymentItemStatus) HasProblems() bool {
+	return s.Errors != 0 || (s.Ready != nil && !*s.Ready)
 }
 
 func (s *KluctlDeploymentStatus) SetLastDiffResult(crs *result.CommandResultSummary) {
@@ -621,6 +870,7 @@ func (s *KluctlDeploymentStatus) GetDriftDetectionResult() (*result.DriftDetecti
 //+kubebuilder:printcolumn:name="Deployed",type="date",JSONPath=".status.lastDeployResult.commandInfo.endTime",description=""
 //+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description=""
 //+kubebuilder:printcolumn:name="Drift",type="string",JSONPath=".status.lastDriftDetectionResultMessage",description=""
+//+kubebuilder:printcolumn:name="Items",type="string",JSONPath=".status.deploymentItemsMessage",description=""
 //+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].message",description=""
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
 
@@ -12,6 +12,9 @@ type ManualRequest struct {
 
 	// +optional
 	OverridesPatch *runtime.RawExtension `json:"overridesPatch,omitempty"`
+
+	// +optional
+	OverrideDeployWindow bool `json:"overrideDeployWindow,omitempty"`
 }
 
 type ManualRequestResult struct {
@@ -48,6 +48,207 @@ func (in *Decryption) DeepCopy() *Decryption {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeployFreeze) DeepCopyInto(out *DeployFreeze) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeployFreeze.
+func (in *DeployFreeze) DeepCopy() *DeployFreeze {
+	if in == nil {
+		return nil
+	}
+	out := new(DeployFreeze)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeployFreeze) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeployFreezeList) DeepCopyInto(out *DeployFreezeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeployFreeze, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeployFreezeList.
+func (in *DeployFreezeList) DeepCopy() *DeployFreezeList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeployFreezeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeployFreezeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeployFreezeSpec) DeepCopyInto(out *DeployFreezeSpec) {
+	*out = *in
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = (*in).DeepCopy()
+	}
+	if in.End != nil {
+		in, out := &in.End, &out.End
+		*out = (*in).DeepCopy()
+	}
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]DeployWindow, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeployFreezeSpec.
+func (in *DeployFreezeSpec) DeepCopy() *DeployFreezeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeployFreezeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeployWindow) DeepCopyInto(out *DeployWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeployWindow.
+func (in *DeployWindow) DeepCopy() *DeployWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(DeployWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeployWindows) DeepCopyInto(out *DeployWindows) {
+	*out = *in
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]DeployWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]DeployWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.FreezeRefs != nil {
+		in, out := &in.FreezeRefs, &out.FreezeRefs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeployWindows.
+func (in *DeployWindows) DeepCopy() *DeployWindows {
+	if in == nil {
+		return nil
+	}
+	out := new(DeployWindows)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentItemStatus) DeepCopyInto(out *DeploymentItemStatus) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ready != nil {
+		in, out := &in.Ready, &out.Ready
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LastChangeTime != nil {
+		in, out := &in.LastChangeTime, &out.LastChangeTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentItemStatus.
+func (in *DeploymentItemStatus) DeepCopy() *DeploymentItemStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentItemStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftRemediation) DeepCopyInto(out *DriftRemediation) {
+	*out = *in
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]DriftRemediationExclude, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxRemediationsPerHour != nil {
+		in, out := &in.MaxRemediationsPerHour, &out.MaxRemediationsPerHour
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftRemediation.
+func (in *DriftRemediation) DeepCopy() *DriftRemediation {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftRemediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftRemediationExclude) DeepCopyInto(out *DriftRemediationExclude) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftRemediationExclude.
+func (in *DriftRemediationExclude) DeepCopy() *DriftRemediationExclude {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftRemediationExclude)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmCredentials) DeepCopyInto(out *HelmCredentials) {
 	*out = *in
@@ -91,6 +292,21 @@ func (in *KluctlDeployment) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KluctlDeploymentDependency) DeepCopyInto(out *KluctlDeploymentDependency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KluctlDeploymentDependency.
+func (in *KluctlDeploymentDependency) DeepCopy() *KluctlDeploymentDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(KluctlDeploymentDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KluctlDeploymentList) DeepCopyInto(out *KluctlDeploymentList) {
 	*out = *in
@@ -221,6 +437,28 @@ func (in *KluctlDeploymentSpec) DeepCopyInto(out *KluctlDeploymentSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]KluctlDeploymentDependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeployWindows != nil {
+		in, out := &in.DeployWindows, &out.DeployWindows
+		*out = new(DeployWindows)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DriftRemediation != nil {
+		in, out := &in.DriftRemediation, &out.DriftRemediation
+		*out = new(DriftRemediation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResultRetention != nil {
+		in, out := &in.ResultRetention, &out.ResultRetention
+		*out = make([]ResultRetentionPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KluctlDeploymentSpec.
@@ -303,6 +541,24 @@ func (in *KluctlDeploymentStatus) DeepCopyInto(out *KluctlDeploymentStatus) {
 		*out = new(runtime.RawExtension)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DeployPostponedUntil != nil {
+		in, out := &in.DeployPostponedUntil, &out.DeployPostponedUntil
+		*out = (*in).DeepCopy()
+	}
+	if in.DriftRemediationTimes != nil {
+		in, out := &in.DriftRemediationTimes, &out.DriftRemediationTimes
+		*out = make([]v1.Time, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DeploymentItems != nil {
+		in, out := &in.DeploymentItems, &out.DeploymentItems
+		*out = make([]DeploymentItemStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KluctlDeploymentStatus.
@@ -494,6 +750,11 @@ func (in *ProjectSource) DeepCopyInto(out *ProjectSource) {
 		*out = new(ProjectSourceOci)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SourceRef != nil {
+		in, out := &in.SourceRef, &out.SourceRef
+		*out = new(ProjectSourceFluxRef)
+		**out = **in
+	}
 	if in.URL != nil {
 		in, out := &in.URL, &out.URL
 		*out = new(string)
@@ -526,6 +787,21 @@ func (in *ProjectSource) DeepCopy() *ProjectSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectSourceFluxRef) DeepCopyInto(out *ProjectSourceFluxRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectSourceFluxRef.
+func (in *ProjectSourceFluxRef) DeepCopy() *ProjectSourceFluxRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectSourceFluxRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProjectSourceGit) DeepCopyInto(out *ProjectSourceGit) {
 	*out = *in
@@ -566,6 +842,31 @@ func (in *ProjectSourceOci) DeepCopy() *ProjectSourceOci {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResultRetentionPolicy) DeepCopyInto(out *ResultRetentionPolicy) {
+	*out = *in
+	if in.KeepCount != nil {
+		in, out := &in.KeepCount, &out.KeepCount
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResultRetentionPolicy.
+func (in *ResultRetentionPolicy) DeepCopy() *ResultRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ResultRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SafeDuration) DeepCopyInto(out *SafeDuration) {
 	*out = *in
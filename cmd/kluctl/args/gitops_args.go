@@ -25,6 +25,10 @@ type GitOpsLogArgs struct {
 	LogTime         bool          `group:"logs" help:"If enabled, adds timestamps to log lines"`
 }
 
+type GitOpsDeployWindowArgs struct {
+	OverrideDeployWindow bool `group:"gitops" help:"Perform the request even if the deploy window of the KluctlDeployment is closed or a DeployFreeze is active."`
+}
+
 type GitOpsOverridableArgs struct {
 	SourceOverrides
 	TargetFlagsBase
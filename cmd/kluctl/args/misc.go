@@ -8,15 +8,31 @@ type YesFlags struct {
 	Yes bool `group:"misc" short:"y" help:"Suppresses 'Are you sure?' questions and proceeds as if you would answer 'yes'."`
 }
 
+type ConfirmationPhraseFlags struct {
+	ConfirmationPhrase string `group:"misc" help:"Passes the confirmation phrase required by the target's 'requireConfirmationPhrase' policy, so that no interactive prompt is shown."`
+}
+
 type OfflineKubernetesFlags struct {
 	OfflineKubernetes bool   `group:"misc" help:"Run command in offline mode, meaning that it will not try to connect the target cluster"`
 	KubernetesVersion string `group:"misc" help:"Specify the Kubernetes version that will be assumed. This will also override the kubeVersion used when rendering Helm Charts."`
 }
 
+type CheckApisFlags struct {
+	CheckApisForVersion string `group:"misc" help:"Additionally warn about APIs that are deprecated or removed in the given Kubernetes version, e.g. to prepare for a planned cluster upgrade. Unlike --kubernetes-version, this only affects the API deprecation checks and not how Helm Charts are rendered."`
+}
+
+type SchemaValidationFlags struct {
+	SchemaDir []string `group:"misc" help:"Directory containing additional CRDs or OpenAPI documents (swagger.json or OpenAPI v3) to be used for schema validation. Can be specified multiple times."`
+}
+
 type DryRunFlags struct {
 	DryRun bool `group:"misc" help:"Performs all kubernetes API calls in dry-run mode."`
 }
 
+type BackupFlags struct {
+	BackupFile string `group:"misc" help:"Write the state of all objects into the given file before deleting or pruning them. The file can later be passed to 'kluctl restore --from-file'. Secrets are stored unobfuscated and the file is only readable by the current user. Backups are required to restore Secrets, as the command results in the result store are obfuscated."`
+}
+
 type ForceApplyFlags struct {
 	ForceApply bool `group:"misc" help:"Force conflict resolution when applying. See documentation for details"`
 }
@@ -42,9 +58,9 @@ type AbortOnErrorFlags struct {
 }
 
 type OutputFormatFlags struct {
-	OutputFormat []string `group:"misc" short:"o" help:"Specify output format and target file, in the format 'format=path'. Format can either be 'text' or 'yaml'. Can be specified multiple times. The actual format for yaml is currently not documented and subject to change."`
+	OutputFormat []string `group:"misc" short:"o" help:"Specify output format and target file, in the format 'format=path'. Format can be 'text', 'yaml', 'json', 'json-patch' or 'markdown'. Can be specified multiple times. See the output formats documentation for details."`
 	NoObfuscate  bool     `group:"misc" help:"Disable obfuscation of sensitive/secret data"`
-	ShortOutput  bool     `group:"misc" help:"When using the 'text' output format (which is the default), only names of changes objects are shown instead of showing all changes."`
+	ShortOutput  bool     `group:"misc" help:"When using the 'text' or 'markdown' output format ('text' is the default), only names of changes objects are shown instead of showing all changes."`
 }
 
 type OutputFlags struct {
@@ -78,6 +78,23 @@ type KubeconfigFlags struct {
 
 type CommandResultReadOnlyFlags struct {
 	CommandResultNamespace string `group:"results" help:"Override the namespace to be used when writing command results." default:"kluctl-results"`
+	ResultStore            string `group:"results" help:"Use a local result store instead of storing results as Secrets inside the target cluster. Supported are 'file://<dir>' and 'sqlite://<file>'."`
+}
+
+type ResultStoreFlags struct {
+	CommandResultReadOnlyFlags
+
+	Kubeconfig ExistingFileType `group:"results" help:"Overrides the kubeconfig to use for accessing the result store."`
+	Context    string           `group:"results" help:"Override the context to use for accessing the result store."`
+}
+
+type ResultFilterFlags struct {
+	Validate      bool   `group:"misc" help:"Operate on validate results instead of command results."`
+	Project       string `group:"misc" help:"Only include results of the given project, specified as repo key (e.g. 'github.com/org/repo')."`
+	ProjectSubdir string `group:"misc" help:"Only include results of the given project subdirectory."`
+	Target        string `group:"misc" help:"Only include results of the given target name."`
+	Command       string `group:"misc" help:"Only include results of the given command (e.g. 'deploy', 'diff' or 'prune'). Ignored for validate results."`
+	Status        string `group:"misc" help:"Only include results with the given status. Can be 'success', 'warnings' or 'failed'."`
 }
 
 type CommandResultWriteFlags struct {
@@ -85,6 +102,8 @@ type CommandResultWriteFlags struct {
 	ForceWriteCommandResult  bool `group:"results" help:"Force writing of command results, even if the command is run in dry-run mode."`
 	KeepCommandResultsCount  int  `group:"results" help:"Configure how many old command results to keep." default:"5"`
 	KeepValidateResultsCount int  `group:"results" help:"Configure how many old validate results to keep." default:"2"`
+
+	ResultRetentionFile ExistingFileType `group:"results" help:"Load result retention rules from the given yaml file. Rules can match by project and command and specify keep counts, maximum ages and the total size limit of all results. Results not matched by any rule fall back to --keep-command-results-count and --keep-validate-results-count."`
 }
 
 type CommandResultFlags struct {
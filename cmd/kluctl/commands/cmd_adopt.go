@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	"github.com/kluctl/kluctl/v2/pkg/prompts"
+	"strings"
+)
+
+type adoptCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.ImageFlags
+	args.InclusionFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.YesFlags
+	args.ConfirmationPhraseFlags
+	args.DryRunFlags
+	args.OutputFormatFlags
+	args.RenderOutputDirFlags
+	args.CommandResultFlags
+
+	RemoveHelmReleases bool `group:"misc" help:"Delete the Helm release secrets of all Helm releases for which all objects got adopted. Helm will then forget about these releases."`
+	AdoptForeign       bool `group:"misc" help:"Also adopt objects that are already managed by another kluctl target, meaning that they carry a different discriminator. These objects are skipped by default."`
+}
+
+func (cmd *adoptCmd) Help() string {
+	return `Adoption works by:
+
+  1. Render the local target and list all objects.
+  2. Search the cluster for objects from the list of 1. that already exist but do not
+     carry the target's discriminator, e.g. because they were created by kubectl or Helm.
+  3. Show the field manager conflicts that a regular deploy would run into.
+  4. Take ownership by force-applying the rendered objects, which adds the kluctl labels
+     and transfers ownership of all rendered fields to kluctl.
+
+Objects that do not exist yet are not touched, they will be created by the next deploy. Objects that
+carry the discriminator of another kluctl target are skipped, unless --adopt-foreign is passed.`
+}
+
+func (cmd *adoptCmd) Run(ctx context.Context) error {
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:         cmd.ProjectFlags,
+		kubeconfigFlags:      cmd.KubeconfigFlags,
+		targetFlags:          cmd.TargetFlags,
+		argsFlags:            cmd.ArgsFlags,
+		imageFlags:           cmd.ImageFlags,
+		inclusionFlags:       cmd.InclusionFlags,
+		gitCredentials:       cmd.GitCredentials,
+		helmCredentials:      cmd.HelmCredentials,
+		registryCredentials:  cmd.RegistryCredentials,
+		dryRunArgs:           &cmd.DryRunFlags,
+		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		commandResultFlags:   &cmd.CommandResultFlags,
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		err := checkConfirmationPhrase(ctx, cmdCtx, cmd.ConfirmationPhraseFlags, cmd.DryRun)
+		if err != nil {
+			return err
+		}
+
+		cmd2 := commands.NewAdoptCommand(cmdCtx.targetCtx)
+		cmd2.RemoveHelmReleases = cmd.RemoveHelmReleases
+		cmd2.AdoptForeign = cmd.AdoptForeign
+
+		result := cmd2.Run(func(report *commands.AdoptReport) error {
+			return cmd.confirmAdoption(ctx, report)
+		})
+		err = outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
+		if err != nil {
+			return err
+		}
+		if len(result.Errors) != 0 {
+			return fmt.Errorf("command failed")
+		}
+		return nil
+	})
+}
+
+func (cmd *adoptCmd) confirmAdoption(ctx context.Context, report *commands.AdoptReport) error {
+	stderr := getStderr(ctx)
+	_, _ = stderr.WriteString("The following objects will be adopted:\n")
+	for _, c := range report.Candidates {
+		line := fmt.Sprintf("  %s", c.Ref.String())
+		if len(c.Managers) != 0 {
+			line += fmt.Sprintf(" (managers: %s)", strings.Join(c.Managers, ", "))
+		}
+		if c.HelmRelease != nil {
+			line += fmt.Sprintf(" (Helm release %s/%s)", c.HelmRelease.Namespace, c.HelmRelease.Name)
+		}
+		_, _ = stderr.WriteString(line + "\n")
+		for _, conflict := range c.Conflicts {
+			prefix := "CONFLICT"
+			if conflict.Lost {
+				prefix = "CONFLICT (requires --force-apply on deploy)"
+			}
+			_, _ = stderr.WriteString(fmt.Sprintf("    %s: %s\n", prefix, conflict.Message))
+		}
+	}
+	if len(report.Skipped) != 0 {
+		_, _ = stderr.WriteString("The following objects are managed by another target and will be skipped:\n")
+		for _, ref := range report.Skipped {
+			_, _ = stderr.WriteString(fmt.Sprintf("  %s\n", ref.String()))
+		}
+	}
+	if cmd.RemoveHelmReleases && len(report.HelmReleases) != 0 {
+		_, _ = stderr.WriteString("The following Helm releases will be removed:\n")
+		for _, hr := range report.HelmReleases {
+			_, _ = stderr.WriteString(fmt.Sprintf("  %s/%s\n", hr.Namespace, hr.Name))
+		}
+	}
+
+	if len(report.Candidates) == 0 || cmd.Yes || cmd.DryRun {
+		return nil
+	}
+	if !prompts.AskForConfirmation(ctx, fmt.Sprintf("Do you really want to adopt %d objects?", len(report.Candidates))) {
+		return fmt.Errorf("aborted")
+	}
+	return nil
+}
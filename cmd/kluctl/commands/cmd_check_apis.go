@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+)
+
+type checkApisCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.ImageFlags
+	args.InclusionFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.RenderOutputDirFlags
+	args.OfflineKubernetesFlags
+
+	FailOnDeprecated bool `group:"misc" help:"Also fail when deprecated APIs are used, instead of only failing for removed APIs"`
+}
+
+func (cmd *checkApisCmd) Help() string {
+	return `Renders the target and checks all rendered objects (including Helm Chart output) for usages of
+APIs that are deprecated or removed in the given Kubernetes version. If --kubernetes-version is omitted,
+the version of the target cluster is used.
+
+This is useful to prepare for cluster upgrades, e.g. by running
+'kluctl check-apis -t prod --offline-kubernetes --kubernetes-version 1.31'.
+
+The render, diff and deploy commands also check for deprecated and removed APIs of the target's Kubernetes version.
+Pass --check-apis-for-version to these commands to additionally get warnings for a planned Kubernetes version,
+without affecting how Helm Charts are rendered.`
+}
+
+func (cmd *checkApisCmd) Run(ctx context.Context) error {
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:         cmd.ProjectFlags,
+		kubeconfigFlags:      cmd.KubeconfigFlags,
+		targetFlags:          cmd.TargetFlags,
+		argsFlags:            cmd.ArgsFlags,
+		imageFlags:           cmd.ImageFlags,
+		inclusionFlags:       cmd.InclusionFlags,
+		gitCredentials:       cmd.GitCredentials,
+		helmCredentials:      cmd.HelmCredentials,
+		registryCredentials:  cmd.RegistryCredentials,
+		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		offlineKubernetes:    cmd.OfflineKubernetes,
+		kubernetesVersion:    cmd.KubernetesVersion,
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		dc := cmdCtx.targetCtx.DeploymentCollection
+		k8sVersion, err := dc.GetK8sVersion()
+		if err != nil {
+			return err
+		}
+		if k8sVersion == nil {
+			return fmt.Errorf("unable to determine the Kubernetes version, please pass --kubernetes-version")
+		}
+
+		status.Flush(ctx)
+		stdout := getStdout(ctx)
+		for _, e := range dc.ApiErrors {
+			_, _ = fmt.Fprintf(stdout, "ERROR: %s: %s\n", e.Ref.String(), e.Message)
+		}
+		for _, e := range dc.ApiWarnings {
+			_, _ = fmt.Fprintf(stdout, "WARNING: %s: %s\n", e.Ref.String(), e.Message)
+		}
+
+		if len(dc.ApiErrors) != 0 {
+			return fmt.Errorf("found %d objects using APIs removed in Kubernetes %s", len(dc.ApiErrors), k8sVersion.String())
+		}
+		if len(dc.ApiWarnings) != 0 {
+			if cmd.FailOnDeprecated {
+				return fmt.Errorf("found %d objects using APIs deprecated in Kubernetes %s", len(dc.ApiWarnings), k8sVersion.String())
+			}
+			return nil
+		}
+		status.Infof(ctx, "No deprecated or removed APIs used for Kubernetes %s", k8sVersion.String())
+		return nil
+	})
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/kluctl/go-embed-python/embed_util"
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
@@ -15,8 +16,9 @@ type controllerInstallCmd struct {
 	args.DryRunFlags
 	args.CommandResultFlags
 
-	Context       string `group:"misc" help:"Override the context to use."`
-	KluctlVersion string `group:"misc" help:"Specify the controller version to install."`
+	Context       string   `group:"misc" help:"Override the context to use."`
+	KluctlVersion string   `group:"misc" help:"Specify the controller version to install."`
+	Shards        []string `group:"misc" help:"Install an additional controller shard for each given shard key. KluctlDeployments are assigned to shards via the kluctl.io/shard-key label, all other KluctlDeployments are reconciled by the default controller."`
 }
 
 func (cmd *controllerInstallCmd) Help() string {
@@ -33,6 +35,13 @@ func (cmd *controllerInstallCmd) Run(ctx context.Context) error {
 	if cmd.KluctlVersion != "" {
 		deployArgs = append(deployArgs, fmt.Sprintf("kluctl_version=%s", cmd.KluctlVersion))
 	}
+	if len(cmd.Shards) != 0 {
+		shardsJson, err := json.Marshal(cmd.Shards)
+		if err != nil {
+			return err
+		}
+		deployArgs = append(deployArgs, fmt.Sprintf("controller_shards=%s", string(shardsJson)))
+	}
 
 	cmd2 := deployCmd{
 		ProjectFlags: args.ProjectFlags{
@@ -7,6 +7,8 @@ import (
 	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
 	"github.com/kluctl/kluctl/v2/pkg/controllers"
+	"github.com/kluctl/kluctl/v2/pkg/gitwebhook"
+	"github.com/kluctl/kluctl/v2/pkg/notifications"
 	"github.com/kluctl/kluctl/v2/pkg/sourceoverride"
 	"github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/metrics"
 	log "github.com/sirupsen/logrus"
@@ -22,6 +24,7 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/flowcontrol"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -48,12 +51,23 @@ type controllerRunCmd struct {
 	HealthProbeBindAddress    string `group:"misc" help:"The address the probe endpoint binds to." default:":8081"`
 	SourceOverrideBindAddress string `group:"misc" help:"The address the source override manager endpoint binds to." default:":8082"`
 
+	WebhookReceiverBindAddress string `group:"misc" help:"The address the git webhook receiver binds to. The receiver is disabled by default." default:"0"`
+	WebhookReceiverSecretName  string `group:"misc" help:"Specify the secret name for the secret used to verify git webhooks. The secret must exist in the controller namespace." default:"kluctl-webhook-receiver"`
+	WebhookReceiverSecretKey   string `group:"misc" help:"Specify the secret key for the secret used to verify git webhooks." default:"token"`
+
 	LeaderElect bool `group:"misc" help:"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager."`
 	Concurrency int  `group:"misc" help:"Configures how many KluctlDeployments can be be reconciled concurrently." default:"4"`
 
+	ShardKey           string `group:"misc" help:"Only reconcile KluctlDeployments that have the kluctl.io/shard-key label set to this value. This allows to distribute KluctlDeployments across multiple controller instances."`
+	WatchLabelSelector string `group:"misc" help:"Only reconcile KluctlDeployments that match this label selector. In sharded installations, the default controller should use '!kluctl.io/shard-key' to ignore KluctlDeployments handled by shards."`
+
 	DefaultServiceAccount string `group:"misc" help:"Default service account used for impersonation."`
 	DryRun                bool   `group:"misc" help:"Run all deployments in dryRun=true mode."`
 
+	AllowCrossNamespaceRefs bool `group:"misc" help:"Allow KluctlDeployments to reference Flux sources (spec.source.sourceRef) in other namespaces. This is disabled by default, as the controller reads the sources with its own permissions."`
+
+	NotificationConfig args.ExistingFileType `group:"misc" help:"Load notification providers and rules from the given yaml file. Notifications are sent on deployment success/failure, drift detection, validation failures and pruning."`
+
 	args.CommandResultFlags
 }
 
@@ -104,6 +118,23 @@ func (cmd *controllerRunCmd) Run(ctx context.Context) error {
 		restConfig.Burst = -1
 	}
 
+	shardSelector, err := controllers.BuildShardSelector(cmd.ShardKey, cmd.WatchLabelSelector)
+	if err != nil {
+		return err
+	}
+	var cacheByObject map[client.Object]cache.ByObject
+	if shardSelector != nil {
+		cacheByObject = map[client.Object]cache.ByObject{
+			&kluctlv1.KluctlDeployment{}: {Label: shardSelector},
+		}
+	}
+
+	leaderElectionID := "5ab5d0f9.kluctl.io"
+	if cmd.ShardKey != "" {
+		// each shard needs its own leader
+		leaderElectionID = fmt.Sprintf("%s.%s", cmd.ShardKey, leaderElectionID)
+	}
+
 	var cacheNamespaces map[string]cache.Config
 	if cmd.Namespace != "" {
 		cacheNamespaces = map[string]cache.Config{
@@ -122,7 +153,7 @@ func (cmd *controllerRunCmd) Run(ctx context.Context) error {
 		},
 		HealthProbeBindAddress: cmd.HealthProbeBindAddress,
 		LeaderElection:         cmd.LeaderElect,
-		LeaderElectionID:       "5ab5d0f9.kluctl.io",
+		LeaderElectionID:       leaderElectionID,
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -136,6 +167,7 @@ func (cmd *controllerRunCmd) Run(ctx context.Context) error {
 		// LeaderElectionReleaseOnCancel: true,
 		Cache: cache.Options{
 			DefaultNamespaces: cacheNamespaces,
+			ByObject:          cacheByObject,
 		},
 	})
 	if err != nil {
@@ -173,21 +205,44 @@ func (cmd *controllerRunCmd) Run(ctx context.Context) error {
 	}
 
 	r := controllers.KluctlDeploymentReconciler{
-		ControllerName:        cmd.ControllerName,
-		ControllerNamespace:   cmd.ControllerNamespace,
-		DefaultServiceAccount: cmd.DefaultServiceAccount,
-		DryRun:                cmd.DryRun,
-		UseSystemPython:       globalFlags.UseSystemPython,
-		RestConfig:            restConfig,
-		ApiReader:             mgr.GetAPIReader(),
-		Client:                mgr.GetClient(),
-		Scheme:                mgr.GetScheme(),
-		EventRecorder:         eventRecorder,
-		MetricsRecorder:       metricsRecorder,
-		SshPool:               sshPool,
+		ControllerName:          cmd.ControllerName,
+		ControllerNamespace:     cmd.ControllerNamespace,
+		DefaultServiceAccount:   cmd.DefaultServiceAccount,
+		DryRun:                  cmd.DryRun,
+		AllowCrossNamespaceRefs: cmd.AllowCrossNamespaceRefs,
+		UseSystemPython:         globalFlags.UseSystemPython,
+		RestConfig:              restConfig,
+		ApiReader:               mgr.GetAPIReader(),
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		EventRecorder:           eventRecorder,
+		MetricsRecorder:         metricsRecorder,
+		SshPool:                 sshPool,
+		ShardSelector:           shardSelector,
+	}
+
+	r.ResultRetention, err = buildResultRetention(&cmd.CommandResultWriteFlags)
+	if err != nil {
+		return err
+	}
+	r.ResultStore, err = buildResultStoreRW(ctx, restConfig, mgr.GetRESTMapper(), &cmd.CommandResultFlags, r.ResultRetention, true)
+	if err != nil {
+		return err
+	}
+
+	if cmd.WebhookReceiverBindAddress != "0" {
+		receiver := gitwebhook.NewReceiver(mgr.GetClient(), mgr.GetAPIReader(), cmd.Namespace, client.ObjectKey{
+			Namespace: cmd.ControllerNamespace,
+			Name:      cmd.WebhookReceiverSecretName,
+		}, cmd.WebhookReceiverSecretKey, cmd.WebhookReceiverBindAddress)
+		err = mgr.Add(receiver)
+		if err != nil {
+			setupLog.Error(err, "unable to add git webhook receiver")
+			os.Exit(1)
+		}
 	}
 
-	r.ResultStore, err = buildResultStoreRW(ctx, restConfig, mgr.GetRESTMapper(), &cmd.CommandResultFlags, true)
+	r.Notifier, err = cmd.buildNotifier()
 	if err != nil {
 		return err
 	}
@@ -219,6 +274,17 @@ func (cmd *controllerRunCmd) Run(ctx context.Context) error {
 	return nil
 }
 
+func (cmd *controllerRunCmd) buildNotifier() (*notifications.Notifier, error) {
+	if cmd.NotificationConfig.String() == "" {
+		return nil, nil
+	}
+	config, err := notifications.LoadConfig(cmd.NotificationConfig.String())
+	if err != nil {
+		return nil, err
+	}
+	return notifications.NewNotifier(config)
+}
+
 // taken from clientcmd
 func (cmd *controllerRunCmd) loadConfig(kubeconfig string, context string) (config *rest.Config, configErr error) {
 	// If a flag is specified with the config location, use that
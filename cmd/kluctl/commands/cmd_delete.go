@@ -20,7 +20,9 @@ type deleteCmd struct {
 	args.HelmCredentials
 	args.RegistryCredentials
 	args.YesFlags
+	args.ConfirmationPhraseFlags
 	args.DryRunFlags
+	args.BackupFlags
 	args.OutputFormatFlags
 	args.RenderOutputDirFlags
 	args.CommandResultFlags
@@ -54,13 +56,19 @@ func (cmd *deleteCmd) Run(ctx context.Context) error {
 		commandResultFlags:   &cmd.CommandResultFlags,
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		err := checkConfirmationPhrase(ctx, cmdCtx, cmd.ConfirmationPhraseFlags, cmd.DryRun)
+		if err != nil {
+			return err
+		}
+
 		cmd2 := commands.NewDeleteCommand(cmd.Discriminator, cmdCtx.targetCtx, nil, !cmd.NoWait)
+		cmd2.BackupFile = cmd.BackupFile
 
 		result := cmd2.Run(cmdCtx.targetCtx.SharedContext.Ctx, cmdCtx.targetCtx.SharedContext.K, func(refs []k8s2.ObjectRef) error {
 			return confirmDeletion(ctx, refs, cmd.DryRun, cmd.Yes)
 		})
 
-		err := outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
+		err = outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
 		if err != nil {
 			return err
 		}
@@ -21,7 +21,9 @@ type deployCmd struct {
 	args.HelmCredentials
 	args.RegistryCredentials
 	args.YesFlags
+	args.ConfirmationPhraseFlags
 	args.DryRunFlags
+	args.BackupFlags
 	args.ForceApplyFlags
 	args.ReplaceOnErrorFlags
 	args.AbortOnErrorFlags
@@ -29,10 +31,12 @@ type deployCmd struct {
 	args.OutputFormatFlags
 	args.RenderOutputDirFlags
 	args.CommandResultFlags
+	args.CheckApisFlags
 
 	DeployExtraFlags
 
-	Discriminator string `group:"misc" help:"Override the target discriminator."`
+	Discriminator       string `group:"misc" help:"Override the target discriminator."`
+	AllowDangerousPrune bool   `group:"misc" help:"Also prune orphan objects for which the prune impact analysis reported dangerous side effects, e.g. PVCs with the Delete reclaim policy or non-empty namespaces."`
 
 	internal bool
 }
@@ -65,8 +69,14 @@ func (cmd *deployCmd) Run(ctx context.Context) error {
 		commandResultFlags:   &cmd.CommandResultFlags,
 		internalDeploy:       cmd.internal,
 		discriminator:        cmd.Discriminator,
+		checkApisForVersion:  cmd.CheckApisForVersion,
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		err := checkConfirmationPhrase(ctx, cmdCtx, cmd.ConfirmationPhraseFlags, cmd.DryRun)
+		if err != nil {
+			return err
+		}
+
 		return cmd.runCmdDeploy(ctx, cmdCtx)
 	})
 }
@@ -84,6 +94,8 @@ func (cmd *deployCmd) runCmdDeploy(ctx context.Context, cmdCtx *commandCtx) erro
 	cmd2.NoWait = cmd.NoWait
 	cmd2.Prune = cmd.Prune
 	cmd2.WaitPrune = !cmd.NoWait
+	cmd2.AllowDangerousPrune = cmd.AllowDangerousPrune
+	cmd2.PruneBackupFile = cmd.BackupFile
 
 	cb := func(diffResult *result.CommandResult) error {
 		return cmd.diffResultCb(ctx, cmdCtx, diffResult)
@@ -3,8 +3,20 @@ package commands
 import (
 	"context"
 	"fmt"
+	git2 "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/kluctl/kluctl/lib/git"
+	ssh_pool "github.com/kluctl/kluctl/lib/git/ssh-pool"
+	"github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
 	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/repocache"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"path/filepath"
+	"strings"
 )
 
 type diffCmd struct {
@@ -22,15 +34,28 @@ type diffCmd struct {
 	args.IgnoreFlags
 	args.OutputFormatFlags
 	args.RenderOutputDirFlags
+	args.CheckApisFlags
 
 	Discriminator string `group:"misc" help:"Override the target discriminator."`
+	AgainstTarget string `group:"misc" help:"Instead of comparing against the target cluster, render the given target and compare against it. No cluster access is performed in this mode."`
+	GitBase       string `group:"misc" help:"Instead of comparing against the target cluster, render the project at the given git revision (e.g. 'origin/main') and compare the working tree against it. No cluster access is performed in this mode."`
 }
 
 func (cmd *diffCmd) Help() string {
 	return `The output is by default in human readable form (a table combined with unified diffs).
-The output can also be changed to output a yaml file. Please note however that the format
-is currently not documented and prone to changes.
-After the diff is performed, the command will also search for prunable objects and list them.`
+The output can also be changed to json, json-patch or markdown (e.g. for pull request comments).
+See the documentation of output formats for details.
+After the diff is performed, the command will also search for prunable objects and list them.
+
+When --against-target is used, both targets are rendered without accessing any cluster and the rendered
+objects are compared. Objects only rendered by the other target are shown as deleted objects.
+
+When --git-base is used, the given revision is resolved in the local git repository and the resulting commit
+is checked out from the origin remote, meaning that the base revision must be pushed. The selected target, or
+all targets if none is selected, is then rendered for the base revision and for the working tree, both without
+accessing any cluster. This is useful to review the manifest impact of changes, e.g. in pull requests. When
+multiple targets are diffed, the json and yaml outputs contain a list of results and the json-patch output
+contains a list of patches per target.`
 }
 
 func (cmd *diffCmd) Run(ctx context.Context) error {
@@ -46,6 +71,16 @@ func (cmd *diffCmd) Run(ctx context.Context) error {
 		registryCredentials:  cmd.RegistryCredentials,
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
 		discriminator:        cmd.Discriminator,
+		checkApisForVersion:  cmd.CheckApisForVersion,
+	}
+	if cmd.AgainstTarget != "" && cmd.GitBase != "" {
+		return fmt.Errorf("--against-target and --git-base can not be combined")
+	}
+	if cmd.AgainstTarget != "" {
+		return cmd.runAgainstTarget(ctx, ptArgs)
+	}
+	if cmd.GitBase != "" {
+		return cmd.runGitBase(ctx, ptArgs)
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
 		cmd2 := commands.NewDiffCommand(cmdCtx.targetCtx)
@@ -67,3 +102,202 @@ func (cmd *diffCmd) Run(ctx context.Context) error {
 		return nil
 	})
 }
+
+func (cmd *diffCmd) runAgainstTarget(ctx context.Context, ptArgs projectTargetCommandArgs) error {
+	ptArgs.offlineKubernetes = true
+
+	otherArgs := ptArgs
+	otherArgs.targetFlags = args.TargetFlags{}
+	otherArgs.targetFlags.Target = cmd.AgainstTarget
+	otherArgs.renderOutputDirFlags = args.RenderOutputDirFlags{}
+	otherArgs.discriminator = ""
+
+	return withKluctlProjectFromArgs(ctx, &ptArgs.kubeconfigFlags, ptArgs.projectFlags, &ptArgs.argsFlags, &ptArgs.gitCredentials, &ptArgs.helmCredentials, &ptArgs.registryCredentials, false, true, false, func(ctx context.Context, p *kluctl_project.LoadedKluctlProject) error {
+		return withProjectTargetCommandContext(ctx, otherArgs, p, func(otherCmdCtx *commandCtx) error {
+			return withProjectTargetCommandContext(ctx, ptArgs, p, func(cmdCtx *commandCtx) error {
+				cr := cmd.runDiffTargets(cmdCtx, otherCmdCtx.targetCtx)
+				err := outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, cr, false)
+				if err != nil {
+					return err
+				}
+				if len(cr.Errors) != 0 {
+					return fmt.Errorf("command failed")
+				}
+				return nil
+			})
+		})
+	})
+}
+
+func (cmd *diffCmd) runGitBase(ctx context.Context, ptArgs projectTargetCommandArgs) error {
+	ptArgs.offlineKubernetes = true
+
+	projectDir, err := cmd.ProjectDir.GetProjectDir()
+	if err != nil {
+		return err
+	}
+	repoRoot, err := git.DetectGitRepositoryRoot(projectDir)
+	if err != nil {
+		return fmt.Errorf("--git-base requires the project to be inside a git repository: %w", err)
+	}
+	relProjectDir, err := filepath.Rel(repoRoot, projectDir)
+	if err != nil {
+		return err
+	}
+
+	gitAuth, err := buildGitAuthProviders(ctx, &ptArgs.gitCredentials)
+	if err != nil {
+		return err
+	}
+	gitRp := repocache.NewGitRepoCache(ctx, &ssh_pool.SshPool{}, gitAuth, nil, cmd.GitCacheUpdateInterval)
+	defer gitRp.Clear()
+
+	baseDir, err := checkoutGitBase(ctx, gitRp, repoRoot, cmd.GitBase)
+	if err != nil {
+		return err
+	}
+
+	baseArgs := ptArgs
+	baseArgs.projectFlags.ProjectDir.ProjectDir = args.ExistingDirType(filepath.Join(baseDir, relProjectDir))
+	baseArgs.renderOutputDirFlags = args.RenderOutputDirFlags{}
+	if cmd.ProjectConfig != "" {
+		// a project config from inside the repository must also be taken from the base revision
+		projectConfig, err := filepath.Abs(cmd.ProjectConfig.String())
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(repoRoot, projectConfig)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			baseArgs.projectFlags.ProjectConfig = args.ExistingFileType(filepath.Join(baseDir, rel))
+		}
+	}
+
+	var results []*result.CommandResult
+	err = withKluctlProjectFromArgs(ctx, &baseArgs.kubeconfigFlags, baseArgs.projectFlags, &baseArgs.argsFlags, &baseArgs.gitCredentials, &baseArgs.helmCredentials, &baseArgs.registryCredentials, false, true, false, func(ctx context.Context, baseP *kluctl_project.LoadedKluctlProject) error {
+		return withKluctlProjectFromArgs(ctx, &ptArgs.kubeconfigFlags, ptArgs.projectFlags, &ptArgs.argsFlags, &ptArgs.gitCredentials, &ptArgs.helmCredentials, &ptArgs.registryCredentials, false, true, false, func(ctx context.Context, p *kluctl_project.LoadedKluctlProject) error {
+			for _, t := range baseP.Targets {
+				if ptArgs.targetFlags.Target == "" && !hasTarget(p, t.Name) {
+					status.Warningf(ctx, "Target %s only exists at %s and is not diffed", t.Name, cmd.GitBase)
+				}
+			}
+
+			for _, targetName := range selectDiffTargets(p, ptArgs.targetFlags.Target) {
+				targetArgs := ptArgs
+				targetArgs.targetFlags.Target = targetName
+				baseTargetArgs := baseArgs
+				baseTargetArgs.targetFlags.Target = targetName
+
+				cb := func(cmdCtx *commandCtx, baseCmdCtx *commandCtx) error {
+					var baseTargetCtx *target_context.TargetContext
+					if baseCmdCtx != nil {
+						baseTargetCtx = baseCmdCtx.targetCtx
+					}
+					cr := cmd.runDiffTargets(cmdCtx, baseTargetCtx)
+					cr.Id = cmdCtx.resultId
+					results = append(results, cr)
+					return nil
+				}
+
+				var err error
+				if !hasTarget(baseP, targetName) {
+					status.Warningf(ctx, "Target %s does not exist at %s, all objects are shown as new", targetName, cmd.GitBase)
+					err = withProjectTargetCommandContext(ctx, targetArgs, p, func(cmdCtx *commandCtx) error {
+						return cb(cmdCtx, nil)
+					})
+				} else {
+					err = withProjectTargetCommandContext(ctx, baseTargetArgs, baseP, func(baseCmdCtx *commandCtx) error {
+						return withProjectTargetCommandContext(ctx, targetArgs, p, func(cmdCtx *commandCtx) error {
+							return cb(cmdCtx, baseCmdCtx)
+						})
+					})
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = outputCommandResults(ctx, cmd.OutputFormatFlags, results)
+	if err != nil {
+		return err
+	}
+	for _, cr := range results {
+		if len(cr.Errors) != 0 {
+			return fmt.Errorf("command failed")
+		}
+	}
+	return nil
+}
+
+// checkoutGitBase resolves the given revision in the local repository and then checks out the resolved commit from
+// the origin remote via the git cache. This means that the base revision must be pushed to origin.
+func checkoutGitBase(ctx context.Context, gitRp *repocache.GitRepoCache, repoRoot string, revision string) (string, error) {
+	r, err := git2.PlainOpen(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	h, err := r.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+	}
+	remote, err := r.Remote("origin")
+	if err != nil {
+		return "", fmt.Errorf("--git-base requires the git repository to have an origin remote: %w", err)
+	}
+	if len(remote.Config().URLs) == 0 {
+		return "", fmt.Errorf("origin remote has no url")
+	}
+	originUrl := remote.Config().URLs[0]
+
+	s := status.Startf(ctx, "Checking out %s (%s) from %s", revision, h.String(), originUrl)
+	defer s.Failed()
+
+	e, err := gitRp.GetEntry(originUrl)
+	if err != nil {
+		s.FailedWithMessage(err.Error())
+		return "", err
+	}
+	dir, _, err := e.GetClonedDir(&types.GitRef{Commit: h.String()})
+	if err != nil {
+		err = fmt.Errorf("failed to check out %s (%s) from %s, make sure that it has been pushed: %w", revision, h.String(), originUrl, err)
+		s.FailedWithMessage(err.Error())
+		return "", err
+	}
+	s.Success()
+	return dir, nil
+}
+
+func hasTarget(p *kluctl_project.LoadedKluctlProject, targetName string) bool {
+	if targetName == "" {
+		return p.NoNameTarget != nil
+	}
+	_, err := p.FindTarget(targetName)
+	return err == nil
+}
+
+// selectDiffTargets returns the explicitly selected target or all targets of the project. Projects without targets
+// are diffed once without a target name.
+func selectDiffTargets(p *kluctl_project.LoadedKluctlProject, targetName string) []string {
+	if targetName != "" || len(p.Targets) == 0 {
+		return []string{targetName}
+	}
+	var ret []string
+	for _, t := range p.Targets {
+		ret = append(ret, t.Name)
+	}
+	return ret
+}
+
+func (cmd *diffCmd) runDiffTargets(cmdCtx *commandCtx, otherTargetCtx *target_context.TargetContext) *result.CommandResult {
+	cmd2 := commands.NewDiffTargetsCommand(cmdCtx.targetCtx, otherTargetCtx)
+	cmd2.IgnoreTags = cmd.IgnoreTags
+	cmd2.IgnoreLabels = cmd.IgnoreLabels
+	cmd2.IgnoreAnnotations = cmd.IgnoreAnnotations
+	cmd2.IgnoreKluctlMetadata = cmd.IgnoreKluctlMetadata
+	return cmd2.Run()
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kluctl/kluctl/lib/git/auth"
+	ssh_pool "github.com/kluctl/kluctl/lib/git/ssh-pool"
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test-utils"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
+	"github.com/kluctl/kluctl/v2/pkg/repocache"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckoutGitBase(t *testing.T) {
+	gs := test_utils.NewTestGitServer(t)
+	gs.GitInit("repo")
+	gs.CommitYaml("repo", "a.yaml", "base", map[string]any{"a": "base"})
+	gs.UpdateYaml("repo", "a.yaml", func(o map[string]any) error {
+		o["a"] = "head"
+		return nil
+	}, "head")
+
+	ctx := utils.WithTmpBaseDir(context.Background(), t.TempDir())
+	ctx = utils.WithCacheDir(ctx, t.TempDir())
+	gitRp := repocache.NewGitRepoCache(ctx, &ssh_pool.SshPool{}, auth.NewDefaultAuthProviders("KLUCTL_GIT", nil), nil, 0)
+	defer gitRp.Clear()
+
+	dir, err := checkoutGitBase(ctx, gitRp, gs.LocalWorkDir("repo"), "HEAD~1")
+	assert.NoError(t, err)
+	b, err := os.ReadFile(filepath.Join(dir, "a.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a: base\n", string(b))
+
+	_, err = checkoutGitBase(ctx, gitRp, gs.LocalWorkDir("repo"), "does-not-exist")
+	assert.ErrorContains(t, err, "failed to resolve revision does-not-exist")
+}
+
+func TestSelectDiffTargets(t *testing.T) {
+	p := &kluctl_project.LoadedKluctlProject{
+		Targets: []*types.Target{{Name: "a"}, {Name: "b"}},
+	}
+	assert.Equal(t, []string{"a", "b"}, selectDiffTargets(p, ""))
+	assert.Equal(t, []string{"b"}, selectDiffTargets(p, "b"))
+	assert.True(t, hasTarget(p, "a"))
+	assert.False(t, hasTarget(p, "c"))
+	assert.False(t, hasTarget(p, ""))
+
+	p = &kluctl_project.LoadedKluctlProject{
+		NoNameTarget: &types.Target{},
+	}
+	assert.Equal(t, []string{""}, selectDiffTargets(p, ""))
+	assert.True(t, hasTarget(p, ""))
+}
@@ -30,7 +30,6 @@ import (
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
-	"math/rand"
 	"net/url"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"strconv"
@@ -47,12 +46,14 @@ type gitopsCmd struct {
 	Prune     gitopsPruneCmd     `cmd:"" help:"Trigger a GitOps prune"`
 	Validate  gitopsValidateCmd  `cmd:"" help:"Trigger a GitOps validate"`
 	Logs      gitopsLogsCmd      `cmd:"" help:"Show logs from controller"`
+	Status    gitopsStatusCmd    `cmd:"" help:"Show the status of GitOps deployments"`
 }
 
 type gitopsCmdHelper struct {
-	args            args.GitOpsArgs
-	logsArgs        args.GitOpsLogArgs
-	overridableArgs args.GitOpsOverridableArgs
+	args             args.GitOpsArgs
+	logsArgs         args.GitOpsLogArgs
+	overridableArgs  args.GitOpsOverridableArgs
+	deployWindowArgs args.GitOpsDeployWindowArgs
 
 	noArgsReact noArgsReact
 
@@ -276,6 +277,12 @@ func (g *gitopsCmdHelper) autoDetectDeployment(ctx context.Context) error {
 			isGit = true
 			u = *kd.Spec.Source.URL
 			subDir = kd.Spec.Source.Path
+		} else if kd.Spec.Source.SourceRef != nil {
+			// the repo key of Flux sources is only known to the controller
+			if kd.Status.ProjectKey != nil && *kd.Status.ProjectKey == *g.projectKey {
+				matching = append(matching, kd)
+			}
+			continue
 		}
 		var repoKey gittypes.RepoKey
 		if isGit {
@@ -430,7 +437,8 @@ func (g *gitopsCmdHelper) patchManualRequest(ctx context.Context, key client.Obj
 		}
 
 		mr := v1beta1.ManualRequest{
-			RequestValue: value,
+			RequestValue:         value,
+			OverrideDeployWindow: g.deployWindowArgs.OverrideDeployWindow,
 		}
 		if len(overridePatch) != 0 && !bytes.Equal(overridePatch, []byte("{}")) {
 			mr.OverridesPatch = &runtime.RawExtension{Raw: overridePatch}
@@ -821,14 +829,14 @@ func (g *gitopsCmdHelper) initSourceOverrides(ctx context.Context) error {
 	}
 
 	pods, err := g.corev1Client.Pods(g.args.ControllerNamespace).List(ctx, metav1.ListOptions{
-		LabelSelector: "control-plane=kluctl-controller",
+		LabelSelector: logs.ControllerPodsLabelSelector,
 	})
 	if err != nil {
 		return err
 	}
-	var pod *v12.Pod
-	if len(pods.Items) > 0 {
-		pod = &pods.Items[rand.Int()%len(pods.Items)]
+	pod, err := selectSourceOverridePod(pods.Items, g.kds)
+	if err != nil {
+		return err
 	}
 
 	soClient, err := sourceoverride.NewClientCli(ctx, g.client, g.args.ControllerNamespace, g.soResolver)
@@ -867,6 +875,39 @@ func (g *gitopsCmdHelper) initSourceOverrides(ctx context.Context) error {
 	return nil
 }
 
+// selectSourceOverridePod returns the controller pod responsible for the given KluctlDeployments, which is the pod
+// of the shard with a matching kluctl.io/shard-key label or the default controller for KluctlDeployments without the
+// label. Source overrides are only available from the controller that actually reconciles the KluctlDeployments.
+func selectSourceOverridePod(pods []v12.Pod, kds []v1beta1.KluctlDeployment) (*v12.Pod, error) {
+	shardKey := ""
+	for i, kd := range kds {
+		k := kd.GetLabels()[v1beta1.KluctlDeploymentShardKeyLabel]
+		if i != 0 && k != shardKey {
+			return nil, fmt.Errorf("source overrides can not be used for KluctlDeployments of different controller shards")
+		}
+		shardKey = k
+	}
+
+	var ret *v12.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if shardKey != "" {
+			if pod.GetLabels()[v1beta1.KluctlDeploymentShardKeyLabel] != shardKey {
+				continue
+			}
+		} else if pod.GetLabels()["control-plane"] != "kluctl-controller" {
+			continue
+		}
+		if pod.Status.Phase != v12.PodRunning {
+			continue
+		}
+		if ret == nil || pod.Name < ret.Name {
+			ret = pod
+		}
+	}
+	return ret, nil
+}
+
 func (g *gitopsCmdHelper) checkCRDSupport(ctx context.Context) error {
 	var crd apiextensionsv1.CustomResourceDefinition
 	err := g.client.Get(ctx, client.ObjectKey{Name: "kluctldeployments.gitops.kluctl.io"}, &crd)
@@ -15,6 +15,7 @@ type gitopsDeployCmd struct {
 	args.OutputFormatFlags
 	args.GitOpsLogArgs
 	args.GitOpsOverridableArgs `groupOverride:"override"`
+	args.GitOpsDeployWindowArgs
 
 	DeployExtraFlags `groupOverride:"override"`
 }
@@ -28,10 +29,11 @@ You can override many deployment relevant fields, see the list of command flags
 
 func (cmd *gitopsDeployCmd) Run(ctx context.Context) error {
 	g := gitopsCmdHelper{
-		args:            cmd.GitOpsArgs,
-		logsArgs:        cmd.GitOpsLogArgs,
-		overridableArgs: cmd.GitOpsOverridableArgs,
-		noArgsReact:     noArgsAutoDetectProjectAsk,
+		args:             cmd.GitOpsArgs,
+		logsArgs:         cmd.GitOpsLogArgs,
+		overridableArgs:  cmd.GitOpsOverridableArgs,
+		deployWindowArgs: cmd.GitOpsDeployWindowArgs,
+		noArgsReact:      noArgsAutoDetectProjectAsk,
 	}
 	err := g.init(ctx)
 	if err != nil {
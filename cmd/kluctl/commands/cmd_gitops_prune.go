@@ -15,6 +15,7 @@ type gitopsPruneCmd struct {
 	args.GitOpsLogArgs
 	args.OutputFormatFlags
 	args.GitOpsOverridableArgs
+	args.GitOpsDeployWindowArgs
 }
 
 func (cmd *gitopsPruneCmd) Help() string {
@@ -26,10 +27,11 @@ You can override many deployment relevant fields, see the list of command flags
 
 func (cmd *gitopsPruneCmd) Run(ctx context.Context) error {
 	g := gitopsCmdHelper{
-		args:            cmd.GitOpsArgs,
-		logsArgs:        cmd.GitOpsLogArgs,
-		overridableArgs: cmd.GitOpsOverridableArgs,
-		noArgsReact:     noArgsAutoDetectProjectAsk,
+		args:             cmd.GitOpsArgs,
+		logsArgs:         cmd.GitOpsLogArgs,
+		overridableArgs:  cmd.GitOpsOverridableArgs,
+		deployWindowArgs: cmd.GitOpsDeployWindowArgs,
+		noArgsReact:      noArgsAutoDetectProjectAsk,
 	}
 	err := g.init(ctx)
 	if err != nil {
@@ -13,6 +13,7 @@ type gitopsReconcileCmd struct {
 	args.GitOpsArgs
 	args.GitOpsLogArgs
 	args.GitOpsOverridableArgs
+	args.GitOpsDeployWindowArgs
 
 	DeployExtraFlags `groupOverride:"override"`
 }
@@ -26,10 +27,11 @@ You can override many deployment relevant fields, see the list of command flags
 
 func (cmd *gitopsReconcileCmd) Run(ctx context.Context) error {
 	g := gitopsCmdHelper{
-		args:            cmd.GitOpsArgs,
-		logsArgs:        cmd.GitOpsLogArgs,
-		overridableArgs: cmd.GitOpsOverridableArgs,
-		noArgsReact:     noArgsAutoDetectProjectAsk,
+		args:             cmd.GitOpsArgs,
+		logsArgs:         cmd.GitOpsLogArgs,
+		overridableArgs:  cmd.GitOpsOverridableArgs,
+		deployWindowArgs: cmd.GitOpsDeployWindowArgs,
+		noArgsReact:      noArgsAutoDetectProjectAsk,
 	}
 	err := g.init(ctx)
 	if err != nil {
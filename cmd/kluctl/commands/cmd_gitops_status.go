@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/meta"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type gitopsStatusCmd struct {
+	args.GitOpsArgs
+
+	OutputFormat []string `group:"misc" short:"o" help:"Specify output format and target file, in the format 'format=path'. Format can be 'text', 'yaml' or 'json'. Can be specified multiple times."`
+}
+
+func (cmd *gitopsStatusCmd) Help() string {
+	return `This command shows the status of KluctlDeployments, including the status of each deployment item.
+The deployment item status is derived from the last deploy and validate results of the controller.
+
+If neither --name nor --label-selector is given, all KluctlDeployments are shown, restricted to the given
+--namespace if specified.`
+}
+
+type gitopsStatusEntry struct {
+	Name            string                         `json:"name"`
+	Namespace       string                         `json:"namespace"`
+	Ready           string                         `json:"ready"`
+	Message         string                         `json:"message,omitempty"`
+	Drift           string                         `json:"drift,omitempty"`
+	DeploymentItems []v1beta1.DeploymentItemStatus `json:"deploymentItems,omitempty"`
+	Truncated       bool                           `json:"truncated,omitempty"`
+}
+
+func (cmd *gitopsStatusCmd) Run(ctx context.Context) error {
+	g := gitopsCmdHelper{
+		args:        cmd.GitOpsArgs,
+		noArgsReact: noArgsAllDeployments,
+	}
+	err := g.init(ctx)
+	if err != nil {
+		return err
+	}
+
+	var l []gitopsStatusEntry
+	for _, kd := range g.kds {
+		e := gitopsStatusEntry{
+			Name:            kd.Name,
+			Namespace:       kd.Namespace,
+			Ready:           "Unknown",
+			Drift:           kd.Status.LastDriftDetectionResultMessage,
+			DeploymentItems: kd.Status.DeploymentItems,
+			Truncated:       kd.Status.DeploymentItemsTruncated,
+		}
+		if c := apimeta.FindStatusCondition(kd.Status.Conditions, meta.ReadyCondition); c != nil {
+			e.Ready = string(c.Status)
+			e.Message = c.Message
+		}
+		l = append(l, e)
+	}
+
+	return outputHelper(ctx, cmd.OutputFormat, func(format string) (string, error) {
+		if format == "text" {
+			return formatGitopsStatusText(l), nil
+		}
+		return formatResultSummaries(l, format)
+	})
+}
+
+func formatGitopsStatusText(l []gitopsStatusEntry) string {
+	buf := bytes.NewBuffer(nil)
+	for i, e := range l {
+		if i != 0 {
+			buf.WriteString("\n")
+		}
+		_, _ = fmt.Fprintf(buf, "%s/%s: Ready=%s", e.Namespace, e.Name, e.Ready)
+		if e.Message != "" {
+			_, _ = fmt.Fprintf(buf, ", %s", e.Message)
+		}
+		buf.WriteString("\n")
+		if e.Drift != "" {
+			_, _ = fmt.Fprintf(buf, "Drift: %s\n", e.Drift)
+		}
+		if len(e.DeploymentItems) == 0 {
+			continue
+		}
+		buf.WriteString("\n")
+
+		w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "PATH\tTAGS\tAPPLIED\tCHANGED\tERRORS\tWARNINGS\tREADY\tLAST CHANGE\n")
+		for _, item := range e.DeploymentItems {
+			ready := "-"
+			if item.Ready != nil {
+				ready = fmt.Sprintf("%t", *item.Ready)
+			}
+			lastChange := "-"
+			if item.LastChangeTime != nil {
+				lastChange = item.LastChangeTime.Format(time.RFC3339)
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%s\t%s\n",
+				item.Path, strings.Join(item.Tags, ","), item.AppliedObjects, item.ChangedObjects,
+				item.Errors, item.Warnings, ready, lastChange)
+		}
+		_ = w.Flush()
+		if e.Truncated {
+			buf.WriteString("(only items with problems and the first items are shown)\n")
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	v12 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestSelectSourceOverridePod(t *testing.T) {
+	buildPod := func(name string, controlPlane string, shardKey string, phase v12.PodPhase) v12.Pod {
+		labels := map[string]string{"control-plane": controlPlane}
+		if shardKey != "" {
+			labels[v1beta1.KluctlDeploymentShardKeyLabel] = shardKey
+		}
+		return v12.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status:     v12.PodStatus{Phase: phase},
+		}
+	}
+	buildKd := func(name string, shardKey string) v1beta1.KluctlDeployment {
+		kd := v1beta1.KluctlDeployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		if shardKey != "" {
+			kd.Labels = map[string]string{v1beta1.KluctlDeploymentShardKeyLabel: shardKey}
+		}
+		return kd
+	}
+
+	pods := []v12.Pod{
+		buildPod("shard-a-2", "kluctl-controller-shard", "a", v12.PodRunning),
+		buildPod("shard-a-1", "kluctl-controller-shard", "a", v12.PodRunning),
+		buildPod("shard-b-1", "kluctl-controller-shard", "b", v12.PodPending),
+		buildPod("default-2", "kluctl-controller", "", v12.PodRunning),
+		buildPod("default-1", "kluctl-controller", "", v12.PodFailed),
+	}
+
+	podName := func(pod *v12.Pod) string {
+		if pod == nil {
+			return ""
+		}
+		return pod.Name
+	}
+
+	pod, err := selectSourceOverridePod(pods, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "default-2", podName(pod))
+
+	pod, err = selectSourceOverridePod(pods, []v1beta1.KluctlDeployment{buildKd("kd1", ""), buildKd("kd2", "")})
+	assert.NoError(t, err)
+	assert.Equal(t, "default-2", podName(pod))
+
+	pod, err = selectSourceOverridePod(pods, []v1beta1.KluctlDeployment{buildKd("kd1", "a")})
+	assert.NoError(t, err)
+	assert.Equal(t, "shard-a-1", podName(pod))
+
+	// the only pod of shard b is not running
+	pod, err = selectSourceOverridePod(pods, []v1beta1.KluctlDeployment{buildKd("kd1", "b")})
+	assert.NoError(t, err)
+	assert.Nil(t, pod)
+
+	pod, err = selectSourceOverridePod(pods, []v1beta1.KluctlDeployment{buildKd("kd1", "unknown")})
+	assert.NoError(t, err)
+	assert.Nil(t, pod)
+
+	_, err = selectSourceOverridePod(pods, []v1beta1.KluctlDeployment{buildKd("kd1", "a"), buildKd("kd2", "")})
+	assert.ErrorContains(t, err, "different controller shards")
+}
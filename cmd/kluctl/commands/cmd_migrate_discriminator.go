@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	"github.com/kluctl/kluctl/v2/pkg/prompts"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+)
+
+type migrateDiscriminatorCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.ImageFlags
+	args.GitCredentials
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.YesFlags
+	args.ConfirmationPhraseFlags
+	args.DryRunFlags
+	args.OutputFormatFlags
+	args.RenderOutputDirFlags
+	args.CommandResultFlags
+
+	From string `group:"misc" help:"The old discriminator. All objects carrying this discriminator are relabelled."`
+	To   string `group:"misc" help:"The new discriminator. Defaults to the discriminator of the target."`
+}
+
+func (cmd *migrateDiscriminatorCmd) Help() string {
+	return `Changing the discriminator of a target causes all already deployed objects to be
+treated as orphans, as these still carry the old discriminator. This command searches
+the cluster for all objects carrying the old discriminator and relabels them with the
+new discriminator.
+
+Each object is modified with a JSON patch that first tests for the old discriminator,
+so objects that were modified in-between are not touched.`
+}
+
+func (cmd *migrateDiscriminatorCmd) Run(ctx context.Context) error {
+	if cmd.From == "" {
+		return fmt.Errorf("--from is required")
+	}
+
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:         cmd.ProjectFlags,
+		kubeconfigFlags:      cmd.KubeconfigFlags,
+		targetFlags:          cmd.TargetFlags,
+		argsFlags:            cmd.ArgsFlags,
+		imageFlags:           cmd.ImageFlags,
+		gitCredentials:       cmd.GitCredentials,
+		helmCredentials:      cmd.HelmCredentials,
+		registryCredentials:  cmd.RegistryCredentials,
+		dryRunArgs:           &cmd.DryRunFlags,
+		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		commandResultFlags:   &cmd.CommandResultFlags,
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		err := checkConfirmationPhrase(ctx, cmdCtx, cmd.ConfirmationPhraseFlags, cmd.DryRun)
+		if err != nil {
+			return err
+		}
+
+		to := cmd.To
+		if to == "" {
+			to = cmdCtx.targetCtx.Target.Discriminator
+		}
+
+		cmd2 := commands.NewMigrateDiscriminatorCommand(cmdCtx.targetCtx, cmd.From, to)
+		result := cmd2.Run(func(refs []k8s2.ObjectRef) error {
+			_, _ = getStderr(ctx).WriteString(fmt.Sprintf("The following objects will be relabelled from discriminator '%s' to '%s':\n", cmd.From, to))
+			for _, ref := range refs {
+				_, _ = getStderr(ctx).WriteString(fmt.Sprintf("  %s\n", ref.String()))
+			}
+			if cmd.Yes || cmd.DryRun {
+				return nil
+			}
+			if !prompts.AskForConfirmation(ctx, fmt.Sprintf("Do you really want to relabel %d objects?", len(refs))) {
+				return fmt.Errorf("aborted")
+			}
+			return nil
+		})
+
+		err = outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
+		if err != nil {
+			return err
+		}
+		if len(result.Errors) != 0 {
+			return fmt.Errorf("command failed")
+		}
+		return nil
+	})
+}
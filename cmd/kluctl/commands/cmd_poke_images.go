@@ -19,6 +19,7 @@ type pokeImagesCmd struct {
 	args.HelmCredentials
 	args.RegistryCredentials
 	args.YesFlags
+	args.ConfirmationPhraseFlags
 	args.DryRunFlags
 	args.OutputFormatFlags
 	args.RenderOutputDirFlags
@@ -47,6 +48,11 @@ func (cmd *pokeImagesCmd) Run(ctx context.Context) error {
 		commandResultFlags:   &cmd.CommandResultFlags,
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		err := checkConfirmationPhrase(ctx, cmdCtx, cmd.ConfirmationPhraseFlags, cmd.DryRun)
+		if err != nil {
+			return err
+		}
+
 		if !cmd.Yes && !cmd.DryRun {
 			if !prompts.AskForConfirmation(ctx, fmt.Sprintf("Do you really want to poke images to the context/cluster %s?", cmdCtx.targetCtx.ClusterContext)) {
 				return fmt.Errorf("aborted")
@@ -56,7 +62,7 @@ func (cmd *pokeImagesCmd) Run(ctx context.Context) error {
 		cmd2 := commands.NewPokeImagesCommand(cmdCtx.targetCtx)
 
 		result := cmd2.Run()
-		err := outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
+		err = outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
 		if err != nil {
 			return err
 		}
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
 	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	"github.com/kluctl/kluctl/v2/pkg/prompts"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"time"
 )
 
 type pruneCmd struct {
@@ -19,12 +22,15 @@ type pruneCmd struct {
 	args.HelmCredentials
 	args.RegistryCredentials
 	args.YesFlags
+	args.ConfirmationPhraseFlags
 	args.DryRunFlags
+	args.BackupFlags
 	args.OutputFormatFlags
 	args.RenderOutputDirFlags
 	args.CommandResultFlags
 
-	Discriminator string `group:"misc" help:"Override the target discriminator."`
+	Discriminator       string `group:"misc" help:"Override the target discriminator."`
+	AllowDangerousPrune bool   `group:"misc" help:"Also prune orphan objects for which the prune impact analysis reported dangerous side effects, e.g. PVCs with the Delete reclaim policy or non-empty namespaces."`
 }
 
 func (cmd *pruneCmd) Help() string {
@@ -32,7 +38,14 @@ func (cmd *pruneCmd) Help() string {
 
   1. Search the cluster for all objects match 'commonLabels', as configured in 'deployment.yaml'
   2. Render the local target and list all objects.
-  3. Remove all objects from the list of 1. that are part of the list in 2.`
+  3. Remove all objects from the list of 1. that are part of the list in 2.
+
+Before deleting, the impact of deleting each orphan object is analyzed. PVCs with the Delete
+reclaim policy, namespaces with objects not managed by this target, CRDs with existing
+custom resources and objects that own other objects are considered dangerous. These are
+reported as warnings and pruned anyway, unless the 'dangerousPrune' target policy is set
+to 'fail' or 'skip', in which case they are only pruned when '--allow-dangerous-prune' is
+passed.`
 }
 
 func (cmd *pruneCmd) Run(ctx context.Context) error {
@@ -52,14 +65,22 @@ func (cmd *pruneCmd) Run(ctx context.Context) error {
 		discriminator:        cmd.Discriminator,
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		err := checkConfirmationPhrase(ctx, cmdCtx, cmd.ConfirmationPhraseFlags, cmd.DryRun)
+		if err != nil {
+			return err
+		}
+
 		return cmd.runCmdPrune(ctx, cmdCtx)
 	})
 }
 
 func (cmd *pruneCmd) runCmdPrune(ctx context.Context, cmdCtx *commandCtx) error {
 	cmd2 := commands.NewPruneCommand(cmdCtx.targetCtx.Target.Discriminator, cmdCtx.targetCtx, true)
-	result := cmd2.Run(func(refs []k8s2.ObjectRef) error {
-		return confirmDeletion(ctx, refs, cmd.DryRun, cmd.Yes)
+	cmd2.AllowDangerousPrune = cmd.AllowDangerousPrune
+	cmd2.ResultStore = cmdCtx.resultStore
+	cmd2.BackupFile = cmd.BackupFile
+	result := cmd2.Run(func(report *commands.PruneReport) error {
+		return confirmPrune(ctx, report, cmd.DryRun, cmd.Yes)
 	})
 	err := outputCommandResult(ctx, cmdCtx, cmd.OutputFormatFlags, result, !cmd.DryRun || cmd.ForceWriteCommandResult)
 	if err != nil {
@@ -70,3 +91,44 @@ func (cmd *pruneCmd) runCmdPrune(ctx context.Context, cmdCtx *commandCtx) error
 	}
 	return nil
 }
+
+func confirmPrune(ctx context.Context, report *commands.PruneReport, dryRun bool, forceYes bool) error {
+	printRefs := func(refs []k8s2.ObjectRef) {
+		for _, ref := range refs {
+			_, _ = getStderr(ctx).WriteString(fmt.Sprintf("  %s%s\n", ref.String(), formatLastRendered(report.LastRendered[ref])))
+			for _, i := range report.GetImpacts(ref) {
+				_, _ = getStderr(ctx).WriteString(fmt.Sprintf("    DANGER: %s\n", i.Message))
+			}
+		}
+	}
+
+	if len(report.Skipped) != 0 {
+		_, _ = getStderr(ctx).WriteString("The following objects will NOT be deleted due to dangerous prune impacts (pass --allow-dangerous-prune to delete them):\n")
+		printRefs(report.Skipped)
+	}
+	if len(report.Orphans) != 0 {
+		_, _ = getStderr(ctx).WriteString("The following objects will be deleted:\n")
+		printRefs(report.Orphans)
+		if !forceYes && !dryRun {
+			if !prompts.AskForConfirmation(ctx, fmt.Sprintf("Do you really want to delete %d objects?", len(report.Orphans))) {
+				return fmt.Errorf("aborted")
+			}
+		}
+	}
+	return nil
+}
+
+func formatLastRendered(s *result.CommandResultSummary) string {
+	if s == nil {
+		return ""
+	}
+	ret := fmt.Sprintf(" (last rendered by %s %s at %s", s.Command.Command, s.Id, s.Command.StartTime.Format(time.RFC3339))
+	if s.GitInfo.Commit != "" {
+		commit := s.GitInfo.Commit
+		if len(commit) > 8 {
+			commit = commit[:8]
+		}
+		ret += fmt.Sprintf(", commit %s", commit)
+	}
+	return ret + ")"
+}
@@ -2,9 +2,13 @@ package commands
 
 import (
 	"context"
+	"fmt"
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/lib/yaml"
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"io/ioutil"
 	"os"
@@ -22,13 +26,22 @@ type renderCmd struct {
 	args.RegistryCredentials
 	args.RenderOutputDirFlags
 	args.OfflineKubernetesFlags
+	args.SchemaValidationFlags
+	args.CheckApisFlags
 
-	PrintAll bool `group:"misc" help:"Write all rendered manifests to stdout"`
+	PrintAll       bool `group:"misc" help:"Write all rendered manifests to stdout"`
+	ValidateSchema bool `group:"misc" help:"Validate all rendered objects against the bundled Kubernetes OpenAPI schemas and the CRDs found in the rendered objects or in --schema-dir. This does not require a cluster connection."`
 }
 
 func (cmd *renderCmd) Help() string {
 	return `Renders all resources and configuration files and stores the result in either
-a temporary directory or a specified directory.`
+a temporary directory or a specified directory.
+
+When --validate-schema is passed, all rendered objects are validated against the bundled OpenAPI schemas matching
+--kubernetes-version (or the version of the target cluster). Custom resources are validated against the CRDs that are
+part of the rendered objects or found in the directories passed via --schema-dir. If no schemas are bundled for the
+Kubernetes version, the OpenAPI schemas served by the target cluster are used if a cluster is available. Otherwise, the
+OpenAPI document (swagger.json) of this version must be passed via --schema-dir.`
 }
 
 func (cmd *renderCmd) Run(ctx context.Context) error {
@@ -55,11 +68,30 @@ func (cmd *renderCmd) Run(ctx context.Context) error {
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
 		offlineKubernetes:    cmd.OfflineKubernetes,
 		kubernetesVersion:    cmd.KubernetesVersion,
+		checkApisForVersion:  cmd.CheckApisForVersion,
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		dc := cmdCtx.targetCtx.DeploymentCollection
+		printPreparationFindings(ctx, dc)
+
+		var schemaResult *result.ValidateResult
+		if cmd.ValidateSchema {
+			var err error
+			schemaResult, err = commands.NewValidateSchemasCommand(cmdCtx.targetCtx, cmd.SchemaDir).Run(ctx)
+			if err != nil {
+				return err
+			}
+			for _, e := range schemaResult.Warnings {
+				status.Warningf(ctx, "%s: %s", e.Ref.String(), e.Message)
+			}
+			for _, e := range schemaResult.Errors {
+				status.Errorf(ctx, "%s: %s", e.Ref.String(), e.Message)
+			}
+		}
+
 		if cmd.PrintAll {
 			var all []any
-			for _, d := range cmdCtx.targetCtx.DeploymentCollection.Deployments {
+			for _, d := range dc.Deployments {
 				for _, o := range d.Objects {
 					all = append(all, o)
 				}
@@ -68,10 +100,37 @@ func (cmd *renderCmd) Run(ctx context.Context) error {
 				defer os.RemoveAll(cmd.RenderOutputDir)
 			}
 			status.Flush(ctx)
-			return yaml.WriteYamlAllStream(getStdout(ctx), all)
+			err := yaml.WriteYamlAllStream(getStdout(ctx), all)
+			if err != nil {
+				return err
+			}
 		} else {
 			status.Infof(ctx, "Rendered into %s", cmdCtx.targetCtx.SharedContext.RenderDir)
 		}
+		if len(dc.PolicyErrors) != 0 {
+			return fmt.Errorf("rendered objects violate policies")
+		}
+		if len(dc.ApiErrors) != 0 {
+			return fmt.Errorf("rendered objects use APIs that are not available in the target Kubernetes version")
+		}
+		if schemaResult != nil && len(schemaResult.Errors) != 0 {
+			return fmt.Errorf("rendered objects failed schema validation")
+		}
 		return nil
 	})
 }
+
+// printPreparationFindings prints the policy violations and usages of deprecated/removed APIs found while preparing
+// the deployment collection
+func printPreparationFindings(ctx context.Context, dc *deployment.DeploymentCollection) {
+	for _, l := range [][]result.DeploymentError{dc.PolicyWarnings, dc.ApiWarnings} {
+		for _, e := range l {
+			status.Warningf(ctx, "%s: %s", e.Ref.String(), e.Message)
+		}
+	}
+	for _, l := range [][]result.DeploymentError{dc.PolicyErrors, dc.ApiErrors} {
+		for _, e := range l {
+			status.Errorf(ctx, "%s: %s", e.Ref.String(), e.Message)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/commands"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/prompts"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/spf13/cobra"
+)
+
+type restoreCmd struct {
+	args.KubeconfigFlags
+	args.CommandResultReadOnlyFlags
+	args.YesFlags
+	args.DryRunFlags
+	args.OutputFormatFlags
+
+	Context  string `group:"project" help:"Override the context to use."`
+	FromFile string `group:"misc" help:"Restore the objects from the given backup file instead of a command result. See '--backup-file' of the delete, prune and deploy commands."`
+
+	resultId string
+}
+
+func (cmd *restoreCmd) Help() string {
+	return `Re-creates objects that were deleted by 'kluctl delete' or 'kluctl prune'. The objects are
+either taken from the given command result, which must be stored in the result store, or
+from a backup file written via '--backup-file'.
+
+The status and all server-set fields (e.g. uid, resourceVersion and managedFields) are
+removed before re-creating the objects. Objects that already exist are skipped.
+
+Please note that Secrets are obfuscated in command results by default, meaning that
+they can only be restored from backup files or from command results that were written
+with '--no-obfuscate'.`
+}
+
+func (cmd *restoreCmd) PositionalArgs() (string, cobra.PositionalArgs) {
+	return "[<result-id>]", cobra.MaximumNArgs(1)
+}
+
+func (cmd *restoreCmd) SetPositionalArgs(args []string) {
+	if len(args) != 0 {
+		cmd.resultId = args[0]
+	}
+}
+
+func (cmd *restoreCmd) Run(ctx context.Context) error {
+	if (cmd.resultId == "") == (cmd.FromFile == "") {
+		return fmt.Errorf("either a result id or --from-file must be specified")
+	}
+
+	var contextOverride *string
+	if cmd.Context != "" {
+		contextOverride = &cmd.Context
+	}
+	restConfig, _, err := clientConfigGetter(&cmd.KubeconfigFlags, false)(contextOverride)
+	if err != nil {
+		return err
+	}
+	discovery, mapper, err := k8s.CreateDiscoveryAndMapper(ctx, restConfig)
+	if err != nil {
+		return err
+	}
+
+	s := status.Start(ctx, "Initializing k8s client")
+	k, err := k8s.NewK8sCluster(ctx, restConfig, discovery, mapper, cmd.DryRun)
+	if err != nil {
+		s.Failed()
+		return err
+	}
+	s.Success()
+
+	var objects []*uo.UnstructuredObject
+	if cmd.FromFile != "" {
+		objects, err = utils2.ReadBackupFile(cmd.FromFile)
+		if err != nil {
+			return err
+		}
+	} else {
+		store, err := buildResultStoreRO(ctx, restConfig, mapper, &cmd.CommandResultReadOnlyFlags)
+		if err != nil {
+			return err
+		}
+		cr, err := store.GetCommandResult(results.GetCommandResultOptions{Id: cmd.resultId})
+		if err != nil {
+			return err
+		}
+		if cr == nil {
+			return fmt.Errorf("command result %s not found", cmd.resultId)
+		}
+		clusterId, err := k.GetClusterId()
+		if err != nil {
+			return err
+		}
+		if cr.ClusterInfo.ClusterId != "" && cr.ClusterInfo.ClusterId != clusterId {
+			return fmt.Errorf("command result %s belongs to cluster %s, but the current cluster has the id %s", cmd.resultId, cr.ClusterInfo.ClusterId, clusterId)
+		}
+		objects = utils2.GetDeletedObjectsFromResult(cr)
+	}
+
+	if len(objects) == 0 {
+		status.Info(ctx, "No objects to restore")
+		return nil
+	}
+
+	cmd2 := commands.NewRestoreCommand(objects)
+	cr := cmd2.Run(ctx, k, func(refs []k8s2.ObjectRef) error {
+		return cmd.confirmRestore(ctx, refs)
+	})
+
+	if !cmd.NoObfuscate {
+		var obfuscator diff.Obfuscator
+		err = obfuscator.ObfuscateResult(cr)
+		if err != nil {
+			return err
+		}
+	}
+	err = outputCommandResult2(ctx, cmd.OutputFormatFlags, cr)
+	if err != nil {
+		return err
+	}
+	if len(cr.Errors) != 0 {
+		return fmt.Errorf("command failed")
+	}
+	return nil
+}
+
+func (cmd *restoreCmd) confirmRestore(ctx context.Context, refs []k8s2.ObjectRef) error {
+	_, _ = getStderr(ctx).WriteString("The following objects will be restored:\n")
+	for _, ref := range refs {
+		_, _ = getStderr(ctx).WriteString(fmt.Sprintf("  %s\n", ref.String()))
+	}
+	if cmd.Yes || cmd.DryRun {
+		return nil
+	}
+	if !prompts.AskForConfirmation(ctx, fmt.Sprintf("Do you really want to restore %d objects?", len(refs))) {
+		return fmt.Errorf("aborted")
+	}
+	return nil
+}
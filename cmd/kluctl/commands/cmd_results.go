@@ -0,0 +1,227 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"k8s.io/client-go/tools/clientcmd"
+	client2 "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type resultsCmd struct {
+	Delete resultsDeleteCmd `cmd:"" help:"Delete stored command results"`
+	Diff   resultsDiffCmd   `cmd:"" help:"Compare two command results"`
+	Export resultsExportCmd `cmd:"" help:"Export stored command results"`
+	List   resultsListCmd   `cmd:"" help:"List stored command results"`
+	Show   resultsShowCmd   `cmd:"" help:"Show a stored command result"`
+}
+
+func buildResultStoreFromFlags(ctx context.Context, flags *args.ResultStoreFlags, allowWrite bool) (results.ResultStore, error) {
+	if flags.ResultStore != "" {
+		return results.NewResultStoreFromUrl(ctx, flags.ResultStore, allowWrite, nil)
+	}
+
+	r := clientcmd.NewDefaultClientConfigLoadingRules()
+	r.ExplicitPath = flags.Kubeconfig.String()
+	configOverrides := &clientcmd.ConfigOverrides{
+		CurrentContext: flags.Context,
+	}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(r, configOverrides).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	_, mapper, err := k8s.CreateDiscoveryAndMapper(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowWrite {
+		return buildResultStoreRO(ctx, config, mapper, &flags.CommandResultReadOnlyFlags)
+	}
+
+	c, err := client2.NewWithWatch(config, client2.Options{
+		Mapper: mapper,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results.NewResultStoreSecrets(ctx, config, c, true, flags.CommandResultNamespace, nil)
+}
+
+func buildResultsListOptions(flags *args.ResultFilterFlags) (results.ListResultSummariesOptions, error) {
+	var ret results.ListResultSummariesOptions
+
+	switch flags.Status {
+	case "", "success", "warnings", "failed":
+	default:
+		return ret, fmt.Errorf("invalid status '%s', must be one of 'success', 'warnings' or 'failed'", flags.Status)
+	}
+
+	if flags.Project != "" || flags.ProjectSubdir != "" {
+		ret.ProjectFilter = &gittypes.ProjectKey{
+			SubDir: flags.ProjectSubdir,
+		}
+		if flags.Project != "" {
+			repoKey, err := gittypes.ParseRepoKey(flags.Project, "git")
+			if err != nil {
+				return ret, err
+			}
+			ret.ProjectFilter.RepoKey = repoKey
+		}
+	}
+	return ret, nil
+}
+
+func hasResultFilters(flags *args.ResultFilterFlags) bool {
+	return flags.Project != "" || flags.ProjectSubdir != "" || flags.Target != "" || flags.Command != "" || flags.Status != ""
+}
+
+func commandResultStatus(s *result.CommandResultSummary) string {
+	if len(s.Errors) != 0 {
+		return "failed"
+	} else if len(s.Warnings) != 0 {
+		return "warnings"
+	}
+	return "success"
+}
+
+func validateResultStatus(s *result.ValidateResultSummary) string {
+	if s.Errors != 0 || !s.Ready {
+		return "failed"
+	} else if s.Warnings != 0 {
+		return "warnings"
+	}
+	return "success"
+}
+
+func listFilteredCommandResults(store results.ResultStore, flags *args.ResultFilterFlags) ([]result.CommandResultSummary, error) {
+	opts, err := buildResultsListOptions(flags)
+	if err != nil {
+		return nil, err
+	}
+	l, err := store.ListCommandResultSummaries(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]result.CommandResultSummary, 0, len(l))
+	for _, s := range l {
+		if flags.Target != "" && s.TargetKey.TargetName != flags.Target {
+			continue
+		}
+		if flags.Command != "" && s.Command.Command != flags.Command {
+			continue
+		}
+		if flags.Status != "" && commandResultStatus(&s) != flags.Status {
+			continue
+		}
+		ret = append(ret, s)
+	}
+	return ret, nil
+}
+
+func listFilteredValidateResults(store results.ResultStore, flags *args.ResultFilterFlags) ([]result.ValidateResultSummary, error) {
+	opts, err := buildResultsListOptions(flags)
+	if err != nil {
+		return nil, err
+	}
+	l, err := store.ListValidateResultSummaries(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]result.ValidateResultSummary, 0, len(l))
+	for _, s := range l {
+		if flags.Target != "" && s.TargetKey.TargetName != flags.Target {
+			continue
+		}
+		if flags.Status != "" && validateResultStatus(&s) != flags.Status {
+			continue
+		}
+		ret = append(ret, s)
+	}
+	return ret, nil
+}
+
+// selectResultIds returns the ids of the command results and validate results to operate on. If explicit ids are
+// given, these are looked up in the result store to determine whether they refer to command or validate results.
+// Otherwise, the filter flags are used to select results.
+func selectResultIds(store results.ResultStore, ids []string, flags *args.ResultFilterFlags) ([]string, []string, error) {
+	var crIds, vrIds []string
+
+	if len(ids) == 0 {
+		if flags.Validate {
+			l, err := listFilteredValidateResults(store, flags)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, s := range l {
+				vrIds = append(vrIds, s.Id)
+			}
+		} else {
+			l, err := listFilteredCommandResults(store, flags)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, s := range l {
+				crIds = append(crIds, s.Id)
+			}
+		}
+		return crIds, vrIds, nil
+	}
+
+	crs, err := store.ListCommandResultSummaries(results.ListResultSummariesOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	vrs, err := store.ListValidateResultSummaries(results.ListResultSummariesOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	known := map[string]bool{}
+	for _, s := range crs {
+		known[s.Id] = false
+	}
+	for _, s := range vrs {
+		known[s.Id] = true
+	}
+
+	for _, id := range ids {
+		isValidate, ok := known[id]
+		if !ok {
+			return nil, nil, fmt.Errorf("result %s not found", id)
+		}
+		if isValidate {
+			vrIds = append(vrIds, id)
+		} else {
+			crIds = append(crIds, id)
+		}
+	}
+	return crIds, vrIds, nil
+}
+
+func formatResultProject(k gittypes.ProjectKey) string {
+	if k.RepoKey.String() == "" {
+		if k.SubDir != "" {
+			return "//" + k.SubDir
+		}
+		return "<none>"
+	}
+	s := fmt.Sprintf("%s/%s", k.RepoKey.Host, k.RepoKey.Path)
+	if k.SubDir != "" {
+		s += "//" + k.SubDir
+	}
+	return s
+}
+
+func formatResultTarget(k result.TargetKey) string {
+	if k.TargetName == "" {
+		return "<no-name>"
+	}
+	return k.TargetName
+}
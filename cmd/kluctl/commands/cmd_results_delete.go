@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/prompts"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+type resultsDeleteCmd struct {
+	args.ResultStoreFlags
+	args.ResultFilterFlags
+	args.YesFlags
+	args.DryRunFlags
+
+	resultIds []string
+}
+
+func (cmd *resultsDeleteCmd) Help() string {
+	return `Deletes command results or validate results from the result store. Results can either be selected by
+passing their ids or via the filter arguments. At least one id or filter must be specified.`
+}
+
+func (cmd *resultsDeleteCmd) PositionalArgs() (string, cobra.PositionalArgs) {
+	return "[<result-id>...]", cobra.ArbitraryArgs
+}
+
+func (cmd *resultsDeleteCmd) SetPositionalArgs(args []string) {
+	cmd.resultIds = args
+}
+
+func (cmd *resultsDeleteCmd) Run(ctx context.Context) error {
+	if len(cmd.resultIds) == 0 && !hasResultFilters(&cmd.ResultFilterFlags) {
+		return fmt.Errorf("either result ids or at least one filter must be specified")
+	}
+
+	store, err := buildResultStoreFromFlags(ctx, &cmd.ResultStoreFlags, !cmd.DryRun)
+	if err != nil {
+		return err
+	}
+
+	crIds, vrIds, err := selectResultIds(store, cmd.resultIds, &cmd.ResultFilterFlags)
+	if err != nil {
+		return err
+	}
+	if len(crIds)+len(vrIds) == 0 {
+		_, _ = getStderr(ctx).WriteString("No results matched.\n")
+		return nil
+	}
+
+	stderr := getStderr(ctx)
+	_, _ = stderr.WriteString("The following results will be deleted:\n")
+	for _, id := range crIds {
+		_, _ = stderr.WriteString(fmt.Sprintf("  %s (command result)\n", id))
+	}
+	for _, id := range vrIds {
+		_, _ = stderr.WriteString(fmt.Sprintf("  %s (validate result)\n", id))
+	}
+
+	if cmd.DryRun {
+		return nil
+	}
+	if !cmd.Yes {
+		if !prompts.AskForConfirmation(ctx, fmt.Sprintf("Do you really want to delete %d results?", len(crIds)+len(vrIds))) {
+			return fmt.Errorf("aborted")
+		}
+	}
+
+	var errs []string
+	for _, id := range crIds {
+		err = store.DeleteCommandResult(id)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", id, err.Error()))
+		}
+	}
+	for _, id := range vrIds {
+		err = store.DeleteValidateResult(id)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", id, err.Error()))
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("failed to delete results: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/spf13/cobra"
+)
+
+type resultsDiffCmd struct {
+	args.ResultStoreFlags
+	args.IgnoreFlags
+	args.OutputFormatFlags
+
+	resultIdA string
+	resultIdB string
+}
+
+func (cmd *resultsDiffCmd) Help() string {
+	return `Compares the rendered objects of two command results, which must be stored in the result store.
+The changes are shown from the point of view of the first result, meaning that objects only found in
+the second result are shown as new objects and objects only found in the first result as deleted objects.
+This does not require access to the target cluster, except for reading the result store.`
+}
+
+func (cmd *resultsDiffCmd) PositionalArgs() (string, cobra.PositionalArgs) {
+	return "<result-id-a> <result-id-b>", cobra.ExactArgs(2)
+}
+
+func (cmd *resultsDiffCmd) SetPositionalArgs(args []string) {
+	cmd.resultIdA = args[0]
+	cmd.resultIdB = args[1]
+}
+
+func (cmd *resultsDiffCmd) Run(ctx context.Context) error {
+	store, err := buildResultStoreFromFlags(ctx, &cmd.ResultStoreFlags, false)
+	if err != nil {
+		return err
+	}
+
+	getResult := func(id string) (*result.CommandResult, error) {
+		cr, err := store.GetCommandResult(results.GetCommandResultOptions{Id: id})
+		if err != nil {
+			return nil, err
+		}
+		if cr == nil {
+			return nil, fmt.Errorf("command result %s not found", id)
+		}
+		return cr, nil
+	}
+
+	a, err := getResult(cmd.resultIdA)
+	if err != nil {
+		return err
+	}
+	b, err := getResult(cmd.resultIdB)
+	if err != nil {
+		return err
+	}
+
+	ignoreForDiffs := deployment.IgnoreForDiffsFromFlags(cmd.IgnoreTags, cmd.IgnoreLabels, cmd.IgnoreAnnotations, cmd.IgnoreKluctlMetadata)
+	cr, err := results.DiffCommandResults(a, b, ignoreForDiffs)
+	if err != nil {
+		return err
+	}
+	cr.Command.Initiator = result.CommandInititiator_CommandLine
+
+	if !cmd.NoObfuscate {
+		var obfuscator diff.Obfuscator
+		err = obfuscator.ObfuscateResult(cr)
+		if err != nil {
+			return err
+		}
+	}
+
+	return outputCommandResult2(ctx, cmd.OutputFormatFlags, cr)
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+)
+
+type resultsExportCmd struct {
+	args.ResultStoreFlags
+	args.ResultFilterFlags
+
+	Format      string `group:"misc" help:"Specify the export format. Can be 'json' or 'yaml'." default:"json"`
+	OutputDir   string `group:"misc" help:"Write each exported result into '<id>.<format>' inside the given directory. Required when more than one result is exported."`
+	NoObfuscate bool   `group:"misc" help:"Disable obfuscation of sensitive/secret data"`
+
+	resultIds []string
+}
+
+func (cmd *resultsExportCmd) Help() string {
+	return `Exports the full command results or validate results, including all rendered, remote and applied objects.
+Results can either be selected by passing their ids or via the filter arguments. If exactly one result
+is selected and no output directory is specified, the result is written to stdout.`
+}
+
+func (cmd *resultsExportCmd) PositionalArgs() (string, cobra.PositionalArgs) {
+	return "[<result-id>...]", cobra.ArbitraryArgs
+}
+
+func (cmd *resultsExportCmd) SetPositionalArgs(args []string) {
+	cmd.resultIds = args
+}
+
+func (cmd *resultsExportCmd) Run(ctx context.Context) error {
+	if cmd.Format != "json" && cmd.Format != "yaml" {
+		return fmt.Errorf("invalid format: %s", cmd.Format)
+	}
+
+	store, err := buildResultStoreFromFlags(ctx, &cmd.ResultStoreFlags, false)
+	if err != nil {
+		return err
+	}
+
+	crIds, vrIds, err := selectResultIds(store, cmd.resultIds, &cmd.ResultFilterFlags)
+	if err != nil {
+		return err
+	}
+	if len(crIds)+len(vrIds) == 0 {
+		return fmt.Errorf("no results matched")
+	}
+	if cmd.OutputDir == "" && len(crIds)+len(vrIds) != 1 {
+		return fmt.Errorf("%d results matched, --output-dir is required to export more than one result", len(crIds)+len(vrIds))
+	}
+	if cmd.OutputDir != "" {
+		err = os.MkdirAll(cmd.OutputDir, 0o700)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, id := range crIds {
+		cr, err := store.GetCommandResult(results.GetCommandResultOptions{Id: id})
+		if err != nil {
+			return err
+		}
+		if cr == nil {
+			return fmt.Errorf("command result %s not found", id)
+		}
+		if !cmd.NoObfuscate {
+			var obfuscator diff.Obfuscator
+			err = obfuscator.ObfuscateResult(cr)
+			if err != nil {
+				return err
+			}
+		}
+		err = cmd.exportResult(ctx, id, cr)
+		if err != nil {
+			return err
+		}
+	}
+	for _, id := range vrIds {
+		vr, err := store.GetValidateResult(results.GetValidateResultOptions{Id: id})
+		if err != nil {
+			return err
+		}
+		if vr == nil {
+			return fmt.Errorf("validate result %s not found", id)
+		}
+		err = cmd.exportResult(ctx, id, vr)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cmd *resultsExportCmd) exportResult(ctx context.Context, id string, o any) error {
+	var s string
+	var err error
+	if cmd.Format == "yaml" {
+		s, err = yaml.WriteYamlString(o)
+	} else {
+		s, err = formatJson(o)
+	}
+	if err != nil {
+		return err
+	}
+
+	if cmd.OutputDir == "" {
+		return outputResult(ctx, nil, s)
+	}
+	p := filepath.Join(cmd.OutputDir, fmt.Sprintf("%s.%s", id, cmd.Format))
+	return outputResult(ctx, &p, s)
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"text/tabwriter"
+	"time"
+)
+
+type resultsListCmd struct {
+	args.ResultStoreFlags
+	args.ResultFilterFlags
+
+	Limit        int      `group:"misc" help:"Only show the given number of newest results. 0 means no limit."`
+	OutputFormat []string `group:"misc" short:"o" help:"Specify output format and target file, in the format 'format=path'. Format can be 'text', 'yaml' or 'json'. Can be specified multiple times."`
+}
+
+func (cmd *resultsListCmd) Help() string {
+	return `Lists the command results or validate results stored in the result store, newest results first.
+The list can be filtered by project, target, command and status. The ids shown in the list can be
+passed to 'kluctl results show', 'kluctl results export' and 'kluctl results delete'.`
+}
+
+func (cmd *resultsListCmd) Run(ctx context.Context) error {
+	store, err := buildResultStoreFromFlags(ctx, &cmd.ResultStoreFlags, false)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Validate {
+		l, err := listFilteredValidateResults(store, &cmd.ResultFilterFlags)
+		if err != nil {
+			return err
+		}
+		if cmd.Limit > 0 && len(l) > cmd.Limit {
+			l = l[:cmd.Limit]
+		}
+		return outputHelper(ctx, cmd.OutputFormat, func(format string) (string, error) {
+			if format == "text" {
+				return formatValidateResultSummariesText(l), nil
+			}
+			return formatResultSummaries(l, format)
+		})
+	}
+
+	l, err := listFilteredCommandResults(store, &cmd.ResultFilterFlags)
+	if err != nil {
+		return err
+	}
+	if cmd.Limit > 0 && len(l) > cmd.Limit {
+		l = l[:cmd.Limit]
+	}
+	return outputHelper(ctx, cmd.OutputFormat, func(format string) (string, error) {
+		if format == "text" {
+			return formatCommandResultSummariesText(l), nil
+		}
+		return formatResultSummaries(l, format)
+	})
+}
+
+func formatResultSummaries(l any, format string) (string, error) {
+	switch format {
+	case "yaml":
+		return yaml.WriteYamlString(l)
+	case "json":
+		return formatJson(l)
+	default:
+		return "", fmt.Errorf("invalid format: %s", format)
+	}
+}
+
+func formatCommandResultSummariesText(l []result.CommandResultSummary) string {
+	buf := bytes.NewBuffer(nil)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "ID\tSTART TIME\tCOMMAND\tPROJECT\tTARGET\tSTATUS\tNEW\tCHANGED\tORPHAN\tDELETED\n")
+	for _, s := range l {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\n",
+			s.Id, s.Command.StartTime.Format(time.RFC3339), s.Command.Command,
+			formatResultProject(s.ProjectKey), formatResultTarget(s.TargetKey), commandResultStatus(&s),
+			s.NewObjects, s.ChangedObjects, s.OrphanObjects, s.DeletedObjects)
+	}
+	_ = w.Flush()
+	return buf.String()
+}
+
+func formatValidateResultSummariesText(l []result.ValidateResultSummary) string {
+	buf := bytes.NewBuffer(nil)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "ID\tSTART TIME\tPROJECT\tTARGET\tSTATUS\tREADY\tERRORS\tWARNINGS\n")
+	for _, s := range l {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\t%d\t%d\n",
+			s.Id, s.StartTime.Format(time.RFC3339),
+			formatResultProject(s.ProjectKey), formatResultTarget(s.TargetKey), validateResultStatus(&s),
+			s.Ready, s.Errors, s.Warnings)
+	}
+	_ = w.Flush()
+	return buf.String()
+}
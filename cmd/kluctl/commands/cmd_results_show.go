@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+type resultsShowCmd struct {
+	args.ResultStoreFlags
+	args.OutputFormatFlags
+
+	resultId string
+}
+
+func (cmd *resultsShowCmd) Help() string {
+	return `Shows a single command result or validate result from the result store, in the same formats as
+they are shown by the command that produced the result. The result id can be found via 'kluctl results list'.`
+}
+
+func (cmd *resultsShowCmd) PositionalArgs() (string, cobra.PositionalArgs) {
+	return "<result-id>", cobra.ExactArgs(1)
+}
+
+func (cmd *resultsShowCmd) SetPositionalArgs(args []string) {
+	cmd.resultId = args[0]
+}
+
+func (cmd *resultsShowCmd) Run(ctx context.Context) error {
+	store, err := buildResultStoreFromFlags(ctx, &cmd.ResultStoreFlags, false)
+	if err != nil {
+		return err
+	}
+
+	crIds, _, err := selectResultIds(store, []string{cmd.resultId}, nil)
+	if err != nil {
+		return err
+	}
+
+	if len(crIds) == 0 {
+		vr, err := store.GetValidateResult(results.GetValidateResultOptions{Id: cmd.resultId})
+		if err != nil {
+			return err
+		}
+		if vr == nil {
+			return fmt.Errorf("validate result %s not found", cmd.resultId)
+		}
+		return outputValidateResult2(ctx, cmd.OutputFormat, vr)
+	}
+
+	cr, err := store.GetCommandResult(results.GetCommandResultOptions{Id: cmd.resultId})
+	if err != nil {
+		return err
+	}
+	if cr == nil {
+		return fmt.Errorf("command result %s not found", cmd.resultId)
+	}
+
+	if !cmd.NoObfuscate {
+		var obfuscator diff.Obfuscator
+		err = obfuscator.ObfuscateResult(cr)
+		if err != nil {
+			return err
+		}
+	}
+
+	return outputCommandResult2(ctx, cmd.OutputFormatFlags, cr)
+}
@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"context"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func buildTestResultStore(t *testing.T) results.ResultStore {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	store, err := results.NewResultStoreFile(ctx, t.TempDir(), true, nil)
+	assert.NoError(t, err)
+
+	repoKey, err := gittypes.ParseRepoKey("github.com/example/repo", "git")
+	assert.NoError(t, err)
+
+	now := time.Now()
+	crs := []*result.CommandResult{
+		{Id: "cr1", TargetKey: result.TargetKey{TargetName: "t1"}, Command: result.CommandInfo{Command: "deploy"}},
+		{Id: "cr2", TargetKey: result.TargetKey{TargetName: "t2"}, Command: result.CommandInfo{Command: "diff"},
+			Warnings: []result.DeploymentError{{Message: "w"}}},
+		{Id: "cr3", ProjectKey: gittypes.ProjectKey{RepoKey: repoKey}, TargetKey: result.TargetKey{TargetName: "t1"}, Command: result.CommandInfo{Command: "deploy"},
+			Errors: []result.DeploymentError{{Message: "e"}}},
+	}
+	for i, cr := range crs {
+		cr.Command.Initiator = result.CommandInititiator_CommandLine
+		cr.Command.StartTime = metav1.NewTime(now.Add(time.Duration(i) * time.Second))
+		cr.Command.EndTime = cr.Command.StartTime
+		assert.NoError(t, store.WriteCommandResult(cr))
+	}
+
+	vrs := []*result.ValidateResult{
+		{Id: "vr1", TargetKey: result.TargetKey{TargetName: "t1"}, Ready: true},
+		{Id: "vr2", TargetKey: result.TargetKey{TargetName: "t2"}, Ready: false},
+	}
+	for _, vr := range vrs {
+		vr.StartTime = metav1.NewTime(now)
+		vr.EndTime = vr.StartTime
+		assert.NoError(t, store.WriteValidateResult(vr))
+	}
+	return store
+}
+
+func commandResultIds(l []result.CommandResultSummary) []string {
+	var ret []string
+	for _, s := range l {
+		ret = append(ret, s.Id)
+	}
+	return ret
+}
+
+func TestBuildResultsListOptions(t *testing.T) {
+	opts, err := buildResultsListOptions(&args.ResultFilterFlags{})
+	assert.NoError(t, err)
+	assert.Nil(t, opts.ProjectFilter)
+
+	_, err = buildResultsListOptions(&args.ResultFilterFlags{Status: "unknown"})
+	assert.ErrorContains(t, err, "invalid status 'unknown'")
+
+	opts, err = buildResultsListOptions(&args.ResultFilterFlags{Project: "github.com/example/repo", ProjectSubdir: "sub"})
+	assert.NoError(t, err)
+	assert.Equal(t, "github.com", opts.ProjectFilter.RepoKey.Host)
+	assert.Equal(t, "example/repo", opts.ProjectFilter.RepoKey.Path)
+	assert.Equal(t, "sub", opts.ProjectFilter.SubDir)
+
+	opts, err = buildResultsListOptions(&args.ResultFilterFlags{ProjectSubdir: "sub"})
+	assert.NoError(t, err)
+	assert.Equal(t, "", opts.ProjectFilter.RepoKey.String())
+	assert.Equal(t, "sub", opts.ProjectFilter.SubDir)
+}
+
+func TestHasResultFilters(t *testing.T) {
+	assert.False(t, hasResultFilters(&args.ResultFilterFlags{}))
+	// --validate only selects the kind of results, it does not filter them
+	assert.False(t, hasResultFilters(&args.ResultFilterFlags{Validate: true}))
+	assert.True(t, hasResultFilters(&args.ResultFilterFlags{Target: "t"}))
+	assert.True(t, hasResultFilters(&args.ResultFilterFlags{Command: "deploy"}))
+	assert.True(t, hasResultFilters(&args.ResultFilterFlags{Status: "failed"}))
+	assert.True(t, hasResultFilters(&args.ResultFilterFlags{Project: "github.com/example/repo"}))
+	assert.True(t, hasResultFilters(&args.ResultFilterFlags{ProjectSubdir: "sub"}))
+}
+
+func TestResultStatus(t *testing.T) {
+	assert.Equal(t, "success", commandResultStatus(&result.CommandResultSummary{}))
+	assert.Equal(t, "warnings", commandResultStatus(&result.CommandResultSummary{Warnings: []result.DeploymentError{{}}}))
+	assert.Equal(t, "failed", commandResultStatus(&result.CommandResultSummary{Errors: []result.DeploymentError{{}}, Warnings: []result.DeploymentError{{}}}))
+
+	assert.Equal(t, "success", validateResultStatus(&result.ValidateResultSummary{Ready: true}))
+	assert.Equal(t, "warnings", validateResultStatus(&result.ValidateResultSummary{Ready: true, Warnings: 1}))
+	assert.Equal(t, "failed", validateResultStatus(&result.ValidateResultSummary{Ready: true, Errors: 1}))
+	assert.Equal(t, "failed", validateResultStatus(&result.ValidateResultSummary{Ready: false}))
+}
+
+func TestListFilteredResults(t *testing.T) {
+	store := buildTestResultStore(t)
+
+	l, err := listFilteredCommandResults(store, &args.ResultFilterFlags{})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"cr1", "cr2", "cr3"}, commandResultIds(l))
+
+	l, err = listFilteredCommandResults(store, &args.ResultFilterFlags{Target: "t1"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"cr1", "cr3"}, commandResultIds(l))
+
+	l, err = listFilteredCommandResults(store, &args.ResultFilterFlags{Command: "diff"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"cr2"}, commandResultIds(l))
+
+	l, err = listFilteredCommandResults(store, &args.ResultFilterFlags{Status: "failed"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"cr3"}, commandResultIds(l))
+
+	l, err = listFilteredCommandResults(store, &args.ResultFilterFlags{Project: "github.com/example/repo"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"cr3"}, commandResultIds(l))
+
+	_, err = listFilteredCommandResults(store, &args.ResultFilterFlags{Status: "unknown"})
+	assert.Error(t, err)
+
+	vl, err := listFilteredValidateResults(store, &args.ResultFilterFlags{Status: "failed"})
+	assert.NoError(t, err)
+	assert.Len(t, vl, 1)
+	assert.Equal(t, "vr2", vl[0].Id)
+
+	vl, err = listFilteredValidateResults(store, &args.ResultFilterFlags{Target: "t1"})
+	assert.NoError(t, err)
+	assert.Len(t, vl, 1)
+	assert.Equal(t, "vr1", vl[0].Id)
+}
+
+func TestSelectResultIds(t *testing.T) {
+	store := buildTestResultStore(t)
+
+	crIds, vrIds, err := selectResultIds(store, []string{"cr1", "vr2"}, &args.ResultFilterFlags{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cr1"}, crIds)
+	assert.Equal(t, []string{"vr2"}, vrIds)
+
+	_, _, err = selectResultIds(store, []string{"cr1", "unknown"}, &args.ResultFilterFlags{})
+	assert.ErrorContains(t, err, "result unknown not found")
+
+	crIds, vrIds, err = selectResultIds(store, nil, &args.ResultFilterFlags{Target: "t1"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"cr1", "cr3"}, crIds)
+	assert.Empty(t, vrIds)
+
+	crIds, vrIds, err = selectResultIds(store, nil, &args.ResultFilterFlags{Validate: true, Target: "t1"})
+	assert.NoError(t, err)
+	assert.Empty(t, crIds)
+	assert.Equal(t, []string{"vr1"}, vrIds)
+}
+
+func TestFormatResultProjectAndTarget(t *testing.T) {
+	repoKey, err := gittypes.ParseRepoKey("github.com/example/repo", "git")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "<none>", formatResultProject(gittypes.ProjectKey{}))
+	assert.Equal(t, "//sub", formatResultProject(gittypes.ProjectKey{SubDir: "sub"}))
+	assert.Equal(t, "github.com/example/repo", formatResultProject(gittypes.ProjectKey{RepoKey: repoKey}))
+	assert.Equal(t, "github.com/example/repo//sub", formatResultProject(gittypes.ProjectKey{RepoKey: repoKey, SubDir: "sub"}))
+
+	assert.Equal(t, "<no-name>", formatResultTarget(result.TargetKey{}))
+	assert.Equal(t, "t1", formatResultTarget(result.TargetKey{TargetName: "t1"}))
+}
@@ -20,6 +20,10 @@ type validateCmd struct {
 	args.RegistryCredentials
 	args.OutputFlags
 	args.RenderOutputDirFlags
+	args.SchemaValidationFlags
+
+	Offline           bool   `group:"misc" help:"Validate the rendered objects against the bundled Kubernetes OpenAPI schemas and CRDs instead of validating the objects found on the target cluster. No cluster connection is required."`
+	KubernetesVersion string `group:"misc" help:"Specify the Kubernetes version that will be assumed. Used to select the bundled OpenAPI schemas when --offline is passed."`
 
 	Wait             time.Duration `group:"misc" help:"Wait for the given amount of time until the deployment validates"`
 	Sleep            time.Duration `group:"misc" help:"Sleep duration between validation attempts" default:"5s"`
@@ -28,6 +32,13 @@ type validateCmd struct {
 
 func (cmd *validateCmd) Help() string {
 	return `This means that all objects are retrieved from the cluster and checked for readiness.
+The output format can be changed via '-o format=path', with format being one of 'text', 'yaml', 'json' or 'markdown'.
+
+When --offline is passed, no objects are retrieved from the cluster. Instead, all rendered objects are validated
+against the bundled OpenAPI schemas matching --kubernetes-version and the CRDs found in the rendered objects or
+passed via --schema-dir. If no schemas are bundled for the Kubernetes version, the OpenAPI schemas served by the
+target cluster are used if a cluster is available. Otherwise, the OpenAPI document (swagger.json) of this version must
+be passed via --schema-dir.
 
 TODO: This needs to be better documented!`
 }
@@ -43,14 +54,37 @@ func (cmd *validateCmd) Run(ctx context.Context) error {
 		helmCredentials:      cmd.HelmCredentials,
 		registryCredentials:  cmd.RegistryCredentials,
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		offlineKubernetes:    cmd.Offline,
+		kubernetesVersion:    cmd.KubernetesVersion,
 	}
 
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		if cmd.Offline {
+			return cmd.doValidateOffline(ctx, cmdCtx)
+		}
 		cmd2 := commands.NewValidateCommand("", cmdCtx.targetCtx)
 		return cmd.doValidate(ctx, cmdCtx, cmd2)
 	})
 }
 
+func (cmd *validateCmd) doValidateOffline(ctx context.Context, cmdCtx *commandCtx) error {
+	result, err := commands.NewValidateSchemasCommand(cmdCtx.targetCtx, cmd.SchemaDir).Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = outputValidateResult(ctx, cmdCtx, cmd.Output, result)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Errors) != 0 || (cmd.WarningsAsErrors && len(result.Warnings) != 0) {
+		return fmt.Errorf("Validation failed")
+	}
+	status.Info(ctx, "Validation succeeded")
+	return nil
+}
+
 func (cmd *validateCmd) doValidate(ctx context.Context, cmdCtx *commandCtx, cmd2 *commands.ValidateCommand) error {
 	startTime := time.Now()
 	for true {
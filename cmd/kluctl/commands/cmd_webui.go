@@ -16,7 +16,7 @@ type webuiCmd struct {
 	Build webuiBuildCmd `cmd:"build" help:"Build the static Kluctl Webui"`
 }
 
-func createResultStores(ctx context.Context, kubeconfigOverride string, k8sContexts []string, allContexts bool, inCluster bool) ([]results.ResultStore, []*rest.Config, error) {
+func createResultStores(ctx context.Context, kubeconfigOverride string, k8sContexts []string, allContexts bool, inCluster bool, resultStoreUrls []string) ([]results.ResultStore, []*rest.Config, error) {
 	r := clientcmd.NewDefaultClientConfigLoadingRules()
 	r.ExplicitPath = kubeconfigOverride
 
@@ -87,5 +87,13 @@ func createResultStores(ctx context.Context, kubeconfigOverride string, k8sConte
 		return nil, nil, gh.ErrorOrNil()
 	}
 
+	for _, u := range resultStoreUrls {
+		store, err := results.NewResultStoreFromUrl(ctx, u, false, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		stores = append(stores, store)
+	}
+
 	return stores, configs, nil
 }
@@ -13,6 +13,7 @@ type webuiBuildCmd struct {
 	Path        string   `group:"misc" help:"Output path." required:"true"`
 	Context     []string `group:"misc" help:"List of kubernetes contexts to use. Defaults to the current context."`
 	AllContexts bool     `group:"misc" help:"Use all Kubernetes contexts found in the kubeconfig."`
+	ResultStore []string `group:"misc" help:"List of additional local result stores to read results from. Supported are 'file://<dir>' and 'sqlite://<file>'."`
 	MaxResults  int      `group:"misc" help:"Specify the maximum number of results per target." default:"1"`
 }
 
@@ -26,7 +27,7 @@ func (cmd *webuiBuildCmd) Run(ctx context.Context) error {
 		return fmt.Errorf("this build of Kluctl does not have the webui embedded")
 	}
 
-	stores, _, err := createResultStores(ctx, "", cmd.Context, cmd.AllContexts, false)
+	stores, _, err := createResultStores(ctx, "", cmd.Context, cmd.AllContexts, false, cmd.ResultStore)
 	if err != nil {
 		return err
 	}
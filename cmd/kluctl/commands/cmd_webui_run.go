@@ -21,6 +21,7 @@ type webuiRunCmd struct {
 	Kubeconfig  args.ExistingFileType `group:"misc" help:"Overrides the kubeconfig to use."`
 	Context     []string              `group:"misc" help:"List of kubernetes contexts to use."`
 	AllContexts bool                  `group:"misc" help:"Use all Kubernetes contexts found in the kubeconfig."`
+	ResultStore []string              `group:"misc" help:"List of additional local result stores to read results from. Supported are 'file://<dir>' and 'sqlite://<file>'."`
 
 	InCluster           bool   `group:"misc" help:"This enables in-cluster functionality. This also enforces authentication."`
 	InClusterContext    string `group:"misc" help:"The context to use fo in-cluster functionality."`
@@ -147,7 +148,7 @@ func (cmd *webuiRunCmd) Run(ctx context.Context) error {
 		}
 	}
 
-	stores, configs, err := createResultStores(ctx, cmd.Kubeconfig.String(), cmd.Context, cmd.AllContexts, cmd.InCluster)
+	stores, configs, err := createResultStores(ctx, cmd.Kubeconfig.String(), cmd.Context, cmd.AllContexts, cmd.InCluster, cmd.ResultStore)
 	if err != nil {
 		return err
 	}
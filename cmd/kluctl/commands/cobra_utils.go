@@ -25,6 +25,11 @@ type runProvider interface {
 	Run(ctx context.Context) error
 }
 
+type positionalArgsProvider interface {
+	PositionalArgs() (string, cobra.PositionalArgs)
+	SetPositionalArgs(args []string)
+}
+
 type rootCommand struct {
 	rootCmd    *commandAndGroups
 	groupInfos []groupInfo
@@ -67,9 +72,19 @@ func (c *rootCommand) buildCobraCmd(parent *commandAndGroups, cmdStruct interfac
 		},
 	}
 
+	posP, hasPositionalArgs := cmdStruct.(positionalArgsProvider)
+	if hasPositionalArgs {
+		var usage string
+		usage, cg.cmd.Args = posP.PositionalArgs()
+		cg.cmd.Use = fmt.Sprintf("%s %s", name, usage)
+	}
+
 	runP, ok := cmdStruct.(runProvider)
 	if ok {
 		cg.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			if hasPositionalArgs {
+				posP.SetPositionalArgs(args)
+			}
 			return runP.Run(cmd.Context())
 		}
 	}
@@ -3,6 +3,7 @@ package commands
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/lib/yaml"
@@ -11,6 +12,7 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"html"
 	"io"
 	"os"
 	"strings"
@@ -115,7 +117,7 @@ func prettyChanges(buf io.StringWriter, ref k8s.ObjectRef, changes []result.Chan
 	t.AddRow("Path", "Diff")
 
 	for _, c := range changes {
-		t.AddRow(c.JsonPath, c.UnifiedDiff)
+		t.AddRow(c.DisplayPath(), c.UnifiedDiff)
 	}
 	s := t.Render([]int{60})
 	_, _ = buf.WriteString(s)
@@ -129,12 +131,211 @@ func formatCommandResultYaml(cr *result.CommandResult) (string, error) {
 	return b, nil
 }
 
+func formatJson(o any) (string, error) {
+	b, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b) + "\n", nil
+}
+
+type objectJsonPatch struct {
+	Ref   k8s.ObjectRef             `json:"ref"`
+	Patch []diff.JsonPatchOperation `json:"patch"`
+}
+
+func formatCommandResultJsonPatch(cr *result.CommandResult) (string, error) {
+	patches := make([]objectJsonPatch, 0)
+	for _, o := range cr.Objects {
+		if len(o.Changes) == 0 {
+			continue
+		}
+		newObject := o.Applied
+		if newObject == nil {
+			newObject = o.Rendered
+		}
+		patch, err := diff.BuildJsonPatch(o.Changes, newObject)
+		if err != nil {
+			return "", fmt.Errorf("failed to build json patch for %s: %w", o.Ref.String(), err)
+		}
+		patches = append(patches, objectJsonPatch{
+			Ref:   o.Ref,
+			Patch: patch,
+		})
+	}
+	return formatJson(patches)
+}
+
+func markdownObjectRefs(buf io.StringWriter, title string, refs []k8s.ObjectRef) {
+	if len(refs) == 0 {
+		return
+	}
+	_, _ = buf.WriteString(fmt.Sprintf("\n### %s\n\n", title))
+	for _, ref := range refs {
+		_, _ = buf.WriteString(fmt.Sprintf("- `%s`\n", ref.String()))
+	}
+}
+
+func markdownErrors(buf io.StringWriter, title string, errors []result.DeploymentError) {
+	if len(errors) == 0 {
+		return
+	}
+	_, _ = buf.WriteString(fmt.Sprintf("\n### %s\n\n", title))
+	for _, e := range errors {
+		prefix := ""
+		if s := e.Ref.String(); s != "" {
+			prefix = fmt.Sprintf("`%s`: ", s)
+		}
+		_, _ = buf.WriteString(fmt.Sprintf("- %s%s\n", prefix, e.Message))
+	}
+}
+
+func markdownCodeFence(s string) string {
+	fence := "```"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	return fence
+}
+
+func formatCommandResultMarkdown(cr *result.CommandResult, short bool) string {
+	buf := bytes.NewBuffer(nil)
+
+	var newObjects []k8s.ObjectRef
+	var changedObjects []result.ResultObject
+	var deletedObjects []k8s.ObjectRef
+	var orphanObjects []k8s.ObjectRef
+	var appliedHookObjects []k8s.ObjectRef
+
+	for _, o := range cr.Objects {
+		if o.New {
+			newObjects = append(newObjects, o.Ref)
+		}
+		if len(o.Changes) != 0 {
+			changedObjects = append(changedObjects, o)
+		}
+		if o.Deleted {
+			deletedObjects = append(deletedObjects, o.Ref)
+		}
+		if o.Orphan {
+			orphanObjects = append(orphanObjects, o.Ref)
+		}
+		if o.Hook {
+			appliedHookObjects = append(appliedHookObjects, o.Ref)
+		}
+	}
+
+	title := cr.Command.Command
+	if title == "" {
+		title = "command"
+	}
+	if cr.Target.Name != "" {
+		title = fmt.Sprintf("%s for target `%s`", title, cr.Target.Name)
+	}
+	buf.WriteString(fmt.Sprintf("## Kluctl %s\n\n", title))
+
+	buf.WriteString("| New | Changed | Deleted | Orphan | Applied hooks | Errors | Warnings |\n")
+	buf.WriteString("|-----|---------|---------|--------|---------------|--------|----------|\n")
+	buf.WriteString(fmt.Sprintf("| %d | %d | %d | %d | %d | %d | %d |\n",
+		len(newObjects), len(changedObjects), len(deletedObjects), len(orphanObjects), len(appliedHookObjects),
+		len(cr.Errors), len(cr.Warnings)))
+
+	markdownObjectRefs(buf, "New objects", newObjects)
+
+	if len(changedObjects) != 0 {
+		buf.WriteString("\n### Changed objects\n\n")
+		for _, o := range changedObjects {
+			if short {
+				buf.WriteString(fmt.Sprintf("- `%s`\n", o.Ref.String()))
+				continue
+			}
+			buf.WriteString(fmt.Sprintf("<details>\n<summary><code>%s</code> (%d changes)</summary>\n\n", html.EscapeString(o.Ref.String()), len(o.Changes)))
+			for _, c := range o.Changes {
+				fence := markdownCodeFence(c.UnifiedDiff)
+				buf.WriteString(fmt.Sprintf("`%s`\n\n%sdiff\n%s\n%s\n\n", c.DisplayPath(), fence, strings.TrimSuffix(c.UnifiedDiff, "\n"), fence))
+			}
+			buf.WriteString("</details>\n\n")
+		}
+	}
+
+	markdownObjectRefs(buf, "Deleted objects", deletedObjects)
+	markdownObjectRefs(buf, "Applied hooks", appliedHookObjects)
+	markdownObjectRefs(buf, "Orphan objects", orphanObjects)
+	markdownErrors(buf, "Warnings", cr.Warnings)
+	markdownErrors(buf, "Errors", cr.Errors)
+
+	return buf.String()
+}
+
 func formatCommandResult(cr *result.CommandResult, format string, short bool) (string, error) {
 	switch format {
 	case "text":
 		return formatCommandResultText(cr, short), nil
 	case "yaml":
 		return formatCommandResultYaml(cr)
+	case "json":
+		return formatJson(cr)
+	case "json-patch":
+		return formatCommandResultJsonPatch(cr)
+	case "markdown":
+		return formatCommandResultMarkdown(cr, short), nil
+	default:
+		return "", fmt.Errorf("invalid format: %s", format)
+	}
+}
+
+type targetJsonPatch struct {
+	Target  string            `json:"target"`
+	Patches []objectJsonPatch `json:"patches"`
+}
+
+// formatCommandResults formats the results of multiple targets. A single result is formatted the same way as by
+// formatCommandResult.
+func formatCommandResults(crs []*result.CommandResult, format string, short bool) (string, error) {
+	if len(crs) == 1 {
+		return formatCommandResult(crs[0], format, short)
+	}
+
+	switch format {
+	case "text", "markdown":
+		buf := bytes.NewBuffer(nil)
+		for i, cr := range crs {
+			s, err := formatCommandResult(cr, format, short)
+			if err != nil {
+				return "", err
+			}
+			if i != 0 {
+				buf.WriteString("\n")
+			}
+			if format == "text" {
+				buf.WriteString(fmt.Sprintf("Target %s:\n", cr.Target.Name))
+			}
+			buf.WriteString(s)
+		}
+		return buf.String(), nil
+	case "yaml":
+		var l []*result.CompactedCommandResult
+		for _, cr := range crs {
+			l = append(l, cr.ToCompacted())
+		}
+		return yaml.WriteYamlString(l)
+	case "json":
+		return formatJson(crs)
+	case "json-patch":
+		l := make([]targetJsonPatch, 0)
+		for _, cr := range crs {
+			s, err := formatCommandResultJsonPatch(cr)
+			if err != nil {
+				return "", err
+			}
+			var patches []objectJsonPatch
+			err = json.Unmarshal([]byte(s), &patches)
+			if err != nil {
+				return "", err
+			}
+			l = append(l, targetJsonPatch{Target: cr.Target.Name, Patches: patches})
+		}
+		return formatJson(l)
 	default:
 		return "", fmt.Errorf("invalid format: %s", format)
 	}
@@ -186,12 +387,46 @@ func formatValidateResultYaml(vr *result.ValidateResult) (string, error) {
 	return string(b), nil
 }
 
+func formatValidateResultMarkdown(vr *result.ValidateResult) string {
+	buf := bytes.NewBuffer(nil)
+
+	readyStr := "not ready"
+	if vr.Ready {
+		readyStr = "ready"
+	}
+	title := "Kluctl validate"
+	if vr.TargetKey.TargetName != "" {
+		title = fmt.Sprintf("%s for target `%s`", title, vr.TargetKey.TargetName)
+	}
+	buf.WriteString(fmt.Sprintf("## %s\n\nThe target is **%s**.\n", title, readyStr))
+
+	markdownErrors(buf, "Validation Warnings", vr.Warnings)
+	markdownErrors(buf, "Validation Errors", vr.Errors)
+
+	if len(vr.Results) != 0 {
+		buf.WriteString("\n### Results\n\n")
+		buf.WriteString("| Object | Message |\n")
+		buf.WriteString("|--------|---------|\n")
+		for _, e := range vr.Results {
+			msg := strings.ReplaceAll(e.Message, "|", "\\|")
+			msg = strings.ReplaceAll(msg, "\n", "<br>")
+			buf.WriteString(fmt.Sprintf("| `%s` | %s |\n", e.Ref.String(), msg))
+		}
+	}
+
+	return buf.String()
+}
+
 func formatValidateResult(vr *result.ValidateResult, format string) (string, error) {
 	switch format {
 	case "text":
 		return formatValidateResultText(vr), nil
 	case "yaml":
 		return formatValidateResultYaml(vr)
+	case "json":
+		return formatJson(vr)
+	case "markdown":
+		return formatValidateResultMarkdown(vr), nil
 	default:
 		return "", fmt.Errorf("invalid validation result format: %s", format)
 	}
@@ -275,6 +510,29 @@ func outputCommandResult2(ctx context.Context, flags args.OutputFormatFlags, cr
 	return err
 }
 
+// outputCommandResults is like outputCommandResult, but for the results of multiple targets. The results are not
+// written to the result store.
+func outputCommandResults(ctx context.Context, flags args.OutputFormatFlags, crs []*result.CommandResult) error {
+	for _, cr := range crs {
+		cr.Command.Initiator = result.CommandInititiator_CommandLine
+
+		if !flags.NoObfuscate {
+			var obfuscator diff.Obfuscator
+			err := obfuscator.ObfuscateResult(cr)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	status.Flush(ctx)
+	err := outputHelper(ctx, flags.OutputFormat, func(format string) (string, error) {
+		return formatCommandResults(crs, format, flags.ShortOutput)
+	})
+	status.Flush(ctx)
+	return err
+}
+
 func outputValidateResult(ctx context.Context, cmdCtx *commandCtx, output []string, vr *result.ValidateResult) error {
 	vr.Id = cmdCtx.resultId
 
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func buildTestCommandResult() *result.CommandResult {
+	cmRef := k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm", Namespace: "default"}
+	return &result.CommandResult{
+		Command: result.CommandInfo{Command: "deploy"},
+		Target:  types.Target{Name: "prod"},
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Version: "v1", Kind: "Service", Name: "new", Namespace: "default"}, New: true}},
+			{BaseObject: result.BaseObject{Ref: cmRef, Changes: []result.Change{
+				{
+					Type:        "update",
+					JsonPath:    "data.a",
+					OldValue:    &apiextensionsv1.JSON{Raw: []byte(`"x"`)},
+					NewValue:    &apiextensionsv1.JSON{Raw: []byte(`"y"`)},
+					UnifiedDiff: "-x\n+y\n```",
+				},
+			}}},
+			{BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Version: "v1", Kind: "Secret", Name: "orphan", Namespace: "default"}, Orphan: true}},
+		},
+		Warnings: []result.DeploymentError{{Ref: cmRef, Message: "a warning"}},
+	}
+}
+
+func TestFormatCommandResultMarkdown(t *testing.T) {
+	cr := buildTestCommandResult()
+
+	s, err := formatCommandResult(cr, "markdown", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "## Kluctl deploy for target `prod`\n"+
+		"\n"+
+		"| New | Changed | Deleted | Orphan | Applied hooks | Errors | Warnings |\n"+
+		"|-----|---------|---------|--------|---------------|--------|----------|\n"+
+		"| 1 | 1 | 0 | 1 | 0 | 0 | 1 |\n"+
+		"\n"+
+		"### New objects\n"+
+		"\n"+
+		"- `default/Service/new`\n"+
+		"\n"+
+		"### Changed objects\n"+
+		"\n"+
+		"<details>\n"+
+		"<summary><code>default/ConfigMap/cm</code> (1 changes)</summary>\n"+
+		"\n"+
+		"`data.a`\n"+
+		"\n"+
+		"````diff\n"+
+		"-x\n"+
+		"+y\n"+
+		"```\n"+
+		"````\n"+
+		"\n"+
+		"</details>\n"+
+		"\n"+
+		"\n"+
+		"### Orphan objects\n"+
+		"\n"+
+		"- `default/Secret/orphan`\n"+
+		"\n"+
+		"### Warnings\n"+
+		"\n"+
+		"- `default/ConfigMap/cm`: a warning\n", s)
+
+	s, err = formatCommandResult(cr, "markdown", true)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "### Changed objects\n\n- `default/ConfigMap/cm`\n")
+	assert.NotContains(t, s, "<details>")
+}
+
+func TestFormatCommandResultJson(t *testing.T) {
+	cr := buildTestCommandResult()
+
+	s, err := formatCommandResult(cr, "json", false)
+	assert.NoError(t, err)
+
+	var parsed result.CommandResult
+	err = json.Unmarshal([]byte(s), &parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, *cr, parsed)
+}
+
+func TestFormatCommandResultJsonPatch(t *testing.T) {
+	cr := buildTestCommandResult()
+
+	s, err := formatCommandResult(cr, "json-patch", false)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{
+		"ref": {"version": "v1", "kind": "ConfigMap", "name": "cm", "namespace": "default"},
+		"patch": [{"op": "replace", "path": "/data/a", "value": "y"}]
+	}]`, s)
+}
+
+func TestFormatValidateResultMarkdown(t *testing.T) {
+	vr := &result.ValidateResult{
+		TargetKey: result.TargetKey{TargetName: "prod"},
+		Results: []result.ValidateResultEntry{
+			{Ref: k8s.ObjectRef{Group: "apps", Version: "v1", Kind: "Deployment", Name: "d", Namespace: "default"}, Message: "not | ready\nyet"},
+		},
+	}
+
+	s, err := formatValidateResult(vr, "markdown")
+	assert.NoError(t, err)
+	assert.Equal(t, "## Kluctl validate for target `prod`\n"+
+		"\n"+
+		"The target is **not ready**.\n"+
+		"\n"+
+		"### Results\n"+
+		"\n"+
+		"| Object | Message |\n"+
+		"|--------|---------|\n"+
+		"| `default/Deployment/d` | not \\| ready<br>yet |\n", s)
+}
+
+func TestFormatCommandResults(t *testing.T) {
+	cr1 := buildTestCommandResult()
+	cr2 := buildTestCommandResult()
+	cr2.Target.Name = "test"
+
+	s, err := formatCommandResults([]*result.CommandResult{cr1}, "json", false)
+	assert.NoError(t, err)
+	s2, err := formatCommandResult(cr1, "json", false)
+	assert.NoError(t, err)
+	assert.Equal(t, s2, s)
+
+	s, err = formatCommandResults([]*result.CommandResult{cr1, cr2}, "json", false)
+	assert.NoError(t, err)
+	var parsed []result.CommandResult
+	err = json.Unmarshal([]byte(s), &parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, []result.CommandResult{*cr1, *cr2}, parsed)
+
+	s, err = formatCommandResults([]*result.CommandResult{cr1, cr2}, "json-patch", false)
+	assert.NoError(t, err)
+	patch := `{"ref": {"version": "v1", "kind": "ConfigMap", "name": "cm", "namespace": "default"}, "patch": [{"op": "replace", "path": "/data/a", "value": "y"}]}`
+	assert.JSONEq(t, `[{"target": "prod", "patches": [`+patch+`]}, {"target": "test", "patches": [`+patch+`]}]`, s)
+
+	s, err = formatCommandResults([]*result.CommandResult{cr1, cr2}, "text", true)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "Target prod:\n")
+	assert.Contains(t, s, "\nTarget test:\n")
+
+	s, err = formatCommandResults([]*result.CommandResult{cr1, cr2}, "markdown", true)
+	assert.NoError(t, err)
+	assert.Contains(t, s, "## Kluctl deploy for target `prod`\n")
+	assert.Contains(t, s, "## Kluctl deploy for target `test`\n")
+}
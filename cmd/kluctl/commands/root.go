@@ -62,21 +62,26 @@ type GlobalFlags struct {
 type cli struct {
 	GlobalFlags
 
-	Delete      deleteCmd      `cmd:"" help:"Delete a target (or parts of it) from the corresponding cluster"`
-	Deploy      deployCmd      `cmd:"" help:"Deploys a target to the corresponding cluster"`
-	Diff        diffCmd        `cmd:"" help:"Perform a diff between the locally rendered target and the already deployed target"`
-	HelmPull    helmPullCmd    `cmd:"" help:"Recursively searches for 'helm-chart.yaml' files and pre-pulls the specified Helm charts"`
-	HelmUpdate  helmUpdateCmd  `cmd:"" help:"Recursively searches for 'helm-chart.yaml' files and checks for new available versions"`
-	ListImages  listImagesCmd  `cmd:"" help:"Renders the target and outputs all images used via 'images.get_image(...)"`
-	ListTargets listTargetsCmd `cmd:"" help:"Outputs a yaml list with all targets"`
-	PokeImages  pokeImagesCmd  `cmd:"" help:"Replace all images in target"`
-	Prune       pruneCmd       `cmd:"" help:"Searches the target cluster for prunable objects and deletes them"`
-	Render      renderCmd      `cmd:"" help:"Renders all resources and configuration files"`
-	Validate    validateCmd    `cmd:"" help:"Validates the already deployed deployment"`
-	Controller  controllerCmd  `cmd:"" help:"Kluctl controller sub-commands"`
-	Gitops      gitopsCmd      `cmd:"" help:"GitOps sub-commands"`
-	Webui       webuiCmd       `cmd:"" help:"Kluctl Webui sub-commands"`
-	Oci         ociCmd         `cmd:"" help:"Oci sub-commands"`
+	Adopt                adoptCmd                `cmd:"" help:"Adopts already existing objects that were not deployed by kluctl into the target"`
+	CheckApis            checkApisCmd            `cmd:"" help:"Checks the target for usages of deprecated or removed Kubernetes APIs"`
+	Delete               deleteCmd               `cmd:"" help:"Delete a target (or parts of it) from the corresponding cluster"`
+	Deploy               deployCmd               `cmd:"" help:"Deploys a target to the corresponding cluster"`
+	Diff                 diffCmd                 `cmd:"" help:"Perform a diff between the locally rendered target and the already deployed target"`
+	HelmPull             helmPullCmd             `cmd:"" help:"Recursively searches for 'helm-chart.yaml' files and pre-pulls the specified Helm charts"`
+	HelmUpdate           helmUpdateCmd           `cmd:"" help:"Recursively searches for 'helm-chart.yaml' files and checks for new available versions"`
+	ListImages           listImagesCmd           `cmd:"" help:"Renders the target and outputs all images used via 'images.get_image(...)"`
+	ListTargets          listTargetsCmd          `cmd:"" help:"Outputs a yaml list with all targets"`
+	MigrateDiscriminator migrateDiscriminatorCmd `cmd:"" help:"Relabels all objects carrying an old discriminator with a new discriminator"`
+	PokeImages           pokeImagesCmd           `cmd:"" help:"Replace all images in target"`
+	Prune                pruneCmd                `cmd:"" help:"Searches the target cluster for prunable objects and deletes them"`
+	Render               renderCmd               `cmd:"" help:"Renders all resources and configuration files"`
+	Restore              restoreCmd              `cmd:"" help:"Restores objects that were deleted by delete or prune"`
+	Results              resultsCmd              `cmd:"" help:"Command results sub-commands"`
+	Validate             validateCmd             `cmd:"" help:"Validates the already deployed deployment"`
+	Controller           controllerCmd           `cmd:"" help:"Kluctl controller sub-commands"`
+	Gitops               gitopsCmd               `cmd:"" help:"GitOps sub-commands"`
+	Webui                webuiCmd                `cmd:"" help:"Kluctl Webui sub-commands"`
+	Oci                  ociCmd                  `cmd:"" help:"Oci sub-commands"`
 
 	Version versionCmd `cmd:"" help:"Print kluctl version"`
 }
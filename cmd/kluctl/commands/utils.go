@@ -28,6 +28,7 @@ import (
 	"k8s.io/client-go/tools/clientcmd/api"
 	"os"
 	client2 "sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
 )
 
 func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.KubeconfigFlags, projectFlags args.ProjectFlags,
@@ -71,20 +72,12 @@ func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.Kubeco
 		return err
 	}
 
-	messageCallbacks := &messages.MessageCallbacks{
-		WarningFn:            func(s string) { status.Warning(ctx, s) },
-		TraceFn:              func(s string) { status.Trace(ctx, s) },
-		AskForPasswordFn:     func(s string) (string, error) { return prompts.AskForPassword(ctx, s) },
-		AskForConfirmationFn: func(s string) bool { return prompts.AskForConfirmation(ctx, s) },
+	gitAuth, err := buildGitAuthProviders(ctx, gitCredentials)
+	if err != nil {
+		return err
 	}
-	gitAuth := auth.NewDefaultAuthProviders("KLUCTL_GIT", messageCallbacks)
 	ociAuth := auth_provider.NewDefaultAuthProviders("KLUCTL_REGISTRY")
 	helmAuth := helm_auth.NewDefaultAuthProviders("KLUCTL_HELM")
-	if x, err := gitCredentials.BuildAuthProvider(ctx); err != nil {
-		return err
-	} else {
-		gitAuth.RegisterAuthProvider(x, false)
-	}
 	if x, err := helmCredentials.BuildAuthProvider(ctx); err != nil {
 		return err
 	} else {
@@ -127,6 +120,22 @@ func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.Kubeco
 	return cb(ctx, p)
 }
 
+func buildGitAuthProviders(ctx context.Context, gitCredentials *args.GitCredentials) (*auth.GitAuthProviders, error) {
+	messageCallbacks := &messages.MessageCallbacks{
+		WarningFn:            func(s string) { status.Warning(ctx, s) },
+		TraceFn:              func(s string) { status.Trace(ctx, s) },
+		AskForPasswordFn:     func(s string) (string, error) { return prompts.AskForPassword(ctx, s) },
+		AskForConfirmationFn: func(s string) bool { return prompts.AskForConfirmation(ctx, s) },
+	}
+	gitAuth := auth.NewDefaultAuthProviders("KLUCTL_GIT", messageCallbacks)
+	x, err := gitCredentials.BuildAuthProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gitAuth.RegisterAuthProvider(x, false)
+	return gitAuth, nil
+}
+
 type projectTargetCommandArgs struct {
 	projectFlags         args.ProjectFlags
 	kubeconfigFlags      args.KubeconfigFlags
@@ -147,6 +156,8 @@ type projectTargetCommandArgs struct {
 	forCompletion     bool
 	offlineKubernetes bool
 	kubernetesVersion string
+
+	checkApisForVersion string
 }
 
 type commandCtx struct {
@@ -196,18 +207,19 @@ func withProjectTargetCommandContext(ctx context.Context, args projectTargetComm
 	}
 
 	targetParams := target_context.TargetContextParams{
-		TargetName:         args.targetFlags.Target,
-		TargetNameOverride: args.targetFlags.TargetNameOverride,
-		ContextOverride:    args.targetFlags.Context,
-		Discriminator:      args.discriminator,
-		OfflineK8s:         args.offlineKubernetes,
-		K8sVersion:         args.kubernetesVersion,
-		DryRun:             args.dryRunArgs == nil || args.dryRunArgs.DryRun || args.forCompletion,
-		Images:             images,
-		Inclusion:          inclusion,
-		OciAuthProvider:    p.LoadArgs.OciAuthProvider,
-		HelmAuthProvider:   p.LoadArgs.HelmAuthProvider,
-		RenderOutputDir:    renderOutputDir,
+		TargetName:          args.targetFlags.Target,
+		TargetNameOverride:  args.targetFlags.TargetNameOverride,
+		ContextOverride:     args.targetFlags.Context,
+		Discriminator:       args.discriminator,
+		OfflineK8s:          args.offlineKubernetes,
+		K8sVersion:          args.kubernetesVersion,
+		CheckApisForVersion: args.checkApisForVersion,
+		DryRun:              args.dryRunArgs == nil || args.dryRunArgs.DryRun || args.forCompletion,
+		Images:              images,
+		Inclusion:           inclusion,
+		OciAuthProvider:     p.LoadArgs.OciAuthProvider,
+		HelmAuthProvider:    p.LoadArgs.HelmAuthProvider,
+		RenderOutputDir:     renderOutputDir,
 	}
 
 	commandResultId := uuid.NewString()
@@ -233,7 +245,14 @@ func withProjectTargetCommandContext(ctx context.Context, args projectTargetComm
 		}
 		s.Success()
 
-		resultStore, err = buildResultStoreRW(ctx, clientConfig, mapper, args.commandResultFlags, false)
+		var retention *results.Retention
+		if args.commandResultFlags != nil {
+			retention, err = buildResultRetention(&args.commandResultFlags.CommandResultWriteFlags)
+			if err != nil {
+				return err
+			}
+		}
+		resultStore, err = buildResultStoreRW(ctx, clientConfig, mapper, args.commandResultFlags, retention, false)
 		if err != nil {
 			if !errors.IsForbidden(err) {
 				return err
@@ -263,6 +282,29 @@ func withProjectTargetCommandContext(ctx context.Context, args projectTargetComm
 	return cb(cmdCtx)
 }
 
+func checkConfirmationPhrase(ctx context.Context, cmdCtx *commandCtx, flags args.ConfirmationPhraseFlags, dryRun bool) error {
+	phrase := cmdCtx.targetCtx.RequiredConfirmationPhrase()
+	if phrase == "" || dryRun {
+		return nil
+	}
+
+	if flags.ConfirmationPhrase != "" {
+		if flags.ConfirmationPhrase != phrase {
+			return fmt.Errorf("the passed confirmation phrase does not match the phrase required by target '%s'", cmdCtx.targetCtx.Target.Name)
+		}
+		return nil
+	}
+
+	response, err := prompts.Prompt(ctx, false, fmt.Sprintf("Target '%s' requires explicit confirmation. Please type '%s' to proceed: ", cmdCtx.targetCtx.Target.Name, phrase))
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(response) != phrase {
+		return fmt.Errorf("aborted, confirmation phrase did not match")
+	}
+	return nil
+}
+
 func clientConfigGetter(kubeconfigFlags *args.KubeconfigFlags, forCompletion bool) func(context *string) (*rest.Config, *api.Config, error) {
 	return func(context *string) (*rest.Config, *api.Config, error) {
 		if forCompletion {
@@ -298,6 +340,10 @@ func buildResultStoreRO(ctx context.Context, restConfig *rest.Config, mapper met
 		return nil, nil
 	}
 
+	if flags.ResultStore != "" {
+		return results.NewResultStoreFromUrl(ctx, flags.ResultStore, false, nil)
+	}
+
 	c, err := client2.NewWithWatch(restConfig, client2.Options{
 		Mapper: mapper,
 	})
@@ -305,7 +351,7 @@ func buildResultStoreRO(ctx context.Context, restConfig *rest.Config, mapper met
 		return nil, err
 	}
 
-	resultStore, err := results.NewResultStoreSecrets(ctx, restConfig, c, false, flags.CommandResultNamespace, 0, 0)
+	resultStore, err := results.NewResultStoreSecrets(ctx, restConfig, c, false, flags.CommandResultNamespace, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -313,11 +359,27 @@ func buildResultStoreRO(ctx context.Context, restConfig *rest.Config, mapper met
 	return resultStore, nil
 }
 
-func buildResultStoreRW(ctx context.Context, restConfig *rest.Config, mapper meta.RESTMapper, flags *args.CommandResultFlags, startCleanup bool) (results.ResultStore, error) {
+func buildResultRetention(flags *args.CommandResultWriteFlags) (*results.Retention, error) {
+	var config *results.RetentionConfig
+	if flags.ResultRetentionFile.String() != "" {
+		var err error
+		config, err = results.LoadRetentionConfig(flags.ResultRetentionFile.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results.NewRetention(config, flags.KeepCommandResultsCount, flags.KeepValidateResultsCount), nil
+}
+
+func buildResultStoreRW(ctx context.Context, restConfig *rest.Config, mapper meta.RESTMapper, flags *args.CommandResultFlags, retention *results.Retention, startCleanup bool) (results.ResultStore, error) {
 	if flags == nil || !flags.WriteCommandResult {
 		return nil, nil
 	}
 
+	if flags.ResultStore != "" {
+		return results.NewResultStoreFromUrl(ctx, flags.ResultStore, true, retention)
+	}
+
 	c, err := client2.NewWithWatch(restConfig, client2.Options{
 		Mapper: mapper,
 	})
@@ -325,7 +387,7 @@ func buildResultStoreRW(ctx context.Context, restConfig *rest.Config, mapper met
 		return nil, err
 	}
 
-	resultStore, err := results.NewResultStoreSecrets(ctx, restConfig, c, true, flags.CommandResultNamespace, flags.KeepCommandResultsCount, flags.KeepValidateResultsCount)
+	resultStore, err := results.NewResultStoreSecrets(ctx, restConfig, c, true, flags.CommandResultNamespace, retention)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,66 @@
+package e2e
+
+import (
+	"context"
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestAdopt(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_utils.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	addConfigMapDeployment(p, "cm1", map[string]string{"d1": "v1"}, resourceOpts{
+		name:      "cm1",
+		namespace: p.TestSlug(),
+	})
+	addConfigMapDeployment(p, "cm2", map[string]string{"d1": "v1"}, resourceOpts{
+		name:      "cm2",
+		namespace: p.TestSlug(),
+	})
+
+	// cm1 was created by some other tool, cm2 is managed by another kluctl target
+	for _, x := range []struct {
+		name   string
+		labels map[string]string
+	}{
+		{name: "cm1"},
+		{name: "cm2", labels: map[string]string{"kluctl.io/discriminator": "other"}},
+	} {
+		cm := v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      x.name,
+				Namespace: p.TestSlug(),
+				Labels:    x.labels,
+			},
+			Data: map[string]string{"d1": "v0"},
+		}
+		err := k.Client.Create(context.Background(), &cm)
+		assert.NoError(t, err)
+	}
+
+	_, stderr := p.KluctlMust(t, "adopt", "--yes", "-t", "test")
+	assert.Contains(t, stderr, "managed by another target and will be skipped")
+
+	cm1 := assertConfigMapExists(t, k, p.TestSlug(), "cm1")
+	assertNestedFieldEquals(t, cm1, p.Discriminator("test"), "metadata", "labels", "kluctl.io/discriminator")
+	assertNestedFieldEquals(t, cm1, "v1", "data", "d1")
+	cm2 := assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+	assertNestedFieldEquals(t, cm2, "other", "metadata", "labels", "kluctl.io/discriminator")
+	assertNestedFieldEquals(t, cm2, "v0", "data", "d1")
+
+	p.KluctlMust(t, "adopt", "--yes", "-t", "test", "--adopt-foreign")
+	cm2 = assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+	assertNestedFieldEquals(t, cm2, p.Discriminator("test"), "metadata", "labels", "kluctl.io/discriminator")
+	assertNestedFieldEquals(t, cm2, "v1", "data", "d1")
+}
@@ -0,0 +1,70 @@
+package e2e
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestDiffGitBase(t *testing.T) {
+	t.Parallel()
+
+	p := test_project.NewTestProject(t)
+
+	p.UpdateTarget("a", nil)
+	p.UpdateTarget("b", nil)
+
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"d1": "{{ target.name }}-1",
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+	p.UpdateYaml("cm/configmap-cm.yml", func(o *uo.UnstructuredObject) error {
+		return o.SetNestedField("{{ target.name }}-2", "data", "d1")
+	}, "")
+
+	cmRef := k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm", Namespace: p.TestSlug()}
+	buildChange := func(targetName string) result.Change {
+		return result.Change{
+			Type:        "update",
+			JsonPath:    "data.d1",
+			OldValue:    &v1.JSON{Raw: []byte(`"` + targetName + `-1"`)},
+			NewValue:    &v1.JSON{Raw: []byte(`"` + targetName + `-2"`)},
+			UnifiedDiff: "-" + targetName + "-1\n+" + targetName + "-2",
+		}
+	}
+
+	// all targets are diffed when no target is selected
+	stdout, _ := p.KluctlMust(t, "diff", "--git-base", "HEAD~1", "-ojson")
+	var crs []result.CommandResult
+	err := json.Unmarshal([]byte(stdout), &crs)
+	assert.NoError(t, err)
+	assert.Len(t, crs, 2)
+	for i, targetName := range []string{"a", "b"} {
+		assert.Equal(t, targetName, crs[i].Target.Name)
+		assert.Len(t, crs[i].Objects, 1)
+		assert.Equal(t, cmRef, crs[i].Objects[0].Ref)
+		assert.Equal(t, []result.Change{buildChange(targetName)}, crs[i].Objects[0].Changes)
+	}
+
+	cr, _ := p.KluctlMustCommandResult(t, "diff", "--git-base", "HEAD~1", "-t", "b", "-oyaml")
+	assert.Equal(t, "b", cr.Target.Name)
+	assert.Len(t, cr.Objects, 1)
+	assert.Equal(t, []result.Change{buildChange("b")}, cr.Objects[0].Changes)
+
+	// a target that does not exist at the base revision shows all objects as new
+	p.UpdateTarget("c", nil)
+	cr, _ = p.KluctlMustCommandResult(t, "diff", "--git-base", "HEAD~1", "-t", "c", "-oyaml")
+	assert.Len(t, cr.Objects, 1)
+	assert.True(t, cr.Objects[0].New)
+
+	_, _, err = p.Kluctl(t, "diff", "--git-base", "does-not-exist")
+	assert.ErrorContains(t, err, "failed to resolve revision does-not-exist")
+}
@@ -0,0 +1,129 @@
+package e2e
+
+import (
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/onsi/gomega"
+	"github.com/stretchr/testify/suite"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+	"time"
+)
+
+type GitOpsDependsOnSuite struct {
+	GitopsTestSuite
+}
+
+func TestGitOpsDependsOn(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(GitOpsDependsOnSuite))
+}
+
+func (suite *GitOpsDependsOnSuite) waitForReadyReason(key client.ObjectKey, status metav1.ConditionStatus, reason string) {
+	g := gomega.NewWithT(suite.T())
+	g.Eventually(func() bool {
+		kd := suite.getKluctlDeployment(key)
+		c := suite.getReadiness(kd)
+		if c == nil {
+			return false
+		}
+		return c.Status == status && c.Reason == reason
+	}, timeout, time.Second).Should(gomega.BeTrue())
+}
+
+func (suite *GitOpsDependsOnSuite) TestDependsOn() {
+	p1 := test_project.NewTestProject(suite.T())
+	p2 := test_project.NewTestProject(suite.T())
+	createNamespace(suite.T(), suite.k, p1.TestSlug())
+	createNamespace(suite.T(), suite.k, p2.TestSlug())
+
+	p1.UpdateTarget("target1", nil)
+	addConfigMapDeployment(p1, "d1", nil, resourceOpts{
+		name:      "cm1",
+		namespace: p1.TestSlug(),
+	})
+	p2.UpdateTarget("target1", nil)
+	addConfigMapDeployment(p2, "d1", nil, resourceOpts{
+		name:      "cm2",
+		namespace: p2.TestSlug(),
+	})
+
+	key2 := suite.createKluctlDeployment2(p2, "target1", nil, func(kd *kluctlv1.KluctlDeployment) {
+		kd.Spec.Source.Git = &kluctlv1.ProjectSourceGit{
+			URL: p2.GitUrl(),
+		}
+		kd.Spec.DependsOn = []kluctlv1.KluctlDeploymentDependency{
+			{Name: p1.TestSlug()},
+		}
+	})
+
+	suite.Run("dependency not found", func() {
+		suite.waitForReadyReason(key2, metav1.ConditionFalse, kluctlv1.DependencyNotReadyReason)
+		assertConfigMapNotExists(suite.T(), suite.k, p2.TestSlug(), "cm2")
+	})
+
+	key1 := suite.createKluctlDeployment(p1, "target1", nil)
+
+	suite.Run("dependency ready", func() {
+		suite.waitForCommit(key1, getHeadRevision(suite.T(), p1))
+		assertConfigMapExists(suite.T(), suite.k, p1.TestSlug(), "cm1")
+
+		suite.waitForReadyReason(key2, metav1.ConditionTrue, kluctlv1.ReconciliationSucceededReason)
+		assertConfigMapExists(suite.T(), suite.k, p2.TestSlug(), "cm2")
+	})
+}
+
+func (suite *GitOpsDependsOnSuite) TestDependsOnCycle() {
+	p1 := test_project.NewTestProject(suite.T())
+	p2 := test_project.NewTestProject(suite.T())
+	createNamespace(suite.T(), suite.k, p1.TestSlug())
+	createNamespace(suite.T(), suite.k, p2.TestSlug())
+
+	p1.UpdateTarget("target1", nil)
+	addConfigMapDeployment(p1, "d1", nil, resourceOpts{
+		name:      "cm1",
+		namespace: p1.TestSlug(),
+	})
+	p2.UpdateTarget("target1", nil)
+	addConfigMapDeployment(p2, "d1", nil, resourceOpts{
+		name:      "cm2",
+		namespace: p2.TestSlug(),
+	})
+
+	key1 := suite.createKluctlDeployment2(p1, "target1", nil, func(kd *kluctlv1.KluctlDeployment) {
+		kd.Spec.Source.Git = &kluctlv1.ProjectSourceGit{
+			URL: p1.GitUrl(),
+		}
+		kd.Spec.DependsOn = []kluctlv1.KluctlDeploymentDependency{
+			{Name: p2.TestSlug()},
+		}
+	})
+	key2 := suite.createKluctlDeployment2(p2, "target1", nil, func(kd *kluctlv1.KluctlDeployment) {
+		kd.Spec.Source.Git = &kluctlv1.ProjectSourceGit{
+			URL: p2.GitUrl(),
+		}
+		kd.Spec.DependsOn = []kluctlv1.KluctlDeploymentDependency{
+			{Name: p1.TestSlug()},
+		}
+	})
+
+	suite.Run("cycle detected", func() {
+		suite.waitForReadyReason(key1, metav1.ConditionFalse, kluctlv1.DependencyNotReadyReason)
+		suite.waitForReadyReason(key2, metav1.ConditionFalse, kluctlv1.DependencyNotReadyReason)
+
+		kd := suite.getKluctlDeployment(key1)
+		suite.Contains(suite.getReadiness(kd).Message, "dependency cycle detected")
+		suite.Empty(kd.Status.ObservedCommit)
+		assertConfigMapNotExists(suite.T(), suite.k, p1.TestSlug(), "cm1")
+		assertConfigMapNotExists(suite.T(), suite.k, p2.TestSlug(), "cm2")
+	})
+
+	suite.Run("manual reconcile ignores dependencies", func() {
+		kd := suite.waitForReconcile(key1)
+		suite.NotNil(kd.Status.ReconcileRequestResult)
+		suite.Empty(kd.Status.ReconcileRequestResult.CommandError)
+		assertConfigMapExists(suite.T(), suite.k, p1.TestSlug(), "cm1")
+		assertConfigMapNotExists(suite.T(), suite.k, p2.TestSlug(), "cm2")
+	})
+}
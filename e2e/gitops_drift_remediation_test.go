@@ -0,0 +1,135 @@
+package e2e
+
+import (
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/onsi/gomega"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+	"time"
+)
+
+type GitOpsDriftRemediationSuite struct {
+	GitopsTestSuite
+}
+
+func TestGitOpsDriftRemediation(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(GitOpsDriftRemediationSuite))
+}
+
+func (suite *GitOpsDriftRemediationSuite) getLastDeployResultId(key client.ObjectKey) string {
+	kd := suite.getKluctlDeployment(key)
+	summary, err := kd.Status.GetLastDeployResult()
+	assert.NoError(suite.T(), err)
+	if summary == nil {
+		return ""
+	}
+	return summary.Id
+}
+
+func (suite *GitOpsDriftRemediationSuite) getDriftedObjects(key client.ObjectKey) int {
+	kd := suite.getKluctlDeployment(key)
+	dr, err := kd.Status.GetDriftDetectionResult()
+	assert.NoError(suite.T(), err)
+	if dr == nil {
+		return -1
+	}
+	return len(dr.Objects)
+}
+
+func (suite *GitOpsDriftRemediationSuite) doTestDriftRemediation(mode string) {
+	g := gomega.NewWithT(suite.T())
+
+	p := test_project.NewTestProject(suite.T())
+	createNamespace(suite.T(), suite.k, p.TestSlug())
+
+	p.UpdateTarget("target1", nil)
+	addConfigMapDeployment(p, "d1", map[string]string{"a": "v1"}, resourceOpts{
+		name:      "cm1",
+		namespace: p.TestSlug(),
+		// the drift is introduced by another field manager, so re-applying must overwrite it
+		annotations: map[string]string{"kluctl.io/force-apply": "true"},
+	})
+	addConfigMapDeployment(p, "hook", nil, resourceOpts{
+		name:        "hook1",
+		namespace:   p.TestSlug(),
+		annotations: map[string]string{"kluctl.io/hook": "post-deploy"},
+	})
+
+	key := suite.createKluctlDeployment2(p, "target1", nil, func(kd *kluctlv1.KluctlDeployment) {
+		kd.Spec.Source.Git = &kluctlv1.ProjectSourceGit{
+			URL: p.GitUrl(),
+		}
+		kd.Spec.DriftRemediation = &kluctlv1.DriftRemediation{
+			Mode: mode,
+		}
+	})
+
+	suite.waitForCommit(key, getHeadRevision(suite.T(), p))
+	g.Eventually(func() int {
+		return suite.getDriftedObjects(key)
+	}, timeout, time.Second).Should(gomega.Equal(0))
+
+	deployResultId := suite.getLastDeployResultId(key)
+	assert.NotEmpty(suite.T(), deployResultId)
+	hookUid := assertConfigMapExists(suite.T(), suite.k, p.TestSlug(), "hook1").GetK8sUid()
+
+	patchConfigMap(suite.T(), suite.k, p.TestSlug(), "cm1", func(o *uo.UnstructuredObject) {
+		_ = o.SetNestedField("drifted", "data", "a")
+	})
+
+	getValue := func() string {
+		o := assertConfigMapExists(suite.T(), suite.k, p.TestSlug(), "cm1")
+		v, _, _ := o.GetNestedString("data", "a")
+		return v
+	}
+
+	if mode == kluctlv1.DriftRemediationReport {
+		g.Eventually(func() int {
+			return suite.getDriftedObjects(key)
+		}, timeout, time.Second).Should(gomega.Equal(1))
+
+		// give the controller a few more reconciliations to make sure nothing gets remediated
+		time.Sleep(interval * 2)
+		assert.Equal(suite.T(), "drifted", getValue())
+		assert.Equal(suite.T(), 1, suite.getDriftedObjects(key))
+		assert.Empty(suite.T(), suite.getKluctlDeployment(key).Status.DriftRemediationTimes)
+		assert.Equal(suite.T(), deployResultId, suite.getLastDeployResultId(key))
+		return
+	}
+
+	g.Eventually(getValue, timeout, time.Second).Should(gomega.Equal("v1"))
+	g.Eventually(func() int {
+		return suite.getDriftedObjects(key)
+	}, timeout, time.Second).Should(gomega.Equal(0))
+
+	kd := suite.getKluctlDeployment(key)
+	assert.Len(suite.T(), kd.Status.DriftRemediationTimes, 1)
+
+	newHookUid := assertConfigMapExists(suite.T(), suite.k, p.TestSlug(), "hook1").GetK8sUid()
+	if mode == kluctlv1.DriftRemediationRedeployDrifted {
+		// only the drifted object is re-applied, so hooks are not executed and the partial result does not replace
+		// the last deploy result
+		assert.Equal(suite.T(), hookUid, newHookUid)
+		assert.Equal(suite.T(), deployResultId, suite.getLastDeployResultId(key))
+	} else {
+		assert.NotEqual(suite.T(), hookUid, newHookUid)
+		assert.NotEqual(suite.T(), deployResultId, suite.getLastDeployResultId(key))
+	}
+}
+
+func (suite *GitOpsDriftRemediationSuite) TestReport() {
+	suite.doTestDriftRemediation(kluctlv1.DriftRemediationReport)
+}
+
+func (suite *GitOpsDriftRemediationSuite) TestRedeployDrifted() {
+	suite.doTestDriftRemediation(kluctlv1.DriftRemediationRedeployDrifted)
+}
+
+func (suite *GitOpsDriftRemediationSuite) TestRedeployAll() {
+	suite.doTestDriftRemediation(kluctlv1.DriftRemediationRedeployAll)
+}
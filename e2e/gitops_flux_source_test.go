@@ -0,0 +1,73 @@
+package e2e
+
+import (
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/fluxsource"
+	"github.com/onsi/gomega"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+	"time"
+)
+
+type GitOpsFluxSourceSuite struct {
+	GitopsTestSuite
+}
+
+func TestGitOpsFluxSource(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(GitOpsFluxSourceSuite))
+}
+
+func (suite *GitOpsFluxSourceSuite) createFluxSourceKluctlDeployment(p *test_project.TestProject, namespace string) client.ObjectKey {
+	return suite.createKluctlDeployment2(p, "", nil, func(kd *kluctlv1.KluctlDeployment) {
+		kd.Spec.Source.SourceRef = &kluctlv1.ProjectSourceFluxRef{
+			Kind:      fluxsource.GitRepositoryKind,
+			Name:      "source",
+			Namespace: namespace,
+		}
+	})
+}
+
+func (suite *GitOpsFluxSourceSuite) waitForPrepareError(key client.ObjectKey) string {
+	g := gomega.NewWithT(suite.T())
+
+	var prepareError string
+	g.Eventually(func() string {
+		prepareError = suite.getKluctlDeployment(key).Status.LastPrepareError
+		return prepareError
+	}, timeout, time.Second).ShouldNot(gomega.BeEmpty())
+
+	readiness := suite.getReadiness(suite.getKluctlDeployment(key))
+	if assert.NotNil(suite.T(), readiness) {
+		assert.Equal(suite.T(), metav1.ConditionFalse, readiness.Status)
+		assert.Equal(suite.T(), kluctlv1.PrepareFailedReason, readiness.Reason)
+	}
+	return prepareError
+}
+
+func (suite *GitOpsFluxSourceSuite) TestCrossNamespaceRefRejected() {
+	p := test_project.NewTestProject(suite.T())
+	otherNamespace := p.TestSlug() + "-other"
+	createNamespace(suite.T(), suite.k, otherNamespace)
+
+	key := suite.createFluxSourceKluctlDeployment(p, otherNamespace)
+
+	prepareError := suite.waitForPrepareError(key)
+	assert.Contains(suite.T(), prepareError, "cross-namespace references to Flux sources are not allowed")
+	assert.Nil(suite.T(), suite.getKluctlDeployment(key).Status.ProjectKey)
+}
+
+func (suite *GitOpsFluxSourceSuite) TestSameNamespaceRefAllowed() {
+	p := test_project.NewTestProject(suite.T())
+
+	// an explicit namespace equal to the KluctlDeployment's namespace is not a cross-namespace reference. The Flux
+	// CRDs are not installed in the test cluster, so fetching the source fails for another reason
+	key := suite.createFluxSourceKluctlDeployment(p, suite.gitopsNamespace)
+
+	prepareError := suite.waitForPrepareError(key)
+	assert.NotContains(suite.T(), prepareError, "cross-namespace")
+}
@@ -0,0 +1,59 @@
+package e2e
+
+import (
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	v1 "k8s.io/api/core/v1"
+	"testing"
+)
+
+func TestMigrateDiscriminator(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_utils.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	addConfigMapDeployment(p, "cm1", map[string]string{}, resourceOpts{
+		name:      "cm1",
+		namespace: p.TestSlug(),
+	})
+	addConfigMapDeployment(p, "cm2", map[string]string{}, resourceOpts{
+		name:      "cm2",
+		namespace: p.TestSlug(),
+	})
+
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+
+	oldDiscriminator := p.Discriminator("test")
+	newDiscriminator := p.TestSlug() + "-new"
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+		_ = target.SetNestedField(newDiscriminator, "discriminator")
+	})
+
+	// cm2 gets relabelled by someone else and must not be touched
+	patchObject(t, k, v1.SchemeGroupVersion.WithResource("configmaps"), p.TestSlug(), "cm2", func(o *uo.UnstructuredObject) {
+		o.SetK8sLabel("kluctl.io/discriminator", "other")
+	})
+
+	p.KluctlMust(t, "migrate-discriminator", "--yes", "-t", "test", "--from", oldDiscriminator, "--dry-run")
+	cm1 := assertConfigMapExists(t, k, p.TestSlug(), "cm1")
+	assertNestedFieldEquals(t, cm1, oldDiscriminator, "metadata", "labels", "kluctl.io/discriminator")
+
+	p.KluctlMust(t, "migrate-discriminator", "--yes", "-t", "test", "--from", oldDiscriminator)
+	cm1 = assertConfigMapExists(t, k, p.TestSlug(), "cm1")
+	assertNestedFieldEquals(t, cm1, newDiscriminator, "metadata", "labels", "kluctl.io/discriminator")
+	cm2 := assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+	assertNestedFieldEquals(t, cm2, "other", "metadata", "labels", "kluctl.io/discriminator")
+
+	// cm1 is now managed via the new discriminator
+	p.DeleteKustomizeDeployment("cm1")
+	p.KluctlMust(t, "prune", "--yes", "-t", "test")
+	assertConfigMapNotExists(t, k, p.TestSlug(), "cm1")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+}
@@ -1,8 +1,10 @@
 package e2e
 
 import (
+	"context"
 	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -125,3 +127,59 @@ func TestPruneForceManaged(t *testing.T) {
 	p.KluctlMust(t, "prune", "--yes", "-t", "test")
 	assertConfigMapNotExists(t, k, p.TestSlug(), "cm2")
 }
+
+func TestPruneDangerousOwnerDependents(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_utils.NewTestProject(t)
+
+	createNamespace(t, k, p.TestSlug())
+
+	// the default policy "warn" would prune cm2 anyway
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+		_ = target.SetNestedField("fail", "policies", "dangerousPrune")
+	})
+
+	addConfigMapDeployment(p, "cm1", map[string]string{}, resourceOpts{
+		name:      "cm1",
+		namespace: p.TestSlug(),
+	})
+	addConfigMapDeployment(p, "cm2", map[string]string{}, resourceOpts{
+		name:      "cm2",
+		namespace: p.TestSlug(),
+	})
+
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm1")
+	cm2 := assertConfigMapExists(t, k, p.TestSlug(), "cm2")
+
+	// the dependent is not managed by kluctl and thus has no discriminator
+	dependent := v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dependent",
+			Namespace: p.TestSlug(),
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+				Name:       "cm2",
+				UID:        types.UID(cm2.GetK8sUid()),
+			}},
+		},
+	}
+	err := k.Client.Create(context.Background(), &dependent)
+	assert.NoError(t, err)
+
+	p.DeleteKustomizeDeployment("cm2")
+
+	_, stderr, err := p.Kluctl(t, "prune", "--yes", "-t", "test")
+	assert.Error(t, err)
+	assert.Contains(t, stderr, "depend on this object via ownerReferences")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm1")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm2") // refused because it owns the dependent
+
+	p.KluctlMust(t, "prune", "--yes", "-t", "test", "--allow-dangerous-prune")
+	assertConfigMapExists(t, k, p.TestSlug(), "cm1")
+	assertConfigMapNotExists(t, k, p.TestSlug(), "cm2")
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// versions contains the Kubernetes minor versions for which OpenAPI schemas are bundled into kluctl. Only add versions
+// here together with the generated schema files, as kluctl falls back to the schemas of the connected cluster (or
+// --schema-dir) for versions that are not bundled.
+var versions = []string{
+	"1.23",
+}
+
+const swaggerUrl = "https://raw.githubusercontent.com/kubernetes/kubernetes/release-%s/api/openapi-spec/swagger.json"
+
+func main() {
+	outDir := flag.String("out-dir", "../pkg/schemas/k8s", "Output directory")
+	version := flag.String("version", "", "Only generate schemas for the given version")
+	swaggerFile := flag.String("swagger-file", "", "Use the given swagger.json instead of downloading it. Requires --version")
+	flag.Parse()
+
+	if *swaggerFile != "" {
+		if *version == "" {
+			panic("--swagger-file requires --version")
+		}
+		b, err := os.ReadFile(*swaggerFile)
+		if err != nil {
+			panic(err)
+		}
+		err = writeSchemas(*outDir, *version, b)
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	for _, v := range versions {
+		if *version != "" && *version != v {
+			continue
+		}
+		b, err := download(fmt.Sprintf(swaggerUrl, v))
+		if err != nil {
+			panic(err)
+		}
+		err = writeSchemas(*outDir, v, b)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func download(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func writeSchemas(outDir string, version string, swagger []byte) error {
+	var doc struct {
+		Definitions map[string]any `json:"definitions"`
+	}
+	err := json.Unmarshal(swagger, &doc)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range doc.Definitions {
+		doc.Definitions[k] = stripDescriptions(v)
+	}
+
+	// these are represented as plain strings in swagger.json, while the API server also accepts numbers
+	intOrString := map[string]any{"x-kubernetes-int-or-string": true}
+	doc.Definitions["io.k8s.apimachinery.pkg.api.resource.Quantity"] = intOrString
+	doc.Definitions["io.k8s.apimachinery.pkg.util.intstr.IntOrString"] = intOrString
+
+	b, err := json.Marshal(&doc)
+	if err != nil {
+		return err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	gz, err := gzip.NewWriterLevel(buf, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	_, err = gz.Write(b)
+	if err != nil {
+		return err
+	}
+	err = gz.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(outDir, 0o755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, fmt.Sprintf("v%s.json.gz", version)), buf.Bytes(), 0o644)
+}
+
+func stripDescriptions(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		delete(x, "description")
+		for k, v2 := range x {
+			if k == "properties" {
+				if props, ok := v2.(map[string]any); ok {
+					for pk, pv := range props {
+						props[pk] = stripDescriptions(pv)
+					}
+					continue
+				}
+			}
+			x[k] = stripDescriptions(v2)
+		}
+		return x
+	case []any:
+		for i, v2 := range x {
+			x[i] = stripDescriptions(v2)
+		}
+		return x
+	default:
+		return v
+	}
+}
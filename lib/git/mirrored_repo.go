@@ -438,6 +438,32 @@ func (g *MirroredGitRepo) GetObjectByHash(hash string) (object.Object, error) {
 	return object.GetObject(r.Storer, h)
 }
 
+// IsAncestor returns true if the commit with the hash ancestor is the same as or an ancestor of the commit with the
+// hash commit
+func (g *MirroredGitRepo) IsAncestor(ancestor string, commit string) (bool, error) {
+	if !g.IsLocked() || !g.hasUpdated {
+		panic("tried to read commits from a project that is not locked/updated")
+	}
+
+	r, err := git.PlainOpen(g.mirrorDir)
+	if err != nil {
+		return false, err
+	}
+
+	c1, err := object.GetCommit(r.Storer, plumbing.NewHash(ancestor))
+	if err != nil {
+		return false, fmt.Errorf("failed to get commit %s: %w", ancestor, err)
+	}
+	c2, err := object.GetCommit(r.Storer, plumbing.NewHash(commit))
+	if err != nil {
+		return false, fmt.Errorf("failed to get commit %s: %w", commit, err)
+	}
+	if c1.Hash == c2.Hash {
+		return true, nil
+	}
+	return c1.IsAncestor(c2)
+}
+
 func buildMirrorRepoName(u types.GitUrl) string {
 	h := sha256.New()
 	h.Write([]byte(u.String()))
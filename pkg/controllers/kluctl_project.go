@@ -11,6 +11,7 @@ import (
 	"github.com/kluctl/kluctl/lib/go-jinja2"
 	"github.com/kluctl/kluctl/v2/pkg/clouds/aws"
 	internal_metrics "github.com/kluctl/kluctl/v2/pkg/controllers/metrics"
+	"github.com/kluctl/kluctl/v2/pkg/fluxsource"
 	helm_auth "github.com/kluctl/kluctl/v2/pkg/helm/auth"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
@@ -66,6 +67,9 @@ type preparedProject struct {
 	projectDir string
 
 	soClients []*sourceoverride.ProxyClientController
+
+	fluxSource   *uo.UnstructuredObject
+	fluxArtifact *fluxsource.Artifact
 }
 
 type preparedTarget struct {
@@ -185,6 +189,12 @@ func prepareProject(ctx context.Context,
 			if err != nil {
 				return nil, err
 			}
+		} else if pp.obj.Spec.Source.SourceRef != nil {
+			pth = pp.obj.Spec.Source.SourceRef.Path
+			err = pp.fetchFluxSource(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch Flux source: %w", err)
+			}
 		} else {
 			return nil, fmt.Errorf("missing source spec")
 		}
@@ -699,6 +709,14 @@ func (pt *preparedTarget) addCommandResultInfo(ctx context.Context, cmdResult *r
 		return err
 	}
 
+	if pt.pp.fluxArtifact != nil {
+		// artifacts from the Flux source-controller don't contain the .git dir, so we have to build the info ourselves
+		cmdResult.GitInfo = fluxsource.BuildGitInfo(pt.pp.fluxSource, pt.pp.fluxArtifact, pt.pp.obj.Spec.Source.SourceRef.Path)
+		if pt.pp.obj.Status.ProjectKey != nil {
+			cmdResult.ProjectKey = *pt.pp.obj.Status.ProjectKey
+		}
+	}
+
 	// the ref is not properly set by addGitInfo due to the way the repo cache checks out by commit
 	if pt.pp.co.CheckedOutRef != (gittypes.GitRef{}) {
 		cmdResult.GitInfo.Ref = &pt.pp.co.CheckedOutRef
@@ -734,6 +752,7 @@ func (pt *preparedTarget) writeCommandResult(ctx context.Context, cmdResult *res
 	if !needStore {
 		log.Info("skipping storing of empty command result")
 	} else if pt.pp.r.ResultStore != nil {
+		pt.updateResultRetention()
 		log.Info(fmt.Sprintf("Writing command result %s", cmdResult.Id))
 		err = pt.pp.r.ResultStore.WriteCommandResult(cmdResult)
 		if err != nil {
@@ -750,6 +769,14 @@ func (pt *preparedTarget) writeCommandResult(ctx context.Context, cmdResult *res
 	return nil
 }
 
+// updateResultRetention makes sure that the result store applies the retention policies of the KluctlDeployment
+// before results are written and old results are cleaned up
+func (pt *preparedTarget) updateResultRetention() {
+	if pt.pp.r.ResultRetention != nil {
+		pt.pp.r.ResultRetention.SetKluctlDeploymentPolicies(pt.pp.obj.Name, pt.pp.obj.Namespace, pt.pp.obj.Spec.ResultRetention)
+	}
+}
+
 func (pt *preparedTarget) writeValidateResult(ctx context.Context, validateResult *result.ValidateResult, rr *kluctlv1.ManualRequestResult, reconcileId string, objectsHash string) error {
 	log := ctrl.LoggerFrom(ctx)
 
@@ -775,7 +802,12 @@ func (pt *preparedTarget) writeValidateResult(ctx context.Context, validateResul
 		return err
 	}
 
+	if pt.pp.fluxArtifact != nil && pt.pp.obj.Status.ProjectKey != nil {
+		validateResult.ProjectKey = *pt.pp.obj.Status.ProjectKey
+	}
+
 	if pt.pp.r.ResultStore != nil {
+		pt.updateResultRetention()
 		log.Info(fmt.Sprintf("Writing validate result %s", validateResult.Id))
 		err = pt.pp.r.ResultStore.WriteValidateResult(validateResult)
 		if err != nil {
@@ -788,7 +820,7 @@ func (pt *preparedTarget) writeValidateResult(ctx context.Context, validateResul
 
 func (pt *preparedTarget) kluctlDeployOrPokeImages(deployMode string, targetContext *target_context.TargetContext) (*result.CommandResult, error) {
 	if deployMode == kluctlv1.KluctlDeployModeFull {
-		return pt.kluctlDeploy(targetContext), nil
+		return pt.kluctlDeploy(targetContext, nil), nil
 	} else if deployMode == kluctlv1.KluctlDeployPokeImages {
 		return pt.kluctlPokeImages(targetContext), nil
 	} else {
@@ -796,7 +828,9 @@ func (pt *preparedTarget) kluctlDeployOrPokeImages(deployMode string, targetCont
 	}
 }
 
-func (pt *preparedTarget) kluctlDeploy(targetContext *target_context.TargetContext) *result.CommandResult {
+// kluctlDeploy performs a deployment. If onlyRefs is non-nil, only the given objects are applied and pruning is
+// skipped, which is used to remediate drift.
+func (pt *preparedTarget) kluctlDeploy(targetContext *target_context.TargetContext, onlyRefs map[k8s.ObjectRef]bool) *result.CommandResult {
 	timer := prometheus.NewTimer(internal_metrics.NewKluctlDeploymentDuration(pt.pp.obj.ObjectMeta.Namespace, pt.pp.obj.ObjectMeta.Name, pt.pp.obj.Spec.DeployMode))
 	defer timer.ObserveDuration()
 	cmd := commands.NewDeployCommand(targetContext)
@@ -806,8 +840,9 @@ func (pt *preparedTarget) kluctlDeploy(targetContext *target_context.TargetConte
 	cmd.AbortOnError = pt.pp.obj.Spec.AbortOnError
 	cmd.ReadinessTimeout = time.Minute * 10
 	cmd.NoWait = pt.pp.obj.Spec.NoWait
-	cmd.Prune = pt.pp.obj.Spec.Prune
+	cmd.Prune = pt.pp.obj.Spec.Prune && onlyRefs == nil
 	cmd.WaitPrune = false
+	cmd.OnlyRefs = onlyRefs
 
 	cmdResult := cmd.Run(nil)
 	return cmdResult
@@ -826,9 +861,10 @@ func (pt *preparedTarget) kluctlPrune(targetContext *target_context.TargetContex
 	timer := prometheus.NewTimer(internal_metrics.NewKluctlDeploymentDuration(pt.pp.obj.ObjectMeta.Namespace, pt.pp.obj.ObjectMeta.Name, pt.pp.obj.Spec.DeployMode))
 	defer timer.ObserveDuration()
 	cmd := commands.NewPruneCommand("", targetContext, false)
+	cmd.ResultStore = pt.pp.r.ResultStore
 
-	cmdResult := cmd.Run(func(refs []k8s.ObjectRef) error {
-		pt.printDeletedRefs(targetContext.SharedContext.Ctx, refs)
+	cmdResult := cmd.Run(func(report *commands.PruneReport) error {
+		pt.printDeletedRefs(targetContext.SharedContext.Ctx, report.Orphans)
 		return nil
 	})
 	return cmdResult
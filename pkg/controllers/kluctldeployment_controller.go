@@ -10,11 +10,14 @@ import (
 	"github.com/kluctl/kluctl/lib/yaml"
 	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
 	internal_metrics "github.com/kluctl/kluctl/v2/pkg/controllers/metrics"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/notifications"
 	"github.com/kluctl/kluctl/v2/pkg/results"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/metrics"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
 	kuberecorder "k8s.io/client-go/tools/record"
@@ -40,12 +43,24 @@ type KluctlDeploymentReconciler struct {
 	UseSystemPython       bool
 	DryRun                bool
 
+	// AllowCrossNamespaceRefs allows KluctlDeployments to reference Flux sources in other namespaces.
+	AllowCrossNamespaceRefs bool
+
 	SshPool *ssh_pool.SshPool
 
-	ResultStore results.ResultStore
+	ResultStore     results.ResultStore
+	ResultRetention *results.Retention
+
+	Notifier *notifications.Notifier
+
+	// ShardSelector restricts reconciliation to the KluctlDeployments of this controller shard. nil means that all
+	// KluctlDeployments are reconciled.
+	ShardSelector labels.Selector
 
 	mutex               sync.Mutex
 	resourceVersionsMap map[client.ObjectKey]map[k8s.ObjectRef]string
+
+	clusterVarsWatcher *clusterVarsWatcher
 }
 
 // KluctlDeploymentReconcilerOpts contains options for the BaseReconciler.
@@ -56,6 +71,8 @@ type KluctlDeploymentReconcilerOpts struct {
 // +kubebuilder:rbac:groups=gitops.kluctl.io,resources=kluctldeployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gitops.kluctl.io,resources=kluctldeployments/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=gitops.kluctl.io,resources=kluctldeployments/finalizers,verbs=get;create;update;patch;delete
+// +kubebuilder:rbac:groups=gitops.kluctl.io,resources=deployfreezes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=gitrepositories;ocirepositories;buckets,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=configmaps;secrets;serviceaccounts,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
@@ -77,6 +94,12 @@ func (r *KluctlDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	if err := r.ApiReader.Get(ctx, req.NamespacedName, obj); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	if !r.isInShard(obj) {
+		// the KluctlDeployment was moved to another shard, which is now responsible for it
+		log.Info("KluctlDeployment is not part of this shard anymore, skipping")
+		r.stopClusterVarsWatches(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
 
 	retryInterval := obj.Spec.GetRetryInterval()
 	interval := obj.Spec.Interval.Duration
@@ -184,10 +207,21 @@ func (r *KluctlDeploymentReconciler) doReconcile(
 		return &ctrl.Result{Requeue: true}, nil
 	}
 
-	_, err = r.reconcileFullRequest(ctx, timeoutCtx, obj, reconcileId)
+	_, dependencyMsg, err := r.reconcileFullRequest(ctx, timeoutCtx, obj, reconcileId)
 	if err != nil {
 		return nil, err
 	}
+	if dependencyMsg != "" {
+		// we'll also get triggered when the dependency becomes ready, so the retry interval is just a fallback
+		internal_metrics.NewKluctlLastObjectStatus(obj.Namespace, obj.Name).Set(0.0)
+		err = fmt.Errorf("waiting for dependencies: %s", dependencyMsg)
+		patchErr = r.patchReadyCondition(ctx, obj, metav1.ConditionFalse, kluctlv1.DependencyNotReadyReason, err.Error())
+		if patchErr != nil {
+			err = multierror.Append(err, patchErr)
+			return nil, err
+		}
+		return &ctrl.Result{RequeueAfter: obj.Spec.GetRetryInterval()}, err
+	}
 
 	var ctrlResult ctrl.Result
 	ctrlResult.RequeueAfter = r.nextReconcileTime(obj).Sub(time.Now())
@@ -318,6 +352,9 @@ func (r *KluctlDeploymentReconciler) buildFinalStatus(ctx context.Context, obj *
 	}
 
 	if lastDeployResult == nil {
+		if obj.Status.DeployPostponedUntil != nil {
+			return "initial deployment is postponed as the deploy window is closed", kluctlv1.DeployWindowClosedReason
+		}
 		return "deployment status unknown", kluctlv1.DeployFailedReason
 	}
 
@@ -364,6 +401,9 @@ func (r *KluctlDeploymentReconciler) nextReconcileTime(obj *kluctlv1.KluctlDeplo
 	if obj.Spec.Validate && t3 != nil && t3.Before(t1) {
 		t1 = *t3
 	}
+	if obj.Status.DeployPostponedUntil != nil && obj.Status.DeployPostponedUntil.Time.Before(t1) {
+		t1 = obj.Status.DeployPostponedUntil.Time
+	}
 	return t1
 }
 
@@ -414,11 +454,20 @@ func (r *KluctlDeploymentReconciler) nextValidateTime(obj *kluctlv1.KluctlDeploy
 }
 
 func (r *KluctlDeploymentReconciler) finalize(ctx context.Context, obj *kluctlv1.KluctlDeployment, reconcileId string) (ctrl.Result, error) {
-	r.doFinalize(ctx, obj, reconcileId)
+	err := r.doFinalize(ctx, obj, reconcileId)
+	if err != nil {
+		// keep the finalizer so that deletion is retried
+		return ctrl.Result{}, err
+	}
 
 	r.mutex.Lock()
 	delete(r.resourceVersionsMap, client.ObjectKeyFromObject(obj))
 	r.mutex.Unlock()
+	r.stopClusterVarsWatches(client.ObjectKeyFromObject(obj))
+
+	if r.ResultRetention != nil {
+		r.ResultRetention.ForgetKluctlDeployment(obj.Name, obj.Namespace)
+	}
 
 	// Remove our finalizer from the list and update it
 	patch := client.MergeFrom(obj.DeepCopy())
@@ -431,25 +480,37 @@ func (r *KluctlDeploymentReconciler) finalize(ctx context.Context, obj *kluctlv1
 	return ctrl.Result{}, nil
 }
 
-func (r *KluctlDeploymentReconciler) doFinalize(ctx context.Context, obj *kluctlv1.KluctlDeployment, reconcileId string) {
+func (r *KluctlDeploymentReconciler) doFinalize(ctx context.Context, obj *kluctlv1.KluctlDeployment, reconcileId string) error {
 	log := ctrl.LoggerFrom(ctx)
 
 	log.Info("Finalizing")
 
 	if !obj.Spec.Delete || obj.Spec.Suspend {
-		return
+		return nil
 	}
 
 	if obj.Status.ProjectKey == nil || obj.Status.TargetKey == nil {
 		log.V(1).Info("No project/target key set, skipping deletion")
-		return
+		return nil
+	}
+
+	lastDeployResult, err := obj.Status.GetLastDeployResult()
+	if err != nil {
+		// without the last deploy result, we can't verify the target policies, so we must not delete anything
+		return fmt.Errorf("failed to parse last deploy result, refusing to delete (set spec.delete to false or suspend the KluctlDeployment to skip deletion): %w", err)
+	} else if lastDeployResult != nil {
+		err = target_context.CheckTargetPolicies(&lastDeployResult.Target, obj.Status.TargetKey.ClusterId, "delete")
+		if err != nil {
+			log.Error(err, "skipping deletion due to target policies")
+			return nil
+		}
 	}
 
 	log.Info(fmt.Sprintf("Deleting objects with discriminator '%s'", obj.Status.TargetKey.Discriminator))
 
 	pp, err := prepareProject(ctx, r, obj, false)
 	if err != nil {
-		return
+		return nil
 	}
 	defer pp.cleanup(ctx)
 
@@ -465,6 +526,7 @@ func (r *KluctlDeploymentReconciler) doFinalize(ctx context.Context, obj *kluctl
 			log.Error(err, "write delete command result failed")
 		}
 	}
+	return nil
 }
 
 func (r *KluctlDeploymentReconciler) exportDeploymentObjectToProm(obj *kluctlv1.KluctlDeployment) {
@@ -507,5 +569,9 @@ func (r *KluctlDeploymentReconciler) exportDeploymentObjectToProm(obj *kluctlv1.
 	} else if obj.Spec.Source.URL != nil {
 		internal_metrics.NewKluctlSourceSpec(obj.Namespace, obj.Name,
 			*obj.Spec.Source.URL, obj.Spec.Source.Path, obj.Spec.Source.Ref.String()).Set(0.0)
+	} else if obj.Spec.Source.SourceRef != nil {
+		key := getFluxSourceKey(obj)
+		internal_metrics.NewKluctlSourceSpec(obj.Namespace, obj.Name,
+			buildFluxSourceIndexValue(obj.Spec.Source.SourceRef.Kind, key.Namespace, key.Name), obj.Spec.Source.SourceRef.Path, "").Set(0.0)
 	}
 }
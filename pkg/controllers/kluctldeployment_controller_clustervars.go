@@ -0,0 +1,402 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sync"
+	"time"
+)
+
+// clusterVarsDebounce is the time to wait after a change of a watched cluster vars object before reconciliation is
+// triggered. This avoids multiple reconciliations when multiple objects are modified at once.
+const clusterVarsDebounce = 10 * time.Second
+
+// clusterVarsWatcher watches the cluster objects that were read via clusterConfigMap, clusterSecret and
+// clusterObject vars and triggers reconciliation of the affected KluctlDeployments when these objects change.
+// Informers are shared between all KluctlDeployments that watch the same resource in the same namespace with the same
+// credentials, and are stopped when the last KluctlDeployment stops watching.
+type clusterVarsWatcher struct {
+	ctx      context.Context
+	events   chan event.GenericEvent
+	debounce time.Duration
+
+	newDynamicClient func(restConfig *rest.Config) (dynamic.Interface, error)
+
+	mutex     sync.Mutex
+	informers map[clusterVarsInformerKey]*clusterVarsInformer
+	watches   map[client.ObjectKey]*clusterVarsWatch
+}
+
+type clusterVarsWatchRef struct {
+	vars.ClusterVarsRef
+	GVR schema.GroupVersionResource
+}
+
+type clusterVarsInformerKey struct {
+	configKey string
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+type clusterVarsInformer struct {
+	informer cache.SharedIndexInformer
+	cancel   context.CancelFunc
+	refCount int
+}
+
+type clusterVarsWatch struct {
+	configKey string
+	refs      []clusterVarsWatchRef
+
+	ctx           context.Context
+	cancel        context.CancelFunc
+	registrations []clusterVarsRegistration
+
+	timerMutex sync.Mutex
+	timer      *time.Timer
+}
+
+type clusterVarsRegistration struct {
+	informerKey clusterVarsInformerKey
+	handle      cache.ResourceEventHandlerRegistration
+}
+
+// clusterVarsHandler handles the events of a shared informer for a single ref of a single KluctlDeployment.
+type clusterVarsHandler struct {
+	w   *clusterVarsWatcher
+	key client.ObjectKey
+	cw  *clusterVarsWatch
+	ref clusterVarsWatchRef
+
+	selector labels.Selector
+
+	initialListMutex sync.Mutex
+	initialList      map[types.NamespacedName]bool
+}
+
+func newClusterVarsWatcher(ctx context.Context) *clusterVarsWatcher {
+	return &clusterVarsWatcher{
+		ctx:      ctx,
+		events:   make(chan event.GenericEvent),
+		debounce: clusterVarsDebounce,
+		newDynamicClient: func(restConfig *rest.Config) (dynamic.Interface, error) {
+			return dynamic.NewForConfig(restConfig)
+		},
+		informers: map[clusterVarsInformerKey]*clusterVarsInformer{},
+		watches:   map[client.ObjectKey]*clusterVarsWatch{},
+	}
+}
+
+// buildClusterVarsConfigKey builds a key that identifies the cluster and credentials of the given config, so that
+// informers are only shared between KluctlDeployments which have the same access to the cluster.
+func buildClusterVarsConfigKey(restConfig *rest.Config) (string, error) {
+	b, err := json.Marshal(map[string]any{
+		"host":            restConfig.Host,
+		"apiPath":         restConfig.APIPath,
+		"username":        restConfig.Username,
+		"password":        restConfig.Password,
+		"bearerToken":     restConfig.BearerToken,
+		"bearerTokenFile": restConfig.BearerTokenFile,
+		"impersonate":     restConfig.Impersonate,
+		"certFile":        restConfig.CertFile,
+		"certData":        restConfig.CertData,
+		"keyFile":         restConfig.KeyFile,
+		"keyData":         restConfig.KeyData,
+	})
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// update ensures that exactly the given cluster objects are watched for the KluctlDeployment with the given key.
+// Existing watches are kept if nothing changed.
+func (w *clusterVarsWatcher) update(key client.ObjectKey, restConfig *rest.Config, refs []clusterVarsWatchRef) error {
+	configKey, err := buildClusterVarsConfigKey(restConfig)
+	if err != nil {
+		return err
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	old, ok := w.watches[key]
+	if ok {
+		if old.configKey == configKey && reflect.DeepEqual(old.refs, refs) {
+			return nil
+		}
+		w.releaseWatch(old)
+		delete(w.watches, key)
+	}
+
+	if len(refs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(w.ctx)
+	cw := &clusterVarsWatch{
+		configKey: configKey,
+		refs:      refs,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	var dc dynamic.Interface
+	for _, ref := range refs {
+		ik := clusterVarsInformerKey{
+			configKey: configKey,
+			gvr:       ref.GVR,
+			namespace: ref.Namespace,
+		}
+		inf, ok := w.informers[ik]
+		if !ok {
+			if dc == nil {
+				dc, err = w.newDynamicClient(restConfig)
+				if err != nil {
+					w.releaseWatch(cw)
+					return err
+				}
+			}
+			inf = w.startInformer(dc, ik)
+			w.informers[ik] = inf
+		}
+		inf.refCount++
+
+		h := &clusterVarsHandler{
+			w:           w,
+			key:         key,
+			cw:          cw,
+			ref:         ref,
+			selector:    labels.SelectorFromSet(ref.Labels),
+			initialList: map[types.NamespacedName]bool{},
+		}
+		handle, err := inf.informer.AddEventHandler(h)
+		if err != nil {
+			inf.refCount--
+			w.releaseInformer(ik, inf)
+			w.releaseWatch(cw)
+			return err
+		}
+		cw.registrations = append(cw.registrations, clusterVarsRegistration{
+			informerKey: ik,
+			handle:      handle,
+		})
+		go h.checkInitialListDeletions(handle)
+	}
+
+	w.watches[key] = cw
+	return nil
+}
+
+func (w *clusterVarsWatcher) startInformer(dc dynamic.Interface, ik clusterVarsInformerKey) *clusterVarsInformer {
+	ctx, cancel := context.WithCancel(w.ctx)
+	informer := dynamicinformer.NewFilteredDynamicInformer(dc, ik.gvr, ik.namespace, 0, cache.Indexers{}, nil)
+	_ = informer.Informer().SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+		ctrl.LoggerFrom(w.ctx).V(1).Info("watching cluster vars failed", "gvr", ik.gvr.String(), "namespace", ik.namespace, "error", err.Error())
+	})
+	go informer.Informer().Run(ctx.Done())
+	return &clusterVarsInformer{
+		informer: informer.Informer(),
+		cancel:   cancel,
+	}
+}
+
+// releaseInformer stops the informer if nobody is using it anymore. Must be called with the mutex held.
+func (w *clusterVarsWatcher) releaseInformer(ik clusterVarsInformerKey, inf *clusterVarsInformer) {
+	if inf.refCount > 0 {
+		return
+	}
+	inf.cancel()
+	delete(w.informers, ik)
+}
+
+// releaseWatch removes all event handlers of the given watch and releases the informers. Must be called with the
+// mutex held.
+func (w *clusterVarsWatcher) releaseWatch(cw *clusterVarsWatch) {
+	cw.cancel()
+	cw.timerMutex.Lock()
+	if cw.timer != nil {
+		cw.timer.Stop()
+	}
+	cw.timerMutex.Unlock()
+
+	for _, r := range cw.registrations {
+		inf, ok := w.informers[r.informerKey]
+		if !ok {
+			continue
+		}
+		_ = inf.informer.RemoveEventHandler(r.handle)
+		inf.refCount--
+		w.releaseInformer(r.informerKey, inf)
+	}
+	cw.registrations = nil
+}
+
+func (w *clusterVarsWatcher) trigger(key client.ObjectKey, cw *clusterVarsWatch) {
+	cw.timerMutex.Lock()
+	defer cw.timerMutex.Unlock()
+
+	if cw.ctx.Err() != nil {
+		return
+	}
+	if cw.timer != nil {
+		cw.timer.Stop()
+	}
+	cw.timer = time.AfterFunc(w.debounce, func() {
+		ctrl.LoggerFrom(w.ctx).Info("cluster vars changed, triggering reconciliation", "kluctlDeployment", key.String())
+		obj := &kluctlv1.KluctlDeployment{}
+		obj.Namespace = key.Namespace
+		obj.Name = key.Name
+		select {
+		case w.events <- event.GenericEvent{Object: obj}:
+		case <-cw.ctx.Done():
+		}
+	})
+}
+
+func (w *clusterVarsWatcher) stop(key client.ObjectKey) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	cw, ok := w.watches[key]
+	if !ok {
+		return
+	}
+	w.releaseWatch(cw)
+	delete(w.watches, key)
+}
+
+func (h *clusterVarsHandler) matches(obj interface{}) (types.NamespacedName, bool) {
+	if d, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = d.Obj
+	}
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return types.NamespacedName{}, false
+	}
+	name := types.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetName()}
+	if h.ref.Name != "" {
+		return name, o.GetName() == h.ref.Name
+	}
+	return name, h.selector.Matches(labels.Set(o.GetLabels()))
+}
+
+// OnAdd is also called for all objects of the initial list, including the objects that existed before the handler was
+// added to an already running informer. These are compared with the resource versions seen while loading vars, so
+// that objects created or modified between loading vars and starting the watch are not missed.
+func (h *clusterVarsHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	name, ok := h.matches(obj)
+	if !ok {
+		return
+	}
+	if isInInitialList {
+		h.initialListMutex.Lock()
+		h.initialList[name] = true
+		h.initialListMutex.Unlock()
+
+		o, _ := meta.Accessor(obj)
+		rv, ok := h.ref.ResourceVersions[name]
+		if ok && rv == o.GetResourceVersion() {
+			return
+		}
+	}
+	h.w.trigger(h.key, h.cw)
+}
+
+func (h *clusterVarsHandler) OnUpdate(oldObj, newObj interface{}) {
+	_, oldMatches := h.matches(oldObj)
+	_, newMatches := h.matches(newObj)
+	if oldMatches || newMatches {
+		h.w.trigger(h.key, h.cw)
+	}
+}
+
+func (h *clusterVarsHandler) OnDelete(obj interface{}) {
+	if _, ok := h.matches(obj); ok {
+		h.w.trigger(h.key, h.cw)
+	}
+}
+
+// checkInitialListDeletions waits for the initial list to be delivered and then triggers reconciliation if any of
+// the objects seen while loading vars was deleted in the meantime.
+func (h *clusterVarsHandler) checkInitialListDeletions(handle cache.ResourceEventHandlerRegistration) {
+	if !cache.WaitForCacheSync(h.cw.ctx.Done(), handle.HasSynced) {
+		return
+	}
+
+	h.initialListMutex.Lock()
+	defer h.initialListMutex.Unlock()
+	for name := range h.ref.ResourceVersions {
+		if !h.initialList[name] {
+			h.w.trigger(h.key, h.cw)
+			return
+		}
+	}
+}
+
+// updateClusterVarsWatches records the cluster objects read by the VarsLoader while loading the target and starts
+// watching them.
+func (r *KluctlDeploymentReconciler) updateClusterVarsWatches(ctx context.Context, obj *kluctlv1.KluctlDeployment, targetContext *target_context.TargetContext) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if r.clusterVarsWatcher == nil || targetContext.SharedContext.K == nil {
+		return
+	}
+
+	key := client.ObjectKeyFromObject(obj)
+
+	mapper, err := targetContext.SharedContext.K.ToRESTMapper()
+	if err != nil {
+		log.Error(err, "failed to get REST mapper for cluster vars watches")
+		return
+	}
+
+	var refs []clusterVarsWatchRef
+	for _, ref := range targetContext.SharedContext.VarsLoader.GetClusterVarsRefs() {
+		m, err := mapper.RESTMapping(ref.GVK.GroupKind(), ref.GVK.Version)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("failed to get REST mapping for %s", ref.GVK.String()))
+			continue
+		}
+		refs = append(refs, clusterVarsWatchRef{
+			ClusterVarsRef: ref,
+			GVR:            m.Resource,
+		})
+	}
+
+	restConfig, err := targetContext.SharedContext.K.ToRESTConfig()
+	if err != nil {
+		log.Error(err, "failed to get REST config for cluster vars watches")
+		return
+	}
+
+	err = r.clusterVarsWatcher.update(key, restConfig, refs)
+	if err != nil {
+		log.Error(err, "failed to watch cluster vars")
+	}
+}
+
+func (r *KluctlDeploymentReconciler) stopClusterVarsWatches(key client.ObjectKey) {
+	if r.clusterVarsWatcher != nil {
+		r.clusterVarsWatcher.stop(key)
+	}
+}
@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+	"time"
+)
+
+var configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func buildClusterVarsTestConfigMap(namespace string, name string, resourceVersion string, labels map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       namespace,
+			Name:            name,
+			ResourceVersion: resourceVersion,
+			Labels:          labels,
+		},
+	}
+}
+
+func buildClusterVarsTestRef(namespace string, name string, labels map[string]string, resourceVersions map[types.NamespacedName]string) clusterVarsWatchRef {
+	return clusterVarsWatchRef{
+		ClusterVarsRef: vars.ClusterVarsRef{
+			GVK:              schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+			Namespace:        namespace,
+			Name:             name,
+			Labels:           labels,
+			ResourceVersions: resourceVersions,
+		},
+		GVR: configMapsGVR,
+	}
+}
+
+func newClusterVarsTestWatcher(t *testing.T, objs ...runtime.Object) (*clusterVarsWatcher, *dynamicfake.FakeDynamicClient) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	dc := dynamicfake.NewSimpleDynamicClient(scheme, objs...)
+
+	w := newClusterVarsWatcher(ctx)
+	w.debounce = 50 * time.Millisecond
+	w.newDynamicClient = func(restConfig *rest.Config) (dynamic.Interface, error) {
+		return dc, nil
+	}
+	return w, dc
+}
+
+func waitClusterVarsEvent(w *clusterVarsWatcher, timeout time.Duration) *client.ObjectKey {
+	select {
+	case e := <-w.events:
+		key := client.ObjectKeyFromObject(e.Object)
+		return &key
+	case <-time.After(timeout):
+		return nil
+	}
+}
+
+func updateClusterVarsTestConfigMap(t *testing.T, dc dynamic.Interface, cm *corev1.ConfigMap) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+	assert.NoError(t, err)
+	_, err = dc.Resource(configMapsGVR).Namespace(cm.Namespace).Update(context.Background(), &unstructured.Unstructured{Object: u}, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+}
+
+func TestClusterVarsWatcherSharedInformers(t *testing.T) {
+	w, _ := newClusterVarsTestWatcher(t)
+	restConfig := &rest.Config{Host: "https://cluster"}
+
+	kd1 := client.ObjectKey{Namespace: "kd", Name: "kd1"}
+	kd2 := client.ObjectKey{Namespace: "kd", Name: "kd2"}
+
+	assert.NoError(t, w.update(kd1, restConfig, []clusterVarsWatchRef{
+		buildClusterVarsTestRef("ns1", "cm1", nil, nil),
+		buildClusterVarsTestRef("ns1", "cm2", nil, nil),
+	}))
+	assert.NoError(t, w.update(kd2, restConfig, []clusterVarsWatchRef{
+		buildClusterVarsTestRef("ns1", "cm1", nil, nil),
+		buildClusterVarsTestRef("ns2", "cm1", nil, nil),
+	}))
+
+	getRefCounts := func() map[string]int {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+		ret := map[string]int{}
+		for ik, inf := range w.informers {
+			ret[ik.namespace] = inf.refCount
+		}
+		return ret
+	}
+
+	assert.Equal(t, map[string]int{"ns1": 3, "ns2": 1}, getRefCounts())
+
+	// different credentials must not share informers
+	assert.NoError(t, w.update(kd2, &rest.Config{Host: "https://cluster", BearerToken: "other"}, []clusterVarsWatchRef{
+		buildClusterVarsTestRef("ns1", "cm1", nil, nil),
+	}))
+	w.mutex.Lock()
+	assert.Len(t, w.informers, 2)
+	w.mutex.Unlock()
+
+	w.stop(kd2)
+	assert.Equal(t, map[string]int{"ns1": 2}, getRefCounts())
+
+	assert.NoError(t, w.update(kd1, restConfig, nil))
+	assert.Empty(t, getRefCounts())
+	w.mutex.Lock()
+	assert.Empty(t, w.watches)
+	w.mutex.Unlock()
+}
+
+func TestClusterVarsWatcherChanges(t *testing.T) {
+	cm1 := buildClusterVarsTestConfigMap("ns", "cm1", "1", map[string]string{"l": "v"})
+	cm2 := buildClusterVarsTestConfigMap("ns", "cm2", "1", nil)
+	w, dc := newClusterVarsTestWatcher(t, cm1, cm2)
+	restConfig := &rest.Config{Host: "https://cluster"}
+
+	kd1 := client.ObjectKey{Namespace: "kd", Name: "kd1"}
+	kd2 := client.ObjectKey{Namespace: "kd", Name: "kd2"}
+
+	assert.NoError(t, w.update(kd1, restConfig, []clusterVarsWatchRef{
+		buildClusterVarsTestRef("ns", "cm1", nil, map[types.NamespacedName]string{{Namespace: "ns", Name: "cm1"}: "1"}),
+	}))
+	assert.NoError(t, w.update(kd2, restConfig, []clusterVarsWatchRef{
+		buildClusterVarsTestRef("ns", "", map[string]string{"l": "v"}, map[types.NamespacedName]string{{Namespace: "ns", Name: "cm1"}: "1"}),
+	}))
+
+	// nothing changed since the vars were loaded
+	assert.Nil(t, waitClusterVarsEvent(w, 500*time.Millisecond))
+
+	// not watched by any of the KluctlDeployments
+	cm2.ResourceVersion = "2"
+	updateClusterVarsTestConfigMap(t, dc, cm2)
+	assert.Nil(t, waitClusterVarsEvent(w, 500*time.Millisecond))
+
+	// the label is removed, so kd2 must be triggered as well
+	cm1.ResourceVersion = "2"
+	cm1.Labels = nil
+	updateClusterVarsTestConfigMap(t, dc, cm1)
+	var triggered []client.ObjectKey
+	for i := 0; i < 2; i++ {
+		key := waitClusterVarsEvent(w, 5*time.Second)
+		if assert.NotNil(t, key) {
+			triggered = append(triggered, *key)
+		}
+	}
+	assert.ElementsMatch(t, []client.ObjectKey{kd1, kd2}, triggered)
+	assert.Nil(t, waitClusterVarsEvent(w, 500*time.Millisecond))
+
+	w.stop(kd1)
+	w.stop(kd2)
+	cm1.ResourceVersion = "3"
+	updateClusterVarsTestConfigMap(t, dc, cm1)
+	assert.Nil(t, waitClusterVarsEvent(w, 500*time.Millisecond))
+}
+
+func TestClusterVarsWatcherInitialList(t *testing.T) {
+	type testCase struct {
+		name          string
+		ref           clusterVarsWatchRef
+		expectTrigger bool
+	}
+	cm1Key := types.NamespacedName{Namespace: "ns", Name: "cm1"}
+	missingKey := types.NamespacedName{Namespace: "ns", Name: "missing"}
+	testCases := []testCase{
+		{name: "unchanged", ref: buildClusterVarsTestRef("ns", "cm1", nil, map[types.NamespacedName]string{cm1Key: "1"})},
+		{name: "modified", ref: buildClusterVarsTestRef("ns", "cm1", nil, map[types.NamespacedName]string{cm1Key: "0"}), expectTrigger: true},
+		{name: "created", ref: buildClusterVarsTestRef("ns", "cm1", nil, nil), expectTrigger: true},
+		{name: "deleted", ref: buildClusterVarsTestRef("ns", "missing", nil, map[types.NamespacedName]string{missingKey: "1"}), expectTrigger: true},
+		{name: "still missing", ref: buildClusterVarsTestRef("ns", "missing", nil, nil)},
+		{name: "labels unchanged", ref: buildClusterVarsTestRef("ns", "", map[string]string{"l": "v"}, map[types.NamespacedName]string{cm1Key: "1"})},
+		{name: "labels created", ref: buildClusterVarsTestRef("ns", "", map[string]string{"l": "v"}, nil), expectTrigger: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, _ := newClusterVarsTestWatcher(t, buildClusterVarsTestConfigMap("ns", "cm1", "1", map[string]string{"l": "v"}))
+			key := client.ObjectKey{Namespace: "kd", Name: "kd1"}
+
+			assert.NoError(t, w.update(key, &rest.Config{Host: "https://cluster"}, []clusterVarsWatchRef{tc.ref}))
+			triggered := waitClusterVarsEvent(w, time.Second)
+			if tc.expectTrigger {
+				assert.Equal(t, &key, triggered)
+			} else {
+				assert.Nil(t, triggered)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/meta"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"strings"
+)
+
+const dependsOnIndexKey = ".metadata.dependsOn"
+
+func buildDependencyKey(obj *kluctlv1.KluctlDeployment, d kluctlv1.KluctlDeploymentDependency) client.ObjectKey {
+	ns := d.Namespace
+	if ns == "" {
+		ns = obj.Namespace
+	}
+	return client.ObjectKey{Namespace: ns, Name: d.Name}
+}
+
+// indexDependsOn is used as field indexer so that dependants can be looked up when a dependency changes
+func indexDependsOn(o client.Object) []string {
+	obj, ok := o.(*kluctlv1.KluctlDeployment)
+	if !ok {
+		return nil
+	}
+	var ret []string
+	for _, d := range obj.Spec.DependsOn {
+		ret = append(ret, buildDependencyKey(obj, d).String())
+	}
+	return ret
+}
+
+// requestsForDependants returns reconcile requests for all KluctlDeployments that depend on the given object
+func (r *KluctlDeploymentReconciler) requestsForDependants(ctx context.Context, o client.Object) []reconcile.Request {
+	var l kluctlv1.KluctlDeploymentList
+	err := r.Client.List(ctx, &l, client.MatchingFields{dependsOnIndexKey: client.ObjectKeyFromObject(o).String()})
+	if err != nil {
+		return nil
+	}
+	var ret []reconcile.Request
+	for _, x := range l.Items {
+		ret = append(ret, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&x)})
+	}
+	return ret
+}
+
+// checkDependencies returns a message describing the first dependency that is not ready yet. An empty message is
+// returned if all dependencies are ready. It does not require the project to be prepared, so that blocked
+// deployments don't need to clone/pull the source. See checkDependencyCommits for the commit based checks.
+func (r *KluctlDeploymentReconciler) checkDependencies(ctx context.Context, obj *kluctlv1.KluctlDeployment) (string, error) {
+	if len(obj.Spec.DependsOn) == 0 {
+		return "", nil
+	}
+
+	// we're not using the cache here as the dependency might be part of another shard
+	cycle, err := findDependencyCycle(ctx, r.ApiReader, obj)
+	if err != nil {
+		return "", err
+	}
+	if cycle != nil {
+		var keys []string
+		for _, k := range cycle {
+			keys = append(keys, k.String())
+		}
+		return fmt.Sprintf("dependency cycle detected: %s", strings.Join(keys, " -> ")), nil
+	}
+
+	for _, d := range obj.Spec.DependsOn {
+		key := buildDependencyKey(obj, d)
+
+		var dep kluctlv1.KluctlDeployment
+		err := r.ApiReader.Get(ctx, key, &dep)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return fmt.Sprintf("dependency '%s' not found", key.String()), nil
+			}
+			return "", err
+		}
+
+		if dep.Status.ObservedGeneration != dep.Generation {
+			return fmt.Sprintf("dependency '%s' has not been reconciled yet", key.String()), nil
+		}
+		readyCondition := apimeta.FindStatusCondition(dep.Status.Conditions, meta.ReadyCondition)
+		if readyCondition == nil || readyCondition.Status != metav1.ConditionTrue {
+			return fmt.Sprintf("dependency '%s' is not ready", key.String()), nil
+		}
+
+		if d.Strict {
+			lastDeployResult, err := dep.Status.GetLastDeployResult()
+			if err != nil {
+				return "", err
+			}
+			if lastDeployResult == nil || len(lastDeployResult.Errors) != 0 {
+				return fmt.Sprintf("last deployment of dependency '%s' did not succeed", key.String()), nil
+			}
+			driftDetectionResult, err := dep.Status.GetDriftDetectionResult()
+			if err != nil {
+				return "", err
+			}
+			if driftDetectionResult != nil && len(driftDetectionResult.Objects) != 0 {
+				return fmt.Sprintf("dependency '%s' has drifted", key.String()), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// checkDependencyCommits returns a message describing the first dependency that has not reconciled the commit
+// checked out in pp yet. An empty message is returned if all dependencies are up-to-date.
+func (r *KluctlDeploymentReconciler) checkDependencyCommits(ctx context.Context, obj *kluctlv1.KluctlDeployment, pp *preparedProject) (string, error) {
+	for _, d := range obj.Spec.DependsOn {
+		key := buildDependencyKey(obj, d)
+
+		var dep kluctlv1.KluctlDeployment
+		err := r.ApiReader.Get(ctx, key, &dep)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return fmt.Sprintf("dependency '%s' not found", key.String()), nil
+			}
+			return "", err
+		}
+
+		msg, err := r.checkDependencyCommit(obj, &dep, pp)
+		if err != nil {
+			return "", err
+		}
+		if msg != "" {
+			return msg, nil
+		}
+	}
+	return "", nil
+}
+
+// findDependencyCycle walks the dependency graph starting at obj and returns the keys forming the first cycle it
+// finds, with the first key repeated at the end. Missing dependencies are ignored.
+func findDependencyCycle(ctx context.Context, c client.Reader, obj *kluctlv1.KluctlDeployment) ([]client.ObjectKey, error) {
+	done := map[client.ObjectKey]bool{}
+	var path []client.ObjectKey
+
+	var visit func(o *kluctlv1.KluctlDeployment) ([]client.ObjectKey, error)
+	visit = func(o *kluctlv1.KluctlDeployment) ([]client.ObjectKey, error) {
+		key := client.ObjectKeyFromObject(o)
+		for i, k := range path {
+			if k == key {
+				cycle := append([]client.ObjectKey{}, path[i:]...)
+				return append(cycle, key), nil
+			}
+		}
+		if done[key] {
+			return nil, nil
+		}
+
+		path = append(path, key)
+		for _, d := range o.Spec.DependsOn {
+			var dep kluctlv1.KluctlDeployment
+			err := c.Get(ctx, buildDependencyKey(o, d), &dep)
+			if err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			cycle, err := visit(&dep)
+			if err != nil || cycle != nil {
+				return cycle, err
+			}
+		}
+		path = path[:len(path)-1]
+		done[key] = true
+		return nil, nil
+	}
+	return visit(obj)
+}
+
+// checkDependencyCommit ensures that a dependency that is deployed from the same git repository has already
+// reconciled the same or a newer commit.
+func (r *KluctlDeploymentReconciler) checkDependencyCommit(obj *kluctlv1.KluctlDeployment, dep *kluctlv1.KluctlDeployment, pp *preparedProject) (string, error) {
+	var url string
+	if obj.Spec.Source.Git != nil {
+		url = obj.Spec.Source.Git.URL
+	} else if obj.Spec.Source.URL != nil {
+		url = *obj.Spec.Source.URL
+	} else {
+		return "", nil
+	}
+
+	if obj.Status.ProjectKey == nil || dep.Status.ProjectKey == nil || obj.Status.ProjectKey.RepoKey != dep.Status.ProjectKey.RepoKey {
+		return "", nil
+	}
+	commit := pp.co.CheckedOutCommit
+	if commit == "" || dep.Status.ObservedCommit == commit {
+		return "", nil
+	}
+	if dep.Status.ObservedCommit == "" {
+		return fmt.Sprintf("dependency '%s/%s' has not observed any commit yet", dep.Namespace, dep.Name), nil
+	}
+
+	rpEntry, err := pp.gitRP.GetEntry(url)
+	if err != nil {
+		return "", err
+	}
+	isAncestor, err := rpEntry.IsAncestor(commit, dep.Status.ObservedCommit)
+	if err != nil {
+		return "", err
+	}
+	if !isAncestor {
+		return fmt.Sprintf("dependency '%s/%s' has not reconciled commit %s yet", dep.Namespace, dep.Name, commit), nil
+	}
+	return "", nil
+}
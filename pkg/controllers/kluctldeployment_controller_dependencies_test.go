@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func buildDependencyTestKd(namespace string, name string, deps ...kluctlv1.KluctlDeploymentDependency) *kluctlv1.KluctlDeployment {
+	return &kluctlv1.KluctlDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: kluctlv1.KluctlDeploymentSpec{
+			DependsOn: deps,
+		},
+	}
+}
+
+func TestFindDependencyCycle(t *testing.T) {
+	key := func(ns string, name string) client.ObjectKey {
+		return client.ObjectKey{Namespace: ns, Name: name}
+	}
+
+	type testCase struct {
+		name     string
+		objs     []*kluctlv1.KluctlDeployment
+		expected []client.ObjectKey
+	}
+	testCases := []testCase{
+		{
+			name: "no dependencies",
+			objs: []*kluctlv1.KluctlDeployment{
+				buildDependencyTestKd("ns", "a"),
+			},
+		},
+		{
+			name: "chain",
+			objs: []*kluctlv1.KluctlDeployment{
+				buildDependencyTestKd("ns", "a", kluctlv1.KluctlDeploymentDependency{Name: "b"}),
+				buildDependencyTestKd("ns", "b", kluctlv1.KluctlDeploymentDependency{Name: "c"}),
+				buildDependencyTestKd("ns", "c"),
+			},
+		},
+		{
+			name: "diamond",
+			objs: []*kluctlv1.KluctlDeployment{
+				buildDependencyTestKd("ns", "a", kluctlv1.KluctlDeploymentDependency{Name: "b"}, kluctlv1.KluctlDeploymentDependency{Name: "c"}),
+				buildDependencyTestKd("ns", "b", kluctlv1.KluctlDeploymentDependency{Name: "d"}),
+				buildDependencyTestKd("ns", "c", kluctlv1.KluctlDeploymentDependency{Name: "d"}),
+				buildDependencyTestKd("ns", "d"),
+			},
+		},
+		{
+			name: "missing dependency",
+			objs: []*kluctlv1.KluctlDeployment{
+				buildDependencyTestKd("ns", "a", kluctlv1.KluctlDeploymentDependency{Name: "missing"}),
+			},
+		},
+		{
+			name: "self",
+			objs: []*kluctlv1.KluctlDeployment{
+				buildDependencyTestKd("ns", "a", kluctlv1.KluctlDeploymentDependency{Name: "a"}),
+			},
+			expected: []client.ObjectKey{key("ns", "a"), key("ns", "a")},
+		},
+		{
+			name: "indirect",
+			objs: []*kluctlv1.KluctlDeployment{
+				buildDependencyTestKd("ns", "a", kluctlv1.KluctlDeploymentDependency{Name: "b"}),
+				buildDependencyTestKd("ns", "b", kluctlv1.KluctlDeploymentDependency{Name: "c", Namespace: "other"}),
+				buildDependencyTestKd("other", "c", kluctlv1.KluctlDeploymentDependency{Name: "a", Namespace: "ns"}),
+			},
+			expected: []client.ObjectKey{key("ns", "a"), key("ns", "b"), key("other", "c"), key("ns", "a")},
+		},
+		{
+			name: "cycle not including the object",
+			objs: []*kluctlv1.KluctlDeployment{
+				buildDependencyTestKd("ns", "a", kluctlv1.KluctlDeploymentDependency{Name: "b"}),
+				buildDependencyTestKd("ns", "b", kluctlv1.KluctlDeploymentDependency{Name: "c"}),
+				buildDependencyTestKd("ns", "c", kluctlv1.KluctlDeploymentDependency{Name: "b"}),
+			},
+			expected: []client.ObjectKey{key("ns", "b"), key("ns", "c"), key("ns", "b")},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, kluctlv1.AddToScheme(scheme))
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var objs []client.Object
+			for _, o := range tc.objs {
+				objs = append(objs, o)
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+			cycle, err := findDependencyCycle(context.Background(), c, tc.objs[0])
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, cycle)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/deploywindows"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"time"
+)
+
+const freezeRefsIndexKey = ".spec.deployWindows.freezeRefs"
+
+// indexFreezeRefs is used as field indexer so that KluctlDeployments can be looked up when a DeployFreeze changes
+func indexFreezeRefs(o client.Object) []string {
+	obj, ok := o.(*kluctlv1.KluctlDeployment)
+	if !ok || obj.Spec.DeployWindows == nil {
+		return nil
+	}
+	return obj.Spec.DeployWindows.FreezeRefs
+}
+
+// requestsForFreeze returns reconcile requests for all KluctlDeployments that reference the given DeployFreeze
+func (r *KluctlDeploymentReconciler) requestsForFreeze(ctx context.Context, o client.Object) []reconcile.Request {
+	var l kluctlv1.KluctlDeploymentList
+	err := r.Client.List(ctx, &l, client.MatchingFields{freezeRefsIndexKey: o.GetName()})
+	if err != nil {
+		return nil
+	}
+	var ret []reconcile.Request
+	for _, x := range l.Items {
+		ret = append(ret, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&x)})
+	}
+	return ret
+}
+
+func (r *KluctlDeploymentReconciler) evaluateDeployWindows(ctx context.Context, obj *kluctlv1.KluctlDeployment) (*deploywindows.Status, error) {
+	if obj.Spec.DeployWindows == nil {
+		return &deploywindows.Status{}, nil
+	}
+
+	var freezes []kluctlv1.DeployFreeze
+	var missingFreezes []string
+	for _, name := range obj.Spec.DeployWindows.FreezeRefs {
+		var f kluctlv1.DeployFreeze
+		err := r.Client.Get(ctx, client.ObjectKey{Name: name}, &f)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				missingFreezes = append(missingFreezes, name)
+				continue
+			}
+			return nil, fmt.Errorf("failed to get DeployFreeze %s: %w", name, err)
+		}
+		freezes = append(freezes, f)
+	}
+
+	return deploywindows.Evaluate(obj.Spec.DeployWindows, freezes, missingFreezes, time.Now())
+}
+
+// checkManualDeployWindow returns an error if the deploy window is closed and the request does not override it
+func (r *KluctlDeploymentReconciler) checkManualDeployWindow(ctx context.Context, obj *kluctlv1.KluctlDeployment, rr *kluctlv1.ManualRequestResult) error {
+	if rr != nil && rr.Request.OverrideDeployWindow {
+		return nil
+	}
+	s, err := r.evaluateDeployWindows(ctx, obj)
+	if err != nil {
+		return err
+	}
+	if s.Closed {
+		return fmt.Errorf("deploy window is closed: %s", s.Message)
+	}
+	return nil
+}
+
+func (r *KluctlDeploymentReconciler) patchDeployWindowCondition(ctx context.Context, obj *kluctlv1.KluctlDeployment, s *deploywindows.Status) error {
+	log := ctrl.LoggerFrom(ctx)
+	key := client.ObjectKeyFromObject(obj)
+
+	c := apimeta.FindStatusCondition(obj.GetConditions(), kluctlv1.DeployWindowClosedCondition)
+	if !s.Closed {
+		if c == nil {
+			return nil
+		}
+		log.Info("deploy window is open")
+		return r.patchCondition(ctx, key, func(c *[]metav1.Condition) error {
+			apimeta.RemoveStatusCondition(c, kluctlv1.DeployWindowClosedCondition)
+			return nil
+		})
+	}
+
+	if c != nil && c.Status == metav1.ConditionTrue && c.Reason == s.Reason && c.Message == s.Message && c.ObservedGeneration == obj.Generation {
+		return nil
+	}
+	log.Info(fmt.Sprintf("deploy window is closed: %s", s.Message))
+	return r.patchCondition(ctx, key, func(c *[]metav1.Condition) error {
+		apimeta.SetStatusCondition(c, metav1.Condition{
+			Type:               kluctlv1.DeployWindowClosedCondition,
+			Status:             metav1.ConditionTrue,
+			Reason:             s.Reason,
+			Message:            trimString(s.Message, kluctlv1.MaxConditionMessageLength),
+			ObservedGeneration: obj.Generation,
+		})
+		return nil
+	})
+}
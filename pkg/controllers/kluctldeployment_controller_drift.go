@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// remediateDrift re-applies drifted objects according to spec.driftRemediation. It returns nil if no remediation
+// was performed, either because there is nothing to remediate or because the rate limit was reached.
+func (r *KluctlDeploymentReconciler) remediateDrift(ctx context.Context, obj *kluctlv1.KluctlDeployment, pt *preparedTarget, targetContext *target_context.TargetContext, driftDetectionResult *result.DriftDetectionResult) (*result.CommandResult, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	mode := obj.Spec.DriftRemediation.GetMode()
+	if mode == kluctlv1.DriftRemediationReport {
+		obj.Status.DriftRemediationTimes = nil
+		return nil, nil
+	}
+
+	refs := buildDriftRemediationRefs(obj.Spec.DriftRemediation, driftDetectionResult)
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	obj.Status.DriftRemediationTimes = trimDriftRemediationTimes(obj.Status.DriftRemediationTimes, now)
+	maxRemediations := obj.Spec.DriftRemediation.GetMaxRemediationsPerHour()
+	if len(obj.Status.DriftRemediationTimes) >= maxRemediations {
+		log.Info("drift remediation rate limit reached, only reporting drift", "maxRemediationsPerHour", maxRemediations)
+		return nil, nil
+	}
+
+	err := r.patchProgressingCondition(ctx, obj, fmt.Sprintf("Remediating drift of %d objects (%s)", len(refs), mode), false)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("remediating drift", "mode", mode, "objects", len(refs))
+	if mode == kluctlv1.DriftRemediationRedeployAll {
+		refs = nil
+	}
+	deployResult := pt.kluctlDeploy(targetContext, refs)
+	obj.Status.DriftRemediationTimes = append(obj.Status.DriftRemediationTimes, metav1.NewTime(now))
+
+	return deployResult, nil
+}
+
+// buildDriftRemediationRefs returns the drifted objects that can be remediated by re-applying them. Orphan and
+// deleted objects are skipped as re-applying would not fix them, and so are hooks.
+func buildDriftRemediationRefs(dr *kluctlv1.DriftRemediation, driftDetectionResult *result.DriftDetectionResult) map[k8s.ObjectRef]bool {
+	if driftDetectionResult == nil {
+		return nil
+	}
+
+	excluded := func(ref k8s.ObjectRef) bool {
+		if dr == nil {
+			return false
+		}
+		for _, e := range dr.Exclude {
+			if e.Group == ref.Group && e.Kind == ref.Kind {
+				return true
+			}
+		}
+		return false
+	}
+
+	ret := map[k8s.ObjectRef]bool{}
+	for _, o := range driftDetectionResult.Objects {
+		if o.Orphan || o.Deleted || o.Hook {
+			continue
+		}
+		if !o.New && len(o.Changes) == 0 {
+			continue
+		}
+		if excluded(o.Ref) {
+			continue
+		}
+		ret[o.Ref] = true
+	}
+	return ret
+}
+
+func trimDriftRemediationTimes(times []metav1.Time, now time.Time) []metav1.Time {
+	var ret []metav1.Time
+	for _, t := range times {
+		if now.Sub(t.Time) < time.Hour {
+			ret = append(ret, t)
+		}
+	}
+	return ret
+}
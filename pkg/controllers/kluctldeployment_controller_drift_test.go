@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestBuildDriftRemediationRefs(t *testing.T) {
+	cm := func(name string) k8s.ObjectRef {
+		return k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: name, Namespace: "ns"}
+	}
+	deployment := k8s.ObjectRef{Group: "apps", Version: "v1", Kind: "Deployment", Name: "d", Namespace: "ns"}
+	changes := []result.Change{{Type: "update", JsonPath: "data.x"}}
+
+	dr := &result.DriftDetectionResult{
+		Objects: []result.DriftedObject{
+			{BaseObject: result.BaseObject{Ref: cm("changed"), Changes: changes}},
+			{BaseObject: result.BaseObject{Ref: cm("new"), New: true}},
+			{BaseObject: result.BaseObject{Ref: cm("orphan"), Orphan: true}},
+			{BaseObject: result.BaseObject{Ref: cm("deleted"), Deleted: true, Changes: changes}},
+			{BaseObject: result.BaseObject{Ref: cm("hook"), Hook: true, Changes: changes}},
+			{BaseObject: result.BaseObject{Ref: cm("unchanged")}},
+			{BaseObject: result.BaseObject{Ref: deployment, Changes: changes}},
+		},
+	}
+
+	assert.Nil(t, buildDriftRemediationRefs(nil, nil))
+
+	refs := buildDriftRemediationRefs(nil, dr)
+	assert.Equal(t, map[k8s.ObjectRef]bool{
+		cm("changed"): true,
+		cm("new"):     true,
+		deployment:    true,
+	}, refs)
+
+	refs = buildDriftRemediationRefs(&kluctlv1.DriftRemediation{
+		Exclude: []kluctlv1.DriftRemediationExclude{
+			{Group: "apps", Kind: "Deployment"},
+			// the core group must match exactly
+			{Group: "apps", Kind: "ConfigMap"},
+		},
+	}, dr)
+	assert.Equal(t, map[k8s.ObjectRef]bool{
+		cm("changed"): true,
+		cm("new"):     true,
+	}, refs)
+}
+
+func TestTrimDriftRemediationTimes(t *testing.T) {
+	now := time.Date(2023, 6, 5, 8, 0, 0, 0, time.UTC)
+	times := []metav1.Time{
+		metav1.NewTime(now.Add(-2 * time.Hour)),
+		metav1.NewTime(now.Add(-time.Hour)),
+		metav1.NewTime(now.Add(-59 * time.Minute)),
+		metav1.NewTime(now),
+	}
+	assert.Equal(t, times[2:], trimDriftRemediationTimes(times, now))
+	assert.Nil(t, trimDriftRemediationTimes(nil, now))
+}
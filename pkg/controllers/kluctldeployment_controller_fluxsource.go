@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/fluxsource"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const fluxSourceRefIndexKey = ".spec.source.sourceRef"
+
+// fluxArtifactTimeout is the timeout for downloading artifacts from the source-controller
+const fluxArtifactTimeout = 5 * time.Minute
+
+func buildFluxSourceIndexValue(kind string, namespace string, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+func getFluxSourceKey(obj *kluctlv1.KluctlDeployment) client.ObjectKey {
+	ns := obj.Spec.Source.SourceRef.Namespace
+	if ns == "" {
+		ns = obj.GetNamespace()
+	}
+	return client.ObjectKey{Namespace: ns, Name: obj.Spec.Source.SourceRef.Name}
+}
+
+// indexFluxSourceRef is used as field indexer so that KluctlDeployments can be looked up when a Flux source changes
+func indexFluxSourceRef(o client.Object) []string {
+	obj, ok := o.(*kluctlv1.KluctlDeployment)
+	if !ok || obj.Spec.Source.SourceRef == nil {
+		return nil
+	}
+	key := getFluxSourceKey(obj)
+	return []string{buildFluxSourceIndexValue(obj.Spec.Source.SourceRef.Kind, key.Namespace, key.Name)}
+}
+
+// requestsForFluxSource returns reconcile requests for all KluctlDeployments that reference the given Flux source
+func (r *KluctlDeploymentReconciler) requestsForFluxSource(ctx context.Context, o client.Object) []reconcile.Request {
+	var l kluctlv1.KluctlDeploymentList
+	v := buildFluxSourceIndexValue(o.GetObjectKind().GroupVersionKind().Kind, o.GetNamespace(), o.GetName())
+	err := r.Client.List(ctx, &l, client.MatchingFields{fluxSourceRefIndexKey: v})
+	if err != nil {
+		return nil
+	}
+	var ret []reconcile.Request
+	for _, x := range l.Items {
+		ret = append(ret, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&x)})
+	}
+	return ret
+}
+
+// setupFluxSourceWatches adds watches for all Flux source kinds that are known to the cluster. Kinds that are not
+// installed are skipped, meaning that the controller must be restarted if Flux is installed afterwards.
+func (r *KluctlDeploymentReconciler) setupFluxSourceWatches(ctx context.Context, mgr ctrl.Manager, b *builder.Builder) {
+	log := ctrl.LoggerFrom(ctx)
+
+	for _, kind := range []string{fluxsource.GitRepositoryKind, fluxsource.OCIRepositoryKind, fluxsource.BucketKind} {
+		m, err := mgr.GetRESTMapper().RESTMapping(schema.GroupKind{Group: fluxsource.Group, Kind: kind})
+		if err != nil {
+			if !meta.IsNoMatchError(err) {
+				log.Error(err, "failed to get REST mapping for Flux source", "kind", kind)
+			}
+			continue
+		}
+
+		var o unstructured.Unstructured
+		o.SetGroupVersionKind(m.GroupVersionKind)
+		b.Watches(&o,
+			handler.EnqueueRequestsFromMapFunc(r.requestsForFluxSource),
+			builder.WithPredicates(fluxArtifactChangedPredicate{}),
+		)
+	}
+}
+
+// fluxArtifactChangedPredicate triggers reconciliation when the Flux source produced a new artifact
+type fluxArtifactChangedPredicate struct {
+	predicate.Funcs
+}
+
+func (fluxArtifactChangedPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+	getRevision := func(o client.Object) string {
+		u, ok := o.(*unstructured.Unstructured)
+		if !ok {
+			return ""
+		}
+		rev, _, _ := unstructured.NestedString(u.Object, "status", "artifact", "revision")
+		digest, _, _ := unstructured.NestedString(u.Object, "status", "artifact", "digest")
+		return rev + "/" + digest
+	}
+	return getRevision(e.ObjectOld) != getRevision(e.ObjectNew)
+}
+
+func (r *KluctlDeploymentReconciler) getFluxSource(ctx context.Context, obj *kluctlv1.KluctlDeployment) (*uo.UnstructuredObject, error) {
+	gvk, err := fluxsource.BuildGVK(obj.Spec.Source.SourceRef.APIVersion, obj.Spec.Source.SourceRef.Kind)
+	if err != nil {
+		return nil, err
+	}
+	key := getFluxSourceKey(obj)
+	if key.Namespace != obj.GetNamespace() && !r.AllowCrossNamespaceRefs {
+		// the controller reads sources with its own permissions, so we must not allow access to other tenants' sources
+		return nil, fmt.Errorf("cross-namespace references to Flux sources are not allowed, %s %s can not be referenced from namespace %s", gvk.Kind, key.String(), obj.GetNamespace())
+	}
+	return fluxsource.GetSource(ctx, r.ApiReader, gvk, key)
+}
+
+// fetchFluxSource downloads and extracts the artifact of the referenced Flux source
+func (pp *preparedProject) fetchFluxSource(ctx context.Context) error {
+	source, err := pp.r.getFluxSource(ctx, pp.obj)
+	if err != nil {
+		return err
+	}
+	artifact, err := fluxsource.GetArtifact(source)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(pp.tmpDir, "flux-source")
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return err
+	}
+
+	ctrl.LoggerFrom(ctx).Info("fetching Flux source artifact", "revision", artifact.Revision)
+	httpClient := &http.Client{Timeout: fluxArtifactTimeout}
+	err = fluxsource.Fetch(ctx, httpClient, artifact, dir)
+	if err != nil {
+		return err
+	}
+
+	pp.fluxSource = source
+	pp.fluxArtifact = artifact
+	pp.repoDir = dir
+
+	gitInfo := fluxsource.BuildGitInfo(source, artifact, pp.obj.Spec.Source.SourceRef.Path)
+	pp.co.CheckedOutCommit = gitInfo.Commit
+	if gitInfo.Ref != nil {
+		pp.co.CheckedOutRef = *gitInfo.Ref
+	}
+	return nil
+}
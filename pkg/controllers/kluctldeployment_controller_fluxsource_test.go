@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/fluxsource"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func TestGetFluxSourceCrossNamespace(t *testing.T) {
+	buildSource := func(namespace string) *unstructured.Unstructured {
+		var o unstructured.Unstructured
+		o.SetAPIVersion(fluxsource.Group + "/v1")
+		o.SetKind(fluxsource.GitRepositoryKind)
+		o.SetNamespace(namespace)
+		o.SetName("source")
+		return &o
+	}
+	buildKd := func(sourceNamespace string) *kluctlv1.KluctlDeployment {
+		return &kluctlv1.KluctlDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kd",
+				Namespace: "tenant1",
+			},
+			Spec: kluctlv1.KluctlDeploymentSpec{
+				Source: kluctlv1.ProjectSource{
+					SourceRef: &kluctlv1.ProjectSourceFluxRef{
+						Kind:      fluxsource.GitRepositoryKind,
+						Name:      "source",
+						Namespace: sourceNamespace,
+					},
+				},
+			},
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, kluctlv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(buildSource("tenant1"), buildSource("tenant2")).Build()
+	r := &KluctlDeploymentReconciler{ApiReader: c}
+
+	for _, ns := range []string{"", "tenant1"} {
+		source, err := r.getFluxSource(context.Background(), buildKd(ns))
+		assert.NoError(t, err)
+		if assert.NotNil(t, source) {
+			assert.Equal(t, "tenant1", source.GetK8sNamespace())
+		}
+	}
+
+	_, err := r.getFluxSource(context.Background(), buildKd("tenant2"))
+	assert.ErrorContains(t, err, "cross-namespace references to Flux sources are not allowed")
+
+	r.AllowCrossNamespaceRefs = true
+	source, err := r.getFluxSource(context.Background(), buildKd("tenant2"))
+	assert.NoError(t, err)
+	if assert.NotNil(t, source) {
+		assert.Equal(t, "tenant2", source.GetK8sNamespace())
+	}
+}
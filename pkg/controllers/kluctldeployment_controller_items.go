@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"fmt"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"path/filepath"
+	"strings"
+)
+
+const maxDeploymentItemsMessagePaths = 3
+
+// updateDeploymentItemsStatus updates status.deploymentItems from the given deploy and/or validate result. Values
+// which are not covered by the given results are taken over from the previous status.
+func updateDeploymentItemsStatus(status *kluctlv1.KluctlDeploymentStatus, c *deployment.DeploymentCollection, deployResult *result.CommandResult, validateResult *result.ValidateResult) {
+	prev := map[string]*kluctlv1.DeploymentItemStatus{}
+	for i := range status.DeploymentItems {
+		prev[status.DeploymentItems[i].Path] = &status.DeploymentItems[i]
+	}
+
+	var items []kluctlv1.DeploymentItemStatus
+	refToItem := map[k8s.ObjectRef]int{}
+	for _, di := range c.Deployments {
+		if di.RelToSourceItemDir == "" {
+			// barriers and other items without a directory
+			continue
+		}
+		s := kluctlv1.DeploymentItemStatus{
+			Path: filepath.ToSlash(di.RelRenderedDir),
+			Tags: di.Tags.ListKeys(),
+		}
+		if p, ok := prev[s.Path]; ok {
+			s.AppliedObjects = p.AppliedObjects
+			s.ChangedObjects = p.ChangedObjects
+			s.Errors = p.Errors
+			s.Warnings = p.Warnings
+			s.Ready = p.Ready
+			s.LastChangeTime = p.LastChangeTime
+		}
+		for _, o := range di.Objects {
+			refToItem[o.GetK8sRef()] = len(items)
+		}
+		items = append(items, s)
+	}
+
+	if deployResult != nil {
+		for i := range items {
+			items[i].AppliedObjects = 0
+			items[i].ChangedObjects = 0
+			items[i].Errors = 0
+			items[i].Warnings = 0
+		}
+		changed := make([]bool, len(items))
+		for _, o := range deployResult.Objects {
+			i, ok := refToItem[o.Ref]
+			if !ok {
+				continue
+			}
+			if o.Applied != nil {
+				items[i].AppliedObjects++
+			}
+			if o.New || len(o.Changes) != 0 {
+				items[i].ChangedObjects++
+				changed[i] = true
+			}
+		}
+		for _, e := range deployResult.Errors {
+			if i, ok := refToItem[e.Ref]; ok {
+				items[i].Errors++
+			}
+		}
+		for _, e := range deployResult.Warnings {
+			if i, ok := refToItem[e.Ref]; ok {
+				items[i].Warnings++
+			}
+		}
+		for i := range items {
+			if changed[i] && !deployResult.Command.DryRun {
+				t := deployResult.Command.EndTime
+				items[i].LastChangeTime = &t
+			}
+		}
+	}
+
+	if validateResult != nil {
+		ready := make([]bool, len(items))
+		for i := range ready {
+			ready[i] = true
+		}
+		for _, e := range validateResult.Errors {
+			if i, ok := refToItem[e.Ref]; ok {
+				ready[i] = false
+			}
+		}
+		for i := range items {
+			items[i].Ready = &ready[i]
+		}
+	}
+
+	status.DeploymentItems, status.DeploymentItemsTruncated = truncateDeploymentItems(items, kluctlv1.MaxDeploymentItemStatuses)
+	status.DeploymentItemsMessage = buildDeploymentItemsMessage(items)
+}
+
+// buildDeploymentItemsMessage builds a short message for `kubectl get` which names the first few items with problems
+func buildDeploymentItemsMessage(items []kluctlv1.DeploymentItemStatus) string {
+	if len(items) == 0 {
+		return ""
+	}
+	var paths []string
+	for _, item := range items {
+		if item.HasProblems() {
+			paths = append(paths, item.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return fmt.Sprintf("%d ok", len(items))
+	}
+	msg := fmt.Sprintf("%d of %d failing: ", len(paths), len(items))
+	if len(paths) > maxDeploymentItemsMessagePaths {
+		return msg + strings.Join(paths[:maxDeploymentItemsMessagePaths], ", ") + ", ..."
+	}
+	return msg + strings.Join(paths, ", ")
+}
+
+// truncateDeploymentItems limits the number of items while preferring items with problems. The original order is kept.
+func truncateDeploymentItems(items []kluctlv1.DeploymentItemStatus, max int) ([]kluctlv1.DeploymentItemStatus, bool) {
+	if len(items) <= max {
+		return items, false
+	}
+
+	keep := make([]bool, len(items))
+	n := 0
+	for i := range items {
+		if n < max && items[i].HasProblems() {
+			keep[i] = true
+			n++
+		}
+	}
+	for i := range items {
+		if n < max && !keep[i] {
+			keep[i] = true
+			n++
+		}
+	}
+
+	ret := make([]kluctlv1.DeploymentItemStatus, 0, max)
+	for i := range items {
+		if keep[i] {
+			ret = append(ret, items[i])
+		}
+	}
+	return ret, true
+}
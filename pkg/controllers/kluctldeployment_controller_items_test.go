@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"fmt"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func buildItemsTestObject(name string) *uo.UnstructuredObject {
+	o := uo.New()
+	o.SetK8sGVKs("", "v1", "ConfigMap")
+	o.SetK8sName(name)
+	o.SetK8sNamespace("ns")
+	return o
+}
+
+func buildItemsTestRef(name string) k8s.ObjectRef {
+	return buildItemsTestObject(name).GetK8sRef()
+}
+
+func buildItemsTestDeploymentItem(dir string, tags []string, objectNames ...string) *deployment.DeploymentItem {
+	di := &deployment.DeploymentItem{
+		RelToSourceItemDir: dir,
+		RelRenderedDir:     dir,
+		Tags:               &utils.OrderedMap[string, bool]{},
+	}
+	for _, t := range tags {
+		di.Tags.Set(t, true)
+	}
+	for _, n := range objectNames {
+		di.Objects = append(di.Objects, buildItemsTestObject(n))
+	}
+	return di
+}
+
+func buildItemsTestCollection() *deployment.DeploymentCollection {
+	return &deployment.DeploymentCollection{
+		Deployments: []*deployment.DeploymentItem{
+			buildItemsTestDeploymentItem("a", []string{"a", "apps"}, "a1", "a2"),
+			// barriers have no directory
+			buildItemsTestDeploymentItem("", nil),
+			buildItemsTestDeploymentItem("b", []string{"b"}, "b1"),
+		},
+	}
+}
+
+func buildItemsTestDeployResult(endTime time.Time, dryRun bool) *result.CommandResult {
+	change := []result.Change{{Type: "update", JsonPath: "data.x", NewValue: &apiextensionsv1.JSON{Raw: []byte(`"y"`)}}}
+	applied := buildItemsTestObject("x")
+	return &result.CommandResult{
+		Command: result.CommandInfo{
+			EndTime: metav1.NewTime(endTime),
+			DryRun:  dryRun,
+		},
+		Objects: []result.ResultObject{
+			{BaseObject: result.BaseObject{Ref: buildItemsTestRef("a1"), New: true}, Applied: applied},
+			{BaseObject: result.BaseObject{Ref: buildItemsTestRef("a2")}, Applied: applied},
+			{BaseObject: result.BaseObject{Ref: buildItemsTestRef("b1"), Changes: change}, Applied: applied},
+			// not part of any deployment item
+			{BaseObject: result.BaseObject{Ref: buildItemsTestRef("other"), New: true}, Applied: applied},
+		},
+		Errors: []result.DeploymentError{
+			{Ref: buildItemsTestRef("b1"), Message: "error"},
+		},
+		Warnings: []result.DeploymentError{
+			{Ref: buildItemsTestRef("a2"), Message: "warning"},
+			{Ref: buildItemsTestRef("other"), Message: "warning"},
+		},
+	}
+}
+
+func TestUpdateDeploymentItemsStatus(t *testing.T) {
+	c := buildItemsTestCollection()
+	t1 := time.Date(2023, 6, 5, 8, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	var status kluctlv1.KluctlDeploymentStatus
+
+	updateDeploymentItemsStatus(&status, c, buildItemsTestDeployResult(t1, false), nil)
+	assert.False(t, status.DeploymentItemsTruncated)
+	assert.Equal(t, []kluctlv1.DeploymentItemStatus{
+		{
+			Path:           "a",
+			Tags:           []string{"a", "apps"},
+			AppliedObjects: 2,
+			ChangedObjects: 1,
+			Warnings:       1,
+			LastChangeTime: &metav1.Time{Time: t1},
+		},
+		{
+			Path:           "b",
+			Tags:           []string{"b"},
+			AppliedObjects: 1,
+			ChangedObjects: 1,
+			Errors:         1,
+			LastChangeTime: &metav1.Time{Time: t1},
+		},
+	}, status.DeploymentItems)
+	assert.Equal(t, "1 of 2 failing: b", status.DeploymentItemsMessage)
+
+	// validation only updates readiness and keeps the deployment counts
+	vr := &result.ValidateResult{
+		Errors: []result.DeploymentError{{Ref: buildItemsTestRef("a1"), Message: "not ready"}},
+	}
+	updateDeploymentItemsStatus(&status, c, nil, vr)
+	assert.Len(t, status.DeploymentItems, 2)
+	assert.Equal(t, utils.Ptr(false), status.DeploymentItems[0].Ready)
+	assert.Equal(t, utils.Ptr(true), status.DeploymentItems[1].Ready)
+	assert.Equal(t, 2, status.DeploymentItems[0].AppliedObjects)
+	assert.Equal(t, 1, status.DeploymentItems[1].Errors)
+	assert.Equal(t, "2 of 2 failing: a, b", status.DeploymentItemsMessage)
+
+	// a dry-run deployment resets the counts, but does not count as change
+	dr := buildItemsTestDeployResult(t2, true)
+	dr.Errors = nil
+	updateDeploymentItemsStatus(&status, c, dr, nil)
+	assert.Equal(t, 0, status.DeploymentItems[1].Errors)
+	assert.Equal(t, 1, status.DeploymentItems[0].Warnings)
+	assert.Equal(t, &metav1.Time{Time: t1}, status.DeploymentItems[0].LastChangeTime)
+	assert.Equal(t, &metav1.Time{Time: t1}, status.DeploymentItems[1].LastChangeTime)
+	// readiness is taken over from the previous status
+	assert.Equal(t, utils.Ptr(false), status.DeploymentItems[0].Ready)
+	assert.Equal(t, "1 of 2 failing: a", status.DeploymentItemsMessage)
+
+	// items which are not part of the collection anymore are removed
+	c.Deployments = c.Deployments[:1]
+	updateDeploymentItemsStatus(&status, c, nil, nil)
+	assert.Len(t, status.DeploymentItems, 1)
+	assert.Equal(t, "a", status.DeploymentItems[0].Path)
+}
+
+func TestTruncateDeploymentItems(t *testing.T) {
+	buildItems := func(problems ...int) []kluctlv1.DeploymentItemStatus {
+		var items []kluctlv1.DeploymentItemStatus
+		for i := 0; i < 5; i++ {
+			items = append(items, kluctlv1.DeploymentItemStatus{Path: fmt.Sprintf("item-%d", i)})
+		}
+		for j, i := range problems {
+			if j%2 == 0 {
+				items[i].Errors = 1
+			} else {
+				items[i].Ready = utils.Ptr(false)
+			}
+		}
+		return items
+	}
+	paths := func(items []kluctlv1.DeploymentItemStatus) []string {
+		var ret []string
+		for _, item := range items {
+			ret = append(ret, item.Path)
+		}
+		return ret
+	}
+
+	items, truncated := truncateDeploymentItems(buildItems(), 5)
+	assert.False(t, truncated)
+	assert.Len(t, items, 5)
+
+	items, truncated = truncateDeploymentItems(buildItems(), 3)
+	assert.True(t, truncated)
+	assert.Equal(t, []string{"item-0", "item-1", "item-2"}, paths(items))
+
+	// items with problems are preferred, the order is kept
+	items, truncated = truncateDeploymentItems(buildItems(1, 3), 3)
+	assert.True(t, truncated)
+	assert.Equal(t, []string{"item-0", "item-1", "item-3"}, paths(items))
+
+	items, truncated = truncateDeploymentItems(buildItems(1, 2, 3, 4), 2)
+	assert.True(t, truncated)
+	assert.Equal(t, []string{"item-1", "item-2"}, paths(items))
+}
+
+func TestBuildDeploymentItemsMessage(t *testing.T) {
+	assert.Equal(t, "", buildDeploymentItemsMessage(nil))
+
+	var items []kluctlv1.DeploymentItemStatus
+	for i := 0; i < 5; i++ {
+		items = append(items, kluctlv1.DeploymentItemStatus{Path: fmt.Sprintf("item-%d", i)})
+	}
+	assert.Equal(t, "5 ok", buildDeploymentItemsMessage(items))
+
+	items[1].Errors = 1
+	assert.Equal(t, "1 of 5 failing: item-1", buildDeploymentItemsMessage(items))
+
+	for i := range items {
+		items[i].Ready = utils.Ptr(false)
+	}
+	assert.Equal(t, "5 of 5 failing: item-0, item-1, item-2, ...", buildDeploymentItemsMessage(items))
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/notifications"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"time"
+)
+
+func (r *KluctlDeploymentReconciler) notify(ctx context.Context, obj *kluctlv1.KluctlDeployment, e notifications.Event) {
+	if r.Notifier == nil {
+		return
+	}
+	e.Name = obj.Name
+	e.Namespace = obj.Namespace
+	e.Time = time.Now()
+	r.Notifier.Notify(ctx, e)
+}
+
+func (r *KluctlDeploymentReconciler) notifyDeployResult(ctx context.Context, obj *kluctlv1.KluctlDeployment, summary *result.CommandResultSummary, commandName string) {
+	t := notifications.EventDeploySucceeded
+	if len(summary.Errors) != 0 {
+		t = notifications.EventDeployFailed
+	}
+	r.notify(ctx, obj, notifications.Event{
+		Type:          t,
+		Message:       r.buildResultMessage(summary, commandName),
+		CommandResult: summary,
+	})
+	if summary.DeletedObjects != 0 {
+		r.notifyPruneResult(ctx, obj, summary, commandName)
+	}
+}
+
+func (r *KluctlDeploymentReconciler) notifyPruneResult(ctx context.Context, obj *kluctlv1.KluctlDeployment, summary *result.CommandResultSummary, commandName string) {
+	r.notify(ctx, obj, notifications.Event{
+		Type:          notifications.EventPrune,
+		Message:       r.buildResultMessage(summary, commandName),
+		CommandResult: summary,
+	})
+}
+
+// notifyValidateResult only notifies about failed validations if the previous validation did not fail, so that
+// periodic validations do not repeat the same notification
+func (r *KluctlDeploymentReconciler) notifyValidateResult(ctx context.Context, obj *kluctlv1.KluctlDeployment, prev *result.ValidateResult, vr *result.ValidateResult) {
+	if !validateFailed(vr) || (prev != nil && validateFailed(prev)) {
+		return
+	}
+	msg := "validate failed, deployment is not ready."
+	if len(vr.Errors) != 0 {
+		msg = r.buildBaseResultMessage(vr.Errors, vr.Warnings, "validate")
+	}
+	summary := vr.BuildSummary()
+	r.notify(ctx, obj, notifications.Event{
+		Type:           notifications.EventValidateFailed,
+		Message:        msg,
+		ValidateResult: &summary,
+	})
+}
+
+// notifyDriftDetectionResult only notifies if the set of drifted objects has changed
+func (r *KluctlDeploymentReconciler) notifyDriftDetectionResult(ctx context.Context, obj *kluctlv1.KluctlDeployment, prev *result.DriftDetectionResult, dr *result.DriftDetectionResult) {
+	if len(dr.Objects) == 0 || !driftChanged(prev, dr) {
+		return
+	}
+	r.notify(ctx, obj, notifications.Event{
+		Type:                 notifications.EventDriftDetected,
+		Message:              fmt.Sprintf("%d drifted objects.", len(dr.Objects)),
+		DriftDetectionResult: dr,
+	})
+}
+
+func validateFailed(vr *result.ValidateResult) bool {
+	return !vr.Ready || len(vr.Errors) != 0
+}
+
+func driftChanged(prev *result.DriftDetectionResult, dr *result.DriftDetectionResult) bool {
+	if prev == nil || len(prev.Objects) != len(dr.Objects) {
+		return true
+	}
+	refs := map[k8s.ObjectRef]bool{}
+	for _, o := range prev.Objects {
+		refs[o.Ref] = true
+	}
+	for _, o := range dr.Objects {
+		if !refs[o.Ref] {
+			return true
+		}
+	}
+	return false
+}
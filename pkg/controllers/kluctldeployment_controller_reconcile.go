@@ -306,6 +306,10 @@ func (r *KluctlDeploymentReconciler) reconcileDeployRequest(ctx context.Context,
 		obj.Spec.DeployMode, kluctlv1.KluctlRequestDeployAnnotation,
 		getResultPtr, false,
 		func(rr *kluctlv1.ManualRequestResult, targetContext *target_context.TargetContext, pt *preparedTarget, reconcileID string, objectsHash string) (any, string, error) {
+			err := r.checkManualDeployWindow(timeoutCtx, obj, rr)
+			if err != nil {
+				return nil, kluctlv1.DeployFailedReason, err
+			}
 			cmdResult, err := pt.kluctlDeployOrPokeImages(obj.Spec.DeployMode, targetContext)
 			if err != nil {
 				return nil, kluctlv1.DeployFailedReason, err
@@ -314,7 +318,10 @@ func (r *KluctlDeploymentReconciler) reconcileDeployRequest(ctx context.Context,
 			if err != nil {
 				log.Error(err, "Failed to write deploy result")
 			}
-			obj.Status.SetLastDeployResult(cmdResult.BuildSummary())
+			summary := cmdResult.BuildSummary()
+			obj.Status.SetLastDeployResult(summary)
+			updateDeploymentItemsStatus(&obj.Status, targetContext.DeploymentCollection, cmdResult, nil)
+			r.notifyDeployResult(ctx, obj, summary, "deploy")
 			return cmdResult, kluctlv1.DeployFailedReason, r.buildErrorFromResult(cmdResult.Errors, cmdResult.Warnings, "deploy")
 		})
 }
@@ -331,12 +338,18 @@ func (r *KluctlDeploymentReconciler) reconcilePruneRequest(ctx context.Context,
 		"prune", kluctlv1.KluctlRequestPruneAnnotation,
 		getResultPtr, false,
 		func(rr *kluctlv1.ManualRequestResult, targetContext *target_context.TargetContext, pt *preparedTarget, reconcileID string, objectsHash string) (any, string, error) {
+			err := r.checkManualDeployWindow(timeoutCtx, obj, rr)
+			if err != nil {
+				return nil, kluctlv1.PruneFailedReason, err
+			}
 			cmdResult := pt.kluctlPrune(targetContext)
-			err := pt.writeCommandResult(ctx, cmdResult, rr, "prune", reconcileId, objectsHash, true)
+			err = pt.writeCommandResult(ctx, cmdResult, rr, "prune", reconcileId, objectsHash, true)
 			if err != nil {
 				log.Error(err, "Failed to write prune result")
 			}
-			obj.Status.SetLastDeployResult(cmdResult.BuildSummary())
+			summary := cmdResult.BuildSummary()
+			obj.Status.SetLastDeployResult(summary)
+			r.notifyPruneResult(ctx, obj, summary, "prune")
 			return cmdResult, kluctlv1.PruneFailedReason, r.buildErrorFromResult(cmdResult.Errors, cmdResult.Warnings, "prune")
 		})
 }
@@ -358,16 +371,24 @@ func (r *KluctlDeploymentReconciler) reconcileValidateRequest(ctx context.Contex
 			if err != nil {
 				log.Error(err, "Failed to write validate result")
 			}
+			prevValidateResult, _ := obj.Status.GetLastValidateResult()
 			obj.Status.SetLastValidateResult(cmdResult)
+			updateDeploymentItemsStatus(&obj.Status, targetContext.DeploymentCollection, nil, cmdResult)
+			r.notifyValidateResult(ctx, obj, prevValidateResult, cmdResult)
 			return cmdResult, kluctlv1.ValidateFailedReason, r.buildErrorFromResult(cmdResult.Errors, cmdResult.Warnings, "validate")
 		})
 }
 
 func (r *KluctlDeploymentReconciler) reconcileFullRequest(ctx context.Context, timeoutCtx context.Context,
-	obj *kluctlv1.KluctlDeployment, reconcileId string) (bool, error) {
+	obj *kluctlv1.KluctlDeployment, reconcileId string) (bool, string, error) {
+
+	// dependencyMsg is set when at least one dependency is not ready, in which case the reconciliation is skipped
+	var dependencyMsg string
 
 	defer func() {
-		obj.Status.ObservedGeneration = obj.GetGeneration()
+		if dependencyMsg == "" {
+			obj.Status.ObservedGeneration = obj.GetGeneration()
+		}
 	}()
 
 	getResultPtr := func(status *kluctlv1.KluctlDeploymentStatus) **kluctlv1.ManualRequestResult {
@@ -380,12 +401,54 @@ func (r *KluctlDeploymentReconciler) reconcileFullRequest(ctx context.Context, t
 		forceReconcile = false
 	}
 
-	return r.reconcileManualRequest(ctx, timeoutCtx, obj, reconcileId,
+	// manual reconcile requests, e.g. via `kluctl gitops reconcile`, ignore dependencies
+	hasManualRequest := obj.GetAnnotations()[kluctlv1.KluctlRequestReconcileAnnotation] != ""
+
+	checkDependencies := func(pp *preparedProject) string {
+		var msg string
+		var err error
+		if pp == nil {
+			msg, err = r.checkDependencies(timeoutCtx, obj)
+		} else {
+			msg, err = r.checkDependencyCommits(timeoutCtx, obj, pp)
+		}
+		if err != nil {
+			return fmt.Sprintf("failed to check dependencies: %s", err.Error())
+		}
+		return msg
+	}
+
+	// check everything that does not require the source before preparing the project
+	if forceReconcile && !hasManualRequest {
+		dependencyMsg = checkDependencies(nil)
+		if dependencyMsg != "" {
+			return false, dependencyMsg, nil
+		}
+	}
+
+	prevObservedCommit := obj.Status.ObservedCommit
+
+	processed, err := r.reconcileManualRequest(ctx, timeoutCtx, obj, reconcileId,
 		"reconcile", kluctlv1.KluctlRequestReconcileAnnotation,
 		getResultPtr, forceReconcile,
 		func(rr *kluctlv1.ManualRequestResult, targetContext *target_context.TargetContext, pt *preparedTarget, reconcileID string, objectsHash string) (any, string, error) {
+			if rr == nil {
+				if hasManualRequest {
+					// the annotation was already processed before, so the early check was skipped
+					dependencyMsg = checkDependencies(nil)
+				}
+				if dependencyMsg == "" {
+					dependencyMsg = checkDependencies(pt.pp)
+				}
+				if dependencyMsg != "" {
+					// we did not deploy the checked out commit, so don't report it as observed
+					obj.Status.ObservedCommit = prevObservedCommit
+					return nil, kluctlv1.DependencyNotReadyReason, nil
+				}
+			}
 			return r.reconcileFullRequest2(rr, ctx, timeoutCtx, obj, reconcileId, targetContext, pt, reconcileId, objectsHash)
 		})
+	return processed, dependencyMsg, err
 }
 
 func (r *KluctlDeploymentReconciler) reconcileFullRequest2(rr *kluctlv1.ManualRequestResult, ctx context.Context, timeoutCtx context.Context, obj *kluctlv1.KluctlDeployment, reconcileId string, targetContext *target_context.TargetContext, pt *preparedTarget, reconcileID string, objectsHash string) (any, string, error) {
@@ -397,6 +460,8 @@ func (r *KluctlDeploymentReconciler) reconcileFullRequest2(rr *kluctlv1.ManualRe
 		return nil, kluctlv1.PrepareFailedReason, err
 	}
 
+	r.updateClusterVarsWatches(ctx, obj, targetContext)
+
 	needDeploy := false
 	needValidate := false
 	needDriftDetection := true
@@ -410,6 +475,9 @@ func (r *KluctlDeploymentReconciler) reconcileFullRequest2(rr *kluctlv1.ManualRe
 	} else if obj.Status.ObservedGeneration != obj.GetGeneration() {
 		// spec has changed
 		needDeploy = true
+	} else if obj.Status.DeployPostponedUntil != nil {
+		// a previous deployment was postponed due to a closed deploy window
+		needDeploy = true
 	} else {
 		// was deployed before, let's check if we need to do periodic deployments
 		nextDeployTime := r.nextDeployTime(obj)
@@ -428,6 +496,29 @@ func (r *KluctlDeploymentReconciler) reconcileFullRequest2(rr *kluctlv1.ManualRe
 		}
 	}
 
+	deployWindowStatus, err := r.evaluateDeployWindows(timeoutCtx, obj)
+	if err != nil {
+		return nil, kluctlv1.PrepareFailedReason, err
+	}
+	err = r.patchDeployWindowCondition(ctx, obj, deployWindowStatus)
+	if err != nil {
+		return nil, kluctlv1.PrepareFailedReason, err
+	}
+	if needDeploy && deployWindowStatus.Closed {
+		if rr != nil && rr.Request.OverrideDeployWindow {
+			log.Info("deploy window is closed, but the request overrides it")
+		} else {
+			// we still perform drift detection, but the deployment is postponed until the window opens again
+			postponedUntil := time.Now().Add(obj.Spec.Interval.Duration)
+			if deployWindowStatus.NextCheck != nil && deployWindowStatus.NextCheck.Before(postponedUntil) {
+				postponedUntil = *deployWindowStatus.NextCheck
+			}
+			log.Info("deploy window is closed, postponing deployment", "postponedUntil", postponedUntil)
+			obj.Status.DeployPostponedUntil = &metav1.Time{Time: postponedUntil}
+			needDeploy = false
+		}
+	}
+
 	if obj.Spec.Validate {
 		if obj.Status.LastValidateResult == nil || needDeploy {
 			// either never validated before or a deployment requested (which required re-validation)
@@ -440,6 +531,9 @@ func (r *KluctlDeploymentReconciler) reconcileFullRequest2(rr *kluctlv1.ManualRe
 		}
 	} else {
 		obj.Status.LastValidateResult = nil
+		for i := range obj.Status.DeploymentItems {
+			obj.Status.DeploymentItems[i].Ready = nil
+		}
 	}
 
 	if !needDeploy && obj.Status.LastObjectsHash != objectsHash {
@@ -467,7 +561,11 @@ func (r *KluctlDeploymentReconciler) reconcileFullRequest2(rr *kluctlv1.ManualRe
 		if err != nil {
 			log.Error(err, "Failed to write deploy result")
 		}
-		obj.Status.SetLastDeployResult(deployResult.BuildSummary())
+		deploySummary := deployResult.BuildSummary()
+		obj.Status.SetLastDeployResult(deploySummary)
+		obj.Status.DeployPostponedUntil = nil
+		updateDeploymentItemsStatus(&obj.Status, targetContext.DeploymentCollection, deployResult, nil)
+		r.notifyDeployResult(ctx, obj, deploySummary, "deploy")
 
 		cmdErrors = r.buildErrorFromResult(deployResult.Errors, deployResult.Warnings, "deploy")
 
@@ -489,7 +587,10 @@ func (r *KluctlDeploymentReconciler) reconcileFullRequest2(rr *kluctlv1.ManualRe
 		if err != nil {
 			log.Error(err, "Failed to write deploy result")
 		}
+		prevValidateResult, _ := obj.Status.GetLastValidateResult()
 		obj.Status.SetLastValidateResult(validateResult)
+		updateDeploymentItemsStatus(&obj.Status, targetContext.DeploymentCollection, nil, validateResult)
+		r.notifyValidateResult(ctx, obj, prevValidateResult, validateResult)
 
 		err = r.buildErrorFromResult(validateResult.Errors, validateResult.Warnings, "validate")
 		if err != nil {
@@ -501,10 +602,10 @@ func (r *KluctlDeploymentReconciler) reconcileFullRequest2(rr *kluctlv1.ManualRe
 		}
 	}
 
-	if needDriftDetection {
+	driftDetection := func() (*result.DriftDetectionResult, error) {
 		err := r.patchProgressingCondition(ctx, obj, "Performing drift detection", false)
 		if err != nil {
-			return nil, kluctlv1.DiffFailedReason, err
+			return nil, err
 		}
 
 		resourceVersions := r.getResourceVersions(key)
@@ -515,7 +616,9 @@ func (r *KluctlDeploymentReconciler) reconcileFullRequest2(rr *kluctlv1.ManualRe
 			log.Error(err, "addCommandResultInfo failed")
 		}
 		driftDetectionResult := diffResult.BuildDriftDetectionResult()
+		prevDriftDetectionResult, _ := obj.Status.GetDriftDetectionResult()
 		obj.Status.SetLastDriftDetectionResult(driftDetectionResult)
+		r.notifyDriftDetectionResult(ctx, obj, prevDriftDetectionResult, driftDetectionResult)
 
 		err = r.buildErrorFromResult(diffResult.Errors, diffResult.Warnings, "diff")
 		if err != nil {
@@ -527,6 +630,59 @@ func (r *KluctlDeploymentReconciler) reconcileFullRequest2(rr *kluctlv1.ManualRe
 		}
 
 		r.updateResourceVersions(key, diffResult.Objects, driftDetectionResult.Objects)
+		return driftDetectionResult, nil
+	}
+
+	if needDriftDetection {
+		driftDetectionResult, err := driftDetection()
+		if err != nil {
+			return nil, kluctlv1.DiffFailedReason, err
+		}
+
+		// drift is only remediated when nothing else prevents a deployment, e.g. a closed deploy window or a
+		// missing approval for manual deployments
+		canRemediate := deployResult == nil &&
+			!deployWindowStatus.Closed &&
+			!obj.Spec.DryRun &&
+			obj.Spec.DeployMode == kluctlv1.KluctlDeployModeFull &&
+			(!obj.Spec.Manual || utils.StrPtrEquals(obj.Spec.ManualObjectsHash, &objectsHash))
+		if canRemediate {
+			remediationResult, err := r.remediateDrift(ctx, obj, pt, targetContext, driftDetectionResult)
+			if err != nil {
+				return nil, kluctlv1.DeployFailedReason, err
+			}
+			if remediationResult != nil {
+				err = pt.writeCommandResult(ctx, remediationResult, rr, "drift-remediation", reconcileId, objectsHash, false)
+				if err != nil {
+					log.Error(err, "Failed to write drift remediation result")
+				}
+				remediationSummary := remediationResult.BuildSummary()
+				if obj.Spec.DriftRemediation.GetMode() == kluctlv1.DriftRemediationRedeployAll {
+					// only a full deployment may replace the last deploy result, as partial results would hide the
+					// state of all other objects and reset the deploy interval
+					obj.Status.SetLastDeployResult(remediationSummary)
+					updateDeploymentItemsStatus(&obj.Status, targetContext.DeploymentCollection, remediationResult, nil)
+				}
+				r.notifyDeployResult(ctx, obj, remediationSummary, "drift-remediation")
+
+				err = r.buildErrorFromResult(remediationResult.Errors, remediationResult.Warnings, "drift-remediation")
+				if err != nil {
+					if cmdErrors == nil {
+						cmdErrors = err
+					} else {
+						cmdErrors = multierror.Append(cmdErrors, err)
+					}
+				}
+
+				r.updateResourceVersions(key, remediationResult.Objects, nil)
+
+				// report the drift that remains after remediation
+				_, err = driftDetection()
+				if err != nil {
+					return nil, kluctlv1.DiffFailedReason, err
+				}
+			}
+		}
 	}
 
 	return nil, "", cmdErrors
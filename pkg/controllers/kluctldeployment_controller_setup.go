@@ -8,14 +8,30 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *KluctlDeploymentReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opts KluctlDeploymentReconcilerOpts) error {
 	r.resourceVersionsMap = map[client.ObjectKey]map[k8s.ObjectRef]string{}
+	r.clusterVarsWatcher = newClusterVarsWatcher(ctx)
 
-	return ctrl.NewControllerManagedBy(mgr).
+	err := mgr.GetFieldIndexer().IndexField(ctx, &kluctlv1.KluctlDeployment{}, dependsOnIndexKey, indexDependsOn)
+	if err != nil {
+		return err
+	}
+	err = mgr.GetFieldIndexer().IndexField(ctx, &kluctlv1.KluctlDeployment{}, freezeRefsIndexKey, indexFreezeRefs)
+	if err != nil {
+		return err
+	}
+	err = mgr.GetFieldIndexer().IndexField(ctx, &kluctlv1.KluctlDeployment{}, fluxSourceRefIndexKey, indexFluxSourceRef)
+	if err != nil {
+		return err
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		Named(r.ControllerName).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: opts.Concurrency,
@@ -23,5 +39,15 @@ func (r *KluctlDeploymentReconciler) SetupWithManager(ctx context.Context, mgr c
 		For(&kluctlv1.KluctlDeployment{}, builder.WithPredicates(
 			predicate.Or(predicate.GenerationChangedPredicate{}, ReconcileRequestedPredicate{}),
 		)).
-		Complete(r)
+		Watches(&kluctlv1.KluctlDeployment{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForDependants),
+			builder.WithPredicates(DependencyReadyChangedPredicate{}),
+		).
+		Watches(&kluctlv1.DeployFreeze{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForFreeze),
+		).
+		WatchesRawSource(source.Channel(r.clusterVarsWatcher.events, &handler.EnqueueRequestForObject{}))
+	r.setupFluxSourceWatches(ctx, mgr, b)
+
+	return b.Complete(r)
 }
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"fmt"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// BuildShardSelector builds the label selector used to select the KluctlDeployments of a controller shard. If
+// shardKey is set, only KluctlDeployments with a matching kluctl.io/shard-key label are selected. The optional
+// watchLabelSelector is combined with the shard key, which e.g. allows the default controller of a sharded
+// installation to only watch KluctlDeployments without a shard key via '!kluctl.io/shard-key'. nil is returned if
+// neither is set.
+func BuildShardSelector(shardKey string, watchLabelSelector string) (labels.Selector, error) {
+	if shardKey == "" && watchLabelSelector == "" {
+		return nil, nil
+	}
+
+	selector := labels.Everything()
+	if watchLabelSelector != "" {
+		var err error
+		selector, err = labels.Parse(watchLabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid watch label selector: %w", err)
+		}
+	}
+	if shardKey != "" {
+		req, err := labels.NewRequirement(kluctlv1.KluctlDeploymentShardKeyLabel, selection.Equals, []string{shardKey})
+		if err != nil {
+			return nil, fmt.Errorf("invalid shard key: %w", err)
+		}
+		selector = selector.Add(*req)
+	}
+	return selector, nil
+}
+
+func (r *KluctlDeploymentReconciler) isInShard(obj *kluctlv1.KluctlDeployment) bool {
+	if r.ShardSelector == nil {
+		return true
+	}
+	return r.ShardSelector.Matches(labels.Set(obj.GetLabels()))
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestBuildShardSelector(t *testing.T) {
+	type testCase struct {
+		name               string
+		shardKey           string
+		watchLabelSelector string
+		expected           string
+		err                string
+	}
+	testCases := []testCase{
+		{name: "none"},
+		{name: "shard key", shardKey: "a", expected: "kluctl.io/shard-key=a"},
+		{name: "default controller", watchLabelSelector: "!kluctl.io/shard-key", expected: "!kluctl.io/shard-key"},
+		{name: "combined", shardKey: "a", watchLabelSelector: "team=x", expected: "kluctl.io/shard-key=a,team=x"},
+		{name: "invalid selector", watchLabelSelector: "team==x=", err: "invalid watch label selector"},
+		{name: "invalid shard key", shardKey: "a b", err: "invalid shard key"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sel, err := BuildShardSelector(tc.shardKey, tc.watchLabelSelector)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			if tc.expected == "" {
+				assert.Nil(t, sel)
+			} else {
+				assert.Equal(t, tc.expected, sel.String())
+			}
+		})
+	}
+}
+
+func TestIsInShard(t *testing.T) {
+	buildKd := func(labels map[string]string) *kluctlv1.KluctlDeployment {
+		return &kluctlv1.KluctlDeployment{ObjectMeta: metav1.ObjectMeta{Name: "kd", Namespace: "ns", Labels: labels}}
+	}
+	noShard := buildKd(nil)
+	shardA := buildKd(map[string]string{kluctlv1.KluctlDeploymentShardKeyLabel: "a"})
+	shardB := buildKd(map[string]string{kluctlv1.KluctlDeploymentShardKeyLabel: "b"})
+
+	r := &KluctlDeploymentReconciler{}
+	assert.True(t, r.isInShard(noShard))
+	assert.True(t, r.isInShard(shardA))
+
+	sel, err := BuildShardSelector("a", "")
+	assert.NoError(t, err)
+	r.ShardSelector = sel
+	assert.False(t, r.isInShard(noShard))
+	assert.True(t, r.isInShard(shardA))
+	assert.False(t, r.isInShard(shardB))
+
+	sel, err = BuildShardSelector("", "!"+kluctlv1.KluctlDeploymentShardKeyLabel)
+	assert.NoError(t, err)
+	r.ShardSelector = sel
+	assert.True(t, r.isInShard(noShard))
+	assert.False(t, r.isInShard(shardA))
+}
@@ -8,6 +8,7 @@ import (
 	gittypes "github.com/kluctl/kluctl/lib/git/types"
 	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
 	internal_metrics "github.com/kluctl/kluctl/v2/pkg/controllers/metrics"
+	"github.com/kluctl/kluctl/v2/pkg/fluxsource"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/meta"
@@ -105,6 +106,19 @@ func (r *KluctlDeploymentReconciler) patchProjectKey(ctx context.Context, obj *k
 			RepoKey: repoKey,
 			SubDir:  path.Clean(obj.Spec.Source.Path),
 		}
+	} else if obj.Spec.Source.SourceRef != nil {
+		source, err := r.getFluxSource(ctx, obj)
+		if err != nil {
+			return err
+		}
+		repoKey, err := fluxsource.BuildRepoKey(source)
+		if err != nil {
+			return err
+		}
+		newProjectKey = gittypes.ProjectKey{
+			RepoKey: repoKey,
+			SubDir:  path.Clean(obj.Spec.Source.SourceRef.Path),
+		}
 	} else {
 		return fmt.Errorf("missing source spec")
 	}
@@ -14,6 +14,9 @@ import (
 	"time"
 )
 
+// ControllerPodsLabelSelector selects the pods of the default controller and of all controller shards
+const ControllerPodsLabelSelector = "control-plane in (kluctl-controller,kluctl-controller-shard)"
+
 type LogLine struct {
 	Level       string    `json:"level"`
 	Timestamp   time.Time `json:"ts"`
@@ -25,7 +28,7 @@ type LogLine struct {
 
 func WatchControllerLogs(ctx context.Context, c *v1.CoreV1Client, controllerNamespace string, kdKey client.ObjectKey, reconcileId string, since time.Duration, follow bool) (chan LogLine, error) {
 	pods, err := c.Pods(controllerNamespace).List(ctx, metav1.ListOptions{
-		LabelSelector: "control-plane=kluctl-controller",
+		LabelSelector: ControllerPodsLabelSelector,
 	})
 	if err != nil {
 		return nil, err
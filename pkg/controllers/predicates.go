@@ -2,6 +2,8 @@ package controllers
 
 import (
 	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/meta"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
@@ -34,3 +36,40 @@ func (ReconcileRequestedPredicate) Update(e event.UpdateEvent) bool {
 		checkManualRequest(kluctlv1.KluctlRequestPruneAnnotation) ||
 		checkManualRequest(kluctlv1.KluctlRequestValidateAnnotation)
 }
+
+// DependencyReadyChangedPredicate triggers on changes of the Ready condition or the observed commit, which are the
+// relevant fields for dependants of a KluctlDeployment.
+type DependencyReadyChangedPredicate struct {
+	predicate.Funcs
+}
+
+func (DependencyReadyChangedPredicate) Create(e event.CreateEvent) bool {
+	return false
+}
+
+func (DependencyReadyChangedPredicate) Delete(e event.DeleteEvent) bool {
+	return false
+}
+
+func (DependencyReadyChangedPredicate) Generic(e event.GenericEvent) bool {
+	return false
+}
+
+func (DependencyReadyChangedPredicate) Update(e event.UpdateEvent) bool {
+	oldObj, ok1 := e.ObjectOld.(*kluctlv1.KluctlDeployment)
+	newObj, ok2 := e.ObjectNew.(*kluctlv1.KluctlDeployment)
+	if !ok1 || !ok2 {
+		return false
+	}
+
+	oldReady := apimeta.FindStatusCondition(oldObj.Status.Conditions, meta.ReadyCondition)
+	newReady := apimeta.FindStatusCondition(newObj.Status.Conditions, meta.ReadyCondition)
+	if (oldReady == nil) != (newReady == nil) {
+		return true
+	}
+	if newReady != nil && (oldReady.Status != newReady.Status || oldReady.Reason != newReady.Reason) {
+		return true
+	}
+	return oldObj.Status.ObservedCommit != newObj.Status.ObservedCommit ||
+		oldObj.Status.LastDriftDetectionResultMessage != newObj.Status.LastDriftDetectionResultMessage
+}
@@ -0,0 +1,341 @@
+package commands
+
+import (
+	errors2 "errors"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sort"
+)
+
+type HelmRelease struct {
+	Name      string
+	Namespace string
+}
+
+type AdoptConflict struct {
+	Field   string
+	Message string
+	// Lost is true if a regular deploy would not overwrite the field, meaning that --force-apply would be required
+	Lost bool
+}
+
+type AdoptCandidate struct {
+	Ref k8s2.ObjectRef
+	// Managers contains the field managers that currently own fields of the object
+	Managers    []string
+	Conflicts   []AdoptConflict
+	HelmRelease *HelmRelease
+}
+
+// AdoptReport is passed to the confirmation callback before objects are adopted
+type AdoptReport struct {
+	Candidates   []AdoptCandidate
+	HelmReleases []HelmRelease
+	// Skipped contains objects that are managed by another kluctl target and thus are not adopted
+	Skipped []k8s2.ObjectRef
+}
+
+// AdoptCommand takes over objects that already exist on the cluster but were created by other tools (e.g. kubectl or
+// Helm). Adoption is performed by force-applying the rendered objects, which adds the kluctl labels and transfers
+// ownership of all rendered fields to kluctl.
+type AdoptCommand struct {
+	targetCtx *target_context.TargetContext
+
+	RemoveHelmReleases bool
+	// AdoptForeign allows to adopt objects that carry the discriminator of another kluctl target
+	AdoptForeign bool
+}
+
+func NewAdoptCommand(targetCtx *target_context.TargetContext) *AdoptCommand {
+	return &AdoptCommand{
+		targetCtx: targetCtx,
+	}
+}
+
+func (cmd *AdoptCommand) Run(confirmCb func(report *AdoptReport) error) *result.CommandResult {
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+
+	r := newCommandResult(cmd.targetCtx, cmd.targetCtx.KluctlProject.LoadTime, "adopt")
+
+	defer func() {
+		finishCommandResult(r, cmd.targetCtx, dew)
+	}()
+
+	discriminator := cmd.targetCtx.Target.Discriminator
+	if discriminator == "" {
+		dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("adopting objects without a discriminator is not supported"))
+		return r
+	}
+
+	err := cmd.targetCtx.CheckCommandPolicies("adopt")
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+	if !addPolicyViolations(cmd.targetCtx, dew) {
+		return r
+	}
+
+	k := cmd.targetCtx.SharedContext.K
+	ru := utils2.NewRemoteObjectsUtil(cmd.targetCtx.SharedContext.Ctx, dew)
+	err = ru.UpdateRemoteObjects(k, &discriminator, cmd.targetCtx.DeploymentCollection.LocalObjectRefs(), false)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+
+	report, objects := cmd.findCandidates(k, ru, dew)
+
+	if confirmCb != nil && (len(report.Candidates) != 0 || len(report.Skipped) != 0) {
+		err = confirmCb(report)
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+	}
+
+	applied := cmd.adoptObjects(k, objects, dew)
+	if cmd.RemoveHelmReleases {
+		cmd.removeHelmReleases(k, report, applied, dew)
+	}
+
+	var adoptedObjects []*uo.UnstructuredObject
+	for _, o := range objects {
+		if _, ok := applied[o.GetK8sRef()]; ok {
+			adoptedObjects = append(adoptedObjects, o)
+		}
+	}
+	du := utils2.NewDiffUtil(dew, ru, applied)
+	du.DiffObjects(adoptedObjects)
+
+	r.Objects = collectObjects(cmd.targetCtx.DeploymentCollection, ru, nil, du, nil, nil)
+	for i := range r.Objects {
+		if o, ok := applied[r.Objects[i].Ref]; ok {
+			r.Objects[i].Applied = o
+		}
+	}
+
+	return r
+}
+
+func (cmd *AdoptCommand) findCandidates(k *k8s.K8sCluster, ru *utils2.RemoteObjectUtils, dew *utils2.DeploymentErrorsAndWarnings) (*AdoptReport, []*uo.UnstructuredObject) {
+	s := status.Start(cmd.targetCtx.SharedContext.Ctx, "Searching for objects to adopt")
+	defer s.Failed()
+
+	report := &AdoptReport{}
+	var objects []*uo.UnstructuredObject
+	helmReleases := map[HelmRelease]bool{}
+
+	for _, d := range cmd.targetCtx.DeploymentCollection.Deployments {
+		for _, o := range d.Objects {
+			ref := o.GetK8sRef()
+			remote := ru.GetRemoteObject(ref)
+			if remote == nil {
+				// will be created by the next deploy
+				continue
+			}
+			if x := remote.GetK8sLabel("kluctl.io/discriminator"); x != nil && *x == cmd.targetCtx.Target.Discriminator {
+				// already managed by this target
+				continue
+			}
+			if fd := getForeignDiscriminator(remote, cmd.targetCtx.Target.Discriminator); fd != "" && !cmd.AdoptForeign {
+				dew.AddWarning(ref, fmt.Errorf("not adopting object as it is managed by another target with discriminator '%s', use --adopt-foreign to adopt it anyway", fd))
+				report.Skipped = append(report.Skipped, ref)
+				continue
+			}
+
+			c := AdoptCandidate{
+				Ref:         ref,
+				Managers:    getFieldManagers(remote),
+				HelmRelease: getHelmRelease(remote),
+			}
+			conflicts, err := cmd.findConflicts(k, d, o, remote)
+			if err != nil {
+				dew.AddError(ref, err)
+				continue
+			}
+			c.Conflicts = conflicts
+
+			if c.HelmRelease != nil && !helmReleases[*c.HelmRelease] {
+				helmReleases[*c.HelmRelease] = true
+				report.HelmReleases = append(report.HelmReleases, *c.HelmRelease)
+			}
+
+			report.Candidates = append(report.Candidates, c)
+			objects = append(objects, o)
+		}
+	}
+
+	sort.Slice(report.Candidates, func(i, j int) bool {
+		return report.Candidates[i].Ref.Less(report.Candidates[j].Ref)
+	})
+	sort.Slice(report.Skipped, func(i, j int) bool {
+		return report.Skipped[i].Less(report.Skipped[j])
+	})
+	sort.Slice(report.HelmReleases, func(i, j int) bool {
+		a, b := report.HelmReleases[i], report.HelmReleases[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	s.UpdateAndInfoFallbackf("Found %d objects to adopt", len(report.Candidates))
+	s.Success()
+
+	return report, objects
+}
+
+// findConflicts performs a dry-run apply without forcing ownership and returns the reported field manager conflicts
+func (cmd *AdoptCommand) findConflicts(k *k8s.K8sCluster, d *deployment.DeploymentItem, o *uo.UnstructuredObject, remote *uo.UnstructuredObject) ([]AdoptConflict, error) {
+	x := k.FixObjectForPatch(o)
+	_, _, err := k.ApplyObject(x, k8s.PatchOptions{ForceDryRun: true})
+	if err == nil {
+		return nil, nil
+	}
+
+	var statusError *errors.StatusError
+	if !errors.IsConflict(err) || !errors2.As(err, &statusError) || statusError.ErrStatus.Details == nil {
+		return nil, err
+	}
+
+	cr := diff.ConflictResolver{
+		Configs: d.Project.GetConflictResolutionConfigs(),
+	}
+	_, lostOwnership, err := cr.ResolveConflicts(x, remote, statusError.ErrStatus)
+	if err != nil {
+		return nil, err
+	}
+	lost := map[string]bool{}
+	for _, lo := range lostOwnership {
+		lost[lo.Field] = true
+	}
+
+	var ret []AdoptConflict
+	for _, cause := range statusError.ErrStatus.Details.Causes {
+		ret = append(ret, AdoptConflict{
+			Field:   cause.Field,
+			Message: cause.Message,
+			Lost:    lost[cause.Field],
+		})
+	}
+	return ret, nil
+}
+
+func (cmd *AdoptCommand) adoptObjects(k *k8s.K8sCluster, objects []*uo.UnstructuredObject, dew *utils2.DeploymentErrorsAndWarnings) map[k8s2.ObjectRef]*uo.UnstructuredObject {
+	ret := map[k8s2.ObjectRef]*uo.UnstructuredObject{}
+	if len(objects) == 0 {
+		return ret
+	}
+
+	s := status.Startf(cmd.targetCtx.SharedContext.Ctx, "Adopting %d objects", len(objects))
+	defer s.Failed()
+
+	errCount := 0
+	for _, o := range objects {
+		ref := o.GetK8sRef()
+		x := k.FixObjectForPatch(o)
+		// force-applying transfers ownership of all rendered fields to kluctl
+		r, apiWarnings, err := k.ApplyObject(x, k8s.PatchOptions{ForceApply: true})
+		dew.AddApiWarnings(ref, apiWarnings)
+		if err != nil {
+			dew.AddError(ref, err)
+			errCount++
+			continue
+		}
+		ret[ref] = r
+	}
+
+	if errCount != 0 {
+		s.UpdateAndInfoFallbackf("Adopting %d objects: Failed with %d errors", len(objects), errCount)
+		s.Warning()
+	} else {
+		s.Success()
+	}
+	return ret
+}
+
+// removeHelmReleases deletes the Helm release secrets of all releases for which all objects got adopted. Helm will
+// then forget about the release, so that future "helm upgrade" or "helm uninstall" invocations won't touch the
+// adopted objects.
+func (cmd *AdoptCommand) removeHelmReleases(k *k8s.K8sCluster, report *AdoptReport, applied map[k8s2.ObjectRef]*uo.UnstructuredObject, dew *utils2.DeploymentErrorsAndWarnings) {
+	secretGvk := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+
+	for _, hr := range report.HelmReleases {
+		complete := true
+		for _, c := range report.Candidates {
+			if c.HelmRelease != nil && *c.HelmRelease == hr {
+				if _, ok := applied[c.Ref]; !ok {
+					complete = false
+				}
+			}
+		}
+		if !complete {
+			dew.AddWarning(k8s2.ObjectRef{}, fmt.Errorf("not removing Helm release %s/%s as not all of its objects got adopted", hr.Namespace, hr.Name))
+			continue
+		}
+
+		secrets, apiWarnings, err := k.ListMetadata(secretGvk, hr.Namespace, map[string]string{
+			"owner": "helm",
+			"name":  hr.Name,
+		})
+		dew.AddApiWarnings(k8s2.ObjectRef{}, apiWarnings)
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("failed to list secrets of Helm release %s/%s: %w", hr.Namespace, hr.Name, err))
+			continue
+		}
+		for _, secret := range secrets {
+			ref := k8s2.NewObjectRef("", "v1", "Secret", secret.GetK8sName(), hr.Namespace)
+			status.Infof(cmd.targetCtx.SharedContext.Ctx, "Deleting Helm release secret %s", ref.String())
+			apiWarnings, err = k.DeleteSingleObject(ref, k8s.DeleteOptions{IgnoreNotFoundError: true})
+			dew.AddApiWarnings(ref, apiWarnings)
+			if err != nil {
+				dew.AddError(ref, err)
+			}
+		}
+	}
+}
+
+func getFieldManagers(o *uo.UnstructuredObject) []string {
+	m := map[string]bool{}
+	var ret []string
+	mfs, _, _ := o.GetNestedObjectList("metadata", "managedFields")
+	for _, mf := range mfs {
+		manager, _, _ := mf.GetNestedString("manager")
+		if manager != "" && !m[manager] {
+			m[manager] = true
+			ret = append(ret, manager)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// getForeignDiscriminator returns the discriminator of the object if it is set and differs from the given one
+func getForeignDiscriminator(o *uo.UnstructuredObject, discriminator string) string {
+	x := o.GetK8sLabel("kluctl.io/discriminator")
+	if x == nil || *x == "" || *x == discriminator {
+		return ""
+	}
+	return *x
+}
+
+func getHelmRelease(o *uo.UnstructuredObject) *HelmRelease {
+	managedBy := o.GetK8sLabel("app.kubernetes.io/managed-by")
+	name := o.GetK8sAnnotation("meta.helm.sh/release-name")
+	namespace := o.GetK8sAnnotation("meta.helm.sh/release-namespace")
+	if managedBy == nil || *managedBy != "Helm" || name == nil || namespace == nil {
+		return nil
+	}
+	return &HelmRelease{Name: *name, Namespace: *namespace}
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetHelmRelease(t *testing.T) {
+	o := uo.New()
+	assert.Nil(t, getHelmRelease(o))
+
+	o.SetK8sLabel("app.kubernetes.io/managed-by", "Helm")
+	assert.Nil(t, getHelmRelease(o))
+
+	o.SetK8sAnnotation("meta.helm.sh/release-name", "my-release")
+	o.SetK8sAnnotation("meta.helm.sh/release-namespace", "my-ns")
+	assert.Equal(t, &HelmRelease{Name: "my-release", Namespace: "my-ns"}, getHelmRelease(o))
+
+	o.SetK8sLabel("app.kubernetes.io/managed-by", "kluctl")
+	assert.Nil(t, getHelmRelease(o))
+}
+
+func TestGetFieldManagers(t *testing.T) {
+	o := uo.FromMap(map[string]any{
+		"metadata": map[string]any{
+			"managedFields": []any{
+				map[string]any{"manager": "kubectl-client-side-apply", "operation": "Update"},
+				map[string]any{"manager": "helm", "operation": "Update"},
+				map[string]any{"manager": "kubectl-client-side-apply", "operation": "Update", "subresource": "status"},
+			},
+		},
+	})
+	assert.Equal(t, []string{"helm", "kubectl-client-side-apply"}, getFieldManagers(o))
+}
+
+func TestGetForeignDiscriminator(t *testing.T) {
+	o := uo.New()
+	assert.Equal(t, "", getForeignDiscriminator(o, "d1"))
+
+	o.SetK8sLabel("kluctl.io/discriminator", "")
+	assert.Equal(t, "", getForeignDiscriminator(o, "d1"))
+
+	o.SetK8sLabel("kluctl.io/discriminator", "d1")
+	assert.Equal(t, "", getForeignDiscriminator(o, "d1"))
+
+	o.SetK8sLabel("kluctl.io/discriminator", "d2")
+	assert.Equal(t, "d2", getForeignDiscriminator(o, "d1"))
+}
@@ -18,6 +18,9 @@ type DeleteCommand struct {
 	targetCtx     *target_context.TargetContext
 	inclusion     *utils.Inclusion
 	wait          bool
+
+	// BackupFile is written with the remote state of all objects before they are deleted
+	BackupFile string
 }
 
 func NewDeleteCommand(discriminator string, targetCtx *target_context.TargetContext, inclusion *utils.Inclusion, wait bool) *DeleteCommand {
@@ -60,6 +63,14 @@ func (cmd *DeleteCommand) Run(ctx context.Context, k *k8s.K8sCluster, confirmCb
 		return r
 	}
 
+	if cmd.targetCtx != nil {
+		err := cmd.targetCtx.CheckCommandPolicies("delete")
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+	}
+
 	ru := utils2.NewRemoteObjectsUtil(ctx, dew)
 	err := ru.UpdateRemoteObjects(k, &discriminator, nil, false)
 	if err != nil {
@@ -81,6 +92,14 @@ func (cmd *DeleteCommand) Run(ctx context.Context, k *k8s.K8sCluster, confirmCb
 		}
 	}
 
+	if cmd.BackupFile != "" && len(deleteRefs) != 0 {
+		err = utils2.WriteBackupFile(cmd.BackupFile, ru, deleteRefs)
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+	}
+
 	deleted := utils2.DeleteObjects(ctx, k, deleteRefs, dew, cmd.wait)
 
 	var c *deployment.DeploymentCollection
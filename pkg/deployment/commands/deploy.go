@@ -21,6 +21,12 @@ type DeployCommand struct {
 	NoWait              bool
 	Prune               bool
 	WaitPrune           bool
+	AllowDangerousPrune bool
+	// PruneBackupFile is written with the remote state of all pruned objects before they are deleted
+	PruneBackupFile string
+	// OnlyRefs restricts the deployment to the given objects, e.g. to re-apply drifted objects. Hooks and
+	// deletions are skipped when set.
+	OnlyRefs map[k8s2.ObjectRef]bool
 }
 
 func NewDeployCommand(targetCtx *target_context.TargetContext) *DeployCommand {
@@ -43,13 +49,22 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 		finishCommandResult(r, cmd.targetCtx, dew)
 	}()
 
+	err := cmd.targetCtx.CheckCommandPolicies("deploy", cmd.policyFlags()...)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+	if !addPolicyViolations(cmd.targetCtx, dew) {
+		return r
+	}
+
 	if cmd.targetCtx.Target.Discriminator == "" {
 		status.Warning(cmd.targetCtx.SharedContext.Ctx, "No discriminator configured. Orphan object detection will not work")
 		dew.AddWarning(k8s2.ObjectRef{}, fmt.Errorf("no discriminator configured. Orphan object detection will not work"))
 	}
 
 	ru := utils2.NewRemoteObjectsUtil(cmd.targetCtx.SharedContext.Ctx, dew)
-	err := ru.UpdateRemoteObjects(cmd.targetCtx.SharedContext.K, &cmd.targetCtx.Target.Discriminator, cmd.targetCtx.DeploymentCollection.LocalObjectRefs(), false)
+	err = ru.UpdateRemoteObjects(cmd.targetCtx.SharedContext.K, &cmd.targetCtx.Target.Discriminator, cmd.targetCtx.DeploymentCollection.LocalObjectRefs(), false)
 	if err != nil {
 		dew.AddError(k8s2.ObjectRef{}, err)
 		return r
@@ -64,6 +79,8 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 		AbortOnError:        false,
 		ReadinessTimeout:    cmd.ReadinessTimeout,
 		NoWait:              cmd.NoWait,
+		ReadinessRules:      cmd.targetCtx.GetReadinessRules,
+		OnlyRefs:            cmd.OnlyRefs,
 	}
 
 	if diffResultCb != nil {
@@ -110,13 +127,52 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 	if cmd.Prune && cmd.targetCtx.Target.Discriminator == "" {
 		dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("pruning without a discriminator is not supported"))
 	} else if cmd.Prune {
-		deleted = utils2.DeleteObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, orphanObjects, dew, cmd.WaitPrune)
-
-		// now clean up the list of orphan objects (remove the ones that got deleted)
-		orphanObjects = filterDeletedOrphans(orphanObjects, deleted)
+		impacts, err := utils2.AnalyzePruneImpact(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.Target.Discriminator, orphanObjects)
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+		} else {
+			toDelete, _ := filterDangerousOrphans(cmd.targetCtx, dew, orphanObjects, impacts, cmd.AllowDangerousPrune)
+			if cmd.PruneBackupFile != "" && len(toDelete) != 0 {
+				err = utils2.WriteBackupFile(cmd.PruneBackupFile, ru, toDelete)
+				if err != nil {
+					dew.AddError(k8s2.ObjectRef{}, err)
+					toDelete = nil
+				}
+			}
+			deleted = utils2.DeleteObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, toDelete, dew, cmd.WaitPrune)
+
+			// now clean up the list of orphan objects (remove the ones that got deleted)
+			orphanObjects = filterDeletedOrphans(orphanObjects, deleted)
+		}
 	}
 
 	r.Objects = collectObjects(cmd.targetCtx.DeploymentCollection, ru, au, du, orphanObjects, deleted)
 
 	return r
 }
+
+func (cmd *DeployCommand) policyFlags() []string {
+	var ret []string
+	if cmd.ForceApply {
+		ret = append(ret, target_context.PolicyFlagForceApply)
+	}
+	if cmd.ReplaceOnError {
+		ret = append(ret, target_context.PolicyFlagReplaceOnError)
+	}
+	if cmd.ForceReplaceOnError {
+		ret = append(ret, target_context.PolicyFlagForceReplaceOnError)
+	}
+	if cmd.AbortOnError {
+		ret = append(ret, target_context.PolicyFlagAbortOnError)
+	}
+	if cmd.NoWait {
+		ret = append(ret, target_context.PolicyFlagNoWait)
+	}
+	if cmd.Prune {
+		ret = append(ret, target_context.PolicyFlagPrune)
+	}
+	if cmd.AllowDangerousPrune {
+		ret = append(ret, target_context.PolicyFlagAllowDangerousPrune)
+	}
+	return ret
+}
@@ -41,6 +41,9 @@ func (cmd *DiffCommand) Run() *result.CommandResult {
 		finishCommandResult(r, cmd.targetCtx, dew)
 	}()
 
+	// policy violations are reported, but the diff is still performed so that the changes can be reviewed
+	addPolicyViolations(cmd.targetCtx, dew)
+
 	if cmd.targetCtx.Target.Discriminator == "" {
 		status.Warning(cmd.targetCtx.SharedContext.Ctx, "No discriminator configured. Orphan object detection will not work")
 		dew.AddWarning(k8s2.ObjectRef{}, fmt.Errorf("no discriminator configured. Orphan object detection will not work"))
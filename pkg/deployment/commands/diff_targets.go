@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+// DiffTargetsCommand compares the rendered objects of two targets without accessing any cluster. Changes are
+// reported from the point of view of otherTargetCtx, meaning that objects only rendered by targetCtx are new.
+// otherTargetCtx might be nil, in which case all objects are reported as new.
+type DiffTargetsCommand struct {
+	targetCtx      *target_context.TargetContext
+	otherTargetCtx *target_context.TargetContext
+
+	IgnoreTags           bool
+	IgnoreLabels         bool
+	IgnoreAnnotations    bool
+	IgnoreKluctlMetadata bool
+}
+
+func NewDiffTargetsCommand(targetCtx *target_context.TargetContext, otherTargetCtx *target_context.TargetContext) *DiffTargetsCommand {
+	return &DiffTargetsCommand{
+		targetCtx:      targetCtx,
+		otherTargetCtx: otherTargetCtx,
+	}
+}
+
+func (cmd *DiffTargetsCommand) Run() *result.CommandResult {
+	dew := utils.NewDeploymentErrorsAndWarnings()
+
+	r := newCommandResult(cmd.targetCtx, cmd.targetCtx.KluctlProject.LoadTime, "diff")
+
+	defer func() {
+		finishCommandResult(r, cmd.targetCtx, dew)
+	}()
+
+	addPolicyViolations(cmd.targetCtx, dew)
+
+	type diffConfig struct {
+		ignoreForDiffs []types.IgnoreForDiffItemConfig
+		embeddedDiffs  []types.EmbeddedDiffItemConfig
+	}
+	configs := map[k8s2.ObjectRef]diffConfig{}
+	for _, d := range cmd.targetCtx.DeploymentCollection.Deployments {
+		c := diffConfig{
+			ignoreForDiffs: d.Project.GetIgnoreForDiffs(cmd.IgnoreTags, cmd.IgnoreLabels, cmd.IgnoreAnnotations, cmd.IgnoreKluctlMetadata),
+			embeddedDiffs:  d.Project.GetEmbeddedDiffs(),
+		}
+		for _, o := range d.Objects {
+			configs[o.GetK8sRef()] = c
+		}
+	}
+
+	var otherObjects []*uo.UnstructuredObject
+	if cmd.otherTargetCtx != nil {
+		otherObjects = cmd.otherTargetCtx.DeploymentCollection.LocalObjects()
+	}
+
+	objects, err := diff.DiffObjectSets(otherObjects, cmd.targetCtx.DeploymentCollection.LocalObjects(), func(o *uo.UnstructuredObject) ([]types.IgnoreForDiffItemConfig, []types.EmbeddedDiffItemConfig) {
+		c, ok := configs[o.GetK8sRef()]
+		if !ok {
+			return deployment.IgnoreForDiffsFromFlags(cmd.IgnoreTags, cmd.IgnoreLabels, cmd.IgnoreAnnotations, cmd.IgnoreKluctlMetadata), nil
+		}
+		return c.ignoreForDiffs, c.embeddedDiffs
+	})
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+	r.Objects = objects
+
+	return r
+}
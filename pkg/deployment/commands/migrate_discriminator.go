@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"sort"
+)
+
+// MigrateDiscriminatorCommand relabels all objects carrying the old discriminator with the new discriminator, so that
+// changing a target's discriminator does not turn all existing objects into orphans
+type MigrateDiscriminatorCommand struct {
+	targetCtx *target_context.TargetContext
+	from      string
+	to        string
+}
+
+func NewMigrateDiscriminatorCommand(targetCtx *target_context.TargetContext, from string, to string) *MigrateDiscriminatorCommand {
+	return &MigrateDiscriminatorCommand{
+		targetCtx: targetCtx,
+		from:      from,
+		to:        to,
+	}
+}
+
+func (cmd *MigrateDiscriminatorCommand) Run(confirmCb func(refs []k8s2.ObjectRef) error) *result.CommandResult {
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+
+	r := newCommandResult(cmd.targetCtx, cmd.targetCtx.KluctlProject.LoadTime, "migrate-discriminator")
+	r.TargetKey.Discriminator = cmd.to
+
+	defer func() {
+		finishCommandResult(r, cmd.targetCtx, dew)
+	}()
+
+	if cmd.from == "" || cmd.to == "" {
+		dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("the old and the new discriminator must both be non-empty"))
+		return r
+	}
+	if cmd.from == cmd.to {
+		dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("the old and the new discriminator are equal"))
+		return r
+	}
+
+	err := cmd.targetCtx.CheckCommandPolicies("migrate-discriminator")
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+
+	k := cmd.targetCtx.SharedContext.K
+	ctx := cmd.targetCtx.SharedContext.Ctx
+
+	ru := utils2.NewRemoteObjectsUtil(ctx, dew)
+	err = ru.UpdateRemoteObjects(k, &cmd.from, nil, false)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+
+	existingRu := utils2.NewRemoteObjectsUtil(ctx, dew)
+	err = existingRu.UpdateRemoteObjects(k, &cmd.to, nil, false)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+	if l := existingRu.GetFilteredRemoteObjects(nil); len(l) != 0 {
+		dew.AddWarning(k8s2.ObjectRef{}, fmt.Errorf("the new discriminator '%s' is already used by %d objects", cmd.to, len(l)))
+	}
+
+	objects := ru.GetFilteredRemoteObjects(nil)
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].GetK8sRef().Less(objects[j].GetK8sRef())
+	})
+
+	if confirmCb != nil && len(objects) != 0 {
+		var refs []k8s2.ObjectRef
+		for _, o := range objects {
+			refs = append(refs, o.GetK8sRef())
+		}
+		err = confirmCb(refs)
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+	}
+
+	applied := cmd.relabelObjects(k, objects, dew)
+
+	du := utils2.NewDiffUtil(dew, ru, applied)
+	du.DiffObjects(objects)
+
+	r.Objects = collectObjects(nil, ru, nil, du, nil, nil)
+	for i := range r.Objects {
+		if o, ok := applied[r.Objects[i].Ref]; ok {
+			r.Objects[i].Applied = o
+		}
+	}
+
+	return r
+}
+
+func (cmd *MigrateDiscriminatorCommand) relabelObjects(k *k8s.K8sCluster, objects []*uo.UnstructuredObject, dew *utils2.DeploymentErrorsAndWarnings) map[k8s2.ObjectRef]*uo.UnstructuredObject {
+	ret := map[k8s2.ObjectRef]*uo.UnstructuredObject{}
+	if len(objects) == 0 {
+		return ret
+	}
+
+	patch, err := buildMigrateDiscriminatorPatch(cmd.from, cmd.to)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return ret
+	}
+
+	s := status.Startf(cmd.targetCtx.SharedContext.Ctx, "Migrating discriminator of %d objects", len(objects))
+	defer s.Failed()
+	s.SetTotal(len(objects))
+
+	errCount := 0
+	for _, o := range objects {
+		ref := o.GetK8sRef()
+		x, apiWarnings, err := k.JsonPatchObject(ref, patch, k8s.PatchOptions{})
+		dew.AddApiWarnings(ref, apiWarnings)
+		s.Increment()
+		if err != nil {
+			dew.AddError(ref, err)
+			errCount++
+			continue
+		}
+		ret[ref] = x
+	}
+
+	if errCount != 0 {
+		s.UpdateAndInfoFallbackf("Migrating discriminator of %d objects: Failed with %d errors", len(objects), errCount)
+		s.Warning()
+	} else {
+		s.Success()
+	}
+	return ret
+}
+
+// buildMigrateDiscriminatorPatch builds a JSON patch that replaces the discriminator label. The test operation ensures
+// that we only modify objects that still carry the old discriminator
+func buildMigrateDiscriminatorPatch(from string, to string) ([]byte, error) {
+	return json.Marshal([]map[string]any{
+		{"op": "test", "path": "/metadata/labels/kluctl.io~1discriminator", "value": from},
+		{"op": "replace", "path": "/metadata/labels/kluctl.io~1discriminator", "value": to},
+	})
+}
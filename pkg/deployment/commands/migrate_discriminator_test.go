@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"encoding/json"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMigrateDiscriminatorPatch(t *testing.T) {
+	patchJson, err := buildMigrateDiscriminatorPatch("old", "new")
+	assert.NoError(t, err)
+	patch, err := jsonpatch.DecodePatch(patchJson)
+	assert.NoError(t, err)
+
+	buildObject := func(labels map[string]any) []byte {
+		o := uo.FromMap(map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]any{
+				"name":      "cm",
+				"namespace": "ns",
+			},
+		})
+		if labels != nil {
+			_ = o.SetNestedField(labels, "metadata", "labels")
+		}
+		b, err := json.Marshal(o.Object)
+		assert.NoError(t, err)
+		return b
+	}
+
+	b, err := patch.Apply(buildObject(map[string]any{
+		"kluctl.io/discriminator": "old",
+		"other":                   "x",
+	}))
+	assert.NoError(t, err)
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(b, &m))
+	assert.Equal(t, map[string]string{
+		"kluctl.io/discriminator": "new",
+		"other":                   "x",
+	}, uo.FromMap(m).GetK8sLabels())
+
+	// objects that were relabelled in-between must not be touched
+	_, err = patch.Apply(buildObject(map[string]any{
+		"kluctl.io/discriminator": "other",
+	}))
+	assert.Error(t, err)
+
+	_, err = patch.Apply(buildObject(map[string]any{
+		"other": "x",
+	}))
+	assert.Error(t, err)
+
+	_, err = patch.Apply(buildObject(nil))
+	assert.Error(t, err)
+}
@@ -32,8 +32,14 @@ func (cmd *PokeImagesCommand) Run() *result.CommandResult {
 		finishCommandResult(r, cmd.targetCtx, dew)
 	}()
 
+	err := cmd.targetCtx.CheckCommandPolicies("poke-images")
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+
 	ru := utils2.NewRemoteObjectsUtil(cmd.targetCtx.SharedContext.Ctx, dew)
-	err := ru.UpdateRemoteObjects(cmd.targetCtx.SharedContext.K, nil, cmd.targetCtx.DeploymentCollection.LocalObjectRefs(), false)
+	err = ru.UpdateRemoteObjects(cmd.targetCtx.SharedContext.K, nil, cmd.targetCtx.DeploymentCollection.LocalObjectRefs(), false)
 	if err != nil {
 		dew.AddError(k8s2.ObjectRef{}, err)
 		return r
@@ -2,10 +2,12 @@ package commands
 
 import (
 	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/v2/pkg/deployment"
 	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/results"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 )
@@ -14,6 +16,12 @@ type PruneCommand struct {
 	discriminator string
 	targetCtx     *target_context.TargetContext
 	wait          bool
+
+	AllowDangerousPrune bool
+	// ResultStore is used to find the command results in which orphan objects were rendered for the last time
+	ResultStore results.ResultStore
+	// BackupFile is written with the remote state of all objects before they are deleted
+	BackupFile string
 }
 
 func NewPruneCommand(discriminator string, targetCtx *target_context.TargetContext, wait bool) *PruneCommand {
@@ -24,7 +32,7 @@ func NewPruneCommand(discriminator string, targetCtx *target_context.TargetConte
 	}
 }
 
-func (cmd *PruneCommand) Run(confirmCb func(refs []k8s2.ObjectRef) error) *result.CommandResult {
+func (cmd *PruneCommand) Run(confirmCb func(report *PruneReport) error) *result.CommandResult {
 	dew := utils2.NewDeploymentErrorsAndWarnings()
 
 	r := newCommandResult(cmd.targetCtx, cmd.targetCtx.KluctlProject.LoadTime, "prune")
@@ -42,8 +50,18 @@ func (cmd *PruneCommand) Run(confirmCb func(refs []k8s2.ObjectRef) error) *resul
 		return r
 	}
 
+	var policyFlags []string
+	if cmd.AllowDangerousPrune {
+		policyFlags = append(policyFlags, target_context.PolicyFlagAllowDangerousPrune)
+	}
+	err := cmd.targetCtx.CheckCommandPolicies("prune", policyFlags...)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+
 	ru := utils2.NewRemoteObjectsUtil(cmd.targetCtx.SharedContext.Ctx, dew)
-	err := ru.UpdateRemoteObjects(cmd.targetCtx.SharedContext.K, &discriminator, nil, false)
+	err = ru.UpdateRemoteObjects(cmd.targetCtx.SharedContext.K, &discriminator, nil, false)
 	if err != nil {
 		dew.AddError(k8s2.ObjectRef{}, err)
 		return r
@@ -55,15 +73,40 @@ func (cmd *PruneCommand) Run(confirmCb func(refs []k8s2.ObjectRef) error) *resul
 		return r
 	}
 
+	impacts, err := utils2.AnalyzePruneImpact(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, ru, discriminator, orphanObjects)
+	if err != nil {
+		dew.AddError(k8s2.ObjectRef{}, err)
+		return r
+	}
+	toDelete, skipped := filterDangerousOrphans(cmd.targetCtx, dew, orphanObjects, impacts, cmd.AllowDangerousPrune)
+
 	if confirmCb != nil {
-		err = confirmCb(orphanObjects)
+		report := &PruneReport{
+			Orphans: toDelete,
+			Skipped: skipped,
+			Impacts: impacts,
+		}
+		report.LastRendered, err = findLastRendered(cmd.ResultStore, r, orphanObjects)
+		if err != nil {
+			status.Warningf(cmd.targetCtx.SharedContext.Ctx, "Failed to determine last rendering of orphan objects: %s", err.Error())
+		}
+
+		err = confirmCb(report)
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+	}
+
+	if cmd.BackupFile != "" && len(toDelete) != 0 {
+		err = utils2.WriteBackupFile(cmd.BackupFile, ru, toDelete)
 		if err != nil {
 			dew.AddError(k8s2.ObjectRef{}, err)
 			return r
 		}
 	}
 
-	deleted := utils2.DeleteObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, orphanObjects, dew, cmd.wait)
+	deleted := utils2.DeleteObjects(cmd.targetCtx.SharedContext.Ctx, cmd.targetCtx.SharedContext.K, toDelete, dew, cmd.wait)
 	orphanObjects = filterDeletedOrphans(orphanObjects, deleted)
 
 	r.Objects = collectObjects(cmd.targetCtx.DeploymentCollection, ru, nil, nil, orphanObjects, deleted)
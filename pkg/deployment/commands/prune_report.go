@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"fmt"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+)
+
+// maxLastRenderedLookups limits the number of command results that are loaded to find out when an orphan object was
+// rendered for the last time
+const maxLastRenderedLookups = 50
+
+// PruneReport is passed to the confirmation callback before orphan objects are deleted
+type PruneReport struct {
+	// Orphans contains the objects that are going to be deleted
+	Orphans []k8s2.ObjectRef
+	// Skipped contains orphan objects that are not deleted due to dangerous prune impacts
+	Skipped []k8s2.ObjectRef
+	Impacts []utils2.PruneImpact
+
+	// LastRendered contains the newest command result in which the orphan object was still rendered
+	LastRendered map[k8s2.ObjectRef]*result.CommandResultSummary
+}
+
+func (r *PruneReport) GetImpacts(ref k8s2.ObjectRef) []utils2.PruneImpact {
+	var ret []utils2.PruneImpact
+	for _, i := range r.Impacts {
+		if i.Ref == ref {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+// filterDangerousOrphans removes orphans with dangerous prune impacts from the list of objects to delete, depending on
+// the dangerousPrune target policy. Each skipped object is reported as error (policy "fail") or warning (policy "skip").
+// With the policy "warn", nothing is removed and each impact is reported as warning.
+func filterDangerousOrphans(targetCtx *target_context.TargetContext, dew *utils2.DeploymentErrorsAndWarnings, orphans []k8s2.ObjectRef, impacts []utils2.PruneImpact, allowDangerous bool) ([]k8s2.ObjectRef, []k8s2.ObjectRef) {
+	policy := targetCtx.DangerousPrunePolicy()
+	if allowDangerous || policy == target_context.DangerousPruneAllow || len(impacts) == 0 {
+		return orphans, nil
+	}
+	if policy == target_context.DangerousPruneWarn {
+		for _, i := range impacts {
+			dew.AddWarning(i.Ref, fmt.Errorf("pruning object with dangerous impact: %s", i.Message))
+		}
+		return orphans, nil
+	}
+
+	dangerous := map[k8s2.ObjectRef]bool{}
+	for _, i := range impacts {
+		dangerous[i.Ref] = true
+		err := fmt.Errorf("refusing to prune object: %s", i.Message)
+		if policy == target_context.DangerousPruneSkip {
+			dew.AddWarning(i.Ref, err)
+		} else {
+			dew.AddError(i.Ref, err)
+		}
+	}
+
+	var toDelete, skipped []k8s2.ObjectRef
+	for _, ref := range orphans {
+		if dangerous[ref] {
+			skipped = append(skipped, ref)
+		} else {
+			toDelete = append(toDelete, ref)
+		}
+	}
+	return toDelete, skipped
+}
+
+// findLastRendered searches the newest command results of the same discriminator and cluster for the given objects
+// and returns the newest result in which each object was rendered
+func findLastRendered(rs results.ResultStore, r *result.CommandResult, refs []k8s2.ObjectRef) (map[k8s2.ObjectRef]*result.CommandResultSummary, error) {
+	ret := map[k8s2.ObjectRef]*result.CommandResultSummary{}
+	if rs == nil || len(refs) == 0 || r.TargetKey.Discriminator == "" {
+		return ret, nil
+	}
+
+	summaries, err := rs.ListCommandResultSummaries(results.ListResultSummariesOptions{
+		ProjectFilter: &r.ProjectKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	missing := map[k8s2.ObjectRef]bool{}
+	for _, ref := range refs {
+		missing[ref] = true
+	}
+
+	lookups := 0
+	for i := range summaries {
+		s := &summaries[i]
+		if len(missing) == 0 || lookups >= maxLastRenderedLookups {
+			break
+		}
+		if s.TargetKey.Discriminator != r.TargetKey.Discriminator || s.TargetKey.ClusterId != r.TargetKey.ClusterId {
+			continue
+		}
+		if s.RenderedObjects == 0 {
+			continue
+		}
+
+		lookups++
+		cr, err := rs.GetCommandResult(results.GetCommandResultOptions{Id: s.Id, Reduced: true})
+		if err != nil {
+			return nil, err
+		}
+		if cr == nil {
+			continue
+		}
+		for _, o := range cr.Objects {
+			if o.Rendered != nil && missing[o.Ref] {
+				ret[o.Ref] = s
+				delete(missing, o.Ref)
+			}
+		}
+	}
+	return ret, nil
+}
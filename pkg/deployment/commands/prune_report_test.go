@@ -0,0 +1,62 @@
+package commands
+
+import (
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFilterDangerousOrphans(t *testing.T) {
+	pvc := k8s2.NewObjectRef("", "v1", "PersistentVolumeClaim", "data", "default")
+	cm := k8s2.NewObjectRef("", "v1", "ConfigMap", "cm", "default")
+	orphans := []k8s2.ObjectRef{pvc, cm}
+	impacts := []utils2.PruneImpact{
+		{Ref: pvc, Category: utils2.PruneImpactPersistentVolumeDeleted, Message: "data will be lost"},
+	}
+
+	newTargetCtx := func(policy string) *target_context.TargetContext {
+		return &target_context.TargetContext{
+			Target: types.Target{Policies: &types.TargetPolicies{DangerousPrune: policy}},
+		}
+	}
+
+	for _, policy := range []string{target_context.DangerousPruneFail, target_context.DangerousPruneSkip} {
+		t.Run(policy, func(t *testing.T) {
+			dew := utils2.NewDeploymentErrorsAndWarnings()
+			toDelete, skipped := filterDangerousOrphans(newTargetCtx(policy), dew, orphans, impacts, false)
+			assert.Equal(t, []k8s2.ObjectRef{cm}, toDelete)
+			assert.Equal(t, []k8s2.ObjectRef{pvc}, skipped)
+			if policy == target_context.DangerousPruneSkip {
+				assert.Empty(t, dew.GetErrorsList())
+				assert.Len(t, dew.GetWarningsList(), 1)
+			} else {
+				assert.Len(t, dew.GetErrorsList(), 1)
+				assert.Empty(t, dew.GetWarningsList())
+			}
+		})
+	}
+
+	for _, policy := range []string{"", target_context.DangerousPruneWarn} {
+		t.Run("warn-"+policy, func(t *testing.T) {
+			dew := utils2.NewDeploymentErrorsAndWarnings()
+			toDelete, skipped := filterDangerousOrphans(newTargetCtx(policy), dew, orphans, impacts, false)
+			assert.Equal(t, orphans, toDelete)
+			assert.Empty(t, skipped)
+			assert.Empty(t, dew.GetErrorsList())
+			assert.Len(t, dew.GetWarningsList(), 1)
+		})
+	}
+
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+	toDelete, skipped := filterDangerousOrphans(newTargetCtx(target_context.DangerousPruneAllow), dew, orphans, impacts, false)
+	assert.Equal(t, orphans, toDelete)
+	assert.Empty(t, skipped)
+
+	toDelete, skipped = filterDangerousOrphans(newTargetCtx(""), dew, orphans, impacts, true)
+	assert.Equal(t, orphans, toDelete)
+	assert.Empty(t, skipped)
+	assert.Empty(t, dew.GetErrorsList())
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+// RestoreCommand re-creates objects that were previously deleted by delete or prune. Objects that already exist
+// are skipped.
+type RestoreCommand struct {
+	objects []*uo.UnstructuredObject
+}
+
+func NewRestoreCommand(objects []*uo.UnstructuredObject) *RestoreCommand {
+	return &RestoreCommand{
+		objects: objects,
+	}
+}
+
+func (cmd *RestoreCommand) Run(ctx context.Context, k *k8s.K8sCluster, confirmCb func(refs []k8s2.ObjectRef) error) *result.CommandResult {
+	dew := utils2.NewDeploymentErrorsAndWarnings()
+
+	r := &result.CommandResult{}
+	r.Command = result.CommandInfo{
+		StartTime: metav1.NewTime(time.Now()),
+		Command:   "restore",
+		DryRun:    k.DryRun,
+	}
+	r.ClusterInfo = buildClusterInfo(k, &r.Warnings)
+
+	defer func() {
+		finishCommandResult(r, nil, dew)
+	}()
+
+	var toRestore []*uo.UnstructuredObject
+	for _, o := range cmd.objects {
+		ref := o.GetK8sRef()
+		x, err := utils2.PrepareObjectForRestore(o)
+		if err != nil {
+			dew.AddError(ref, err)
+			continue
+		}
+
+		_, _, err = k.GetSingleObjectMetadata(ref)
+		if err == nil {
+			dew.AddWarning(ref, fmt.Errorf("object already exists, skipping restore"))
+			continue
+		} else if !errors.IsNotFound(err) {
+			dew.AddError(ref, err)
+			continue
+		}
+		toRestore = append(toRestore, x)
+	}
+	utils2.SortObjectsForRestore(toRestore)
+
+	if confirmCb != nil && len(toRestore) != 0 {
+		var refs []k8s2.ObjectRef
+		for _, x := range toRestore {
+			refs = append(refs, x.GetK8sRef())
+		}
+		err := confirmCb(refs)
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+	}
+
+	s := status.Startf(ctx, "Restoring %d objects", len(toRestore))
+	defer s.Failed()
+
+	errCount := 0
+	for _, x := range toRestore {
+		ref := x.GetK8sRef()
+		applied, apiWarnings, err := k.ApplyObject(k.FixObjectForPatch(x), k8s.PatchOptions{})
+		dew.AddApiWarnings(ref, apiWarnings)
+		if err != nil {
+			dew.AddError(ref, err)
+			errCount++
+			continue
+		}
+		r.Objects = append(r.Objects, result.ResultObject{
+			BaseObject: result.BaseObject{
+				Ref: ref,
+				New: true,
+			},
+			Applied: applied,
+		})
+	}
+
+	if errCount != 0 {
+		s.UpdateAndInfoFallbackf("Restoring %d objects: Failed with %d errors", len(toRestore), errCount)
+		s.Warning()
+	} else {
+		s.Success()
+	}
+
+	return r
+}
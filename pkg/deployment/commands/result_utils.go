@@ -1,6 +1,7 @@
 package commands
 
 import (
+	errors2 "errors"
 	"github.com/kluctl/kluctl/lib/git"
 	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/k8s"
@@ -105,6 +106,23 @@ func finishCommandResult(r *result.CommandResult, targetCtx *target_context.Targ
 	r.Command.EndTime = metav1.Now()
 }
 
+// addPolicyViolations records the policy violations and usages of deprecated/removed APIs found while preparing the
+// deployment collection. It returns false if any of these is an error, in which case nothing must be applied.
+func addPolicyViolations(targetCtx *target_context.TargetContext, dew *utils2.DeploymentErrorsAndWarnings) bool {
+	dc := targetCtx.DeploymentCollection
+	for _, l := range [][]result.DeploymentError{dc.PolicyWarnings, dc.ApiWarnings} {
+		for _, e := range l {
+			dew.AddWarning(e.Ref, errors2.New(e.Message))
+		}
+	}
+	for _, l := range [][]result.DeploymentError{dc.PolicyErrors, dc.ApiErrors} {
+		for _, e := range l {
+			dew.AddError(e.Ref, errors2.New(e.Message))
+		}
+	}
+	return len(dc.PolicyErrors) == 0 && len(dc.ApiErrors) == 0
+}
+
 func finishValidateResult(r *result.ValidateResult, targetCtx *target_context.TargetContext, dew *utils2.DeploymentErrorsAndWarnings) {
 	r.Errors = append(r.Errors, dew.GetErrorsList()...)
 	r.Warnings = append(r.Warnings, dew.GetWarningsList()...)
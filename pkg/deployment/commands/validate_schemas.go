@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"context"
+	errors2 "errors"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	utils2 "github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/schemas"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+// ValidateSchemasCommand validates all rendered objects against the bundled Kubernetes OpenAPI schemas and the CRDs
+// found in the rendered objects or in the given schema directories. It does not require a cluster connection.
+type ValidateSchemasCommand struct {
+	targetCtx  *target_context.TargetContext
+	schemaDirs []string
+
+	dew *utils2.DeploymentErrorsAndWarnings
+}
+
+func NewValidateSchemasCommand(targetCtx *target_context.TargetContext, schemaDirs []string) *ValidateSchemasCommand {
+	return &ValidateSchemasCommand{
+		targetCtx:  targetCtx,
+		schemaDirs: schemaDirs,
+		dew:        utils2.NewDeploymentErrorsAndWarnings(),
+	}
+}
+
+func (cmd *ValidateSchemasCommand) Run(ctx context.Context) (*result.ValidateResult, error) {
+	startTime := cmd.targetCtx.KluctlProject.LoadTime
+
+	cmd.dew.Init()
+
+	s, err := cmd.loadSchemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := newValidateCommandResult(cmd.targetCtx, startTime)
+	defer func() {
+		finishValidateResult(ret, cmd.targetCtx, cmd.dew)
+		ret.Ready = len(ret.Errors) == 0
+	}()
+
+	for _, d := range cmd.targetCtx.DeploymentCollection.Deployments {
+		for _, o := range d.Objects {
+			ref := o.GetK8sRef()
+			r := s.ValidateObject(o)
+			if !r.Found {
+				cmd.dew.AddWarning(ref, fmt.Errorf("no schema found for %s", o.GetK8sGVK().String()))
+				continue
+			}
+			for _, e := range r.Errors {
+				cmd.dew.AddError(ref, errors2.New(e))
+			}
+			for _, w := range r.Warnings {
+				cmd.dew.AddWarning(ref, errors2.New(w))
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+func (cmd *ValidateSchemasCommand) loadSchemas(ctx context.Context) (*schemas.Schemas, error) {
+	k := cmd.targetCtx.SharedContext.K
+
+	k8sVersion := cmd.targetCtx.SharedContext.K8sVersion
+	if k8sVersion == "" && k != nil && k.ServerVersion != nil {
+		k8sVersion = k.ServerVersion.String()
+	}
+
+	s := schemas.NewSchemas()
+	err := s.LoadBundled(k8sVersion)
+	if errors2.Is(err, schemas.ErrNoBundledSchemas) {
+		if k != nil {
+			status.Infof(ctx, "%s, using the OpenAPI schemas of the connected cluster", err.Error())
+			doc, err := k.GetOpenAPIDocument()
+			if err != nil {
+				return nil, fmt.Errorf("failed to retrieve OpenAPI schemas from cluster: %w", err)
+			}
+			err = s.AddOpenAPIDocument(doc)
+			if err != nil {
+				return nil, err
+			}
+		} else if len(cmd.schemaDirs) == 0 {
+			return nil, fmt.Errorf("%w. Use --schema-dir to pass the OpenAPI document (swagger.json) of this Kubernetes version", err)
+		} else {
+			// the user is expected to pass the OpenAPI document of this version via --schema-dir
+			status.Warningf(ctx, "%s, only using the schemas found in --schema-dir", err.Error())
+		}
+	} else if err != nil {
+		return nil, err
+	} else if k8sVersion == "" {
+		status.Warningf(ctx, "No Kubernetes version specified, using the bundled schemas of Kubernetes %s. Use --kubernetes-version or --schema-dir to validate against a newer version", s.K8sVersion)
+	}
+
+	for _, dir := range cmd.schemaDirs {
+		err = s.LoadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var all []*uo.UnstructuredObject
+	for _, d := range cmd.targetCtx.DeploymentCollection.Deployments {
+		all = append(all, d.Objects...)
+	}
+	err = s.AddCRDs(all)
+	if err != nil {
+		return nil, err
+	}
+
+	if k != nil {
+		s.Lookup = k.GetSchemaForGVK
+	}
+
+	return s, nil
+}
@@ -5,14 +5,18 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"github.com/Masterminds/semver/v3"
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/deprecations"
 	"github.com/kluctl/kluctl/v2/pkg/helm"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/validation"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"path/filepath"
 	"sync"
@@ -27,6 +31,16 @@ type DeploymentCollection struct {
 
 	Deployments []*DeploymentItem
 	mutex       sync.Mutex
+
+	// PolicyErrors and PolicyWarnings contain the policy violations found while preparing the collection. Commands
+	// must record these in their results and must not apply anything in case errors were found.
+	PolicyErrors   []result.DeploymentError
+	PolicyWarnings []result.DeploymentError
+
+	// ApiErrors and ApiWarnings contain usages of removed and deprecated APIs, based on the Kubernetes version of the
+	// target. These are handled the same way as PolicyErrors and PolicyWarnings.
+	ApiErrors   []result.DeploymentError
+	ApiWarnings []result.DeploymentError
 }
 
 func NewDeploymentCollection(ctx SharedContext, project *DeploymentProject, images *Images, inclusion *utils.Inclusion) (*DeploymentCollection, error) {
@@ -280,6 +294,127 @@ func (c *DeploymentCollection) collectResultObjects() error {
 	return nil
 }
 
+func (c *DeploymentCollection) checkPolicies() error {
+	policiesByProject := map[*DeploymentProject]*validation.Policies{}
+	hasPolicies := false
+	for _, d := range c.Deployments {
+		if _, ok := policiesByProject[d.Project]; ok {
+			continue
+		}
+		p, err := validation.NewPolicies(d.Project.GetPolicies())
+		if err != nil {
+			return err
+		}
+		policiesByProject[d.Project] = p
+		if !p.IsEmpty() {
+			hasPolicies = true
+		}
+	}
+	if !hasPolicies {
+		return nil
+	}
+
+	s := status.Start(c.ctx.Ctx, "Checking policies")
+	defer s.Failed()
+
+	c.PolicyErrors = nil
+	c.PolicyWarnings = nil
+	for _, d := range c.Deployments {
+		p := policiesByProject[d.Project]
+		for _, o := range d.Objects {
+			ref := o.GetK8sRef()
+			for _, v := range p.Check(o) {
+				e := result.DeploymentError{Ref: ref, Message: v.Message}
+				if v.Action == types.PolicyActionWarn {
+					c.PolicyWarnings = append(c.PolicyWarnings, e)
+				} else {
+					c.PolicyErrors = append(c.PolicyErrors, e)
+				}
+			}
+		}
+	}
+
+	if len(c.PolicyErrors) != 0 {
+		s.FailedWithMessagef("Found %d policy violations", len(c.PolicyErrors))
+	} else if len(c.PolicyWarnings) != 0 {
+		s.UpdateAndInfoFallbackf("Found %d policy warnings", len(c.PolicyWarnings))
+		s.Warning()
+	} else {
+		s.Success()
+	}
+	return nil
+}
+
+// GetK8sVersion returns the Kubernetes version that is assumed for the target, which is either the version passed via
+// --kubernetes-version or the version of the target cluster. Returns nil if no version is known.
+func (c *DeploymentCollection) GetK8sVersion() (*semver.Version, error) {
+	v := c.ctx.K8sVersion
+	if v == "" && c.ctx.K != nil && c.ctx.K.ServerVersion != nil {
+		v = c.ctx.K.ServerVersion.String()
+	}
+	if v == "" {
+		return nil, nil
+	}
+	ret, err := semver.NewVersion(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kubernetes version %s: %w", v, err)
+	}
+	return ret, nil
+}
+
+// getCheckApisForVersion returns the planned Kubernetes version passed via --check-apis-for-version. Returns nil if
+// no version was passed.
+func (c *DeploymentCollection) getCheckApisForVersion() (*semver.Version, error) {
+	v := c.ctx.CheckApisForVersion
+	if v == "" {
+		return nil, nil
+	}
+	ret, err := semver.NewVersion(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kubernetes version %s: %w", v, err)
+	}
+	return ret, nil
+}
+
+func (c *DeploymentCollection) checkApiDeprecations() error {
+	k8sVersion, err := c.GetK8sVersion()
+	if err != nil {
+		return err
+	}
+	plannedVersion, err := c.getCheckApisForVersion()
+	if err != nil {
+		return err
+	}
+	if k8sVersion == nil && plannedVersion == nil {
+		return nil
+	}
+
+	c.ApiErrors = nil
+	c.ApiWarnings = nil
+	for _, d := range c.Deployments {
+		for _, o := range d.Objects {
+			var f, plannedF *deprecations.Finding
+			if k8sVersion != nil {
+				f = deprecations.Check(o.GetK8sGVK(), k8sVersion)
+			}
+			if plannedVersion != nil {
+				plannedF = deprecations.Check(o.GetK8sGVK(), plannedVersion)
+			}
+
+			ref := o.GetK8sRef()
+			if f != nil && f.Removed {
+				c.ApiErrors = append(c.ApiErrors, result.DeploymentError{Ref: ref, Message: f.Message})
+			} else if plannedF != nil && (f == nil || plannedF.Removed) {
+				// the planned version is not the one we're running against, so this is only a warning
+				c.ApiWarnings = append(c.ApiWarnings, result.DeploymentError{Ref: ref, Message: plannedF.Message})
+			} else if f != nil {
+				c.ApiWarnings = append(c.ApiWarnings, result.DeploymentError{Ref: ref, Message: f.Message})
+			}
+		}
+	}
+	return nil
+}
+
 func (c *DeploymentCollection) buildNamespacedFromCRDs() map[schema.GroupKind]*bool {
 	namespacedFromCRDs := map[schema.GroupKind]*bool{}
 	for _, d := range c.Deployments {
@@ -357,6 +492,14 @@ func (c *DeploymentCollection) Prepare() error {
 	if err != nil {
 		return err
 	}
+	err = c.checkPolicies()
+	if err != nil {
+		return err
+	}
+	err = c.checkApiDeprecations()
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
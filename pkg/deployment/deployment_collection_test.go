@@ -0,0 +1,61 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckApiDeprecations(t *testing.T) {
+	buildObject := func(group string, version string, kind string) *uo.UnstructuredObject {
+		o := uo.New()
+		o.SetK8sGVKs(group, version, kind)
+		o.SetK8sName("test")
+		return o
+	}
+	ingress := buildObject("extensions", "v1beta1", "Ingress")
+	psp := buildObject("policy", "v1beta1", "PodSecurityPolicy")
+	deployment := buildObject("apps", "v1", "Deployment")
+
+	messages := func(l []result.DeploymentError) []string {
+		var ret []string
+		for _, e := range l {
+			ret = append(ret, e.Message)
+		}
+		return ret
+	}
+	check := func(k8sVersion string, checkApisForVersion string) *DeploymentCollection {
+		c := &DeploymentCollection{
+			ctx: SharedContext{
+				K8sVersion:          k8sVersion,
+				CheckApisForVersion: checkApisForVersion,
+			},
+			Deployments: []*DeploymentItem{{Objects: []*uo.UnstructuredObject{ingress, psp, deployment}}},
+		}
+		err := c.checkApiDeprecations()
+		assert.NoError(t, err)
+		return c
+	}
+
+	c := check("1.22", "")
+	assert.Equal(t, []string{"extensions/v1beta1 Ingress was removed in Kubernetes 1.22, use networking.k8s.io/v1 instead"}, messages(c.ApiErrors))
+	assert.Equal(t, []string{"policy/v1beta1 PodSecurityPolicy is deprecated since Kubernetes 1.21 and will be removed in 1.25, there is no replacement"}, messages(c.ApiWarnings))
+
+	// APIs removed in the planned version are only reported as warnings
+	c = check("1.22", "1.25.3")
+	assert.Equal(t, []string{"extensions/v1beta1 Ingress was removed in Kubernetes 1.22, use networking.k8s.io/v1 instead"}, messages(c.ApiErrors))
+	assert.Equal(t, []string{"policy/v1beta1 PodSecurityPolicy was removed in Kubernetes 1.25, there is no replacement"}, messages(c.ApiWarnings))
+
+	c = check("", "1.25")
+	assert.Empty(t, c.ApiErrors)
+	assert.Len(t, c.ApiWarnings, 2)
+
+	c = check("", "")
+	assert.Empty(t, c.ApiErrors)
+	assert.Empty(t, c.ApiWarnings)
+
+	c = &DeploymentCollection{ctx: SharedContext{CheckApisForVersion: "invalid"}}
+	assert.ErrorContains(t, c.checkApiDeprecations(), "invalid Kubernetes version invalid")
+}
@@ -378,6 +378,13 @@ func (p *DeploymentProject) GetIgnoreForDiffs(ignoreTags, ignoreLabels, ignoreAn
 	for _, e := range p.getParents() {
 		ret = append(ret, e.p.Config.IgnoreForDiff...)
 	}
+	ret = append(ret, IgnoreForDiffsFromFlags(ignoreTags, ignoreLabels, ignoreAnnotations, ignoreKluctlMetadata)...)
+	return ret
+}
+
+// IgnoreForDiffsFromFlags returns the ignoreForDiff configs that correspond to the --ignore-xxx flags
+func IgnoreForDiffsFromFlags(ignoreTags, ignoreLabels, ignoreAnnotations, ignoreKluctlMetadata bool) []types.IgnoreForDiffItemConfig {
+	var ret []types.IgnoreForDiffItemConfig
 	if ignoreTags {
 		ret = append(ret, types.IgnoreForDiffItemConfig{FieldPathRegex: []string{`metadata\.labels\["kluctl\.io/tag-.*"\]`}})
 	}
@@ -395,6 +402,32 @@ func (p *DeploymentProject) GetIgnoreForDiffs(ignoreTags, ignoreLabels, ignoreAn
 	return ret
 }
 
+func (p *DeploymentProject) GetEmbeddedDiffs() []types.EmbeddedDiffItemConfig {
+	var ret []types.EmbeddedDiffItemConfig
+	for _, e := range p.getParents() {
+		ret = append(ret, e.p.Config.EmbeddedDiff...)
+	}
+	return ret
+}
+
+// GetReadinessRules returns the readiness rules that apply to the objects of this project, which are the rules of
+// this project and of all parent projects
+func (p *DeploymentProject) GetReadinessRules() []types.ReadinessRuleConfig {
+	var ret []types.ReadinessRuleConfig
+	for _, e := range p.getParents() {
+		ret = append(ret, e.p.Config.ReadinessRules...)
+	}
+	return ret
+}
+
+func (p *DeploymentProject) GetPolicies() []types.PolicyConfig {
+	var ret []types.PolicyConfig
+	for _, e := range p.getParents() {
+		ret = append(ret, e.p.Config.Policies...)
+	}
+	return ret
+}
+
 func (p *DeploymentProject) GetConflictResolutionConfigs() []types.ConflictResolutionConfig {
 	var ret []types.ConflictResolutionConfig
 	for _, e := range p.getParents() {
@@ -0,0 +1,28 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReadinessRules(t *testing.T) {
+	buildRule := func(kind string) types.ReadinessRuleConfig {
+		return types.ReadinessRuleConfig{Kind: kind, Cel: utils.Ptr("true")}
+	}
+
+	root := &DeploymentProject{Config: types.DeploymentProjectConfig{ReadinessRules: []types.ReadinessRuleConfig{buildRule("Root")}}}
+	child1 := &DeploymentProject{Config: types.DeploymentProjectConfig{ReadinessRules: []types.ReadinessRuleConfig{buildRule("Child1")}}, parentProject: root}
+	child2 := &DeploymentProject{Config: types.DeploymentProjectConfig{ReadinessRules: []types.ReadinessRuleConfig{buildRule("Child2")}}, parentProject: root}
+	grandChild := &DeploymentProject{parentProject: child1}
+	root.includes = map[int]*DeploymentProject{0: child1, 1: child2}
+	child1.includes = map[int]*DeploymentProject{0: grandChild}
+
+	// rules from included projects must not leak into parents or siblings
+	assert.Equal(t, []types.ReadinessRuleConfig{buildRule("Root")}, root.GetReadinessRules())
+	assert.Equal(t, []types.ReadinessRuleConfig{buildRule("Child1"), buildRule("Root")}, child1.GetReadinessRules())
+	assert.Equal(t, []types.ReadinessRuleConfig{buildRule("Child2"), buildRule("Root")}, child2.GetReadinessRules())
+	assert.Equal(t, []types.ReadinessRuleConfig{buildRule("Child1"), buildRule("Root")}, grandChild.GetReadinessRules())
+}
@@ -11,15 +11,17 @@ import (
 )
 
 type SharedContext struct {
-	Ctx              context.Context
-	K                *k8s.K8sCluster
-	K8sVersion       string
-	GitRP            *repocache.GitRepoCache
-	OciRP            *repocache.OciRepoCache
-	SopsDecrypter    *decryptor.Decryptor
-	VarsLoader       *vars.VarsLoader
-	HelmAuthProvider helm_auth.HelmAuthProvider
-	OciAuthProvider  auth_provider.OciAuthProvider
+	Ctx        context.Context
+	K          *k8s.K8sCluster
+	K8sVersion string
+	// CheckApisForVersion is an additional (planned) Kubernetes version to check for deprecated and removed APIs
+	CheckApisForVersion string
+	GitRP               *repocache.GitRepoCache
+	OciRP               *repocache.OciRepoCache
+	SopsDecrypter       *decryptor.Decryptor
+	VarsLoader          *vars.VarsLoader
+	HelmAuthProvider    helm_auth.HelmAuthProvider
+	OciAuthProvider     auth_provider.OciAuthProvider
 
 	Discriminator string
 	RenderDir     string
@@ -36,8 +36,15 @@ type ApplyUtilOptions struct {
 	AbortOnError        bool
 	ReadinessTimeout    time.Duration
 	NoWait              bool
+	// ReadinessRules returns the readiness rules for the objects of the given deployment item. If nil, only the
+	// built-in readiness rules are used.
+	ReadinessRules func(d *deployment.DeploymentItem) *validation.ReadinessRules
 
 	SkipResourceVersions map[k8s2.ObjectRef]string
+
+	// OnlyRefs restricts applying to the given objects. All other objects are treated as if they were applied
+	// without changes. Hooks and deletions are skipped when set.
+	OnlyRefs map[k8s2.ObjectRef]bool
 }
 
 type ApplyUtil struct {
@@ -352,6 +359,13 @@ func (a *ApplyUtil) ApplyObject(d *deployment.DeploymentItem, x *uo.Unstructured
 	x = a.k.FixObjectForPatch(x)
 	remoteObject := a.ru.GetRemoteObject(ref)
 
+	if a.o.OnlyRefs != nil && !a.o.OnlyRefs[ref] {
+		if remoteObject != nil {
+			a.handleResult(remoteObject, hook)
+		}
+		return
+	}
+
 	if a.o.SkipResourceVersions != nil && remoteObject != nil {
 		remoteResourceVersion := remoteObject.GetK8sResourceVersion()
 		skipVersion, ok := a.o.SkipResourceVersions[ref]
@@ -487,7 +501,7 @@ func (a *ApplyUtil) handleObservedCRD(r *uo.UnstructuredObject) {
 	}
 }
 
-func (a *ApplyUtil) WaitReadiness(ref k8s2.ObjectRef, timeout time.Duration) bool {
+func (a *ApplyUtil) WaitReadiness(d *deployment.DeploymentItem, ref k8s2.ObjectRef, timeout time.Duration) bool {
 	if a.o.DryRun {
 		return true
 	}
@@ -497,6 +511,11 @@ func (a *ApplyUtil) WaitReadiness(ref k8s2.ObjectRef, timeout time.Duration) boo
 	}
 	timeoutTimer := time.NewTimer(timeout)
 
+	var readinessRules *validation.ReadinessRules
+	if a.o.ReadinessRules != nil {
+		readinessRules = a.o.ReadinessRules(d)
+	}
+
 	status.Tracef(a.ctx, "Waiting for %s to get ready", ref.String())
 
 	lastLogTime := time.Now()
@@ -527,7 +546,7 @@ func (a *ApplyUtil) WaitReadiness(ref k8s2.ObjectRef, timeout time.Duration) boo
 		} else {
 			seen = true
 
-			v := validation.ValidateObject(a.ctx, a.k, o, false, false)
+			v := validation.ValidateObject(a.ctx, a.k, o, false, false, readinessRules)
 			if v.Ready {
 				if didLog {
 					a.sctx.InfoFallbackf("Finished waiting for %s (%ds elapsed)", ref.String(), elapsed)
@@ -626,6 +645,18 @@ func (a *ApplyUtil) convertObjectRef(x types2.ObjectRefItem, refs map[k8s2.Objec
 	}
 }
 
+// restrictToOnlyRefs returns the objects to delete and to wait for when only the objects in onlyRefs are re-applied.
+// Nothing is deleted in that case and readiness is only awaited for the re-applied objects.
+func restrictToOnlyRefs(onlyRefs map[k8s2.ObjectRef]bool, toDelete map[k8s2.ObjectRef]bool, toWaitReadiness map[k8s2.ObjectRef]bool) (map[k8s2.ObjectRef]bool, map[k8s2.ObjectRef]bool) {
+	retWaitReadiness := map[k8s2.ObjectRef]bool{}
+	for ref := range toWaitReadiness {
+		if onlyRefs[ref] && !toDelete[ref] {
+			retWaitReadiness[ref] = true
+		}
+	}
+	return map[k8s2.ObjectRef]bool{}, retWaitReadiness
+}
+
 func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
 	h := HooksUtil{a: a}
 
@@ -679,6 +710,12 @@ func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
 		preHooks = h.DetermineHooks(d, []string{"pre-deploy-upgrade", "pre-deploy"})
 		postHooks = h.DetermineHooks(d, []string{"post-deploy-upgrade", "post-deploy"})
 	}
+	if a.o.OnlyRefs != nil {
+		// objects marked for deletion were already excluded from applyObjects above
+		toDelete, toWaitReadiness = restrictToOnlyRefs(a.o.OnlyRefs, toDelete, toWaitReadiness)
+		preHooks = nil
+		postHooks = nil
+	}
 
 	// +1 to ensure that we don't prematurely complete the bar (which would happen as we don't count for waiting)
 	total := len(applyObjects) + len(preHooks) + len(postHooks) + 1
@@ -724,7 +761,7 @@ func (a *ApplyUtil) applyDeploymentItem(d *deployment.DeploymentItem) {
 		}
 
 		if !a.o.NoWait {
-			a.WaitReadiness(ref, 0)
+			a.WaitReadiness(d, ref, 0)
 		}
 	}
 	if a.abortSignal.Load().(bool) {
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRestrictToOnlyRefs(t *testing.T) {
+	ref := func(name string) k8s2.ObjectRef {
+		return k8s2.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: name, Namespace: "ns"}
+	}
+
+	onlyRefs := map[k8s2.ObjectRef]bool{
+		ref("drifted"):         true,
+		ref("drifted-deleted"): true,
+	}
+	toDelete := map[k8s2.ObjectRef]bool{
+		ref("deleted"):         true,
+		ref("drifted-deleted"): true,
+	}
+	toWaitReadiness := map[k8s2.ObjectRef]bool{
+		ref("drifted"):         true,
+		ref("drifted-deleted"): true,
+		ref("other"):           true,
+	}
+
+	toDelete2, toWaitReadiness2 := restrictToOnlyRefs(onlyRefs, toDelete, toWaitReadiness)
+	assert.Empty(t, toDelete2)
+	assert.Equal(t, map[k8s2.ObjectRef]bool{ref("drifted"): true}, toWaitReadiness2)
+
+	// the passed maps must not be modified
+	assert.Len(t, toDelete, 2)
+	assert.Len(t, toWaitReadiness, 3)
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/yaml"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+var obfuscatedSecretValue = base64.StdEncoding.EncodeToString([]byte("*****"))
+
+// server-set metadata fields which must be removed before an object can be re-created
+var restoreStripMetadataFields = []string{
+	"uid",
+	"resourceVersion",
+	"generation",
+	"creationTimestamp",
+	"deletionTimestamp",
+	"deletionGracePeriodSeconds",
+	"managedFields",
+	"selfLink",
+	"ownerReferences",
+}
+
+// WriteBackupFile writes the remote state of the given objects into a multi-document yaml file. It must be called
+// before the objects are deleted. The file contains unobfuscated Secrets, so it is only readable by the current user.
+func WriteBackupFile(path string, ru *RemoteObjectUtils, refs []k8s2.ObjectRef) error {
+	var l []any
+	for _, ref := range refs {
+		o := ru.GetRemoteObject(ref)
+		if o == nil {
+			continue
+		}
+		l = append(l, o.Object)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		err := os.MkdirAll(dir, 0o700)
+		if err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write backup file: %w", err)
+	}
+	defer f.Close()
+	// the mode passed to OpenFile is only applied when the file gets created
+	err = f.Chmod(0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write backup file: %w", err)
+	}
+
+	err = yaml.WriteYamlAllStream(f, l)
+	if err != nil {
+		return fmt.Errorf("failed to write backup file: %w", err)
+	}
+	return f.Close()
+}
+
+// ReadBackupFile reads all objects from a backup file written by WriteBackupFile
+func ReadBackupFile(path string) ([]*uo.UnstructuredObject, error) {
+	l, err := yaml.ReadYamlAllFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*uo.UnstructuredObject
+	for _, x := range l {
+		m, ok := x.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unexpected document in backup file %s", path)
+		}
+		ret = append(ret, uo.FromMap(m))
+	}
+	return ret, nil
+}
+
+// GetDeletedObjectsFromResult returns the remote state of all objects that were deleted by the given command result
+func GetDeletedObjectsFromResult(cr *result.CommandResult) []*uo.UnstructuredObject {
+	var ret []*uo.UnstructuredObject
+	for _, o := range cr.Objects {
+		if o.Deleted && o.Remote != nil {
+			ret = append(ret, o.Remote)
+		}
+	}
+	return ret
+}
+
+// PrepareObjectForRestore removes the status and all server-set fields from the given object, so that it can be
+// re-created
+func PrepareObjectForRestore(o *uo.UnstructuredObject) (*uo.UnstructuredObject, error) {
+	ret := o.Clone()
+	_ = ret.RemoveNestedField("status")
+	for _, f := range restoreStripMetadataFields {
+		_ = ret.RemoveNestedField("metadata", f)
+	}
+
+	ref := ret.GetK8sRef()
+	switch {
+	case ref.Group == "" && ref.Kind == "Secret":
+		data, _, _ := ret.GetNestedStringMapCopy("data")
+		for _, v := range data {
+			if v == obfuscatedSecretValue {
+				return nil, fmt.Errorf("the secret data was obfuscated when it was stored, so it can't be restored")
+			}
+		}
+	case ref.Group == "" && ref.Kind == "Service":
+		// these get allocated by the api server and might already be in use by another service
+		_ = ret.RemoveNestedField("spec", "clusterIP")
+		_ = ret.RemoveNestedField("spec", "clusterIPs")
+	}
+	return ret, nil
+}
+
+// SortObjectsForRestore sorts objects so that namespaces and CRDs are restored before all other objects
+func SortObjectsForRestore(objects []*uo.UnstructuredObject) {
+	prio := func(o *uo.UnstructuredObject) int {
+		gk := o.GetK8sGVK().GroupKind()
+		switch gk {
+		case namespaceGK:
+			return 0
+		case crdGK:
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		pi, pj := prio(objects[i]), prio(objects[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return objects[i].GetK8sRef().Less(objects[j].GetK8sRef())
+	})
+}
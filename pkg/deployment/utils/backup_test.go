@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"context"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestPrepareObjectForRestore(t *testing.T) {
+	o := uo.FromMap(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata": map[string]any{
+			"name":              "svc",
+			"namespace":         "ns",
+			"uid":               "1234",
+			"resourceVersion":   "1",
+			"creationTimestamp": "2024-01-01T00:00:00Z",
+			"managedFields":     []any{map[string]any{"manager": "kluctl"}},
+			"labels":            map[string]any{"kluctl.io/discriminator": "d"},
+		},
+		"spec": map[string]any{
+			"clusterIP": "10.0.0.1",
+			"ports":     []any{map[string]any{"port": int64(80)}},
+		},
+		"status": map[string]any{"loadBalancer": map[string]any{}},
+	})
+
+	x, err := PrepareObjectForRestore(o)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata": map[string]any{
+			"name":      "svc",
+			"namespace": "ns",
+			"labels":    map[string]any{"kluctl.io/discriminator": "d"},
+		},
+		"spec": map[string]any{
+			"ports": []any{map[string]any{"port": int64(80)}},
+		},
+	}, x.Object)
+
+	// the original object must not be modified
+	_, ok, _ := o.GetNestedField("status")
+	assert.True(t, ok)
+
+	secret := uo.FromMap(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]any{"name": "s", "namespace": "ns"},
+		"data":       map[string]any{"a": obfuscatedSecretValue},
+	})
+	_, err = PrepareObjectForRestore(secret)
+	assert.ErrorContains(t, err, "obfuscated")
+}
+
+func TestBackupFile(t *testing.T) {
+	ns := uo.FromMap(map[string]any{"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]any{"name": "ns"}})
+	cm := uo.FromMap(map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": "cm", "namespace": "ns"}, "data": map[string]any{"a": "b"}})
+
+	ru := NewRemoteObjectsUtil(context.TODO(), NewDeploymentErrorsAndWarnings())
+	ru.remoteObjects[ns.GetK8sRef()] = ns
+	ru.remoteObjects[cm.GetK8sRef()] = cm
+
+	p := filepath.Join(t.TempDir(), "backup", "backup.yaml")
+	err := WriteBackupFile(p, ru, []k8s2.ObjectRef{cm.GetK8sRef(), ns.GetK8sRef()})
+	assert.NoError(t, err)
+
+	if runtime.GOOS != "windows" {
+		st, err := os.Stat(p)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), st.Mode().Perm())
+	}
+
+	objects, err := ReadBackupFile(p)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+
+	SortObjectsForRestore(objects)
+	assert.Equal(t, ns.Object, objects[0].Object)
+	assert.Equal(t, cm.Object, objects[1].Object)
+}
+
+func TestBackupFileOverwrite(t *testing.T) {
+	cm := uo.FromMap(map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": "cm", "namespace": "ns"}})
+
+	ru := NewRemoteObjectsUtil(context.TODO(), NewDeploymentErrorsAndWarnings())
+	ru.remoteObjects[cm.GetK8sRef()] = cm
+
+	p := filepath.Join(t.TempDir(), "backup.yaml")
+	err := os.WriteFile(p, []byte("this is a much longer content that must be truncated\n"), 0o644)
+	assert.NoError(t, err)
+
+	err = WriteBackupFile(p, ru, []k8s2.ObjectRef{cm.GetK8sRef()})
+	assert.NoError(t, err)
+
+	if runtime.GOOS != "windows" {
+		st, err := os.Stat(p)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), st.Mode().Perm())
+	}
+
+	objects, err := ReadBackupFile(p)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+	assert.Equal(t, cm.Object, objects[0].Object)
+}
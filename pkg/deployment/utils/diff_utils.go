@@ -42,7 +42,8 @@ func (u *DiffUtil) DiffDeploymentItems(deployments []*deployment.DeploymentItem)
 
 	for _, d := range deployments {
 		ignoreForDiffs := d.Project.GetIgnoreForDiffs(u.IgnoreTags, u.IgnoreLabels, u.IgnoreAnnotations, u.IgnoreKluctlMetadata)
-		u.diffObjects(d.Objects, ignoreForDiffs, &wg)
+		embeddedDiffs := d.Project.GetEmbeddedDiffs()
+		u.diffObjects(d.Objects, ignoreForDiffs, embeddedDiffs, &wg)
 	}
 	wg.Wait()
 
@@ -51,7 +52,7 @@ func (u *DiffUtil) DiffDeploymentItems(deployments []*deployment.DeploymentItem)
 
 func (u *DiffUtil) DiffObjects(objects []*uo.UnstructuredObject) {
 	var wg sync.WaitGroup
-	u.diffObjects(objects, nil, &wg)
+	u.diffObjects(objects, nil, nil, &wg)
 	wg.Wait()
 	u.sortChanges()
 }
@@ -62,7 +63,7 @@ func (u *DiffUtil) sortChanges() {
 	})
 }
 
-func (u *DiffUtil) diffObjects(objects []*uo.UnstructuredObject, ignoreForDiffs []types.IgnoreForDiffItemConfig, wg *sync.WaitGroup) {
+func (u *DiffUtil) diffObjects(objects []*uo.UnstructuredObject, ignoreForDiffs []types.IgnoreForDiffItemConfig, embeddedDiffs []types.EmbeddedDiffItemConfig, wg *sync.WaitGroup) {
 	for _, o := range objects {
 		o := o
 		ref := o.GetK8sRef()
@@ -77,15 +78,15 @@ func (u *DiffUtil) diffObjects(objects []*uo.UnstructuredObject, ignoreForDiffs
 		go func() {
 			defer wg.Done()
 			if u.Swapped {
-				u.diffObject(o, diffRef, ro, ao, ignoreForDiffs)
+				u.diffObject(o, diffRef, ro, ao, ignoreForDiffs, embeddedDiffs)
 			} else {
-				u.diffObject(o, diffRef, ao, ro, ignoreForDiffs)
+				u.diffObject(o, diffRef, ao, ro, ignoreForDiffs, embeddedDiffs)
 			}
 		}()
 	}
 }
 
-func (u *DiffUtil) diffObject(lo *uo.UnstructuredObject, diffRef k8s2.ObjectRef, ao *uo.UnstructuredObject, ro *uo.UnstructuredObject, ignoreForDiffs []types.IgnoreForDiffItemConfig) {
+func (u *DiffUtil) diffObject(lo *uo.UnstructuredObject, diffRef k8s2.ObjectRef, ao *uo.UnstructuredObject, ro *uo.UnstructuredObject, ignoreForDiffs []types.IgnoreForDiffItemConfig, embeddedDiffs []types.EmbeddedDiffItemConfig) {
 	if ao != nil && ro == nil {
 		// new?
 		return
@@ -106,7 +107,8 @@ func (u *DiffUtil) diffObject(lo *uo.UnstructuredObject, diffRef k8s2.ObjectRef,
 			u.dew.AddError(lo.GetK8sRef(), err)
 			return
 		}
-		changes, err := diff.Diff(nro, nao)
+		embeddedFieldPaths := diff.GetEmbeddedDiffFieldPaths(ao, embeddedDiffs, lo)
+		changes, err := diff.Diff(nro, nao, embeddedFieldPaths)
 		if err != nil {
 			u.dew.AddError(lo.GetK8sRef(), err)
 			return
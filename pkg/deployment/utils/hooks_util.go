@@ -112,7 +112,7 @@ func (u *HooksUtil) RunHooks(hooks []*hook) {
 		if !h.wait || u.a.o.NoWait {
 			continue
 		}
-		waitResults[ref] = u.a.WaitReadiness(ref, h.timeout)
+		waitResults[ref] = u.a.WaitReadiness(h.di, ref, h.timeout)
 	}
 
 	var deleteAfterObjects []*hook
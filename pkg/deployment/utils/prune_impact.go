@@ -0,0 +1,371 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	errors2 "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sort"
+	"strings"
+)
+
+type PruneImpactCategory string
+
+const (
+	PruneImpactPersistentVolumeDeleted PruneImpactCategory = "persistent-volume-deleted"
+	PruneImpactNamespaceNotEmpty       PruneImpactCategory = "namespace-not-empty"
+	PruneImpactCustomResourcesDeleted  PruneImpactCategory = "custom-resources-deleted"
+	PruneImpactOwnerOfObjects          PruneImpactCategory = "owner-of-objects"
+)
+
+// PruneImpact describes a dangerous side effect that pruning the referenced object would have
+type PruneImpact struct {
+	Ref      k8s2.ObjectRef
+	Category PruneImpactCategory
+	Message  string
+}
+
+var (
+	pvcGK       = schema.GroupKind{Group: "", Kind: "PersistentVolumeClaim"}
+	namespaceGK = schema.GroupKind{Group: "", Kind: "Namespace"}
+	crdGK       = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+
+	// workloadGKs contains kinds that only own objects through controller references, which are not considered
+	// dangerous to prune
+	workloadGKs = map[schema.GroupKind]bool{
+		{Group: "apps", Kind: "Deployment"}:  true,
+		{Group: "apps", Kind: "ReplicaSet"}:  true,
+		{Group: "apps", Kind: "StatefulSet"}: true,
+		{Group: "apps", Kind: "DaemonSet"}:   true,
+		{Group: "batch", Kind: "Job"}:        true,
+		{Group: "batch", Kind: "CronJob"}:    true,
+		{Group: "", Kind: "Pod"}:             true,
+	}
+
+	// ownerDependentGVKs limits the dependents search to the kinds that are usually attached to owners, so that
+	// analyzing the prune impact does not require to list all resources in all affected namespaces
+	ownerDependentGVKs = []schema.GroupVersionKind{
+		{Group: "", Version: "v1", Kind: "ConfigMap"},
+		{Group: "", Version: "v1", Kind: "Secret"},
+		{Group: "", Version: "v1", Kind: "Service"},
+		{Group: "", Version: "v1", Kind: "ServiceAccount"},
+		{Group: "", Version: "v1", Kind: "PersistentVolumeClaim"},
+		{Group: "", Version: "v1", Kind: "Pod"},
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+		{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+		{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+		{Group: "batch", Version: "v1", Kind: "Job"},
+		{Group: "batch", Version: "v1", Kind: "CronJob"},
+		{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"},
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"},
+	}
+)
+
+// AnalyzePruneImpact checks the given orphan objects for dangerous side effects of deleting them. This includes
+// PersistentVolumeClaims that are bound to volumes with the Delete reclaim policy, Namespaces that contain objects
+// not managed by kluctl, CRDs with existing custom resources and objects that are owners of other objects in the
+// cluster via non-controller ownerReferences, which would then be garbage collected.
+func AnalyzePruneImpact(ctx context.Context, k *k8s.K8sCluster, ru *RemoteObjectUtils, discriminator string, orphans []k8s2.ObjectRef) ([]PruneImpact, error) {
+	if k == nil || len(orphans) == 0 {
+		return nil, nil
+	}
+
+	s := status.Start(ctx, "Analyzing prune impact")
+	defer s.Failed()
+
+	var ret []PruneImpact
+	add := func(ref k8s2.ObjectRef, category PruneImpactCategory, msg string, args ...any) {
+		ret = append(ret, PruneImpact{Ref: ref, Category: category, Message: fmt.Sprintf(msg, args...)})
+	}
+
+	// the discovery result is shared between all orphan namespaces
+	var namespacedARs []v1.APIResource
+	for _, ref := range orphans {
+		switch ref.GroupKind() {
+		case pvcGK:
+			policy, volume, err := getPVCReclaimPolicy(k, ref)
+			if err != nil {
+				return nil, err
+			}
+			if policy == "Delete" {
+				add(ref, PruneImpactPersistentVolumeDeleted, "the volume %s has the reclaim policy Delete, its data will be lost", volume)
+			}
+		case namespaceGK:
+			if namespacedARs == nil {
+				var err error
+				namespacedARs, err = getNamespacedListableAPIResources(k)
+				if err != nil {
+					return nil, err
+				}
+			}
+			foreign, err := listForeignObjectsInNamespace(k, namespacedARs, ref.Name, discriminator)
+			if err != nil {
+				return nil, err
+			}
+			if len(foreign) != 0 {
+				add(ref, PruneImpactNamespaceNotEmpty, "the namespace contains %d objects not managed by this deployment, e.g. %s", len(foreign), foreign[0].String())
+			}
+		case crdGK:
+			cnt, err := countCustomResources(k, ref)
+			if err != nil {
+				return nil, err
+			}
+			if cnt != 0 {
+				add(ref, PruneImpactCustomResourcesDeleted, "%d custom resources exist and will be deleted as well", cnt)
+			}
+		}
+	}
+
+	dependents, err := findOwnerDependents(k, ru, orphans)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range orphans {
+		if l, ok := dependents[ref]; ok {
+			add(ref, PruneImpactOwnerOfObjects, "%d objects depend on this object via ownerReferences and will be garbage collected, e.g. %s", len(l), l[0].String())
+		}
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Ref.Less(ret[j].Ref)
+	})
+
+	if len(ret) != 0 {
+		s.UpdateAndInfoFallbackf("Found %d dangerous prune impacts", len(ret))
+		s.Warning()
+	} else {
+		s.Success()
+	}
+	return ret, nil
+}
+
+// findOwnerDependents queries the cluster for all objects that reference one of the given orphans via ownerReferences,
+// either directly or transitively. Only namespaced orphans that are not workloads are considered as owners and only
+// the kinds from ownerDependentGVKs in the namespaces of these orphans are searched for dependents.
+func findOwnerDependents(k *k8s.K8sCluster, ru *RemoteObjectUtils, orphans []k8s2.ObjectRef) (map[k8s2.ObjectRef][]k8s2.ObjectRef, error) {
+	orphanUids := map[string]k8s2.ObjectRef{}
+	namespaces := map[string]bool{}
+	for _, ref := range orphans {
+		o := ru.GetRemoteObject(ref)
+		if o == nil || o.GetK8sUid() == "" {
+			continue
+		}
+		// all orphans are passed to buildOwnerDependents, so that orphans are never reported as dependents
+		orphanUids[o.GetK8sUid()] = ref
+		if ref.Namespace != "" && !workloadGKs[ref.GroupKind()] {
+			namespaces[ref.Namespace] = true
+		}
+	}
+	if len(namespaces) == 0 {
+		return nil, nil
+	}
+
+	var searchNamespaces []string
+	for ns := range namespaces {
+		searchNamespaces = append(searchNamespaces, ns)
+	}
+	sort.Strings(searchNamespaces)
+
+	var objects []*uo.UnstructuredObject
+	for _, gvk := range ownerDependentGVKs {
+		for _, ns := range searchNamespaces {
+			l, _, err := k.ListMetadata(gvk, ns, nil)
+			if err != nil {
+				if errors2.IsNotFound(err) || errors2.IsForbidden(err) || errors2.IsMethodNotSupported(err) {
+					continue
+				}
+				return nil, err
+			}
+			for _, o := range l {
+				o.SetK8sGVK(gvk)
+				objects = append(objects, o)
+			}
+		}
+	}
+
+	ret := buildOwnerDependents(orphanUids, objects)
+	for ref := range ret {
+		if ref.Namespace == "" || workloadGKs[ref.GroupKind()] {
+			delete(ret, ref)
+		}
+	}
+	return ret, nil
+}
+
+// buildOwnerDependents returns all objects that are direct or transitive dependents of the given orphans, which are
+// passed as a map from uid to ref. Dependents that are orphans themselves are not included, as these are pruned anyway.
+// Controller references are ignored, as the garbage collection of such objects is the expected behaviour.
+func buildOwnerDependents(orphanUids map[string]k8s2.ObjectRef, objects []*uo.UnstructuredObject) map[k8s2.ObjectRef][]k8s2.ObjectRef {
+	children := map[string][]*uo.UnstructuredObject{}
+	for _, o := range objects {
+		for _, or := range o.GetK8sOwnerReferences() {
+			if controller, _, _ := or.GetNestedBool("controller"); controller {
+				continue
+			}
+			uid, _, _ := or.GetNestedString("uid")
+			children[uid] = append(children[uid], o)
+		}
+	}
+
+	ret := map[k8s2.ObjectRef][]k8s2.ObjectRef{}
+	for uid, ref := range orphanUids {
+		visited := map[string]bool{uid: true}
+		queue := []string{uid}
+		for len(queue) != 0 {
+			u := queue[0]
+			queue = queue[1:]
+			for _, c := range children[u] {
+				cuid := c.GetK8sUid()
+				if visited[cuid] {
+					continue
+				}
+				visited[cuid] = true
+				if _, ok := orphanUids[cuid]; ok {
+					continue
+				}
+				ret[ref] = append(ret[ref], c.GetK8sRef())
+				queue = append(queue, cuid)
+			}
+		}
+		sort.Slice(ret[ref], func(i, j int) bool {
+			return ret[ref][i].Less(ret[ref][j])
+		})
+	}
+	return ret
+}
+
+func getPVCReclaimPolicy(k *k8s.K8sCluster, ref k8s2.ObjectRef) (string, string, error) {
+	pvc, _, err := k.GetSingleObject(ref)
+	if err != nil {
+		if errors2.IsNotFound(err) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+
+	volumeName, _, _ := pvc.GetNestedString("spec", "volumeName")
+	if volumeName != "" {
+		pv, _, err := k.GetSingleObject(k8s2.NewObjectRef("", "v1", "PersistentVolume", volumeName, ""))
+		if err == nil {
+			policy, _, _ := pv.GetNestedString("spec", "persistentVolumeReclaimPolicy")
+			return policy, volumeName, nil
+		} else if !errors2.IsNotFound(err) {
+			return "", "", err
+		}
+	}
+
+	// not bound yet, so the storage class decides about the reclaim policy
+	scName, _, _ := pvc.GetNestedString("spec", "storageClassName")
+	if scName == "" {
+		return "", "", nil
+	}
+	sc, _, err := k.GetSingleObject(k8s2.NewObjectRef("storage.k8s.io", "v1", "StorageClass", scName, ""))
+	if err != nil {
+		if errors2.IsNotFound(err) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+	policy, ok, _ := sc.GetNestedString("reclaimPolicy")
+	if !ok {
+		policy = "Delete"
+	}
+	return policy, fmt.Sprintf("provisioned by storage class %s", scName), nil
+}
+
+func getNamespacedListableAPIResources(k *k8s.K8sCluster) ([]v1.APIResource, error) {
+	return k.GetFilteredPreferredAPIResources(func(ar *v1.APIResource) bool {
+		if !ar.Namespaced || utils.FindStrInSlice(ar.Verbs, "list") == -1 {
+			return false
+		}
+		switch {
+		case ar.Kind == "Event", ar.Kind == "Endpoints", ar.Group == "metrics.k8s.io":
+			return false
+		}
+		return true
+	})
+}
+
+// listForeignObjectsInNamespace lists all objects of the given API resources in the given namespace that are not
+// managed by kluctl with the given discriminator. Objects that are created automatically by Kubernetes or by
+// controllers are ignored.
+func listForeignObjectsInNamespace(k *k8s.K8sCluster, ars []v1.APIResource, namespace string, discriminator string) ([]k8s2.ObjectRef, error) {
+	var ret []k8s2.ObjectRef
+	for _, ar := range ars {
+		gvk := schema.GroupVersionKind{Group: ar.Group, Version: ar.Version, Kind: ar.Kind}
+		l, _, err := k.ListMetadata(gvk, namespace, nil)
+		if err != nil {
+			if errors2.IsNotFound(err) || errors2.IsForbidden(err) || errors2.IsMethodNotSupported(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, o := range l {
+			o.SetK8sGVK(gvk)
+			if isAutoCreatedObject(o) {
+				continue
+			}
+			if d := o.GetK8sLabel("kluctl.io/discriminator"); d != nil && *d == discriminator {
+				continue
+			}
+			ret = append(ret, o.GetK8sRef())
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Less(ret[j])
+	})
+	return ret, nil
+}
+
+func isAutoCreatedObject(o *uo.UnstructuredObject) bool {
+	if len(o.GetK8sOwnerReferences()) != 0 {
+		return true
+	}
+	ref := o.GetK8sRef()
+	switch {
+	case ref.Kind == "ServiceAccount" && ref.Name == "default":
+		return true
+	case ref.Kind == "ConfigMap" && ref.Name == "kube-root-ca.crt":
+		return true
+	case ref.Kind == "Secret" && strings.HasPrefix(ref.Name, "default-token-"):
+		return true
+	}
+	return false
+}
+
+func countCustomResources(k *k8s.K8sCluster, ref k8s2.ObjectRef) (int, error) {
+	crd, _, err := k.GetSingleObject(ref)
+	if err != nil {
+		if errors2.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	group, _, _ := crd.GetNestedString("spec", "group")
+	kind, _, _ := crd.GetNestedString("spec", "names", "kind")
+	versions, _, _ := crd.GetNestedObjectList("spec", "versions")
+	for _, v := range versions {
+		storage, _, _ := v.GetNestedBool("storage")
+		if !storage {
+			continue
+		}
+		name, _, _ := v.GetNestedString("name")
+		l, _, err := k.ListMetadata(schema.GroupVersionKind{Group: group, Version: name, Kind: kind}, "", nil)
+		if err != nil {
+			if errors2.IsNotFound(err) {
+				return 0, nil
+			}
+			return 0, err
+		}
+		return len(l), nil
+	}
+	return 0, nil
+}
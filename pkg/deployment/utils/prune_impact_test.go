@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildOwnedObject(kind string, name string, uid string, ownerUids ...string) *uo.UnstructuredObject {
+	o := uo.New()
+	o.SetK8sGVKs("apps", "v1", kind)
+	o.SetK8sName(name)
+	o.SetK8sNamespace("default")
+	_ = o.SetNestedField(uid, "metadata", "uid")
+	var ors []any
+	for _, u := range ownerUids {
+		// a "controller:" prefix marks the owner as controller, like the garbage collected children of workloads
+		controller := strings.HasPrefix(u, "controller:")
+		ors = append(ors, map[string]any{"apiVersion": "apps/v1", "kind": "Owner", "name": "owner", "uid": strings.TrimPrefix(u, "controller:"), "controller": controller})
+	}
+	if len(ors) != 0 {
+		_ = o.SetNestedField(ors, "metadata", "ownerReferences")
+	}
+	return o
+}
+
+func TestBuildOwnerDependents(t *testing.T) {
+	ownerRef := k8s2.NewObjectRef("apps", "v1", "Owner", "o", "default")
+	orphanCmRef := k8s2.NewObjectRef("apps", "v1", "ConfigMap", "orphan", "default")
+	deploymentRef := k8s2.NewObjectRef("apps", "v1", "Deployment", "d", "default")
+	unrelatedRef := k8s2.NewObjectRef("apps", "v1", "Owner", "unrelated", "default")
+
+	objects := []*uo.UnstructuredObject{
+		buildOwnedObject("Owner", "o", "o"),
+		buildOwnedObject("ConfigMap", "cm1", "cm1", "o"),
+		buildOwnedObject("Secret", "s1", "s1", "o"),
+		buildOwnedObject("Secret", "s2", "s2", "cm1"),
+		// cycles must not lead to endless loops
+		buildOwnedObject("Secret", "s3", "s3", "s1", "s4"),
+		buildOwnedObject("Secret", "s4", "s4", "s3"),
+		// dependents that are orphans themselves are pruned anyway
+		buildOwnedObject("ConfigMap", "orphan", "orphan", "o"),
+		buildOwnedObject("Secret", "s5", "s5", "orphan"),
+		// controller references are expected to be garbage collected
+		buildOwnedObject("Deployment", "d", "d"),
+		buildOwnedObject("ReplicaSet", "rs1", "rs1", "controller:d"),
+		buildOwnedObject("Pod", "pod1", "pod1", "controller:rs1"),
+		buildOwnedObject("Secret", "s6", "s6", "controller:o"),
+		buildOwnedObject("Owner", "unrelated", "unrelated"),
+		buildOwnedObject("ConfigMap", "cm2", "cm2", "other"),
+	}
+
+	dependents := buildOwnerDependents(map[string]k8s2.ObjectRef{
+		"o":         ownerRef,
+		"orphan":    orphanCmRef,
+		"d":         deploymentRef,
+		"unrelated": unrelatedRef,
+	}, objects)
+
+	buildRef := func(kind string, name string) k8s2.ObjectRef {
+		return k8s2.NewObjectRef("apps", "v1", kind, name, "default")
+	}
+	assert.Equal(t, map[k8s2.ObjectRef][]k8s2.ObjectRef{
+		ownerRef: {
+			buildRef("ConfigMap", "cm1"),
+			buildRef("Secret", "s1"),
+			buildRef("Secret", "s2"),
+			buildRef("Secret", "s3"),
+			buildRef("Secret", "s4"),
+		},
+		orphanCmRef: {
+			buildRef("Secret", "s5"),
+		},
+	}, dependents)
+}
@@ -0,0 +1,225 @@
+package deploywindows
+
+import (
+	"fmt"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/robfig/cron/v3"
+	"time"
+
+	// ensure time zones are available in minimal container images
+	_ "time/tzdata"
+)
+
+// Status describes whether deployments are currently allowed
+type Status struct {
+	Closed  bool
+	Reason  string
+	Message string
+
+	// NextCheck is the earliest time at which the deploy window might open again. It is nil if the window is open
+	// or if the time is unknown, e.g. because a DeployFreeze without end is active.
+	NextCheck *time.Time
+}
+
+type window struct {
+	schedule string
+	start    time.Time
+	end      time.Time
+}
+
+// Evaluate checks the given deploy windows and freezes at the given time. Deny windows and active freezes take
+// precedence over allow windows. If no allow windows are specified, deployments are allowed at any time that is not
+// denied. missingFreezes are the names of referenced freezes that do not exist. These are treated as active freezes
+// without end, as a deleted or misspelled freeze must not silently allow deployments.
+func Evaluate(windows *kluctlv1.DeployWindows, freezes []kluctlv1.DeployFreeze, missingFreezes []string, now time.Time) (*Status, error) {
+	if windows == nil {
+		return &Status{}, nil
+	}
+
+	loc, err := loadLocation(windows.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	now = now.In(loc)
+
+	var closedReason string
+	var closedMessages []string
+	var nextCheck *time.Time
+	unknownNextCheck := false
+
+	addBlocker := func(reason string, msg string, until *time.Time) {
+		if closedReason == "" || reason == kluctlv1.DeployFreezeActiveReason {
+			closedReason = reason
+		}
+		closedMessages = append(closedMessages, msg)
+		if until == nil {
+			unknownNextCheck = true
+		} else if nextCheck == nil || until.After(*nextCheck) {
+			nextCheck = until
+		}
+	}
+
+	for _, f := range freezes {
+		active, until, err := isFreezeActive(&f, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DeployFreeze %s: %w", f.Name, err)
+		}
+		if !active {
+			continue
+		}
+		msg := fmt.Sprintf("deploy freeze %s is active", f.Name)
+		if f.Spec.Reason != "" {
+			msg += fmt.Sprintf(": %s", f.Spec.Reason)
+		}
+		addBlocker(kluctlv1.DeployFreezeActiveReason, msg, until)
+	}
+	for _, name := range missingFreezes {
+		addBlocker(kluctlv1.DeployFreezeActiveReason, fmt.Sprintf("deploy freeze %s not found", name), nil)
+	}
+
+	for _, d := range windows.Deny {
+		w, err := findOpenWindow(d, now)
+		if err != nil {
+			return nil, err
+		}
+		if w != nil {
+			addBlocker(kluctlv1.DeployWindowClosedReason, fmt.Sprintf("deny window '%s' is active until %s", w.schedule, w.end.Format(time.RFC3339)), &w.end)
+		}
+	}
+
+	if len(windows.Allow) != 0 {
+		var nextAllow *time.Time
+		open := false
+		for _, a := range windows.Allow {
+			w, err := findOpenWindow(a, now)
+			if err != nil {
+				return nil, err
+			}
+			if w != nil {
+				open = true
+				break
+			}
+			next, err := nextWindowStart(a, now)
+			if err != nil {
+				return nil, err
+			}
+			if !next.IsZero() && (nextAllow == nil || next.Before(*nextAllow)) {
+				nextAllow = &next
+			}
+		}
+		if !open {
+			msg := "outside of allowed deploy windows"
+			if nextAllow != nil {
+				msg += fmt.Sprintf(", next window opens at %s", nextAllow.Format(time.RFC3339))
+			}
+			addBlocker(kluctlv1.DeployWindowClosedReason, msg, nextAllow)
+		}
+	}
+
+	if closedReason == "" {
+		return &Status{}, nil
+	}
+
+	s := &Status{
+		Closed: true,
+		Reason: closedReason,
+	}
+	for i, m := range closedMessages {
+		if i != 0 {
+			s.Message += "; "
+		}
+		s.Message += m
+	}
+	if !unknownNextCheck {
+		s.NextCheck = nextCheck
+	}
+	return s, nil
+}
+
+func loadLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %s: %w", tz, err)
+	}
+	return loc, nil
+}
+
+func parseSchedule(s string) (cron.Schedule, error) {
+	sched, err := cron.ParseStandard(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule '%s': %w", s, err)
+	}
+	return sched, nil
+}
+
+// findOpenWindow returns the window that is open at the given time, or nil if the window is closed. now must already
+// be in the correct location.
+func findOpenWindow(w kluctlv1.DeployWindow, now time.Time) (*window, error) {
+	sched, err := parseSchedule(w.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	if w.Duration.Duration <= 0 {
+		return nil, nil
+	}
+
+	// cron.Schedule.Next returns the first activation strictly after the given time, so a window is open if
+	// the first activation after now-duration is not after now
+	start := sched.Next(now.Add(-w.Duration.Duration))
+	if start.IsZero() || start.After(now) {
+		return nil, nil
+	}
+	return &window{
+		schedule: w.Schedule,
+		start:    start,
+		end:      start.Add(w.Duration.Duration),
+	}, nil
+}
+
+func nextWindowStart(w kluctlv1.DeployWindow, now time.Time) (time.Time, error) {
+	sched, err := parseSchedule(w.Schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return sched.Next(now), nil
+}
+
+// isFreezeActive returns true if the freeze is active at the given time, together with the time at which it ends
+// (if known)
+func isFreezeActive(f *kluctlv1.DeployFreeze, now time.Time) (bool, *time.Time, error) {
+	if f.Spec.Start != nil && now.Before(f.Spec.Start.Time) {
+		return false, nil, nil
+	}
+	if f.Spec.End != nil && !now.Before(f.Spec.End.Time) {
+		return false, nil, nil
+	}
+	var end *time.Time
+	if f.Spec.End != nil {
+		end = &f.Spec.End.Time
+	}
+	if len(f.Spec.Windows) == 0 {
+		return true, end, nil
+	}
+
+	loc, err := loadLocation(f.Spec.TimeZone)
+	if err != nil {
+		return false, nil, err
+	}
+	for _, fw := range f.Spec.Windows {
+		w, err := findOpenWindow(fw, now.In(loc))
+		if err != nil {
+			return false, nil, err
+		}
+		if w == nil {
+			continue
+		}
+		if end == nil || w.end.Before(*end) {
+			end = &w.end
+		}
+		return true, end, nil
+	}
+	return false, nil, nil
+}
@@ -0,0 +1,181 @@
+package deploywindows
+
+import (
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func mustParse(t *testing.T, s string) time.Time {
+	x, err := time.Parse(time.RFC3339, s)
+	assert.NoError(t, err)
+	return x
+}
+
+func buildWindow(schedule string, d time.Duration) kluctlv1.DeployWindow {
+	return kluctlv1.DeployWindow{
+		Schedule: schedule,
+		Duration: metav1.Duration{Duration: d},
+	}
+}
+
+func TestEvaluateNoWindows(t *testing.T) {
+	s, err := Evaluate(nil, nil, nil, time.Now())
+	assert.NoError(t, err)
+	assert.False(t, s.Closed)
+
+	s, err = Evaluate(&kluctlv1.DeployWindows{}, nil, nil, time.Now())
+	assert.NoError(t, err)
+	assert.False(t, s.Closed)
+}
+
+func TestEvaluateAllow(t *testing.T) {
+	// weekdays from 08:00 to 16:00
+	w := &kluctlv1.DeployWindows{
+		Allow: []kluctlv1.DeployWindow{buildWindow("0 8 * * 1-5", 8*time.Hour)},
+	}
+
+	// Monday
+	s, err := Evaluate(w, nil, nil, mustParse(t, "2023-06-05T08:00:00Z"))
+	assert.NoError(t, err)
+	assert.False(t, s.Closed)
+
+	s, err = Evaluate(w, nil, nil, mustParse(t, "2023-06-05T15:59:00Z"))
+	assert.NoError(t, err)
+	assert.False(t, s.Closed)
+
+	s, err = Evaluate(w, nil, nil, mustParse(t, "2023-06-05T16:00:00Z"))
+	assert.NoError(t, err)
+	assert.True(t, s.Closed)
+	assert.Equal(t, kluctlv1.DeployWindowClosedReason, s.Reason)
+	assert.Equal(t, mustParse(t, "2023-06-06T08:00:00Z"), s.NextCheck.UTC())
+
+	// Saturday
+	s, err = Evaluate(w, nil, nil, mustParse(t, "2023-06-10T10:00:00Z"))
+	assert.NoError(t, err)
+	assert.True(t, s.Closed)
+	assert.Equal(t, mustParse(t, "2023-06-12T08:00:00Z"), s.NextCheck.UTC())
+}
+
+func TestEvaluateTimeZone(t *testing.T) {
+	w := &kluctlv1.DeployWindows{
+		TimeZone: "Europe/Berlin",
+		Allow:    []kluctlv1.DeployWindow{buildWindow("0 8 * * *", 8*time.Hour)},
+	}
+
+	// 07:00 UTC is 09:00 in Berlin (summer time)
+	s, err := Evaluate(w, nil, nil, mustParse(t, "2023-06-05T07:00:00Z"))
+	assert.NoError(t, err)
+	assert.False(t, s.Closed)
+
+	s, err = Evaluate(w, nil, nil, mustParse(t, "2023-06-05T05:00:00Z"))
+	assert.NoError(t, err)
+	assert.True(t, s.Closed)
+	assert.Equal(t, mustParse(t, "2023-06-05T06:00:00Z"), s.NextCheck.UTC())
+
+	_, err = Evaluate(&kluctlv1.DeployWindows{TimeZone: "Invalid/Zone"}, nil, nil, time.Now())
+	assert.ErrorContains(t, err, "invalid time zone")
+}
+
+func TestEvaluateDeny(t *testing.T) {
+	w := &kluctlv1.DeployWindows{
+		Allow: []kluctlv1.DeployWindow{buildWindow("0 8 * * *", 8*time.Hour)},
+		// no deployments on Friday afternoon
+		Deny: []kluctlv1.DeployWindow{buildWindow("0 12 * * 5", 12*time.Hour)},
+	}
+
+	// Friday
+	s, err := Evaluate(w, nil, nil, mustParse(t, "2023-06-09T10:00:00Z"))
+	assert.NoError(t, err)
+	assert.False(t, s.Closed)
+
+	s, err = Evaluate(w, nil, nil, mustParse(t, "2023-06-09T13:00:00Z"))
+	assert.NoError(t, err)
+	assert.True(t, s.Closed)
+	assert.Contains(t, s.Message, "deny window '0 12 * * 5' is active")
+	assert.Equal(t, mustParse(t, "2023-06-10T00:00:00Z"), s.NextCheck.UTC())
+}
+
+func TestEvaluateFreeze(t *testing.T) {
+	start := metav1.NewTime(mustParse(t, "2023-12-20T00:00:00Z"))
+	end := metav1.NewTime(mustParse(t, "2024-01-02T00:00:00Z"))
+	freezes := []kluctlv1.DeployFreeze{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "xmas"},
+			Spec: kluctlv1.DeployFreezeSpec{
+				Reason: "holidays",
+				Start:  &start,
+				End:    &end,
+			},
+		},
+	}
+	w := &kluctlv1.DeployWindows{
+		FreezeRefs: []string{"xmas"},
+	}
+
+	s, err := Evaluate(w, freezes, nil, mustParse(t, "2023-12-19T23:59:00Z"))
+	assert.NoError(t, err)
+	assert.False(t, s.Closed)
+
+	s, err = Evaluate(w, freezes, nil, mustParse(t, "2023-12-24T12:00:00Z"))
+	assert.NoError(t, err)
+	assert.True(t, s.Closed)
+	assert.Equal(t, kluctlv1.DeployFreezeActiveReason, s.Reason)
+	assert.Equal(t, "deploy freeze xmas is active: holidays", s.Message)
+	assert.Equal(t, end.Time, *s.NextCheck)
+
+	s, err = Evaluate(w, freezes, nil, mustParse(t, "2024-01-02T00:00:00Z"))
+	assert.NoError(t, err)
+	assert.False(t, s.Closed)
+
+	// without end, the next check is unknown
+	freezes[0].Spec.End = nil
+	s, err = Evaluate(w, freezes, nil, mustParse(t, "2024-06-01T00:00:00Z"))
+	assert.NoError(t, err)
+	assert.True(t, s.Closed)
+	assert.Nil(t, s.NextCheck)
+}
+
+func TestEvaluateMissingFreeze(t *testing.T) {
+	w := &kluctlv1.DeployWindows{
+		FreezeRefs: []string{"missing"},
+	}
+
+	s, err := Evaluate(w, nil, []string{"missing"}, mustParse(t, "2023-06-05T08:00:00Z"))
+	assert.NoError(t, err)
+	assert.True(t, s.Closed)
+	assert.Equal(t, kluctlv1.DeployFreezeActiveReason, s.Reason)
+	assert.Equal(t, "deploy freeze missing not found", s.Message)
+	assert.Nil(t, s.NextCheck)
+
+	// a missing freeze also closes an otherwise open allow window
+	w.Allow = []kluctlv1.DeployWindow{buildWindow("0 8 * * *", 8*time.Hour)}
+	s, err = Evaluate(w, nil, []string{"missing"}, mustParse(t, "2023-06-05T09:00:00Z"))
+	assert.NoError(t, err)
+	assert.True(t, s.Closed)
+}
+
+func TestEvaluateFreezeWindows(t *testing.T) {
+	freezes := []kluctlv1.DeployFreeze{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "weekend"},
+			Spec: kluctlv1.DeployFreezeSpec{
+				Windows: []kluctlv1.DeployWindow{buildWindow("0 0 * * 6", 48*time.Hour)},
+			},
+		},
+	}
+	w := &kluctlv1.DeployWindows{
+		FreezeRefs: []string{"weekend"},
+	}
+
+	s, err := Evaluate(w, freezes, nil, mustParse(t, "2023-06-09T23:00:00Z"))
+	assert.NoError(t, err)
+	assert.False(t, s.Closed)
+
+	s, err = Evaluate(w, freezes, nil, mustParse(t, "2023-06-11T10:00:00Z"))
+	assert.NoError(t, err)
+	assert.True(t, s.Closed)
+	assert.Equal(t, mustParse(t, "2023-06-12T00:00:00Z"), s.NextCheck.UTC())
+}
@@ -0,0 +1,63 @@
+package deprecations
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ApiDeprecation describes an API version that is deprecated and/or removed in a given Kubernetes version. If Kind is
+// empty, the deprecation applies to all kinds of the group version.
+type ApiDeprecation struct {
+	Group        string
+	Version      string
+	Kind         string
+	DeprecatedIn string
+	RemovedIn    string
+	Replacement  string
+}
+
+type Finding struct {
+	Deprecation ApiDeprecation
+	Removed     bool
+	Message     string
+}
+
+// Check returns a finding if the given GroupVersionKind is deprecated or removed in the given Kubernetes version
+func Check(gvk schema.GroupVersionKind, k8sVersion *semver.Version) *Finding {
+	v := minorVersion(k8sVersion)
+	for _, d := range apiDeprecations {
+		if d.Group != gvk.Group || d.Version != gvk.Version || (d.Kind != "" && d.Kind != gvk.Kind) {
+			continue
+		}
+
+		f := &Finding{
+			Deprecation: d,
+		}
+		apiVersion := gvk.GroupVersion().String()
+		if d.RemovedIn != "" && !v.LessThan(semver.MustParse(d.RemovedIn)) {
+			f.Removed = true
+			f.Message = fmt.Sprintf("%s %s was removed in Kubernetes %s", apiVersion, gvk.Kind, d.RemovedIn)
+		} else if d.DeprecatedIn != "" && !v.LessThan(semver.MustParse(d.DeprecatedIn)) {
+			f.Message = fmt.Sprintf("%s %s is deprecated since Kubernetes %s", apiVersion, gvk.Kind, d.DeprecatedIn)
+			if d.RemovedIn != "" {
+				f.Message += fmt.Sprintf(" and will be removed in %s", d.RemovedIn)
+			}
+		} else {
+			return nil
+		}
+
+		if d.Replacement != "" {
+			f.Message += fmt.Sprintf(", use %s instead", d.Replacement)
+		} else {
+			f.Message += ", there is no replacement"
+		}
+		return f
+	}
+	return nil
+}
+
+// minorVersion strips the patch version and pre-release information, e.g. "v1.25.3-gke.1" becomes "1.25"
+func minorVersion(v *semver.Version) *semver.Version {
+	return semver.New(v.Major(), v.Minor(), 0, "", "")
+}
@@ -0,0 +1,37 @@
+package deprecations
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	psp := schema.GroupVersionKind{Group: "policy", Version: "v1beta1", Kind: "PodSecurityPolicy"}
+	cronJob := schema.GroupVersionKind{Group: "batch", Version: "v1beta1", Kind: "CronJob"}
+	role := schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Kind: "Role"}
+	deployment := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+
+	assert.Nil(t, Check(psp, semver.MustParse("1.20.5")))
+
+	f := Check(psp, semver.MustParse("1.24.3-gke.100"))
+	assert.NotNil(t, f)
+	assert.False(t, f.Removed)
+	assert.Equal(t, "policy/v1beta1 PodSecurityPolicy is deprecated since Kubernetes 1.21 and will be removed in 1.25, there is no replacement", f.Message)
+
+	f = Check(psp, semver.MustParse("1.25.0"))
+	assert.True(t, f.Removed)
+	assert.Equal(t, "policy/v1beta1 PodSecurityPolicy was removed in Kubernetes 1.25, there is no replacement", f.Message)
+
+	f = Check(cronJob, semver.MustParse("v1.31"))
+	assert.True(t, f.Removed)
+	assert.Equal(t, "batch/v1beta1 CronJob was removed in Kubernetes 1.25, use batch/v1 instead", f.Message)
+
+	// deprecations without a kind apply to the whole group version
+	f = Check(role, semver.MustParse("1.18"))
+	assert.False(t, f.Removed)
+	assert.Equal(t, "rbac.authorization.k8s.io/v1beta1 Role is deprecated since Kubernetes 1.17 and will be removed in 1.22, use rbac.authorization.k8s.io/v1 instead", f.Message)
+
+	assert.Nil(t, Check(deployment, semver.MustParse("1.31")))
+}
@@ -39,19 +39,37 @@ func convertPath(path []string, o interface{}) (string, error) {
 	return ret.ToJsonPath(), nil
 }
 
-func Diff(oldObject *uo.UnstructuredObject, newObject *uo.UnstructuredObject) ([]result.Change, error) {
+// Diff compares both objects and returns the list of changes. embeddedFieldPaths is a list of json paths pointing to
+// string fields that might contain embedded YAML/JSON documents, see diffEmbeddedChanges for details.
+func Diff(oldObject *uo.UnstructuredObject, newObject *uo.UnstructuredObject, embeddedFieldPaths []string) ([]result.Change, error) {
+	changes, err := diffValues(oldObject.Object, newObject.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err = diffEmbeddedChanges(oldObject, newObject, changes, embeddedFieldPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	// The result of the above diff call is not stable
+	stableSortChanges(changes)
+	return changes, nil
+}
+
+func diffValues(oldValue any, newValue any) ([]result.Change, error) {
 	differ, err := diff2.NewDiffer(diff2.AllowTypeMismatch(true))
 	if err != nil {
 		return nil, err
 	}
-	cl, err := differ.Diff(oldObject.Object, newObject.Object)
+	cl, err := differ.Diff(oldValue, newValue)
 	if err != nil {
 		return nil, err
 	}
 
 	var changes []result.Change
 	for _, c := range cl {
-		c2, err := convertChange(c, oldObject, newObject)
+		c2, err := convertChange(c, oldValue, newValue)
 		if err != nil {
 			return nil, err
 		}
@@ -61,16 +79,13 @@ func Diff(oldObject *uo.UnstructuredObject, newObject *uo.UnstructuredObject) ([
 		}
 		changes = append(changes, *c2)
 	}
-
-	// The result of the above diff call is not stable
-	stableSortChanges(changes)
 	return changes, nil
 }
 
-func convertChange(c diff2.Change, oldObject *uo.UnstructuredObject, newObject *uo.UnstructuredObject) (*result.Change, error) {
+func convertChange(c diff2.Change, oldValue any, newValue any) (*result.Change, error) {
 	switch c.Type {
 	case "create":
-		p, err := convertPath(c.Path, newObject.Object)
+		p, err := convertPath(c.Path, newValue)
 		if err != nil {
 			return nil, err
 		}
@@ -84,7 +99,7 @@ func convertChange(c diff2.Change, oldObject *uo.UnstructuredObject, newObject *
 			NewValue: &apiextensionsv1.JSON{Raw: []byte(jto)},
 		}, nil
 	case "delete":
-		p, err := convertPath(c.Path, oldObject.Object)
+		p, err := convertPath(c.Path, oldValue)
 		if err != nil {
 			return nil, err
 		}
@@ -98,7 +113,7 @@ func convertChange(c diff2.Change, oldObject *uo.UnstructuredObject, newObject *
 			OldValue: &apiextensionsv1.JSON{Raw: []byte(jfrom)},
 		}, nil
 	case "update":
-		p, err := convertPath(c.Path, newObject.Object)
+		p, err := convertPath(c.Path, newValue)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,145 @@
+package diff
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"regexp"
+)
+
+var configMapGk = schema.GroupKind{Group: "", Kind: "ConfigMap"}
+
+var embeddedDiffFieldAnnotationRegex = regexp.MustCompile(`^kluctl.io/diff-embedded-field(-\d*)?$`)
+
+// GetEmbeddedDiffFieldPaths returns the json paths of all string fields that might contain embedded YAML/JSON
+// documents. This includes all values of ConfigMaps and Secrets, all fields configured via embeddedDiff and all fields
+// configured via the kluctl.io/diff-embedded-field annotation.
+func GetEmbeddedDiffFieldPaths(o *uo.UnstructuredObject, embeddedDiffs []types.EmbeddedDiffItemConfig, localObject *uo.UnstructuredObject) []string {
+	gvk := o.GetK8sGVK()
+	name := o.GetK8sName()
+	ns := o.GetK8sNamespace()
+
+	var ret []string
+	switch gvk.GroupKind() {
+	case configMapGk:
+		ret = append(ret, "data.*")
+	case secretGk:
+		ret = append(ret, "data.*", "stringData.*")
+	}
+
+	for _, v := range localObject.GetK8sAnnotationsWithRegex(embeddedDiffFieldAnnotationRegex) {
+		ret = append(ret, v)
+	}
+
+	for _, ed := range embeddedDiffs {
+		if !checkSelectorMatch(gvk.Group, ed.Group) ||
+			!checkSelectorMatch(gvk.Kind, ed.Kind) ||
+			!checkSelectorMatch(ns, ed.Namespace) ||
+			!checkSelectorMatch(name, ed.Name) {
+			continue
+		}
+		ret = append(ret, ed.FieldPath...)
+	}
+	return ret
+}
+
+// diffEmbeddedChanges replaces updates of string fields that contain embedded YAML/JSON documents with the changes
+// found inside these documents. Changes which can not be parsed as structured documents are left untouched.
+func diffEmbeddedChanges(oldObject *uo.UnstructuredObject, newObject *uo.UnstructuredObject, changes []result.Change, embeddedFieldPaths []string) ([]result.Change, error) {
+	if len(embeddedFieldPaths) == 0 || len(changes) == 0 {
+		return changes, nil
+	}
+
+	isSecret := newObject.GetK8sGVK().GroupKind() == secretGk
+
+	// maps json paths to a flag that tells if the value is base64 encoded
+	embeddedFields := map[string]bool{}
+	for _, p := range embeddedFieldPaths {
+		j, err := uo.NewMyJsonPath(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range []*uo.UnstructuredObject{oldObject, newObject} {
+			kps, err := j.ListMatchingFields(o)
+			if err != nil {
+				return nil, err
+			}
+			for _, kp := range kps {
+				embeddedFields[kp.ToJsonPath()] = isSecret && len(kp) == 2 && kp[0] == "data"
+			}
+		}
+	}
+
+	ret := make([]result.Change, 0, len(changes))
+	for _, c := range changes {
+		if base64Encoded, ok := embeddedFields[c.JsonPath]; ok && c.Type == "update" {
+			ecs := diffEmbeddedChange(c, base64Encoded)
+			if ecs != nil {
+				ret = append(ret, ecs...)
+				continue
+			}
+		}
+		ret = append(ret, c)
+	}
+	return ret, nil
+}
+
+func diffEmbeddedChange(c result.Change, base64Encoded bool) []result.Change {
+	oldDoc, ok := parseEmbeddedDocument(c.OldValue, base64Encoded)
+	if !ok {
+		return nil
+	}
+	newDoc, ok := parseEmbeddedDocument(c.NewValue, base64Encoded)
+	if !ok {
+		return nil
+	}
+
+	changes, err := diffValues(oldDoc, newDoc)
+	if err != nil || len(changes) == 0 {
+		// either something went wrong or only formatting/comments changed, so we fall back to the unified string diff
+		return nil
+	}
+	for i := range changes {
+		if changes[i].JsonPath == "" {
+			// the type of the whole document changed
+			return nil
+		}
+		changes[i].EmbeddedJsonPath = changes[i].JsonPath
+		changes[i].JsonPath = c.JsonPath
+	}
+	return changes
+}
+
+func parseEmbeddedDocument(j *apiextensionsv1.JSON, base64Encoded bool) (any, bool) {
+	if j == nil {
+		return nil, false
+	}
+	var s string
+	err := json.Unmarshal(j.Raw, &s)
+	if err != nil {
+		return nil, false
+	}
+	if base64Encoded {
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, false
+		}
+		s = string(b)
+	}
+
+	var doc any
+	err = yaml.ReadYamlString(s, &doc)
+	if err != nil {
+		return nil, false
+	}
+	switch doc.(type) {
+	case map[string]any, []any:
+		return doc, true
+	}
+	return nil, false
+}
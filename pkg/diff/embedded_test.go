@@ -0,0 +1,129 @@
+package diff
+
+import (
+	"encoding/base64"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func buildEmbeddedTestObject(kind string, field string, data map[string]any) *uo.UnstructuredObject {
+	o := uo.FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       kind,
+		"metadata": map[string]any{
+			"name":      "test",
+			"namespace": "ns",
+		},
+	})
+	_ = o.SetNestedField(data, field)
+	return o
+}
+
+func TestEmbeddedDiffConfigMap(t *testing.T) {
+	type testCase struct {
+		name     string
+		old      string
+		new      string
+		expected []string
+	}
+
+	tests := []testCase{
+		{name: "yaml", old: "aa: 1\nbb:\n  cc: x\n", new: "aa: 1\nbb:\n  cc: y\n", expected: []string{`data["config.yaml"]#bb.cc`}},
+		{name: "json", old: `{"aa": [1, 2]}`, new: `{"aa": [1, 3], "bb": true}`, expected: []string{`data["config.yaml"]#aa[1]`, `data["config.yaml"]#bb`}},
+		{name: "plain-string", old: "x", new: "y", expected: []string{`data["config.yaml"]`}},
+		{name: "invalid-yaml", old: "a: 1", new: "a: [", expected: []string{`data["config.yaml"]`}},
+		{name: "formatting-only", old: "a: 1", new: "a:   1 # comment", expected: []string{`data["config.yaml"]`}},
+		{name: "type-change", old: "a: 1", new: "- a", expected: []string{`data["config.yaml"]`}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			oldObj := buildEmbeddedTestObject("ConfigMap", "data", map[string]any{"config.yaml": tc.old})
+			newObj := buildEmbeddedTestObject("ConfigMap", "data", map[string]any{"config.yaml": tc.new})
+
+			changes, err := Diff(oldObj, newObj, GetEmbeddedDiffFieldPaths(newObj, nil, newObj))
+			assert.NoError(t, err)
+
+			var paths []string
+			for _, c := range changes {
+				paths = append(paths, c.DisplayPath())
+			}
+			assert.ElementsMatch(t, tc.expected, paths)
+		})
+	}
+}
+
+func TestEmbeddedDiffSecret(t *testing.T) {
+	enc := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+	oldObj := buildEmbeddedTestObject("Secret", "data", map[string]any{"config.yaml": enc("key: secret1\nother: x\n")})
+	newObj := buildEmbeddedTestObject("Secret", "data", map[string]any{"config.yaml": enc("key: secret2\nother: x\n")})
+
+	changes, err := Diff(oldObj, newObj, GetEmbeddedDiffFieldPaths(newObj, nil, newObj))
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, `data["config.yaml"]`, changes[0].JsonPath)
+	assert.Equal(t, "key", changes[0].EmbeddedJsonPath)
+	assert.Equal(t, `"secret2"`, string(changes[0].NewValue.Raw))
+
+	var o Obfuscator
+	err = o.ObfuscateChanges(newObj.GetK8sRef(), changes)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(changes[0].NewValue.Raw), "secret2")
+	assert.NotContains(t, string(changes[0].OldValue.Raw), "secret1")
+	assert.NotContains(t, changes[0].UnifiedDiff, "secret")
+}
+
+func TestEmbeddedDiffConfiguredFields(t *testing.T) {
+	oldObj := buildEmbeddedTestObject("Deployment", "spec", map[string]any{"config": `{"key": 1}`, "other": `{"key": 1}`})
+	newObj := buildEmbeddedTestObject("Deployment", "spec", map[string]any{"config": `{"key": 2}`, "other": `{"key": 2}`})
+	localObj := newObj.Clone()
+	localObj.SetK8sAnnotation("kluctl.io/diff-embedded-field", "spec.other")
+
+	embeddedDiffs := []types.EmbeddedDiffItemConfig{
+		{FieldPath: []string{"spec.config"}, Kind: utils.Ptr("Deployment")},
+		{FieldPath: []string{"spec.other"}, Kind: utils.Ptr("StatefulSet")},
+	}
+
+	changes, err := Diff(oldObj, newObj, GetEmbeddedDiffFieldPaths(newObj, embeddedDiffs, localObj))
+	assert.NoError(t, err)
+
+	var paths []string
+	for _, c := range changes {
+		paths = append(paths, c.DisplayPath())
+	}
+	assert.ElementsMatch(t, []string{"spec.config#key", "spec.other#key"}, paths)
+
+	changes, err = Diff(oldObj, newObj, GetEmbeddedDiffFieldPaths(newObj, embeddedDiffs, newObj))
+	assert.NoError(t, err)
+	paths = nil
+	for _, c := range changes {
+		paths = append(paths, c.DisplayPath())
+	}
+	assert.ElementsMatch(t, []string{"spec.config#key", "spec.other"}, paths)
+}
+
+func TestEmbeddedDiffJsonPatch(t *testing.T) {
+	oldObj := buildEmbeddedTestObject("ConfigMap", "data", map[string]any{"config.yaml": "a: 1\nb: 2\n"})
+	newObj := buildEmbeddedTestObject("ConfigMap", "data", map[string]any{"config.yaml": "a: 2\nb: 3\n"})
+
+	changes, err := Diff(oldObj, newObj, GetEmbeddedDiffFieldPaths(newObj, nil, newObj))
+	assert.NoError(t, err)
+	assert.Len(t, changes, 2)
+
+	ops, err := BuildJsonPatch(changes, newObj)
+	assert.NoError(t, err)
+	assert.Len(t, ops, 1)
+	assert.Equal(t, "replace", ops[0].Op)
+	assert.Equal(t, "/data/config.yaml", ops[0].Path)
+	assert.Equal(t, `"a: 2\nb: 3\n"`, string(ops[0].Value.Raw))
+
+	_, err = BuildJsonPatch([]result.Change{{Type: "update", JsonPath: "data.x", EmbeddedJsonPath: "a"}}, nil)
+	assert.Error(t, err)
+}
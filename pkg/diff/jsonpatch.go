@@ -0,0 +1,126 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sort"
+	"strings"
+)
+
+// JsonPatchOperation is a single RFC 6902 operation
+type JsonPatchOperation struct {
+	Op    string                `json:"op"`
+	Path  string                `json:"path"`
+	Value *apiextensionsv1.JSON `json:"value,omitempty"`
+}
+
+type jsonPatchEntry struct {
+	kp uo.KeyPath
+	op JsonPatchOperation
+}
+
+// BuildJsonPatch converts the given changes into a RFC 6902 JSON Patch. Replacements come first, followed by
+// removals (deepest/highest index first) and additions (lowest index first), so that list indexes stay valid while
+// the patch is applied.
+// Changes inside embedded documents can not be expressed as JSON Patch, so these are converted into a replacement
+// of the whole field, with the value taken from newObject.
+func BuildJsonPatch(changes []result.Change, newObject *uo.UnstructuredObject) ([]JsonPatchOperation, error) {
+	var replaces, removes, adds []jsonPatchEntry
+	handledEmbedded := map[string]bool{}
+	for _, c := range changes {
+		kp, err := uo.NewKeyPathFromJsonPath(c.JsonPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse json path %s: %w", c.JsonPath, err)
+		}
+		e := jsonPatchEntry{
+			kp: kp,
+			op: JsonPatchOperation{
+				Path: kp.ToJsonPointer(),
+			},
+		}
+		if c.EmbeddedJsonPath != "" {
+			if handledEmbedded[c.JsonPath] {
+				continue
+			}
+			handledEmbedded[c.JsonPath] = true
+
+			if newObject == nil {
+				return nil, fmt.Errorf("can not build json patch for embedded change at %s without the new object", c.JsonPath)
+			}
+			v, found, err := newObject.GetNestedField(kp...)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				return nil, fmt.Errorf("field %s not found in new object", c.JsonPath)
+			}
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			e.op.Op = "replace"
+			e.op.Value = &apiextensionsv1.JSON{Raw: b}
+			replaces = append(replaces, e)
+			continue
+		}
+		switch c.Type {
+		case "insert":
+			e.op.Op = "add"
+			e.op.Value = c.NewValue
+			adds = append(adds, e)
+		case "delete":
+			e.op.Op = "remove"
+			removes = append(removes, e)
+		case "update":
+			if c.NewValue == nil {
+				// replace requires a value, so a missing new value can only be expressed as removal
+				e.op.Op = "remove"
+				removes = append(removes, e)
+				continue
+			}
+			e.op.Op = "replace"
+			e.op.Value = c.NewValue
+			replaces = append(replaces, e)
+		default:
+			return nil, fmt.Errorf("unknown change type %s", c.Type)
+		}
+	}
+
+	sort.SliceStable(removes, func(i, j int) bool {
+		return compareKeyPaths(removes[i].kp, removes[j].kp) > 0
+	})
+	sort.SliceStable(adds, func(i, j int) bool {
+		return compareKeyPaths(adds[i].kp, adds[j].kp) < 0
+	})
+
+	ret := make([]JsonPatchOperation, 0, len(changes))
+	for _, l := range [][]jsonPatchEntry{replaces, removes, adds} {
+		for _, e := range l {
+			ret = append(ret, e.op)
+		}
+	}
+	return ret, nil
+}
+
+func compareKeyPaths(a uo.KeyPath, b uo.KeyPath) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		ai, aIsInt := a[i].(int)
+		bi, bIsInt := b[i].(int)
+		if aIsInt && bIsInt {
+			if ai != bi {
+				if ai < bi {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+		if c := strings.Compare(fmt.Sprint(a[i]), fmt.Sprint(b[i])); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
@@ -0,0 +1,89 @@
+package diff
+
+import (
+	"encoding/json"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"testing"
+)
+
+func TestBuildJsonPatch(t *testing.T) {
+	type testCase struct {
+		name string
+		old  string
+		new  string
+	}
+
+	tests := []testCase{
+		{name: "update", old: `{"spec": {"a": "x"}}`, new: `{"spec": {"a": "y"}}`},
+		{name: "insert", old: `{"spec": {"a": "x"}}`, new: `{"spec": {"a": "x", "b": "y"}}`},
+		{name: "delete", old: `{"spec": {"a": "x", "b": "y"}}`, new: `{"spec": {"a": "x"}}`},
+		{name: "list-shrink", old: `{"spec": {"l": [1, 2, 3, 4]}}`, new: `{"spec": {"l": [1]}}`},
+		{name: "list-grow", old: `{"spec": {"l": [1]}}`, new: `{"spec": {"l": [1, 2, 3, 4]}}`},
+		{name: "special-keys", old: `{"metadata": {"labels": {"a/b": "x", "c~d": "y"}}}`, new: `{"metadata": {"labels": {"a/b": "z", "e.f": "y"}}}`},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			oldObj := buildObject(tc.old)
+			newObj := buildObject(tc.new)
+
+			changes, err := Diff(oldObj, newObj, nil)
+			assert.NoError(t, err)
+
+			ops, err := BuildJsonPatch(changes, newObj)
+			assert.NoError(t, err)
+			assert.Len(t, ops, len(changes))
+
+			patchJson, err := json.Marshal(ops)
+			assert.NoError(t, err)
+			patch, err := jsonpatch.DecodePatch(patchJson)
+			assert.NoError(t, err)
+
+			oldJson, err := json.Marshal(oldObj.Object)
+			assert.NoError(t, err)
+			patched, err := patch.Apply(oldJson)
+			assert.NoError(t, err)
+
+			patchedObj, err := uo.FromString(string(patched))
+			assert.NoError(t, err)
+			assert.Equal(t, newObj.Object, patchedObj.Object)
+		})
+	}
+}
+
+func TestBuildJsonPatchPointers(t *testing.T) {
+	ops, err := BuildJsonPatch([]result.Change{
+		{Type: "delete", JsonPath: `$["a/b"]`},
+		{Type: "update", JsonPath: `spec.l[2]`, NewValue: &apiextensionsv1.JSON{Raw: []byte(`"x"`)}},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []JsonPatchOperation{
+		{Op: "replace", Path: "/spec/l/2", Value: &apiextensionsv1.JSON{Raw: []byte(`"x"`)}},
+		{Op: "remove", Path: "/a~1b"},
+	}, ops)
+}
+
+func TestBuildJsonPatchUpdateWithoutValue(t *testing.T) {
+	ops, err := BuildJsonPatch([]result.Change{
+		{Type: "update", JsonPath: `spec.a`},
+		{Type: "update", JsonPath: `spec.b`, NewValue: &apiextensionsv1.JSON{Raw: []byte(`"y"`)}},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []JsonPatchOperation{
+		{Op: "replace", Path: "/spec/b", Value: &apiextensionsv1.JSON{Raw: []byte(`"y"`)}},
+		{Op: "remove", Path: "/spec/a"},
+	}, ops)
+
+	patchJson, err := json.Marshal(ops)
+	assert.NoError(t, err)
+	patch, err := jsonpatch.DecodePatch(patchJson)
+	assert.NoError(t, err)
+	patched, err := patch.Apply([]byte(`{"spec": {"a": "x", "b": "x"}}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"spec": {"b": "y"}}`, string(patched))
+}
@@ -135,6 +135,13 @@ func normalizeFloats(o *uo.UnstructuredObject) {
 var ignoreDiffFieldAnnotationRegex = regexp.MustCompile(`^kluctl.io/ignore-diff-field(-\d*)?$`)
 var ignoreDiffFieldRegexAnnotationRegex = regexp.MustCompile(`^kluctl.io/ignore-diff-field-regex(-\d*)?$`)
 
+func checkSelectorMatch(v string, m *string) bool {
+	if v == "" || m == nil {
+		return true
+	}
+	return v == *m
+}
+
 // NormalizeObject Performs some deterministic sorting and other normalizations to avoid ugly diffs due to order changes
 func NormalizeObject(o_ *uo.UnstructuredObject, ignoreForDiffs []types.IgnoreForDiffItemConfig, localObject *uo.UnstructuredObject) (*uo.UnstructuredObject, error) {
 	gvk := o_.GetK8sGVK()
@@ -160,13 +167,6 @@ func NormalizeObject(o_ *uo.UnstructuredObject, ignoreForDiffs []types.IgnoreFor
 		return &uo.UnstructuredObject{Object: map[string]interface{}{}}, nil
 	}
 
-	checkMatch := func(v string, m *string) bool {
-		if v == "" || m == nil {
-			return true
-		}
-		return v == *m
-	}
-
 	ignoreForDiffs = append([]types.IgnoreForDiffItemConfig{}, ignoreForDiffs...)
 	for _, v := range localObject.GetK8sAnnotationsWithRegex(ignoreDiffFieldAnnotationRegex) {
 		ignoreForDiffs = append(ignoreForDiffs, types.IgnoreForDiffItemConfig{
@@ -180,16 +180,16 @@ func NormalizeObject(o_ *uo.UnstructuredObject, ignoreForDiffs []types.IgnoreFor
 	}
 
 	for _, ifd := range ignoreForDiffs {
-		if !checkMatch(gvk.Group, ifd.Group) {
+		if !checkSelectorMatch(gvk.Group, ifd.Group) {
 			continue
 		}
-		if !checkMatch(gvk.Kind, ifd.Kind) {
+		if !checkSelectorMatch(gvk.Kind, ifd.Kind) {
 			continue
 		}
-		if !checkMatch(ns, ifd.Namespace) {
+		if !checkSelectorMatch(ns, ifd.Namespace) {
 			continue
 		}
-		if !checkMatch(name, ifd.Name) {
+		if !checkSelectorMatch(name, ifd.Name) {
 			continue
 		}
 
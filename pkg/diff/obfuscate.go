@@ -19,7 +19,8 @@ type Obfuscator struct {
 }
 
 func (o *Obfuscator) ObfuscateResult(r *result.CommandResult) error {
-	for _, x := range r.Objects {
+	for i := range r.Objects {
+		x := &r.Objects[i]
 		var err error
 		x.Rendered, err = o.ObfuscateObject(x.Rendered)
 		if err != nil {
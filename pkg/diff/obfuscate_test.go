@@ -0,0 +1,43 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateResult(t *testing.T) {
+	oldSecret := buildObject(`{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "s", "namespace": "default"}, "data": {"a": "eA=="}}`)
+	newSecret := buildObject(`{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "s", "namespace": "default"}, "data": {"a": "eQ=="}}`)
+
+	changes, err := Diff(oldSecret, newSecret, nil)
+	assert.NoError(t, err)
+
+	cr := &result.CommandResult{
+		Objects: []result.ResultObject{
+			{
+				BaseObject: result.BaseObject{Ref: newSecret.GetK8sRef(), Changes: changes},
+				Rendered:   newSecret,
+				Remote:     oldSecret,
+			},
+		},
+	}
+
+	var obfuscator Obfuscator
+	err = obfuscator.ObfuscateResult(cr)
+	assert.NoError(t, err)
+
+	o := cr.Objects[0]
+	for _, x := range []*uo.UnstructuredObject{o.Rendered, o.Remote} {
+		v, _, _ := x.GetNestedString("data", "a")
+		assert.Equal(t, "KioqKio=", v)
+	}
+	assert.NotContains(t, o.Changes[0].UnifiedDiff, "eA==")
+	assert.NotContains(t, o.Changes[0].UnifiedDiff, "eQ==")
+
+	// the original objects must not be modified
+	v, _, _ := newSecret.GetNestedString("data", "a")
+	assert.Equal(t, "eQ==", v)
+}
@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"sort"
+)
+
+// ObjectSetDiffConfig returns the ignoreForDiff and embeddedDiff configs to use for the given object
+type ObjectSetDiffConfig func(o *uo.UnstructuredObject) ([]types.IgnoreForDiffItemConfig, []types.EmbeddedDiffItemConfig)
+
+// DiffObjectSets compares two sets of objects, e.g. the rendered objects of two command results or of two targets.
+// Objects only found in newObjects are marked as new, objects only found in oldObjects are marked as deleted. The
+// returned result objects only contain the ref, flags and changes.
+func DiffObjectSets(oldObjects []*uo.UnstructuredObject, newObjects []*uo.UnstructuredObject, getConfig ObjectSetDiffConfig) ([]result.ResultObject, error) {
+	oldMap := map[k8s.ObjectRef]*uo.UnstructuredObject{}
+	for _, o := range oldObjects {
+		oldMap[o.GetK8sRef()] = o
+	}
+	newMap := map[k8s.ObjectRef]*uo.UnstructuredObject{}
+	for _, o := range newObjects {
+		newMap[o.GetK8sRef()] = o
+	}
+
+	var ret []result.ResultObject
+	for ref, no := range newMap {
+		oo, ok := oldMap[ref]
+		if !ok {
+			ret = append(ret, result.ResultObject{BaseObject: result.BaseObject{Ref: ref, New: true}})
+			continue
+		}
+
+		var ignoreForDiffs []types.IgnoreForDiffItemConfig
+		var embeddedDiffs []types.EmbeddedDiffItemConfig
+		if getConfig != nil {
+			ignoreForDiffs, embeddedDiffs = getConfig(no)
+		}
+
+		noo, err := NormalizeObject(oo, ignoreForDiffs, no)
+		if err != nil {
+			return nil, err
+		}
+		nno, err := NormalizeObject(no, ignoreForDiffs, no)
+		if err != nil {
+			return nil, err
+		}
+		changes, err := Diff(noo, nno, GetEmbeddedDiffFieldPaths(no, embeddedDiffs, no))
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		ret = append(ret, result.ResultObject{BaseObject: result.BaseObject{Ref: ref, Changes: changes}})
+	}
+	for ref := range oldMap {
+		if _, ok := newMap[ref]; !ok {
+			ret = append(ret, result.ResultObject{BaseObject: result.BaseObject{Ref: ref, Deleted: true}})
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Ref.Less(ret[j].Ref)
+	})
+	return ret, nil
+}
@@ -0,0 +1,50 @@
+package diff
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func buildConfigMap(name string, data map[string]any) *uo.UnstructuredObject {
+	o := uo.FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": "ns",
+		},
+	})
+	_ = o.SetNestedField(data, "data")
+	return o
+}
+
+func TestDiffObjectSets(t *testing.T) {
+	oldObjects := []*uo.UnstructuredObject{
+		buildConfigMap("unchanged", map[string]any{"key": "v"}),
+		buildConfigMap("changed", map[string]any{"key": "v1", "ignored": "x"}),
+		buildConfigMap("deleted", map[string]any{"key": "v"}),
+	}
+	newObjects := []*uo.UnstructuredObject{
+		buildConfigMap("unchanged", map[string]any{"key": "v"}),
+		buildConfigMap("changed", map[string]any{"key": "v2", "ignored": "y"}),
+		buildConfigMap("new", map[string]any{"key": "v"}),
+	}
+
+	objects, err := DiffObjectSets(oldObjects, newObjects, func(o *uo.UnstructuredObject) ([]types.IgnoreForDiffItemConfig, []types.EmbeddedDiffItemConfig) {
+		return []types.IgnoreForDiffItemConfig{{FieldPath: []string{"data.ignored"}}}, nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, objects, 3)
+
+	assert.Equal(t, "changed", objects[0].Ref.Name)
+	assert.Len(t, objects[0].Changes, 1)
+	assert.Equal(t, "data.key", objects[0].Changes[0].JsonPath)
+
+	assert.Equal(t, "deleted", objects[1].Ref.Name)
+	assert.True(t, objects[1].Deleted)
+
+	assert.Equal(t, "new", objects[2].Ref.Name)
+	assert.True(t, objects[2].New)
+}
@@ -0,0 +1,288 @@
+// Package fluxsource implements fetching of artifacts produced by the Flux source-controller, so that Flux
+// GitRepository, OCIRepository and Bucket objects can be used as KluctlDeployment sources.
+package fluxsource
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/v2/pkg/tar"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	Group = "source.toolkit.fluxcd.io"
+
+	GitRepositoryKind = "GitRepository"
+	OCIRepositoryKind = "OCIRepository"
+	BucketKind        = "Bucket"
+
+	// SourceControllerLocalhostEnv can be set to a host:port to replace the host of artifact URLs. This is useful when
+	// running the controller outside the cluster while port-forwarding to the source-controller. It's the same
+	// environment variable as used by the Flux controllers.
+	SourceControllerLocalhostEnv = "SOURCE_CONTROLLER_LOCALHOST"
+)
+
+var defaultVersions = map[string]string{
+	GitRepositoryKind: "v1",
+	OCIRepositoryKind: "v1beta2",
+	BucketKind:        "v1",
+}
+
+// Artifact is the artifact reported in status.artifact of a Flux source object.
+type Artifact struct {
+	URL      string
+	Revision string
+	Digest   string
+	Metadata map[string]string
+}
+
+// BuildGVK returns the GroupVersionKind for the given Flux source kind. If apiVersion is empty, the default version
+// for the kind is used.
+func BuildGVK(apiVersion string, kind string) (schema.GroupVersionKind, error) {
+	if apiVersion == "" {
+		v, ok := defaultVersions[kind]
+		if !ok {
+			return schema.GroupVersionKind{}, fmt.Errorf("unsupported Flux source kind %s", kind)
+		}
+		return schema.GroupVersionKind{Group: Group, Version: v, Kind: kind}, nil
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	if gv.Group != Group {
+		return schema.GroupVersionKind{}, fmt.Errorf("unsupported Flux source apiVersion %s", apiVersion)
+	}
+	return gv.WithKind(kind), nil
+}
+
+// GetSource retrieves the Flux source object.
+func GetSource(ctx context.Context, c client.Reader, gvk schema.GroupVersionKind, key client.ObjectKey) (*uo.UnstructuredObject, error) {
+	var o unstructured.Unstructured
+	o.SetGroupVersionKind(gvk)
+	err := c.Get(ctx, key, &o)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%s %s not found", gvk.Kind, key.String())
+		}
+		return nil, err
+	}
+	return uo.FromUnstructured(&o), nil
+}
+
+// GetArtifact returns the artifact of the given Flux source object. An error is returned if the source has not
+// produced an artifact yet.
+func GetArtifact(source *uo.UnstructuredObject) (*Artifact, error) {
+	ref := source.GetK8sRef()
+
+	u, _, _ := source.GetNestedString("status", "artifact", "url")
+	if u == "" {
+		return nil, fmt.Errorf("%s has no artifact yet, the source is not ready", ref.String())
+	}
+	revision, _, _ := source.GetNestedString("status", "artifact", "revision")
+	digest, _, _ := source.GetNestedString("status", "artifact", "digest")
+	if digest == "" {
+		// older versions of the source-controller only reported a sha256 checksum
+		checksum, _, _ := source.GetNestedString("status", "artifact", "checksum")
+		if checksum != "" {
+			digest = "sha256:" + checksum
+		}
+	}
+	if digest == "" {
+		return nil, fmt.Errorf("artifact of %s has no digest", ref.String())
+	}
+
+	metadata, _, _ := source.GetNestedStringMapCopy("status", "artifact", "metadata")
+
+	return &Artifact{
+		URL:      u,
+		Revision: revision,
+		Digest:   digest,
+		Metadata: metadata,
+	}, nil
+}
+
+// Fetch downloads the artifact tarball, verifies its digest and extracts it into dir.
+func Fetch(ctx context.Context, httpClient *http.Client, artifact *Artifact, dir string) error {
+	u, err := buildArtifactUrl(artifact.URL)
+	if err != nil {
+		return err
+	}
+
+	h, expectedDigest, err := newDigestHasher(artifact.Digest)
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp("", "flux-artifact-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download artifact from %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download artifact from %s, status: %s", u, resp.Status)
+	}
+
+	_, err = io.Copy(io.MultiWriter(tmpFile, h), resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to download artifact from %s: %w", u, err)
+	}
+
+	actualDigest := fmt.Sprintf("%x", h.Sum(nil))
+	if actualDigest != expectedDigest {
+		return fmt.Errorf("failed to verify artifact from %s: computed digest '%s' doesn't match '%s'", u, actualDigest, expectedDigest)
+	}
+
+	_, err = tmpFile.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	err = tar.Untar(tmpFile, dir, tar.WithSkipSymlinks())
+	if err != nil {
+		return fmt.Errorf("failed to extract artifact from %s: %w", u, err)
+	}
+	return nil
+}
+
+// ParseRevision splits a Flux revision into the ref name and the commit or digest. Supported formats are
+// '<ref>@<algo>:<hash>' (e.g. 'main@sha1:1234'), '<algo>:<hash>' and the legacy format '<ref>/<hash>'.
+func ParseRevision(revision string) (string, string) {
+	if i := strings.LastIndex(revision, "@"); i != -1 {
+		return revision[:i], stripAlgo(revision[i+1:])
+	}
+	if strings.Contains(revision, ":") {
+		return "", stripAlgo(revision)
+	}
+	if i := strings.LastIndex(revision, "/"); i != -1 {
+		return revision[:i], revision[i+1:]
+	}
+	return "", revision
+}
+
+// BuildRepoKey returns the RepoKey of the repository the Flux source points to. An empty RepoKey is returned for
+// Buckets, as these are not backed by a repository.
+func BuildRepoKey(source *uo.UnstructuredObject) (gittypes.RepoKey, error) {
+	u, _, _ := source.GetNestedString("spec", "url")
+	switch source.GetK8sGVK().Kind {
+	case GitRepositoryKind:
+		return gittypes.NewRepoKeyFromGitUrl(u)
+	case OCIRepositoryKind:
+		return gittypes.NewRepoKeyFromUrl(u)
+	default:
+		return gittypes.RepoKey{}, nil
+	}
+}
+
+// BuildGitInfo builds the GitInfo for the given artifact. For GitRepositories, the info is taken from the source
+// itself. For OCIRepositories, the info is taken from the OCI annotations as written by 'flux push artifact'.
+// Buckets don't provide any git info.
+func BuildGitInfo(source *uo.UnstructuredObject, artifact *Artifact, subDir string) gittypes.GitInfo {
+	var u string
+	var revision string
+	switch source.GetK8sGVK().Kind {
+	case GitRepositoryKind:
+		u, _, _ = source.GetNestedString("spec", "url")
+		revision = artifact.Revision
+	case OCIRepositoryKind:
+		u = artifact.Metadata["org.opencontainers.image.source"]
+		revision = artifact.Metadata["org.opencontainers.image.revision"]
+	}
+
+	gitInfo := gittypes.GitInfo{
+		SubDir: subDir,
+	}
+	if u != "" {
+		gitUrl, err := gittypes.ParseGitUrl(u)
+		if err == nil {
+			gitInfo.Url = gitUrl
+		}
+	}
+	if revision != "" {
+		var refName string
+		refName, gitInfo.Commit = ParseRevision(revision)
+		gitInfo.Ref = buildGitRef(source, refName)
+	}
+	return gitInfo
+}
+
+func buildGitRef(source *uo.UnstructuredObject, refName string) *gittypes.GitRef {
+	if refName == "" {
+		return nil
+	}
+	if strings.HasPrefix(refName, "refs/heads/") {
+		return &gittypes.GitRef{Branch: strings.TrimPrefix(refName, "refs/heads/")}
+	} else if strings.HasPrefix(refName, "refs/tags/") {
+		return &gittypes.GitRef{Tag: strings.TrimPrefix(refName, "refs/tags/")}
+	}
+
+	tag, _, _ := source.GetNestedString("spec", "ref", "tag")
+	semver, _, _ := source.GetNestedString("spec", "ref", "semver")
+	if source.GetK8sGVK().Kind == GitRepositoryKind && (tag != "" || semver != "") {
+		return &gittypes.GitRef{Tag: refName}
+	}
+	return &gittypes.GitRef{Branch: refName}
+}
+
+func stripAlgo(s string) string {
+	if i := strings.Index(s, ":"); i != -1 {
+		return s[i+1:]
+	}
+	return s
+}
+
+func buildArtifactUrl(artifactUrl string) (string, error) {
+	localhost := os.Getenv(SourceControllerLocalhostEnv)
+	if localhost == "" {
+		return artifactUrl, nil
+	}
+	u, err := url.Parse(artifactUrl)
+	if err != nil {
+		return "", err
+	}
+	u.Host = localhost
+	return u.String(), nil
+}
+
+func newDigestHasher(digest string) (hash.Hash, string, error) {
+	s := strings.SplitN(digest, ":", 2)
+	if len(s) != 2 {
+		return nil, "", fmt.Errorf("invalid artifact digest '%s'", digest)
+	}
+	switch s[0] {
+	case "sha256":
+		return sha256.New(), s[1], nil
+	case "sha384":
+		return sha512.New384(), s[1], nil
+	case "sha512":
+		return sha512.New(), s[1], nil
+	default:
+		return nil, "", fmt.Errorf("unsupported artifact digest algorithm '%s'", s[0])
+	}
+}
@@ -0,0 +1,154 @@
+package fluxsource
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTestArtifact(t *testing.T, files map[string]string) []byte {
+	buf := bytes.NewBuffer(nil)
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0o644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		})
+		assert.NoError(t, err)
+		_, err = tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func startArtifactServer(t *testing.T, data []byte) string {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gitrepository/default/repo/latest.tar.gz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(s.Close)
+	return s.URL
+}
+
+func buildTestSource(kind string, artifact map[string]any) *uo.UnstructuredObject {
+	o := uo.New()
+	gvk, _ := BuildGVK("", kind)
+	o.SetK8sGVK(gvk)
+	o.SetK8sName("repo")
+	o.SetK8sNamespace("default")
+	_ = o.SetNestedField("https://github.com/example/repo.git", "spec", "url")
+	if artifact != nil {
+		_ = o.SetNestedField(artifact, "status", "artifact")
+	}
+	return o
+}
+
+func TestFetch(t *testing.T) {
+	data := buildTestArtifact(t, map[string]string{
+		".kluctl.yaml":        "targets: []",
+		"sub/deployment.yaml": "deployments: []",
+	})
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+	u := startArtifactServer(t, data) + "/gitrepository/default/repo/latest.tar.gz"
+
+	dir := t.TempDir()
+	err := Fetch(context.Background(), http.DefaultClient, &Artifact{URL: u, Digest: digest}, dir)
+	assert.NoError(t, err)
+
+	b, err := os.ReadFile(filepath.Join(dir, "sub/deployment.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "deployments: []", string(b))
+
+	err = Fetch(context.Background(), http.DefaultClient, &Artifact{URL: u, Digest: "sha256:1234"}, t.TempDir())
+	assert.ErrorContains(t, err, "doesn't match '1234'")
+
+	err = Fetch(context.Background(), http.DefaultClient, &Artifact{URL: u, Digest: "md5:1234"}, t.TempDir())
+	assert.ErrorContains(t, err, "unsupported artifact digest algorithm 'md5'")
+
+	err = Fetch(context.Background(), http.DefaultClient, &Artifact{URL: u + "-missing", Digest: digest}, t.TempDir())
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestGetArtifact(t *testing.T) {
+	_, err := GetArtifact(buildTestSource(GitRepositoryKind, nil))
+	assert.ErrorContains(t, err, "has no artifact yet")
+
+	a, err := GetArtifact(buildTestSource(GitRepositoryKind, map[string]any{
+		"url":      "http://source-controller/latest.tar.gz",
+		"revision": "main@sha1:1234",
+		"digest":   "sha256:abcd",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, &Artifact{
+		URL:      "http://source-controller/latest.tar.gz",
+		Revision: "main@sha1:1234",
+		Digest:   "sha256:abcd",
+	}, a)
+
+	a, err = GetArtifact(buildTestSource(GitRepositoryKind, map[string]any{
+		"url":      "http://source-controller/latest.tar.gz",
+		"revision": "main/1234",
+		"checksum": "abcd",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:abcd", a.Digest)
+}
+
+func TestParseRevision(t *testing.T) {
+	tests := []struct {
+		revision string
+		ref      string
+		commit   string
+	}{
+		{revision: "main@sha1:1234", ref: "main", commit: "1234"},
+		{revision: "refs/heads/main@sha1:1234", ref: "refs/heads/main", commit: "1234"},
+		{revision: "sha256:1234", ref: "", commit: "1234"},
+		{revision: "main/1234", ref: "main", commit: "1234"},
+		{revision: "1234", ref: "", commit: "1234"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.revision, func(t *testing.T) {
+			ref, commit := ParseRevision(tc.revision)
+			assert.Equal(t, tc.ref, ref)
+			assert.Equal(t, tc.commit, commit)
+		})
+	}
+}
+
+func TestBuildGitInfo(t *testing.T) {
+	source := buildTestSource(GitRepositoryKind, nil)
+	gitInfo := BuildGitInfo(source, &Artifact{Revision: "main@sha1:1234"}, "sub")
+	assert.Equal(t, gittypes.GitInfo{
+		Url:    gittypes.ParseGitUrlMust("https://github.com/example/repo.git"),
+		Ref:    &gittypes.GitRef{Branch: "main"},
+		SubDir: "sub",
+		Commit: "1234",
+	}, gitInfo)
+
+	_ = source.SetNestedField("v1.0.0", "spec", "ref", "tag")
+	gitInfo = BuildGitInfo(source, &Artifact{Revision: "v1.0.0@sha1:1234"}, "")
+	assert.Equal(t, &gittypes.GitRef{Tag: "v1.0.0"}, gitInfo.Ref)
+
+	gitInfo = BuildGitInfo(buildTestSource(BucketKind, nil), &Artifact{Revision: "sha256:1234"}, "")
+	assert.Equal(t, gittypes.GitInfo{}, gitInfo)
+}
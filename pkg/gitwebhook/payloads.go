@@ -0,0 +1,220 @@
+package gitwebhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// pushEvent is the provider independent representation of a push webhook
+type pushEvent struct {
+	// RepoUrls contains all known urls (https, ssh, web) of the pushed repository
+	RepoUrls []string
+	// Refs contains the full names of all pushed refs, e.g. refs/heads/main
+	Refs []string
+	// DefaultBranch is the default branch of the repository, if known
+	DefaultBranch string
+}
+
+type provider struct {
+	name string
+	// detect returns true if the request was sent by this provider
+	detect func(h http.Header) bool
+	// isPush returns true if the request is a push event. Other events (e.g. ping) are ignored.
+	isPush func(h http.Header) bool
+	verify func(h http.Header, body []byte, secret []byte) error
+	parse  func(body []byte) (*pushEvent, error)
+}
+
+// the order matters, as Gitea also sends the GitHub headers
+var providers = []provider{
+	{
+		name: "gitea",
+		detect: func(h http.Header) bool {
+			return h.Get("X-Gitea-Event") != ""
+		},
+		isPush: func(h http.Header) bool {
+			return h.Get("X-Gitea-Event") == "push"
+		},
+		verify: func(h http.Header, body []byte, secret []byte) error {
+			return verifyHmac(h.Get("X-Gitea-Signature"), body, secret)
+		},
+		parse: parseGitHubPush,
+	},
+	{
+		name: "github",
+		detect: func(h http.Header) bool {
+			return h.Get("X-GitHub-Event") != ""
+		},
+		isPush: func(h http.Header) bool {
+			return h.Get("X-GitHub-Event") == "push"
+		},
+		verify: func(h http.Header, body []byte, secret []byte) error {
+			return verifyHmac(strings.TrimPrefix(h.Get("X-Hub-Signature-256"), "sha256="), body, secret)
+		},
+		parse: parseGitHubPush,
+	},
+	{
+		name: "gitlab",
+		detect: func(h http.Header) bool {
+			return h.Get("X-Gitlab-Event") != ""
+		},
+		isPush: func(h http.Header) bool {
+			e := h.Get("X-Gitlab-Event")
+			return e == "Push Hook" || e == "Tag Push Hook"
+		},
+		verify: func(h http.Header, body []byte, secret []byte) error {
+			// GitLab does not sign the payload and instead sends the secret token
+			if subtle.ConstantTimeCompare([]byte(h.Get("X-Gitlab-Token")), secret) != 1 {
+				return fmt.Errorf("invalid token")
+			}
+			return nil
+		},
+		parse: parseGitLabPush,
+	},
+	{
+		name: "bitbucket",
+		detect: func(h http.Header) bool {
+			return h.Get("X-Event-Key") != ""
+		},
+		isPush: func(h http.Header) bool {
+			e := h.Get("X-Event-Key")
+			return e == "repo:push" || e == "repo:refs_changed"
+		},
+		verify: func(h http.Header, body []byte, secret []byte) error {
+			return verifyHmac(strings.TrimPrefix(h.Get("X-Hub-Signature"), "sha256="), body, secret)
+		},
+		parse: parseBitbucketPush,
+	},
+}
+
+func detectProvider(h http.Header) *provider {
+	for i := range providers {
+		if providers[i].detect(h) {
+			return &providers[i]
+		}
+	}
+	return nil
+}
+
+func verifyHmac(signature string, body []byte, secret []byte) error {
+	if signature == "" {
+		return fmt.Errorf("missing signature")
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+func parseGitHubPush(body []byte) (*pushEvent, error) {
+	var p struct {
+		Ref        string `json:"ref"`
+		Repository struct {
+			CloneUrl      string `json:"clone_url"`
+			SshUrl        string `json:"ssh_url"`
+			HtmlUrl       string `json:"html_url"`
+			DefaultBranch string `json:"default_branch"`
+		} `json:"repository"`
+	}
+	err := json.Unmarshal(body, &p)
+	if err != nil {
+		return nil, err
+	}
+	return &pushEvent{
+		RepoUrls:      []string{p.Repository.CloneUrl, p.Repository.SshUrl, p.Repository.HtmlUrl},
+		Refs:          []string{p.Ref},
+		DefaultBranch: p.Repository.DefaultBranch,
+	}, nil
+}
+
+func parseGitLabPush(body []byte) (*pushEvent, error) {
+	var p struct {
+		Ref     string `json:"ref"`
+		Project struct {
+			GitHttpUrl    string `json:"git_http_url"`
+			GitSshUrl     string `json:"git_ssh_url"`
+			WebUrl        string `json:"web_url"`
+			DefaultBranch string `json:"default_branch"`
+		} `json:"project"`
+	}
+	err := json.Unmarshal(body, &p)
+	if err != nil {
+		return nil, err
+	}
+	return &pushEvent{
+		RepoUrls:      []string{p.Project.GitHttpUrl, p.Project.GitSshUrl, p.Project.WebUrl},
+		Refs:          []string{p.Ref},
+		DefaultBranch: p.Project.DefaultBranch,
+	}, nil
+}
+
+// parseBitbucketPush handles payloads of Bitbucket Cloud (repo:push) and Bitbucket Server (repo:refs_changed)
+func parseBitbucketPush(body []byte) (*pushEvent, error) {
+	type link struct {
+		Href string `json:"href"`
+	}
+	var p struct {
+		Repository struct {
+			Links struct {
+				Html  link   `json:"html"`
+				Clone []link `json:"clone"`
+			} `json:"links"`
+			MainBranch *struct {
+				Name string `json:"name"`
+			} `json:"mainbranch"`
+		} `json:"repository"`
+		Push struct {
+			Changes []struct {
+				New *struct {
+					Type string `json:"type"`
+					Name string `json:"name"`
+				} `json:"new"`
+			} `json:"changes"`
+		} `json:"push"`
+		Changes []struct {
+			RefId string `json:"refId"`
+		} `json:"changes"`
+	}
+	err := json.Unmarshal(body, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &pushEvent{}
+	if p.Repository.Links.Html.Href != "" {
+		e.RepoUrls = append(e.RepoUrls, p.Repository.Links.Html.Href)
+	}
+	for _, l := range p.Repository.Links.Clone {
+		e.RepoUrls = append(e.RepoUrls, l.Href)
+	}
+	if p.Repository.MainBranch != nil {
+		e.DefaultBranch = p.Repository.MainBranch.Name
+	}
+	for _, c := range p.Push.Changes {
+		if c.New == nil {
+			continue
+		}
+		switch c.New.Type {
+		case "branch":
+			e.Refs = append(e.Refs, "refs/heads/"+c.New.Name)
+		case "tag":
+			e.Refs = append(e.Refs, "refs/tags/"+c.New.Name)
+		}
+	}
+	for _, c := range p.Changes {
+		e.Refs = append(e.Refs, c.RefId)
+	}
+	return e, nil
+}
@@ -0,0 +1,278 @@
+package gitwebhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-logr/logr"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/lib/yaml"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net"
+	"net/http"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"time"
+)
+
+const (
+	Path = "/webhooks/git"
+
+	maxBodySize  = 10 * 1024 * 1024
+	fieldManager = "kluctl-git-webhook"
+)
+
+// Receiver accepts push webhooks from GitHub, GitLab, Gitea and Bitbucket and requests the reconciliation of all
+// KluctlDeployments that are deployed from the pushed repository and ref
+type Receiver struct {
+	client    client.Client
+	apiReader client.Reader
+	namespace string
+
+	secretRef client.ObjectKey
+	secretKey string
+
+	bindAddress string
+	log         logr.Logger
+}
+
+// NewReceiver creates a receiver that verifies webhooks with the secret found in the given Secret and key. The
+// Secret is read on every request, so that it can be rotated without restarting the controller.
+func NewReceiver(c client.Client, apiReader client.Reader, namespace string, secretRef client.ObjectKey, secretKey string, bindAddress string) *Receiver {
+	return &Receiver{
+		client:      c,
+		apiReader:   apiReader,
+		namespace:   namespace,
+		secretRef:   secretRef,
+		secretKey:   secretKey,
+		bindAddress: bindAddress,
+		log:         ctrl.Log.WithName("git-webhook"),
+	}
+}
+
+// NeedLeaderElection returns false so that all replicas of the controller accept webhooks
+func (r *Receiver) NeedLeaderElection() bool {
+	return false
+}
+
+func (r *Receiver) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, r)
+
+	server := &http.Server{
+		Addr:              r.bindAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(listener net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	r.log.Info("starting git webhook receiver", "address", r.bindAddress)
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	p := detectProvider(req.Header)
+	if p == nil {
+		http.Error(w, "unknown webhook provider", http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	secret, err := r.getSecret(req.Context())
+	if err != nil {
+		r.log.Error(err, "failed to get webhook secret")
+		http.Error(w, "failed to get webhook secret", http.StatusInternalServerError)
+		return
+	}
+
+	err = p.verify(req.Header, body, secret)
+	if err != nil {
+		r.log.Info("rejected webhook", "provider", p.name, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if !p.isPush(req.Header) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	e, err := p.parse(body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid payload: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	triggered, err := r.triggerReconcile(req.Context(), e)
+	if err != nil {
+		r.log.Error(err, "failed to request reconciliation", "provider", p.name)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	r.log.Info("received push webhook", "provider", p.name, "refs", e.Refs, "triggered", triggered)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(fmt.Sprintf("requested reconciliation of %d KluctlDeployments\n", len(triggered))))
+}
+
+func (r *Receiver) getSecret(ctx context.Context) ([]byte, error) {
+	var secret corev1.Secret
+	err := r.apiReader.Get(ctx, r.secretRef, &secret)
+	if err != nil {
+		return nil, err
+	}
+	x, ok := secret.Data[r.secretKey]
+	if !ok || len(x) == 0 {
+		return nil, fmt.Errorf("key %s not found in secret %s", r.secretKey, r.secretRef.String())
+	}
+	return x, nil
+}
+
+func (r *Receiver) triggerReconcile(ctx context.Context, e *pushEvent) ([]string, error) {
+	var repoKeys []gittypes.RepoKey
+	for _, u := range e.RepoUrls {
+		if u == "" {
+			continue
+		}
+		repoKey, err := gittypes.NewRepoKeyFromGitUrl(u)
+		if err != nil {
+			continue
+		}
+		repoKeys = append(repoKeys, repoKey)
+	}
+	if len(repoKeys) == 0 {
+		return nil, nil
+	}
+
+	// the cache of sharded controllers only contains the KluctlDeployments of the own shard, so we list directly from
+	// the api server to trigger the KluctlDeployments of all shards
+	var l kluctlv1.KluctlDeploymentList
+	var opts []client.ListOption
+	if r.namespace != "" {
+		opts = append(opts, client.InNamespace(r.namespace))
+	}
+	err := r.apiReader.List(ctx, &l, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var triggered []string
+	var errs []error
+	for i := range l.Items {
+		kd := &l.Items[i]
+		if !matchesPush(kd, repoKeys, e) {
+			continue
+		}
+		err = r.requestReconcile(ctx, kd)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", kd.Namespace, kd.Name, err))
+			continue
+		}
+		triggered = append(triggered, fmt.Sprintf("%s/%s", kd.Namespace, kd.Name))
+	}
+	return triggered, errors.Join(errs...)
+}
+
+func (r *Receiver) requestReconcile(ctx context.Context, kd *kluctlv1.KluctlDeployment) error {
+	patch := client.MergeFrom(kd.DeepCopy())
+	mr := &kluctlv1.ManualRequest{
+		RequestValue: time.Now().Format(time.RFC3339Nano),
+	}
+	metav1.SetMetaDataAnnotation(&kd.ObjectMeta, kluctlv1.KluctlRequestReconcileAnnotation, yaml.WriteJsonStringMust(mr))
+	return r.client.Patch(ctx, kd, patch, client.FieldOwner(fieldManager))
+}
+
+func matchesPush(kd *kluctlv1.KluctlDeployment, repoKeys []gittypes.RepoKey, e *pushEvent) bool {
+	if kd.Spec.Suspend {
+		return false
+	}
+
+	var url string
+	var ref *gittypes.GitRef
+	if kd.Spec.Source.Git != nil {
+		url = kd.Spec.Source.Git.URL
+		ref = kd.Spec.Source.Git.Ref
+	} else if kd.Spec.Source.URL != nil {
+		url = *kd.Spec.Source.URL
+		ref = kd.Spec.Source.Ref
+	} else {
+		return false
+	}
+
+	repoKey, err := gittypes.NewRepoKeyFromGitUrl(url)
+	if err != nil {
+		return false
+	}
+	found := false
+	for _, k := range repoKeys {
+		if sameRepo(repoKey, k) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+
+	for _, pushedRef := range e.Refs {
+		if matchesRef(ref, pushedRef, e.DefaultBranch) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameRepo compares the host without port, as the https and ssh urls of the same repository might use different ports
+func sameRepo(a gittypes.RepoKey, b gittypes.RepoKey) bool {
+	stripPort := func(h string) string {
+		if x, _, err := net.SplitHostPort(h); err == nil {
+			return x
+		}
+		return h
+	}
+	return strings.EqualFold(stripPort(a.Host), stripPort(b.Host)) && strings.EqualFold(a.Path, b.Path)
+}
+
+func matchesRef(ref *gittypes.GitRef, pushedRef string, defaultBranch string) bool {
+	if ref == nil {
+		// the default branch is used, so we must trigger on all branches if we don't know the default branch
+		if defaultBranch == "" {
+			return strings.HasPrefix(pushedRef, "refs/heads/")
+		}
+		return pushedRef == "refs/heads/"+defaultBranch
+	}
+	if ref.Commit != "" {
+		return false
+	}
+	s := ref.String()
+	if strings.HasPrefix(s, "refs/") {
+		return s == pushedRef
+	}
+	// legacy string based refs might omit the refs/heads/ or refs/tags/ prefix
+	return pushedRef == "refs/heads/"+s || pushedRef == "refs/tags/"+s
+}
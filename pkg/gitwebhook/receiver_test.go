@@ -0,0 +1,266 @@
+package gitwebhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"net/http"
+	"net/http/httptest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+var testSecret = []byte("my-secret")
+
+func buildKd(name string, url string, ref *gittypes.GitRef) *kluctlv1.KluctlDeployment {
+	return &kluctlv1.KluctlDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: kluctlv1.KluctlDeploymentSpec{
+			Source: kluctlv1.ProjectSource{
+				Git: &kluctlv1.ProjectSourceGit{
+					URL: url,
+					Ref: ref,
+				},
+			},
+		},
+	}
+}
+
+func newTestReceiver(t *testing.T, objs ...client.Object) (*Receiver, client.Client) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, kluctlv1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	objs = append(objs, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "webhook-secret",
+			Namespace: "kluctl-system",
+		},
+		Data: map[string][]byte{
+			"token": testSecret,
+		},
+	})
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	return NewReceiver(c, c, "", client.ObjectKey{Namespace: "kluctl-system", Name: "webhook-secret"}, "token", ""), c
+}
+
+func sign(body []byte) string {
+	mac := hmac.New(sha256.New, testSecret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func doRequest(r *Receiver, headers map[string]string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, Path, bytes.NewReader([]byte(body)))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func isTriggered(t *testing.T, c client.Client, name string) bool {
+	var kd kluctlv1.KluctlDeployment
+	err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: name}, &kd)
+	assert.NoError(t, err)
+	_, ok := kd.GetAnnotations()[kluctlv1.KluctlRequestReconcileAnnotation]
+	return ok
+}
+
+const githubPush = `{
+  "ref": "refs/heads/main",
+  "repository": {
+    "clone_url": "https://github.com/example/repo.git",
+    "ssh_url": "git@github.com:example/repo.git",
+    "html_url": "https://github.com/example/repo",
+    "default_branch": "main"
+  }
+}`
+
+func TestReceiverGitHub(t *testing.T) {
+	r, c := newTestReceiver(t,
+		buildKd("default-branch", "https://github.com/example/repo.git", nil),
+		buildKd("ssh", "ssh://git@github.com/example/repo", &gittypes.GitRef{Branch: "main"}),
+		buildKd("other-branch", "https://github.com/example/repo.git", &gittypes.GitRef{Branch: "dev"}),
+		buildKd("tag", "https://github.com/example/repo.git", &gittypes.GitRef{Tag: "main"}),
+		buildKd("other-repo", "https://github.com/example/other.git", nil),
+	)
+
+	w := doRequest(r, map[string]string{
+		"X-GitHub-Event":      "push",
+		"X-Hub-Signature-256": "sha256=" + sign([]byte(githubPush)),
+	}, githubPush)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "requested reconciliation of 2 KluctlDeployments")
+
+	assert.True(t, isTriggered(t, c, "default-branch"))
+	assert.True(t, isTriggered(t, c, "ssh"))
+	assert.False(t, isTriggered(t, c, "other-branch"))
+	assert.False(t, isTriggered(t, c, "tag"))
+	assert.False(t, isTriggered(t, c, "other-repo"))
+}
+
+func TestReceiverInvalidSignature(t *testing.T) {
+	r, c := newTestReceiver(t,
+		buildKd("kd", "https://github.com/example/repo.git", nil),
+	)
+
+	w := doRequest(r, map[string]string{
+		"X-GitHub-Event":      "push",
+		"X-Hub-Signature-256": "sha256=" + sign([]byte("other body")),
+	}, githubPush)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doRequest(r, map[string]string{
+		"X-GitHub-Event": "push",
+	}, githubPush)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doRequest(r, map[string]string{}, githubPush)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	assert.False(t, isTriggered(t, c, "kd"))
+}
+
+func TestReceiverIgnoresNonPush(t *testing.T) {
+	r, c := newTestReceiver(t,
+		buildKd("kd", "https://github.com/example/repo.git", nil),
+	)
+
+	w := doRequest(r, map[string]string{
+		"X-GitHub-Event":      "ping",
+		"X-Hub-Signature-256": "sha256=" + sign([]byte(githubPush)),
+	}, githubPush)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, isTriggered(t, c, "kd"))
+}
+
+func TestReceiverGitea(t *testing.T) {
+	r, c := newTestReceiver(t,
+		buildKd("kd", "https://gitea.example.com/example/repo.git", nil),
+	)
+
+	body := `{"ref": "refs/heads/main", "repository": {"clone_url": "https://gitea.example.com/example/repo.git", "default_branch": "main"}}`
+	w := doRequest(r, map[string]string{
+		"X-Gitea-Event":     "push",
+		"X-GitHub-Event":    "push",
+		"X-Gitea-Signature": sign([]byte(body)),
+	}, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, isTriggered(t, c, "kd"))
+}
+
+func TestReceiverGitLab(t *testing.T) {
+	r, c := newTestReceiver(t,
+		buildKd("kd", "git@gitlab.example.com:group/sub/repo.git", &gittypes.GitRef{Tag: "v1.0.0"}),
+	)
+
+	body := `{"ref": "refs/tags/v1.0.0", "project": {"git_http_url": "https://gitlab.example.com/group/sub/repo.git", "git_ssh_url": "ssh://git@gitlab.example.com:2222/group/sub/repo.git", "default_branch": "main"}}`
+	w := doRequest(r, map[string]string{
+		"X-Gitlab-Event": "Tag Push Hook",
+		"X-Gitlab-Token": "wrong",
+	}, body)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.False(t, isTriggered(t, c, "kd"))
+
+	w = doRequest(r, map[string]string{
+		"X-Gitlab-Event": "Tag Push Hook",
+		"X-Gitlab-Token": string(testSecret),
+	}, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, isTriggered(t, c, "kd"))
+}
+
+func TestReceiverBitbucket(t *testing.T) {
+	r, c := newTestReceiver(t,
+		buildKd("cloud", "https://bitbucket.org/example/repo.git", &gittypes.GitRef{Branch: "release"}),
+		buildKd("server", "ssh://git@bitbucket.example.com:7999/prj/repo.git", nil),
+	)
+
+	cloudBody := `{"repository": {"links": {"html": {"href": "https://bitbucket.org/example/repo"}}}, "push": {"changes": [{"new": {"type": "branch", "name": "release"}}]}}`
+	w := doRequest(r, map[string]string{
+		"X-Event-Key":     "repo:push",
+		"X-Hub-Signature": "sha256=" + sign([]byte(cloudBody)),
+	}, cloudBody)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, isTriggered(t, c, "cloud"))
+	assert.False(t, isTriggered(t, c, "server"))
+
+	// default branch is unknown, so all branch pushes trigger
+	serverBody := `{"repository": {"links": {"clone": [{"href": "https://bitbucket.example.com/scm/prj/repo.git"}, {"href": "ssh://git@bitbucket.example.com:7999/prj/repo.git"}]}}, "changes": [{"refId": "refs/heads/feature"}]}`
+	w = doRequest(r, map[string]string{
+		"X-Event-Key":     "repo:refs_changed",
+		"X-Hub-Signature": "sha256=" + sign([]byte(serverBody)),
+	}, serverBody)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, isTriggered(t, c, "server"))
+}
+
+func TestReceiverSkipsSuspended(t *testing.T) {
+	kd := buildKd("kd", "https://github.com/example/repo.git", nil)
+	kd.Spec.Suspend = true
+	r, c := newTestReceiver(t, kd)
+
+	w := doRequest(r, map[string]string{
+		"X-GitHub-Event":      "push",
+		"X-Hub-Signature-256": "sha256=" + sign([]byte(githubPush)),
+	}, githubPush)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, isTriggered(t, c, "kd"))
+}
+
+// shardClient simulates the cache of a controller shard that does not contain any KluctlDeployments
+type shardClient struct {
+	client.Client
+}
+
+func (c *shardClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return nil
+}
+
+func TestReceiverTriggersOtherShards(t *testing.T) {
+	r, c := newTestReceiver(t, buildKd("kd", "https://github.com/example/repo.git", nil))
+	r.client = &shardClient{Client: c}
+
+	w := doRequest(r, map[string]string{
+		"X-GitHub-Event":      "push",
+		"X-Hub-Signature-256": "sha256=" + sign([]byte(githubPush)),
+	}, githubPush)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, isTriggered(t, c, "kd"))
+}
+
+func TestMatchesRef(t *testing.T) {
+	tests := []struct {
+		ref           *gittypes.GitRef
+		pushedRef     string
+		defaultBranch string
+		result        bool
+	}{
+		{nil, "refs/heads/main", "main", true},
+		{nil, "refs/heads/dev", "main", false},
+		{nil, "refs/heads/dev", "", true},
+		{nil, "refs/tags/v1", "", false},
+		{&gittypes.GitRef{Branch: "dev"}, "refs/heads/dev", "main", true},
+		{&gittypes.GitRef{Tag: "v1"}, "refs/tags/v1", "main", true},
+		{&gittypes.GitRef{Tag: "v1"}, "refs/heads/v1", "main", false},
+		{&gittypes.GitRef{Commit: "abcdef"}, "refs/heads/main", "main", false},
+		{&gittypes.GitRef{Ref: "dev"}, "refs/heads/dev", "main", true},
+		{&gittypes.GitRef{Ref: "refs/heads/dev"}, "refs/heads/dev", "main", true},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.result, matchesRef(tc.ref, tc.pushedRef, tc.defaultBranch), "ref=%s pushedRef=%s", tc.ref.String(), tc.pushedRef)
+	}
+}
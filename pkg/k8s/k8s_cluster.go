@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/kluctl/kluctl/lib/envutils"
 	"github.com/kluctl/kluctl/lib/status"
@@ -23,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/discovery"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -360,6 +362,20 @@ func (k *K8sCluster) ApplyObject(o *uo.UnstructuredObject, options PatchOptions)
 	return uo.FromUnstructured(obj), apiWarnings, nil
 }
 
+// JsonPatchObject applies the given JSON patch (RFC 6902) to the referenced object. In combination with "test"
+// operations, this allows atomic modifications of objects.
+func (k *K8sCluster) JsonPatchObject(ref k8s.ObjectRef, patch []byte, options PatchOptions) (*uo.UnstructuredObject, []ApiWarning, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(ref.GroupVersionKind())
+	obj.SetName(ref.Name)
+	obj.SetNamespace(ref.Namespace)
+	apiWarnings, err := k.doPatch(ref, obj, client.RawPatch(types.JSONPatchType, patch), options)
+	if err != nil {
+		return nil, apiWarnings, err
+	}
+	return uo.FromUnstructured(obj), apiWarnings, nil
+}
+
 type UpdateOptions struct {
 	ForceDryRun bool
 }
@@ -533,6 +549,20 @@ func (k *K8sCluster) ResetMapper() {
 	}
 }
 
+// GetOpenAPIDocument retrieves the OpenAPI v2 document (swagger.json) served by the cluster
+func (k *K8sCluster) GetOpenAPIDocument() (*uo.UnstructuredObject, error) {
+	b, err := k.discovery.RESTClient().Get().AbsPath("/openapi/v2").SetHeader("Accept", "application/json").Do(k.ctx).Raw()
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		return nil, err
+	}
+	return uo.FromMap(m), nil
+}
+
 func (k *K8sCluster) ToRESTConfig() (*rest.Config, error) {
 	return k.config, nil
 }
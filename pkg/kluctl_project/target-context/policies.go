@@ -0,0 +1,91 @@
+package target_context
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+)
+
+const (
+	PolicyFlagForceApply          = "force-apply"
+	PolicyFlagReplaceOnError      = "replace-on-error"
+	PolicyFlagForceReplaceOnError = "force-replace-on-error"
+	PolicyFlagAbortOnError        = "abort-on-error"
+	PolicyFlagNoWait              = "no-wait"
+	PolicyFlagPrune               = "prune"
+	PolicyFlagAllowDangerousPrune = "allow-dangerous-prune"
+)
+
+const (
+	DangerousPruneWarn  = "warn"
+	DangerousPruneFail  = "fail"
+	DangerousPruneSkip  = "skip"
+	DangerousPruneAllow = "allow"
+)
+
+// CheckCommandPolicies verifies that the target policies allow running the given command with the given flags.
+// Only flags that are actually enabled must be passed.
+func (tc *TargetContext) CheckCommandPolicies(command string, flags ...string) error {
+	p := tc.Target.Policies
+	if p == nil {
+		return nil
+	}
+
+	clusterId := ""
+	if len(p.AllowedClusterIds) != 0 && tc.SharedContext.K != nil {
+		var err error
+		clusterId, err = tc.SharedContext.K.GetClusterId()
+		if err != nil {
+			return fmt.Errorf("failed to determine cluster id, which is required to check the allowedClusterIds policy: %w", err)
+		}
+	}
+
+	return CheckTargetPolicies(&tc.Target, clusterId, command, flags...)
+}
+
+// CheckTargetPolicies is the same as CheckCommandPolicies, but can be used in cases where no TargetContext is available,
+// e.g. when the controller deletes a deployment that was previously deployed.
+func CheckTargetPolicies(target *types.Target, clusterId string, command string, flags ...string) error {
+	p := target.Policies
+	if p == nil {
+		return nil
+	}
+
+	if command == "delete" && p.AllowDelete != nil && !*p.AllowDelete {
+		return fmt.Errorf("target '%s' does not allow deletion", target.Name)
+	}
+	if (command == "prune" || utils.FindStrInSlice(flags, PolicyFlagPrune) != -1) && p.AllowPrune != nil && !*p.AllowPrune {
+		return fmt.Errorf("target '%s' does not allow pruning", target.Name)
+	}
+	for _, f := range flags {
+		if utils.FindStrInSlice(p.ForbidFlags, f) != -1 {
+			return fmt.Errorf("target '%s' forbids the usage of --%s", target.Name, f)
+		}
+	}
+	if len(p.AllowedClusterIds) != 0 {
+		if clusterId == "" {
+			return fmt.Errorf("target '%s' only allows specific clusters, but the cluster id could not be determined", target.Name)
+		}
+		if utils.FindStrInSlice(p.AllowedClusterIds, clusterId) == -1 {
+			return fmt.Errorf("target '%s' does not allow cluster with id '%s'", target.Name, clusterId)
+		}
+	}
+	return nil
+}
+
+// RequiredConfirmationPhrase returns the phrase that must be typed in by the user before a modifying command is
+// executed. Returns an empty string if no phrase is required.
+func (tc *TargetContext) RequiredConfirmationPhrase() string {
+	if tc.Target.Policies == nil {
+		return ""
+	}
+	return tc.Target.Policies.RequireConfirmationPhrase
+}
+
+// DangerousPrunePolicy returns how orphan objects with dangerous prune impacts are handled. Defaults to "warn".
+func (tc *TargetContext) DangerousPrunePolicy() string {
+	if tc.Target.Policies == nil || tc.Target.Policies.DangerousPrune == "" {
+		return DangerousPruneWarn
+	}
+	return tc.Target.Policies.DangerousPrune
+}
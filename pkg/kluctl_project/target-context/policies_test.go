@@ -0,0 +1,46 @@
+package target_context
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCheckTargetPolicies(t *testing.T) {
+	type testCase struct {
+		name      string
+		policies  *types.TargetPolicies
+		clusterId string
+		command   string
+		flags     []string
+		e         string
+	}
+
+	tests := []testCase{
+		{name: "no-policies", command: "delete"},
+		{name: "delete-allowed", policies: &types.TargetPolicies{AllowDelete: utils.Ptr(true)}, command: "delete"},
+		{name: "delete-forbidden", policies: &types.TargetPolicies{AllowDelete: utils.Ptr(false)}, command: "delete", e: "target 't' does not allow deletion"},
+		{name: "prune-forbidden", policies: &types.TargetPolicies{AllowPrune: utils.Ptr(false)}, command: "prune", e: "target 't' does not allow pruning"},
+		{name: "deploy-prune-forbidden", policies: &types.TargetPolicies{AllowPrune: utils.Ptr(false)}, command: "deploy", flags: []string{PolicyFlagPrune}, e: "target 't' does not allow pruning"},
+		{name: "deploy-without-prune", policies: &types.TargetPolicies{AllowPrune: utils.Ptr(false)}, command: "deploy"},
+		{name: "forbidden-flag", policies: &types.TargetPolicies{ForbidFlags: []string{PolicyFlagForceReplaceOnError}}, command: "deploy", flags: []string{PolicyFlagForceApply, PolicyFlagForceReplaceOnError}, e: "target 't' forbids the usage of --force-replace-on-error"},
+		{name: "allowed-flag", policies: &types.TargetPolicies{ForbidFlags: []string{PolicyFlagForceReplaceOnError}}, command: "deploy", flags: []string{PolicyFlagForceApply}},
+		{name: "allowed-cluster", policies: &types.TargetPolicies{AllowedClusterIds: []string{"a", "b"}}, clusterId: "b", command: "deploy"},
+		{name: "forbidden-cluster", policies: &types.TargetPolicies{AllowedClusterIds: []string{"a", "b"}}, clusterId: "c", command: "deploy", e: "target 't' does not allow cluster with id 'c'"},
+		{name: "unknown-cluster", policies: &types.TargetPolicies{AllowedClusterIds: []string{"a"}}, command: "deploy", e: "target 't' only allows specific clusters, but the cluster id could not be determined"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			target := &types.Target{Name: "t", Policies: tc.policies}
+			err := CheckTargetPolicies(target, tc.clusterId, tc.command, tc.flags...)
+			if tc.e == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.e)
+			}
+		})
+	}
+}
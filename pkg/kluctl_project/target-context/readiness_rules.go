@@ -0,0 +1,44 @@
+package target_context
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/validation"
+)
+
+// buildReadinessRules compiles the readiness rules for all deployment projects. Rules from a deployment.yml only apply
+// to the objects of the declaring project and its included projects, while rules from .kluctl.yaml apply to all objects.
+func (tc *TargetContext) buildReadinessRules() error {
+	var err error
+	tc.ReadinessRules, err = validation.NewReadinessRules(tc.KluctlProject.Config.ReadinessRules)
+	if err != nil {
+		return err
+	}
+
+	tc.readinessRulesByProject = map[*deployment.DeploymentProject]*validation.ReadinessRules{}
+	for _, d := range tc.DeploymentCollection.Deployments {
+		if _, ok := tc.readinessRulesByProject[d.Project]; ok {
+			continue
+		}
+		var configs []types.ReadinessRuleConfig
+		configs = append(configs, tc.KluctlProject.Config.ReadinessRules...)
+		configs = append(configs, d.Project.GetReadinessRules()...)
+		r, err := validation.NewReadinessRules(configs)
+		if err != nil {
+			return err
+		}
+		tc.readinessRulesByProject[d.Project] = r
+	}
+	return nil
+}
+
+// GetReadinessRules returns the readiness rules that apply to the objects of the given deployment item. If d is nil,
+// only the rules from .kluctl.yaml are returned.
+func (tc *TargetContext) GetReadinessRules(d *deployment.DeploymentItem) *validation.ReadinessRules {
+	if d != nil {
+		if r, ok := tc.readinessRulesByProject[d.Project]; ok {
+			return r
+		}
+	}
+	return tc.ReadinessRules
+}
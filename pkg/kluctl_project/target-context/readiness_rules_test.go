@@ -0,0 +1,54 @@
+package target_context
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReadinessRules(t *testing.T) {
+	p1 := &deployment.DeploymentProject{Config: types.DeploymentProjectConfig{ReadinessRules: []types.ReadinessRuleConfig{
+		{Group: "example.com", Kind: "MyKind", Cel: utils.Ptr(`status.phase == "Running"`)},
+	}}}
+	p2 := &deployment.DeploymentProject{}
+	d1 := &deployment.DeploymentItem{Project: p1}
+	d2 := &deployment.DeploymentItem{Project: p2}
+
+	tc := &TargetContext{
+		KluctlProject: &kluctl_project.LoadedKluctlProject{Config: types.KluctlProject{ReadinessRules: []types.ReadinessRuleConfig{
+			{Group: "example.com", Kind: "OtherKind", Condition: utils.Ptr("Ready")},
+		}}},
+		DeploymentCollection: &deployment.DeploymentCollection{Deployments: []*deployment.DeploymentItem{d1, d2}},
+	}
+	err := tc.buildReadinessRules()
+	assert.NoError(t, err)
+
+	o := uo.FromMap(map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "MyKind",
+		"metadata":   map[string]any{"name": "test", "namespace": "default"},
+		"status":     map[string]any{"phase": "Pending"},
+	})
+	isReady := func(d *deployment.DeploymentItem, o *uo.UnstructuredObject) bool {
+		return validation.ValidateObject(context.TODO(), nil, o, true, false, tc.GetReadinessRules(d)).Ready
+	}
+
+	// the rule declared by p1 must only apply to the objects of p1
+	assert.False(t, isReady(d1, o))
+	assert.True(t, isReady(d2, o))
+	assert.True(t, isReady(nil, o))
+
+	// rules from .kluctl.yaml apply to all objects
+	o2 := o.Clone()
+	o2.SetK8sGVK(o2.GetK8sGVK().GroupVersion().WithKind("OtherKind"))
+	assert.False(t, isReady(d1, o2))
+	assert.False(t, isReady(d2, o2))
+	assert.False(t, isReady(nil, o2))
+}
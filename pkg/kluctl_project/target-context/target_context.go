@@ -13,6 +13,7 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/validation"
 	"github.com/kluctl/kluctl/v2/pkg/vars"
 	"path/filepath"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -28,21 +29,26 @@ type TargetContext struct {
 	ClusterContext       string
 	DeploymentProject    *deployment.DeploymentProject
 	DeploymentCollection *deployment.DeploymentCollection
+
+	// ReadinessRules contains the readiness rules from .kluctl.yaml, which apply to all objects
+	ReadinessRules          *validation.ReadinessRules
+	readinessRulesByProject map[*deployment.DeploymentProject]*validation.ReadinessRules
 }
 
 type TargetContextParams struct {
-	TargetName         string
-	TargetNameOverride string
-	ContextOverride    string
-	Discriminator      string
-	OfflineK8s         bool
-	K8sVersion         string
-	DryRun             bool
-	Images             *deployment.Images
-	Inclusion          *utils.Inclusion
-	HelmAuthProvider   auth.HelmAuthProvider
-	OciAuthProvider    auth_provider.OciAuthProvider
-	RenderOutputDir    string
+	TargetName          string
+	TargetNameOverride  string
+	ContextOverride     string
+	Discriminator       string
+	OfflineK8s          bool
+	K8sVersion          string
+	CheckApisForVersion string
+	DryRun              bool
+	Images              *deployment.Images
+	Inclusion           *utils.Inclusion
+	HelmAuthProvider    auth.HelmAuthProvider
+	OciAuthProvider     auth_provider.OciAuthProvider
+	RenderOutputDir     string
 }
 
 func NewTargetContext(ctx context.Context, p *kluctl_project.LoadedKluctlProject, contextName string, k *k8s.K8sCluster, params TargetContextParams) (*TargetContext, error) {
@@ -102,17 +108,18 @@ func NewTargetContext(ctx context.Context, p *kluctl_project.LoadedKluctlProject
 	varsLoader := vars.NewVarsLoader(ctx, k, sopsDecryptor, p.GitRP, aws.NewClientFactory(client, target.Aws), gcp.NewClientFactory())
 
 	dctx := deployment.SharedContext{
-		Ctx:              ctx,
-		K:                k,
-		K8sVersion:       params.K8sVersion,
-		GitRP:            p.GitRP,
-		OciRP:            p.OciRP,
-		SopsDecrypter:    sopsDecryptor,
-		VarsLoader:       varsLoader,
-		HelmAuthProvider: params.HelmAuthProvider,
-		OciAuthProvider:  params.OciAuthProvider,
-		Discriminator:    target.Discriminator,
-		RenderDir:        params.RenderOutputDir,
+		Ctx:                 ctx,
+		K:                   k,
+		K8sVersion:          params.K8sVersion,
+		CheckApisForVersion: params.CheckApisForVersion,
+		GitRP:               p.GitRP,
+		OciRP:               p.OciRP,
+		SopsDecrypter:       sopsDecryptor,
+		VarsLoader:          varsLoader,
+		HelmAuthProvider:    params.HelmAuthProvider,
+		OciAuthProvider:     params.OciAuthProvider,
+		Discriminator:       target.Discriminator,
+		RenderDir:           params.RenderOutputDir,
 	}
 
 	targetCtx := &TargetContext{
@@ -135,5 +142,10 @@ func NewTargetContext(ctx context.Context, p *kluctl_project.LoadedKluctlProject
 	}
 	targetCtx.DeploymentCollection = c
 
+	err = targetCtx.buildReadinessRules()
+	if err != nil {
+		return targetCtx, err
+	}
+
 	return targetCtx, nil
 }
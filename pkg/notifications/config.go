@@ -0,0 +1,87 @@
+package notifications
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/lib/yaml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type EventType string
+
+const (
+	EventDeploySucceeded EventType = "deploy-succeeded"
+	EventDeployFailed    EventType = "deploy-failed"
+	EventDriftDetected   EventType = "drift-detected"
+	EventValidateFailed  EventType = "validate-failed"
+	EventPrune           EventType = "prune"
+)
+
+var eventTitles = map[EventType]string{
+	EventDeploySucceeded: "deployment succeeded",
+	EventDeployFailed:    "deployment failed",
+	EventDriftDetected:   "drift detected",
+	EventValidateFailed:  "validation failed",
+	EventPrune:           "objects pruned",
+}
+
+const (
+	ProviderWebhook = "webhook"
+	ProviderSlack   = "slack"
+	ProviderMSTeams = "msteams"
+	ProviderSMTP    = "smtp"
+)
+
+// ProviderConfig describes where notifications are sent to
+type ProviderConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	// Address is the URL used by the webhook, slack and msteams providers
+	Address string `json:"address,omitempty"`
+	// Headers are added to all requests sent by the webhook provider
+	Headers map[string]string `json:"headers,omitempty"`
+	Timeout *metav1.Duration  `json:"timeout,omitempty"`
+
+	SMTP *SMTPConfig `json:"smtp,omitempty"`
+}
+
+type SMTPConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// RuleConfig decides which events are sent to which providers
+type RuleConfig struct {
+	Name      string      `json:"name,omitempty"`
+	Events    []EventType `json:"events"`
+	Providers []string    `json:"providers"`
+
+	// Namespaces and Names restrict the rule to KluctlDeployments matching one of the given glob patterns
+	Namespaces []string `json:"namespaces,omitempty"`
+	Names      []string `json:"names,omitempty"`
+
+	// Template is a Go template that is rendered with the Event as data and replaces the default message
+	Template string `json:"template,omitempty"`
+
+	// RateLimit suppresses repeated notifications for the same KluctlDeployment and event type
+	RateLimit *metav1.Duration `json:"rateLimit,omitempty"`
+}
+
+// Config is the content of the file passed via --notification-config
+type Config struct {
+	Providers []ProviderConfig `json:"providers,omitempty"`
+	Rules     []RuleConfig     `json:"rules,omitempty"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	var config Config
+	err := yaml.ReadYamlFile(path, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification config: %w", err)
+	}
+	return &config, nil
+}
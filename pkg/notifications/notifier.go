@@ -0,0 +1,259 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/hashicorp/go-multierror"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"path"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const defaultSendTimeout = 10 * time.Second
+
+// Event describes something that happened to a KluctlDeployment. It is passed as data to rule templates and sent
+// as payload by the webhook provider.
+type Event struct {
+	Type      EventType `json:"type"`
+	Time      time.Time `json:"time"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+
+	CommandResult        *result.CommandResultSummary  `json:"commandResult,omitempty"`
+	ValidateResult       *result.ValidateResultSummary `json:"validateResult,omitempty"`
+	DriftDetectionResult *result.DriftDetectionResult  `json:"driftDetectionResult,omitempty"`
+}
+
+func (e *Event) IsFailure() bool {
+	return e.Type == EventDeployFailed || e.Type == EventValidateFailed || e.Type == EventDriftDetected
+}
+
+type provider interface {
+	send(ctx context.Context, e *Event) error
+}
+
+type rule struct {
+	config    RuleConfig
+	events    map[EventType]bool
+	providers []provider
+	template  *template.Template
+}
+
+// Notifier sends events to the providers of all matching rules
+type Notifier struct {
+	rules []*rule
+
+	now func() time.Time
+
+	mutex    sync.Mutex
+	lastSent map[string]time.Time
+}
+
+func NewNotifier(config *Config) (*Notifier, error) {
+	n := &Notifier{
+		now:      time.Now,
+		lastSent: map[string]time.Time{},
+	}
+	if config == nil {
+		return n, nil
+	}
+
+	providers := map[string]provider{}
+	for _, pc := range config.Providers {
+		if pc.Name == "" {
+			return nil, fmt.Errorf("notification provider without name")
+		}
+		if _, ok := providers[pc.Name]; ok {
+			return nil, fmt.Errorf("duplicate notification provider %s", pc.Name)
+		}
+		p, err := buildProvider(pc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification provider %s: %w", pc.Name, err)
+		}
+		providers[pc.Name] = p
+	}
+
+	for i, rc := range config.Rules {
+		name := rc.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i)
+		}
+		r := &rule{
+			config: rc,
+			events: map[EventType]bool{},
+		}
+		r.config.Name = name
+		if len(rc.Events) == 0 {
+			return nil, fmt.Errorf("notification rule %s has no events", name)
+		}
+		for _, e := range rc.Events {
+			if _, ok := eventTitles[e]; !ok {
+				return nil, fmt.Errorf("notification rule %s has invalid event %s", name, e)
+			}
+			r.events[e] = true
+		}
+		if len(rc.Providers) == 0 {
+			return nil, fmt.Errorf("notification rule %s has no providers", name)
+		}
+		for _, pn := range rc.Providers {
+			p, ok := providers[pn]
+			if !ok {
+				return nil, fmt.Errorf("notification rule %s references unknown provider %s", name, pn)
+			}
+			r.providers = append(r.providers, p)
+		}
+		for _, pattern := range append(append([]string{}, rc.Namespaces...), rc.Names...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("notification rule %s has invalid pattern %s: %w", name, pattern, err)
+			}
+		}
+		if rc.Template != "" {
+			t, err := template.New(name).Option("missingkey=error").Parse(rc.Template)
+			if err != nil {
+				return nil, fmt.Errorf("notification rule %s has invalid template: %w", name, err)
+			}
+			r.template = t
+		}
+		n.rules = append(n.rules, r)
+	}
+	return n, nil
+}
+
+func buildProvider(pc ProviderConfig) (provider, error) {
+	timeout := defaultSendTimeout
+	if pc.Timeout != nil {
+		timeout = pc.Timeout.Duration
+	}
+
+	switch pc.Type {
+	case ProviderWebhook, ProviderSlack, ProviderMSTeams:
+		if pc.Address == "" {
+			return nil, fmt.Errorf("address is required")
+		}
+		switch pc.Type {
+		case ProviderWebhook:
+			return &webhookProvider{address: pc.Address, headers: pc.Headers, timeout: timeout}, nil
+		case ProviderSlack:
+			return &slackProvider{address: pc.Address, timeout: timeout}, nil
+		default:
+			return &msteamsProvider{address: pc.Address, timeout: timeout}, nil
+		}
+	case ProviderSMTP:
+		if pc.SMTP == nil {
+			return nil, fmt.Errorf("smtp is required")
+		}
+		if pc.SMTP.Host == "" || pc.SMTP.From == "" || len(pc.SMTP.To) == 0 {
+			return nil, fmt.Errorf("smtp.host, smtp.from and smtp.to are required")
+		}
+		return &smtpProvider{config: *pc.SMTP, timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("unknown type '%s'", pc.Type)
+	}
+}
+
+// Notify sends the event to all matching rules in the background. Failures are logged.
+func (n *Notifier) Notify(ctx context.Context, e Event) {
+	if n == nil || len(n.rules) == 0 {
+		return
+	}
+	log := ctrl.LoggerFrom(ctx)
+
+	go func() {
+		err := n.notify(context.Background(), e)
+		if err != nil {
+			log.Error(err, "failed to send notification", "event", e.Type)
+		}
+	}()
+}
+
+func (n *Notifier) notify(ctx context.Context, e Event) error {
+	if e.Time.IsZero() {
+		e.Time = n.now()
+	}
+	if e.Title == "" {
+		e.Title = fmt.Sprintf("%s/%s: %s", e.Namespace, e.Name, eventTitles[e.Type])
+	}
+	if e.DriftDetectionResult != nil {
+		e.DriftDetectionResult = stripDriftChanges(e.DriftDetectionResult)
+	}
+
+	var errs *multierror.Error
+	for _, r := range n.rules {
+		if !r.matches(&e) || !n.checkRateLimit(r, &e) {
+			continue
+		}
+
+		e2 := e
+		if r.template != nil {
+			buf := bytes.NewBuffer(nil)
+			err := r.template.Execute(buf, &e)
+			if err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("rule %s: failed to render template: %w", r.config.Name, err))
+				continue
+			}
+			e2.Message = buf.String()
+		}
+
+		for i, p := range r.providers {
+			err := p.send(ctx, &e2)
+			if err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("rule %s: provider %s: %w", r.config.Name, r.config.Providers[i], err))
+			}
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// stripDriftChanges returns a copy of dr without the changes of the drifted objects. Changes contain the old and new
+// values of all changed fields (including Secret data), which must not leave the cluster.
+func stripDriftChanges(dr *result.DriftDetectionResult) *result.DriftDetectionResult {
+	ret := dr.DeepCopy()
+	for i := range ret.Objects {
+		ret.Objects[i].Changes = nil
+	}
+	return ret
+}
+
+func (r *rule) matches(e *Event) bool {
+	if !r.events[e.Type] {
+		return false
+	}
+	return matchesPatterns(r.config.Namespaces, e.Namespace) && matchesPatterns(r.config.Names, e.Name)
+}
+
+func matchesPatterns(patterns []string, s string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if m, _ := path.Match(p, s); m {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRateLimit returns false if the rule already sent a notification for the same KluctlDeployment and event
+// type inside the rate limit interval
+func (n *Notifier) checkRateLimit(r *rule, e *Event) bool {
+	if r.config.RateLimit == nil || r.config.RateLimit.Duration <= 0 {
+		return true
+	}
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	key := fmt.Sprintf("%s/%s/%s/%s", r.config.Name, e.Namespace, e.Name, e.Type)
+	now := n.now()
+	if t, ok := n.lastSent[key]; ok && now.Sub(t) < r.config.RateLimit.Duration {
+		return false
+	}
+	n.lastSent[key] = now
+	return true
+}
@@ -0,0 +1,458 @@
+package notifications
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	"io"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testReceiver struct {
+	server *httptest.Server
+
+	mutex    sync.Mutex
+	requests []map[string]any
+	headers  []http.Header
+	status   int
+}
+
+func newTestReceiver(t *testing.T) *testReceiver {
+	r := &testReceiver{status: http.StatusOK}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var m map[string]any
+		err := json.NewDecoder(req.Body).Decode(&m)
+		assert.NoError(t, err)
+
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.requests = append(r.requests, m)
+		r.headers = append(r.headers, req.Header.Clone())
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *testReceiver) getRequests() []map[string]any {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]map[string]any{}, r.requests...)
+}
+
+func testEvent(t EventType) Event {
+	return Event{
+		Type:      t,
+		Name:      "app",
+		Namespace: "ns",
+		Message:   "deploy finished",
+		CommandResult: &result.CommandResultSummary{
+			Id:             "result-id",
+			ChangedObjects: 2,
+		},
+	}
+}
+
+func TestNotifierWebhook(t *testing.T) {
+	recv := newTestReceiver(t)
+
+	n, err := NewNotifier(&Config{
+		Providers: []ProviderConfig{
+			{Name: "hook", Type: ProviderWebhook, Address: recv.server.URL, Headers: map[string]string{"X-Token": "secret"}},
+		},
+		Rules: []RuleConfig{
+			{Events: []EventType{EventDeploySucceeded, EventDeployFailed}, Providers: []string{"hook"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, n.notify(context.Background(), testEvent(EventDeploySucceeded)))
+	assert.NoError(t, n.notify(context.Background(), testEvent(EventDriftDetected)))
+
+	requests := recv.getRequests()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "deploy-succeeded", requests[0]["type"])
+	assert.Equal(t, "ns/app: deployment succeeded", requests[0]["title"])
+	assert.Equal(t, "deploy finished", requests[0]["message"])
+	assert.Equal(t, "result-id", requests[0]["commandResult"].(map[string]any)["id"])
+	assert.Equal(t, "secret", recv.headers[0].Get("X-Token"))
+}
+
+func TestNotifierWebhookStripsDriftChanges(t *testing.T) {
+	recv := newTestReceiver(t)
+
+	n, err := NewNotifier(&Config{
+		Providers: []ProviderConfig{
+			{Name: "hook", Type: ProviderWebhook, Address: recv.server.URL},
+		},
+		Rules: []RuleConfig{
+			{Events: []EventType{EventDriftDetected}, Providers: []string{"hook"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	dr := &result.DriftDetectionResult{
+		Id: "drift-id",
+		Objects: []result.DriftedObject{{
+			BaseObject: result.BaseObject{
+				Ref: k8s.ObjectRef{Version: "v1", Kind: "Secret", Name: "s", Namespace: "ns"},
+				Changes: []result.Change{{
+					Type:     "update",
+					JsonPath: "data.password",
+					OldValue: &apiextensionsv1.JSON{Raw: []byte(`"old"`)},
+					NewValue: &apiextensionsv1.JSON{Raw: []byte(`"new"`)},
+				}},
+			},
+		}},
+	}
+	e := testEvent(EventDriftDetected)
+	e.DriftDetectionResult = dr
+	assert.NoError(t, n.notify(context.Background(), e))
+
+	requests := recv.getRequests()
+	assert.Len(t, requests, 1)
+	objects := requests[0]["driftDetectionResult"].(map[string]any)["objects"].([]any)
+	assert.Len(t, objects, 1)
+	assert.Equal(t, "s", objects[0].(map[string]any)["ref"].(map[string]any)["name"])
+	assert.NotContains(t, objects[0].(map[string]any), "changes")
+
+	// the original result must not be modified
+	assert.Len(t, dr.Objects[0].Changes, 1)
+}
+
+func TestNotifierSlackAndMSTeams(t *testing.T) {
+	slack := newTestReceiver(t)
+	teams := newTestReceiver(t)
+
+	n, err := NewNotifier(&Config{
+		Providers: []ProviderConfig{
+			{Name: "slack", Type: ProviderSlack, Address: slack.server.URL},
+			{Name: "teams", Type: ProviderMSTeams, Address: teams.server.URL},
+		},
+		Rules: []RuleConfig{
+			{Events: []EventType{EventDeployFailed}, Providers: []string{"slack", "teams"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, n.notify(context.Background(), testEvent(EventDeployFailed)))
+
+	slackRequests := slack.getRequests()
+	assert.Len(t, slackRequests, 1)
+	assert.Equal(t, "*ns/app: deployment failed*\ndeploy finished", slackRequests[0]["text"])
+
+	teamsRequests := teams.getRequests()
+	assert.Len(t, teamsRequests, 1)
+	attachments := teamsRequests[0]["attachments"].([]any)
+	assert.Len(t, attachments, 1)
+	body := attachments[0].(map[string]any)["content"].(map[string]any)["body"].([]any)
+	assert.Equal(t, "ns/app: deployment failed", body[0].(map[string]any)["text"])
+	assert.Equal(t, "Attention", body[0].(map[string]any)["color"])
+	assert.Equal(t, "deploy finished", body[1].(map[string]any)["text"])
+}
+
+func TestNotifierFilters(t *testing.T) {
+	recv := newTestReceiver(t)
+
+	n, err := NewNotifier(&Config{
+		Providers: []ProviderConfig{
+			{Name: "hook", Type: ProviderWebhook, Address: recv.server.URL},
+		},
+		Rules: []RuleConfig{
+			{Events: []EventType{EventPrune}, Providers: []string{"hook"}, Namespaces: []string{"prod-*"}, Names: []string{"app", "infra"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	e := testEvent(EventPrune)
+	assert.NoError(t, n.notify(context.Background(), e))
+	e.Namespace = "prod-1"
+	assert.NoError(t, n.notify(context.Background(), e))
+	e.Name = "other"
+	assert.NoError(t, n.notify(context.Background(), e))
+
+	requests := recv.getRequests()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "prod-1", requests[0]["namespace"])
+}
+
+func TestNotifierTemplate(t *testing.T) {
+	recv := newTestReceiver(t)
+
+	n, err := NewNotifier(&Config{
+		Providers: []ProviderConfig{
+			{Name: "slack", Type: ProviderSlack, Address: recv.server.URL},
+		},
+		Rules: []RuleConfig{
+			{
+				Events:    []EventType{EventDeploySucceeded},
+				Providers: []string{"slack"},
+				Template:  "{{ .Name }} changed {{ .CommandResult.ChangedObjects }} objects",
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, n.notify(context.Background(), testEvent(EventDeploySucceeded)))
+
+	e := testEvent(EventDeploySucceeded)
+	e.CommandResult = nil
+	assert.ErrorContains(t, n.notify(context.Background(), e), "failed to render template")
+
+	requests := recv.getRequests()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "*ns/app: deployment succeeded*\napp changed 2 objects", requests[0]["text"])
+}
+
+func TestNotifierRateLimit(t *testing.T) {
+	recv := newTestReceiver(t)
+
+	n, err := NewNotifier(&Config{
+		Providers: []ProviderConfig{
+			{Name: "hook", Type: ProviderWebhook, Address: recv.server.URL},
+		},
+		Rules: []RuleConfig{
+			{Events: []EventType{EventDriftDetected, EventValidateFailed}, Providers: []string{"hook"}, RateLimit: &metav1.Duration{Duration: time.Hour}},
+		},
+	})
+	assert.NoError(t, err)
+
+	now := time.Now()
+	n.now = func() time.Time {
+		return now
+	}
+
+	assert.NoError(t, n.notify(context.Background(), testEvent(EventDriftDetected)))
+	assert.NoError(t, n.notify(context.Background(), testEvent(EventDriftDetected)))
+	// other event type
+	assert.NoError(t, n.notify(context.Background(), testEvent(EventValidateFailed)))
+	// other deployment
+	e := testEvent(EventDriftDetected)
+	e.Name = "other"
+	assert.NoError(t, n.notify(context.Background(), e))
+	assert.Len(t, recv.getRequests(), 3)
+
+	now = now.Add(time.Hour)
+	assert.NoError(t, n.notify(context.Background(), testEvent(EventDriftDetected)))
+	assert.Len(t, recv.getRequests(), 4)
+}
+
+func TestNotifierSendError(t *testing.T) {
+	recv := newTestReceiver(t)
+	recv.status = http.StatusInternalServerError
+
+	n, err := NewNotifier(&Config{
+		Providers: []ProviderConfig{
+			{Name: "hook", Type: ProviderWebhook, Address: recv.server.URL},
+		},
+		Rules: []RuleConfig{
+			{Name: "r1", Events: []EventType{EventDeployFailed}, Providers: []string{"hook"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	err = n.notify(context.Background(), testEvent(EventDeployFailed))
+	assert.ErrorContains(t, err, "rule r1: provider hook: request failed with status 500")
+}
+
+func TestNotifierInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		err    string
+	}{
+		{name: "unknown provider type", config: Config{
+			Providers: []ProviderConfig{{Name: "p", Type: "irc"}},
+		}, err: "unknown type 'irc'"},
+		{name: "missing address", config: Config{
+			Providers: []ProviderConfig{{Name: "p", Type: ProviderSlack}},
+		}, err: "address is required"},
+		{name: "missing smtp", config: Config{
+			Providers: []ProviderConfig{{Name: "p", Type: ProviderSMTP}},
+		}, err: "smtp is required"},
+		{name: "unknown provider", config: Config{
+			Rules: []RuleConfig{{Events: []EventType{EventPrune}, Providers: []string{"p"}}},
+		}, err: "references unknown provider p"},
+		{name: "invalid event", config: Config{
+			Providers: []ProviderConfig{{Name: "p", Type: ProviderSlack, Address: "http://localhost"}},
+			Rules:     []RuleConfig{{Events: []EventType{"deleted"}, Providers: []string{"p"}}},
+		}, err: "invalid event deleted"},
+		{name: "invalid template", config: Config{
+			Providers: []ProviderConfig{{Name: "p", Type: ProviderSlack, Address: "http://localhost"}},
+			Rules:     []RuleConfig{{Events: []EventType{EventPrune}, Providers: []string{"p"}, Template: "{{ .Name "}},
+		}, err: "invalid template"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewNotifier(&tc.config)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(p, []byte(`
+providers:
+  - name: mail
+    type: smtp
+    smtp:
+      host: smtp.example.com
+      from: kluctl@example.com
+      to: [ops@example.com]
+rules:
+  - events: [deploy-failed]
+    providers: [mail]
+    rateLimit: 10m
+`), 0o600)
+	assert.NoError(t, err)
+
+	config, err := LoadConfig(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "smtp.example.com", config.Providers[0].SMTP.Host)
+	assert.Equal(t, 10*time.Minute, config.Rules[0].RateLimit.Duration)
+
+	_, err = NewNotifier(config)
+	assert.NoError(t, err)
+}
+
+func TestSMTPBuildMail(t *testing.T) {
+	p := &smtpProvider{config: SMTPConfig{
+		Host: "smtp.example.com",
+		From: "kluctl@example.com",
+		To:   []string{"a@example.com", "b@example.com"},
+	}}
+	e := testEvent(EventDeployFailed)
+	e.Title = "ns/app: deployment\nfailed"
+	e.Message = "line1\nline2"
+
+	mail := string(p.buildMail(&e))
+	assert.Contains(t, mail, "To: a@example.com, b@example.com\r\n")
+	assert.Contains(t, mail, "Subject: ns/app: deployment failed\r\n")
+	assert.True(t, strings.HasSuffix(mail, "\r\n\r\nline1\r\nline2\r\n"))
+}
+
+// runTestSMTPServer accepts a single connection and plays the server side of a plain SMTP conversation. The received
+// mail data is sent to the returned channel.
+func runTestSMTPServer(t *testing.T, l net.Listener) <-chan string {
+	ch := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		write := func(s string) {
+			_, _ = conn.Write([]byte(s + "\r\n"))
+		}
+		write("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				write("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+				write("250 OK")
+			case cmd == "DATA":
+				write("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				ch <- data.String()
+				write("250 OK")
+			case cmd == "QUIT":
+				write("221 bye")
+				return
+			default:
+				write("502 not implemented")
+			}
+		}
+	}()
+	return ch
+}
+
+func newTestSMTPProvider(t *testing.T, l net.Listener, timeout time.Duration) *smtpProvider {
+	host, portStr, err := net.SplitHostPort(l.Addr().String())
+	assert.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	assert.NoError(t, err)
+
+	return &smtpProvider{
+		config: SMTPConfig{
+			Host: host,
+			Port: port,
+			From: "kluctl@example.com",
+			To:   []string{"a@example.com"},
+		},
+		timeout: timeout,
+	}
+}
+
+func TestSMTPSend(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+
+	ch := runTestSMTPServer(t, l)
+	p := newTestSMTPProvider(t, l, 5*time.Second)
+
+	e := testEvent(EventDeployFailed)
+	e.Title = "ns/app: deployment failed"
+	assert.NoError(t, p.send(context.Background(), &e))
+
+	data := <-ch
+	assert.Contains(t, data, "Subject: ns/app: deployment failed\r\n")
+	assert.Contains(t, data, "deploy finished\r\n")
+}
+
+func TestSMTPTimeout(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+
+	// accept the connection but never send the greeting
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+
+	p := newTestSMTPProvider(t, l, 200*time.Millisecond)
+
+	e := testEvent(EventDeployFailed)
+	start := time.Now()
+	err = p.send(context.Background(), &e)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,109 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+func postJson(ctx context.Context, address string, headers map[string]string, timeout time.Duration, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// webhookProvider sends the whole event as JSON
+type webhookProvider struct {
+	address string
+	headers map[string]string
+	timeout time.Duration
+}
+
+func (p *webhookProvider) send(ctx context.Context, e *Event) error {
+	return postJson(ctx, p.address, p.headers, p.timeout, e)
+}
+
+// slackProvider sends messages that are compatible with Slack incoming webhooks
+type slackProvider struct {
+	address string
+	timeout time.Duration
+}
+
+func (p *slackProvider) send(ctx context.Context, e *Event) error {
+	payload := map[string]any{
+		"text": fmt.Sprintf("*%s*\n%s", e.Title, e.Message),
+	}
+	return postJson(ctx, p.address, nil, p.timeout, payload)
+}
+
+// msteamsProvider sends adaptive cards to Microsoft Teams incoming webhooks or workflows
+type msteamsProvider struct {
+	address string
+	timeout time.Duration
+}
+
+func (p *msteamsProvider) send(ctx context.Context, e *Event) error {
+	titleColor := "Good"
+	if e.IsFailure() {
+		titleColor = "Attention"
+	}
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body": []any{
+			map[string]any{
+				"type":   "TextBlock",
+				"text":   e.Title,
+				"weight": "Bolder",
+				"size":   "Medium",
+				"color":  titleColor,
+				"wrap":   true,
+			},
+			map[string]any{
+				"type": "TextBlock",
+				"text": e.Message,
+				"wrap": true,
+			},
+		},
+	}
+	payload := map[string]any{
+		"type": "message",
+		"attachments": []any{
+			map[string]any{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content":     card,
+			},
+		},
+	}
+	return postJson(ctx, p.address, nil, p.timeout, payload)
+}
@@ -0,0 +1,110 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultSMTPPort = 587
+
+// smtpProvider sends mails via an SMTP relay. STARTTLS is used if the relay supports it.
+type smtpProvider struct {
+	config  SMTPConfig
+	timeout time.Duration
+}
+
+func (p *smtpProvider) send(ctx context.Context, e *Event) error {
+	port := p.config.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	addr := net.JoinHostPort(p.config.Host, strconv.Itoa(port))
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// the whole conversation must finish in time, otherwise a stalled relay would block the notifier forever
+	deadline, _ := ctx.Deadline()
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, p.config.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		err = c.StartTLS(&tls.Config{ServerName: p.config.Host})
+		if err != nil {
+			return err
+		}
+	}
+	if p.config.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("smtp server does not support AUTH")
+		}
+		err = c.Auth(smtp.PlainAuth("", p.config.Username, p.config.Password, p.config.Host))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.Mail(p.config.From)
+	if err != nil {
+		return err
+	}
+	for _, to := range p.config.To {
+		err = c.Rcpt(to)
+		if err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(p.buildMail(e))
+	if err != nil {
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func (p *smtpProvider) buildMail(e *Event) []byte {
+	buf := bytes.NewBuffer(nil)
+	_, _ = fmt.Fprintf(buf, "From: %s\r\n", p.config.From)
+	_, _ = fmt.Fprintf(buf, "To: %s\r\n", strings.Join(p.config.To, ", "))
+	_, _ = fmt.Fprintf(buf, "Subject: %s\r\n", sanitizeHeader(e.Title))
+	_, _ = fmt.Fprintf(buf, "Date: %s\r\n", e.Time.Format(time.RFC1123Z))
+	_, _ = fmt.Fprintf(buf, "MIME-Version: 1.0\r\n")
+	_, _ = fmt.Fprintf(buf, "Content-Type: text/plain; charset=UTF-8\r\n")
+	_, _ = fmt.Fprintf(buf, "\r\n")
+	_, _ = buf.WriteString(strings.ReplaceAll(e.Message, "\n", "\r\n"))
+	_, _ = buf.WriteString("\r\n")
+	return buf.Bytes()
+}
+
+func sanitizeHeader(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
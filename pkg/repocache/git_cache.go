@@ -197,6 +197,25 @@ func (e *GitCacheEntry) GetRepoInfo() RepoInfo {
 	return info
 }
 
+// IsAncestor returns true if the commit with the hash ancestor is the same as or an ancestor of the commit with the
+// hash commit. Overridden repositories are not supported.
+func (e *GitCacheEntry) IsAncestor(ancestor string, commit string) (bool, error) {
+	e.updateMutex.Lock()
+	defer e.updateMutex.Unlock()
+
+	if e.mr == nil {
+		return false, fmt.Errorf("commit history of overridden repository %s is not available", e.url.String())
+	}
+
+	err := e.mr.Lock()
+	if err != nil {
+		return false, err
+	}
+	defer e.mr.Unlock()
+
+	return e.mr.IsAncestor(ancestor, commit)
+}
+
 func (e *GitCacheEntry) findCommit(ref string) (string, string, error) {
 	ref, objectHash, err := e.findRef(ref)
 	if err != nil {
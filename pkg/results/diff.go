@@ -0,0 +1,53 @@
+package results
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DiffCommandResults compares the rendered objects of two command results and returns a command result that describes
+// the changes from a to b. The ignoreForDiff and embeddedDiff configs of b's root deployment project are honored.
+func DiffCommandResults(a *result.CommandResult, b *result.CommandResult, ignoreForDiffs []types.IgnoreForDiffItemConfig) (*result.CommandResult, error) {
+	startTime := metav1.Now()
+
+	collectRendered := func(cr *result.CommandResult) []*uo.UnstructuredObject {
+		var ret []*uo.UnstructuredObject
+		for _, o := range cr.Objects {
+			if o.Rendered != nil {
+				ret = append(ret, o.Rendered)
+			}
+		}
+		return ret
+	}
+
+	var embeddedDiffs []types.EmbeddedDiffItemConfig
+	if b.Deployment != nil {
+		ignoreForDiffs = append(append([]types.IgnoreForDiffItemConfig{}, b.Deployment.IgnoreForDiff...), ignoreForDiffs...)
+		embeddedDiffs = b.Deployment.EmbeddedDiff
+	}
+
+	objects, err := diff.DiffObjectSets(collectRendered(a), collectRendered(b), func(o *uo.UnstructuredObject) ([]types.IgnoreForDiffItemConfig, []types.EmbeddedDiffItemConfig) {
+		return ignoreForDiffs, embeddedDiffs
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff command results %s and %s: %w", a.Id, b.Id, err)
+	}
+
+	return &result.CommandResult{
+		ProjectKey:  b.ProjectKey,
+		TargetKey:   b.TargetKey,
+		Target:      b.Target,
+		GitInfo:     b.GitInfo,
+		ClusterInfo: b.ClusterInfo,
+		Command: result.CommandInfo{
+			StartTime: startTime,
+			EndTime:   metav1.Now(),
+			Command:   "results-diff",
+		},
+		Objects: objects,
+	}, nil
+}
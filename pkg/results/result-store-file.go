@@ -0,0 +1,200 @@
+package results
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const fileStoreSummaryName = "summary.json"
+
+// ResultStoreFile stores results in a local directory. Each result is stored in its own sub-directory, which is
+// written to a temporary location first and then renamed, so that concurrent readers never see partial results.
+type ResultStoreFile struct {
+	*resultStoreLocal
+}
+
+type fileResultStorage struct {
+	dir string
+}
+
+func NewResultStoreFile(ctx context.Context, dir string, allowWrite bool, retention *Retention) (*ResultStoreFile, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("missing directory for file result store")
+	}
+	if allowWrite {
+		for _, kind := range []string{localKindCommandResult, localKindValidateResult} {
+			err := os.MkdirAll(filepath.Join(dir, kind), 0o700)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	storage := &fileResultStorage{dir: dir}
+	return &ResultStoreFile{
+		resultStoreLocal: newResultStoreLocal(ctx, storage, allowWrite, retention),
+	}, nil
+}
+
+func (s *fileResultStorage) checkId(id string) error {
+	if id == "" || strings.HasPrefix(id, ".") || strings.ContainsAny(id, `/\`) {
+		return fmt.Errorf("invalid result id '%s'", id)
+	}
+	return nil
+}
+
+func (s *fileResultStorage) writeEntry(kind string, id string, summary []byte, parts map[string][]byte) error {
+	err := s.checkId(id)
+	if err != nil {
+		return err
+	}
+
+	kindDir := filepath.Join(s.dir, kind)
+	err = os.MkdirAll(kindDir, 0o700)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp(kindDir, ".tmp-"+id+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for name, data := range parts {
+		err = os.WriteFile(filepath.Join(tmpDir, name+".json.gz"), data, 0o600)
+		if err != nil {
+			return err
+		}
+	}
+	// the summary is written last, as its existence marks the entry as complete
+	err = os.WriteFile(filepath.Join(tmpDir, fileStoreSummaryName), summary, 0o600)
+	if err != nil {
+		return err
+	}
+
+	entryDir := filepath.Join(kindDir, id)
+	err = os.RemoveAll(entryDir)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpDir, entryDir)
+}
+
+func (s *fileResultStorage) deleteEntry(kind string, id string) error {
+	err := s.checkId(id)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(s.dir, kind, id))
+}
+
+func (s *fileResultStorage) listSummaries(kind string) (map[string]localSummary, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, kind))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]localSummary{}, nil
+		}
+		return nil, err
+	}
+
+	ret := make(map[string]localSummary, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		entryDir := filepath.Join(s.dir, kind, e.Name())
+		b, err := os.ReadFile(filepath.Join(entryDir, fileStoreSummaryName))
+		if err != nil {
+			// deleted in-between or incomplete
+			continue
+		}
+		ret[e.Name()] = localSummary{
+			summary: b,
+			size:    s.dirSize(entryDir),
+		}
+	}
+	return ret, nil
+}
+
+func (s *fileResultStorage) listVersions(kind string) (map[string]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, kind))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+
+	ret := make(map[string]string, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		st, err := os.Stat(filepath.Join(s.dir, kind, e.Name(), fileStoreSummaryName))
+		if err != nil {
+			// deleted in-between or incomplete
+			continue
+		}
+		// entries are always written to a new directory, so the summary's modification time changes on every write
+		ret[e.Name()] = fmt.Sprintf("%d-%d", st.ModTime().UnixNano(), st.Size())
+	}
+	return ret, nil
+}
+
+func (s *fileResultStorage) readSummary(kind string, id string) (localSummary, bool, error) {
+	err := s.checkId(id)
+	if err != nil {
+		return localSummary{}, false, err
+	}
+	entryDir := filepath.Join(s.dir, kind, id)
+	b, err := os.ReadFile(filepath.Join(entryDir, fileStoreSummaryName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return localSummary{}, false, nil
+		}
+		return localSummary{}, false, err
+	}
+	return localSummary{
+		summary: b,
+		size:    s.dirSize(entryDir),
+	}, true, nil
+}
+
+func (s *fileResultStorage) dirSize(dir string) int64 {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	var size int64
+	for _, f := range files {
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		size += info.Size()
+	}
+	return size
+}
+
+func (s *fileResultStorage) readPart(kind string, id string, part string) ([]byte, bool, error) {
+	err := s.checkId(id)
+	if err != nil {
+		return nil, false, err
+	}
+	b, err := os.ReadFile(filepath.Join(s.dir, kind, id, part+".json.gz"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return b, true, nil
+}
+
+func (s *fileResultStorage) close() error {
+	return nil
+}
@@ -0,0 +1,557 @@
+package results
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/lib/status"
+	"github.com/kluctl/kluctl/lib/yaml"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	localKindCommandResult  = "command-results"
+	localKindValidateResult = "validate-results"
+
+	localPartReducedResult    = "reduced-result"
+	localPartCompactedObjects = "compacted-objects"
+	localPartResult           = "result"
+)
+
+const localStorePollInterval = 5 * time.Second
+
+// localResultStorage is the storage backend used by resultStoreLocal. Each entry is identified by kind and id and
+// consists of a json summary and multiple named (compressed) parts.
+type localResultStorage interface {
+	writeEntry(kind string, id string, summary []byte, parts map[string][]byte) error
+	deleteEntry(kind string, id string) error
+	listSummaries(kind string) (map[string]localSummary, error)
+	// listVersions returns an opaque version for every entry, which changes whenever the entry is re-written
+	listVersions(kind string) (map[string]string, error)
+	readSummary(kind string, id string) (localSummary, bool, error)
+	readPart(kind string, id string, part string) ([]byte, bool, error)
+	close() error
+}
+
+type localSummary struct {
+	summary []byte
+	// size is the total size of the summary and all parts
+	size int64
+}
+
+// resultStoreLocal implements ResultStore on top of a localResultStorage. Watches are implemented by polling the
+// versions of all entries, as other processes might write to the same storage. Only new or modified summaries are
+// read while polling.
+type resultStoreLocal struct {
+	ctx     context.Context
+	storage localResultStorage
+
+	allowWrite bool
+	retention  *Retention
+
+	mutex    sync.Mutex
+	watchers map[int]chan struct{}
+	nextId   int
+}
+
+func newResultStoreLocal(ctx context.Context, storage localResultStorage, allowWrite bool, retention *Retention) *resultStoreLocal {
+	s := &resultStoreLocal{
+		ctx:        ctx,
+		storage:    storage,
+		allowWrite: allowWrite,
+		retention:  retention,
+		watchers:   map[int]chan struct{}{},
+	}
+	go func() {
+		<-ctx.Done()
+		_ = storage.close()
+	}()
+	return s
+}
+
+// notifyWatchers triggers an immediate poll in all watchers of this process
+func (s *resultStoreLocal) notifyWatchers() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, ch := range s.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *resultStoreLocal) compressJson(o any) ([]byte, error) {
+	j, err := yaml.WriteJsonString(o)
+	if err != nil {
+		return nil, err
+	}
+	return utils.CompressGzip([]byte(j), gzip.BestCompression)
+}
+
+func (s *resultStoreLocal) readJsonPart(kind string, id string, part string, o any) (bool, error) {
+	b, ok, err := s.storage.readPart(kind, id, part)
+	if err != nil || !ok {
+		return false, err
+	}
+	b, err = utils.UncompressGzip(b)
+	if err != nil {
+		return false, err
+	}
+	err = yaml.ReadYamlBytes(b, o)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *resultStoreLocal) WriteCommandResult(cr *result.CommandResult) error {
+	if !s.allowWrite {
+		return fmt.Errorf("result store is read-only")
+	}
+
+	compressedCr, err := s.compressJson(cr.ToReducedObjects())
+	if err != nil {
+		return err
+	}
+	compressedObjects, err := s.compressJson(result.CompactedObjects(cr.Objects))
+	if err != nil {
+		return err
+	}
+	summaryJson, err := yaml.WriteJsonString(cr.BuildSummary())
+	if err != nil {
+		return err
+	}
+
+	err = s.storage.writeEntry(localKindCommandResult, cr.Id, []byte(summaryJson), map[string][]byte{
+		localPartReducedResult:    compressedCr,
+		localPartCompactedObjects: compressedObjects,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cleanupResults(cr.ProjectKey, cr.TargetKey)
+	s.notifyWatchers()
+	return nil
+}
+
+func (s *resultStoreLocal) WriteValidateResult(vr *result.ValidateResult) error {
+	if !s.allowWrite {
+		return fmt.Errorf("result store is read-only")
+	}
+
+	compressedVr, err := s.compressJson(vr)
+	if err != nil {
+		return err
+	}
+	summaryJson, err := yaml.WriteJsonString(vr.BuildSummary())
+	if err != nil {
+		return err
+	}
+
+	err = s.storage.writeEntry(localKindValidateResult, vr.Id, []byte(summaryJson), map[string][]byte{
+		localPartResult: compressedVr,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cleanupResults(vr.ProjectKey, vr.TargetKey)
+	s.notifyWatchers()
+	return nil
+}
+
+func (s *resultStoreLocal) DeleteCommandResult(rsId string) error {
+	if !s.allowWrite {
+		return fmt.Errorf("result store is read-only")
+	}
+	if rsId == "" {
+		return fmt.Errorf("empty rsId is not allowed")
+	}
+	err := s.storage.deleteEntry(localKindCommandResult, rsId)
+	if err != nil {
+		return err
+	}
+	s.notifyWatchers()
+	return nil
+}
+
+func (s *resultStoreLocal) DeleteValidateResult(vrId string) error {
+	if !s.allowWrite {
+		return fmt.Errorf("result store is read-only")
+	}
+	if vrId == "" {
+		return fmt.Errorf("empty vrId is not allowed")
+	}
+	_, ok, err := s.storage.readSummary(localKindValidateResult, vrId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("validate result %s: %w", vrId, ErrResultNotFound)
+	}
+	err = s.storage.deleteEntry(localKindValidateResult, vrId)
+	if err != nil {
+		return err
+	}
+	s.notifyWatchers()
+	return nil
+}
+
+// cleanupResults deletes all results that are expired according to the retention policies after a result for the
+// given project and target was written
+func (s *resultStoreLocal) cleanupResults(projectKey gittypes.ProjectKey, targetKey result.TargetKey) {
+	if s.retention == nil {
+		return
+	}
+
+	var entries []retentionEntry
+	for _, kind := range []string{localKindCommandResult, localKindValidateResult} {
+		m, err := s.storage.listSummaries(kind)
+		if err != nil {
+			status.Warningf(s.ctx, "Failed to list %s for cleanup: %s", kind, err)
+			return
+		}
+		for _, x := range m {
+			if kind == localKindCommandResult {
+				var summary result.CommandResultSummary
+				if yaml.ReadYamlBytes(x.summary, &summary) == nil {
+					entries = append(entries, newCommandRetentionEntry(&summary, x.size))
+				}
+			} else {
+				var summary result.ValidateResultSummary
+				if yaml.ReadYamlBytes(x.summary, &summary) == nil {
+					entries = append(entries, newValidateRetentionEntry(&summary, x.size))
+				}
+			}
+		}
+	}
+
+	for _, e := range s.retention.findExpired(entries, projectKey, targetKey, time.Now()) {
+		kind := localKindCommandResult
+		t := "command result"
+		if e.validate {
+			kind = localKindValidateResult
+			t = "validate result"
+		}
+		err := s.storage.deleteEntry(kind, e.id)
+		if err != nil {
+			status.Warningf(s.ctx, "Failed to delete old %s %s: %s", t, e.id, err)
+		} else {
+			status.Infof(s.ctx, "Deleted old %s %s", t, e.id)
+		}
+	}
+}
+
+func (s *resultStoreLocal) listCommandResultSummaries(options ListResultSummariesOptions) (map[string]result.CommandResultSummary, error) {
+	m, err := s.storage.listSummaries(localKindCommandResult)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]result.CommandResultSummary, len(m))
+	for id, x := range m {
+		var summary result.CommandResultSummary
+		err = yaml.ReadYamlBytes(x.summary, &summary)
+		if err != nil {
+			continue
+		}
+		if !FilterProject(summary.ProjectKey, options.ProjectFilter) {
+			continue
+		}
+		ret[id] = summary
+	}
+	return ret, nil
+}
+
+func (s *resultStoreLocal) listValidateResultSummaries(options ListResultSummariesOptions) (map[string]result.ValidateResultSummary, error) {
+	m, err := s.storage.listSummaries(localKindValidateResult)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]result.ValidateResultSummary, len(m))
+	for id, x := range m {
+		var summary result.ValidateResultSummary
+		err = yaml.ReadYamlBytes(x.summary, &summary)
+		if err != nil {
+			continue
+		}
+		if !FilterProject(summary.ProjectKey, options.ProjectFilter) {
+			continue
+		}
+		ret[id] = summary
+	}
+	return ret, nil
+}
+
+func (s *resultStoreLocal) ListCommandResultSummaries(options ListResultSummariesOptions) ([]result.CommandResultSummary, error) {
+	m, err := s.listCommandResultSummaries(options)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]result.CommandResultSummary, 0, len(m))
+	for _, x := range m {
+		ret = append(ret, x)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return lessCommandSummary(&ret[i], &ret[j])
+	})
+	return ret, nil
+}
+
+func (s *resultStoreLocal) ListValidateResultSummaries(options ListResultSummariesOptions) ([]result.ValidateResultSummary, error) {
+	m, err := s.listValidateResultSummaries(options)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]result.ValidateResultSummary, 0, len(m))
+	for _, x := range m {
+		ret = append(ret, x)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return lessValidateSummary(&ret[i], &ret[j])
+	})
+	return ret, nil
+}
+
+func (s *resultStoreLocal) GetCommandResult(options GetCommandResultOptions) (*result.CommandResult, error) {
+	var cr result.CommandResult
+	ok, err := s.readJsonPart(localKindCommandResult, options.Id, localPartReducedResult, &cr)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if !options.Reduced {
+		var objects result.CompactedObjects
+		ok, err = s.readJsonPart(localKindCommandResult, options.Id, localPartCompactedObjects, &objects)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s not present for %s", localPartCompactedObjects, options.Id)
+		}
+		cr.Objects = objects
+	}
+	return &cr, nil
+}
+
+func (s *resultStoreLocal) GetValidateResult(options GetValidateResultOptions) (*result.ValidateResult, error) {
+	var vr result.ValidateResult
+	ok, err := s.readJsonPart(localKindValidateResult, options.Id, localPartResult, &vr)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &vr, nil
+}
+
+// localSummaryPoller tracks the versions of all entries of one kind
+type localSummaryPoller struct {
+	storage  localResultStorage
+	kind     string
+	versions map[string]string
+}
+
+// poll returns the summaries of all entries that are new or were modified since the last call and the ids of all
+// entries that got deleted
+func (p *localSummaryPoller) poll() (map[string][]byte, []string, error) {
+	versions, err := p.storage.listVersions(p.kind)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changed := map[string][]byte{}
+	for id, v := range versions {
+		if old, ok := p.versions[id]; ok && old == v {
+			continue
+		}
+		x, ok, err := p.storage.readSummary(p.kind, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			// deleted in-between
+			delete(versions, id)
+			continue
+		}
+		changed[id] = x.summary
+	}
+
+	var deleted []string
+	for id := range p.versions {
+		if _, ok := versions[id]; !ok {
+			deleted = append(deleted, id)
+		}
+	}
+	p.versions = versions
+	return changed, deleted, nil
+}
+
+// startPolling calls poll initially, on every poll interval and whenever a result is written by this process. The
+// returned cancel function stops polling.
+func (s *resultStoreLocal) startPolling(poll func(ctx context.Context)) context.CancelFunc {
+	ctx, cancel := context.WithCancel(s.ctx)
+
+	notifyCh := make(chan struct{}, 1)
+	s.mutex.Lock()
+	id := s.nextId
+	s.nextId++
+	s.watchers[id] = notifyCh
+	s.mutex.Unlock()
+
+	go func() {
+		defer func() {
+			s.mutex.Lock()
+			delete(s.watchers, id)
+			s.mutex.Unlock()
+		}()
+
+		ticker := time.NewTicker(localStorePollInterval)
+		defer ticker.Stop()
+		for {
+			poll(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-notifyCh:
+			}
+		}
+	}()
+
+	return cancel
+}
+
+func (s *resultStoreLocal) WatchCommandResultSummaries(options ListResultSummariesOptions) (<-chan WatchCommandResultSummaryEvent, context.CancelFunc, error) {
+	// make sure the storage is accessible before we start polling
+	_, err := s.listCommandResultSummaries(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan WatchCommandResultSummaryEvent)
+	poller := &localSummaryPoller{storage: s.storage, kind: localKindCommandResult}
+	known := map[string]result.CommandResultSummary{}
+
+	send := func(ctx context.Context, e WatchCommandResultSummaryEvent) bool {
+		select {
+		case ch <- e:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	cancel := s.startPolling(func(ctx context.Context) {
+		changed, deleted, err := poller.poll()
+		if err != nil {
+			return
+		}
+		for id, b := range changed {
+			var summary result.CommandResultSummary
+			err = yaml.ReadYamlBytes(b, &summary)
+			if err != nil || !FilterProject(summary.ProjectKey, options.ProjectFilter) {
+				continue
+			}
+			if !send(ctx, WatchCommandResultSummaryEvent{Summary: &summary}) {
+				return
+			}
+			known[id] = summary
+		}
+		for _, id := range deleted {
+			summary, ok := known[id]
+			if !ok {
+				continue
+			}
+			if !send(ctx, WatchCommandResultSummaryEvent{Summary: &summary, Delete: true}) {
+				return
+			}
+			delete(known, id)
+		}
+	})
+
+	return ch, cancel, nil
+}
+
+func (s *resultStoreLocal) WatchValidateResultSummaries(options ListResultSummariesOptions) (<-chan WatchValidateResultSummaryEvent, context.CancelFunc, error) {
+	// make sure the storage is accessible before we start polling
+	_, err := s.listValidateResultSummaries(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan WatchValidateResultSummaryEvent)
+	poller := &localSummaryPoller{storage: s.storage, kind: localKindValidateResult}
+	known := map[string]result.ValidateResultSummary{}
+
+	send := func(ctx context.Context, e WatchValidateResultSummaryEvent) bool {
+		select {
+		case ch <- e:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	cancel := s.startPolling(func(ctx context.Context) {
+		changed, deleted, err := poller.poll()
+		if err != nil {
+			return
+		}
+		for id, b := range changed {
+			var summary result.ValidateResultSummary
+			err = yaml.ReadYamlBytes(b, &summary)
+			if err != nil || !FilterProject(summary.ProjectKey, options.ProjectFilter) {
+				continue
+			}
+			if !send(ctx, WatchValidateResultSummaryEvent{Summary: &summary}) {
+				return
+			}
+			known[id] = summary
+		}
+		for _, id := range deleted {
+			summary, ok := known[id]
+			if !ok {
+				continue
+			}
+			if !send(ctx, WatchValidateResultSummaryEvent{Summary: &summary, Delete: true}) {
+				return
+			}
+			delete(known, id)
+		}
+	})
+
+	return ch, cancel, nil
+}
+
+// ListKluctlDeployments always returns an empty list, as KluctlDeployments only exist inside clusters
+func (s *resultStoreLocal) ListKluctlDeployments() ([]WatchKluctlDeploymentEvent, error) {
+	return []WatchKluctlDeploymentEvent{}, nil
+}
+
+// WatchKluctlDeployments returns a channel that never receives events, as KluctlDeployments only exist inside
+// clusters
+func (s *resultStoreLocal) WatchKluctlDeployments() (<-chan WatchKluctlDeploymentEvent, context.CancelFunc, error) {
+	ch := make(chan WatchKluctlDeploymentEvent)
+	return ch, func() {}, nil
+}
+
+func (s *resultStoreLocal) GetKluctlDeployment(clusterId string, name string, namespace string) (*kluctlv1.KluctlDeployment, error) {
+	return nil, fmt.Errorf("KluctlDeployments are not supported by local result stores")
+}
+
+// NewResultStoreFromUrl creates a local result store from the given url. Supported are 'file://<dir>' and
+// 'sqlite://<file>'.
+func NewResultStoreFromUrl(ctx context.Context, url string, allowWrite bool, retention *Retention) (ResultStore, error) {
+	if p, ok := strings.CutPrefix(url, "file://"); ok {
+		return NewResultStoreFile(ctx, p, allowWrite, retention)
+	} else if p, ok := strings.CutPrefix(url, "sqlite://"); ok {
+		return NewResultStoreSqlite(ctx, p, allowWrite, retention)
+	}
+	return nil, fmt.Errorf("unsupported result store url '%s'", url)
+}
@@ -0,0 +1,262 @@
+package results
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func buildTestCommandResult(id string, startTime time.Time) *result.CommandResult {
+	o := uo.New()
+	o.SetK8sGVKs("", "v1", "ConfigMap")
+	o.SetK8sName("cm")
+	o.SetK8sNamespace("default")
+	_ = o.SetNestedField("v", "data", "k")
+
+	return &result.CommandResult{
+		Id: id,
+		TargetKey: result.TargetKey{
+			TargetName: "test",
+		},
+		Command: result.CommandInfo{
+			Initiator: result.CommandInititiator_CommandLine,
+			Command:   "deploy",
+			StartTime: metav1.NewTime(startTime),
+			EndTime:   metav1.NewTime(startTime.Add(time.Second)),
+		},
+		Objects: []result.ResultObject{
+			{
+				BaseObject: result.BaseObject{Ref: k8s.ObjectRef{Version: "v1", Kind: "ConfigMap", Name: "cm", Namespace: "default"}},
+				Rendered:   o,
+			},
+		},
+	}
+}
+
+func testLocalResultStore(t *testing.T, s ResultStore) {
+	now := time.Now().Truncate(time.Second)
+
+	ch, cancel, err := s.WatchCommandResultSummaries(ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	defer cancel()
+
+	err = s.WriteCommandResult(buildTestCommandResult("id1", now))
+	assert.NoError(t, err)
+	err = s.WriteCommandResult(buildTestCommandResult("id2", now.Add(time.Minute)))
+	assert.NoError(t, err)
+
+	waitEvent := func() WatchCommandResultSummaryEvent {
+		select {
+		case e := <-ch:
+			return e
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timeout while waiting for watch event")
+		}
+		return WatchCommandResultSummaryEvent{}
+	}
+
+	seen := map[string]bool{}
+	for len(seen) < 2 {
+		e := waitEvent()
+		assert.False(t, e.Delete)
+		seen[e.Summary.Id] = true
+	}
+	assert.Equal(t, map[string]bool{"id1": true, "id2": true}, seen)
+
+	l, err := s.ListCommandResultSummaries(ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, l, 2)
+	assert.Equal(t, "id2", l[0].Id)
+	assert.Equal(t, "id1", l[1].Id)
+
+	cr, err := s.GetCommandResult(GetCommandResultOptions{Id: "id1"})
+	assert.NoError(t, err)
+	assert.NotNil(t, cr)
+	assert.Equal(t, "deploy", cr.Command.Command)
+	assert.Len(t, cr.Objects, 1)
+	assert.Equal(t, "cm", cr.Objects[0].Rendered.GetK8sName())
+	assert.Contains(t, cr.Objects[0].Rendered.Object, "data")
+
+	cr, err = s.GetCommandResult(GetCommandResultOptions{Id: "id1", Reduced: true})
+	assert.NoError(t, err)
+	assert.NotNil(t, cr)
+	assert.Len(t, cr.Objects, 1)
+	assert.NotContains(t, cr.Objects[0].Rendered.Object, "data")
+
+	cr, err = s.GetCommandResult(GetCommandResultOptions{Id: "missing"})
+	assert.NoError(t, err)
+	assert.Nil(t, cr)
+
+	// keepCommandResultsCount is 2, so this must delete id1
+	err = s.WriteCommandResult(buildTestCommandResult("id3", now.Add(2*time.Minute)))
+	assert.NoError(t, err)
+
+	gotDelete := false
+	gotId3 := false
+	for !gotDelete || !gotId3 {
+		e := waitEvent()
+		if e.Delete {
+			assert.Equal(t, "id1", e.Summary.Id)
+			gotDelete = true
+		} else {
+			assert.Equal(t, "id3", e.Summary.Id)
+			gotId3 = true
+		}
+	}
+
+	err = s.DeleteCommandResult("id2")
+	assert.NoError(t, err)
+	e := waitEvent()
+	assert.True(t, e.Delete)
+	assert.Equal(t, "id2", e.Summary.Id)
+
+	l, err = s.ListCommandResultSummaries(ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, l, 1)
+	assert.Equal(t, "id3", l[0].Id)
+
+	vr := &result.ValidateResult{
+		Id:        "vr1",
+		StartTime: metav1.NewTime(now),
+		EndTime:   metav1.NewTime(now),
+		Ready:     true,
+	}
+	err = s.WriteValidateResult(vr)
+	assert.NoError(t, err)
+	vl, err := s.ListValidateResultSummaries(ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, vl, 1)
+	vr2, err := s.GetValidateResult(GetValidateResultOptions{Id: "vr1"})
+	assert.NoError(t, err)
+	assert.NotNil(t, vr2)
+	assert.True(t, vr2.Ready)
+
+	err = s.DeleteValidateResult("vr1")
+	assert.NoError(t, err)
+	vl, err = s.ListValidateResultSummaries(ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, vl, 0)
+
+	err = s.DeleteValidateResult("vr1")
+	assert.ErrorIs(t, err, ErrResultNotFound)
+
+	kds, err := s.ListKluctlDeployments()
+	assert.NoError(t, err)
+	assert.Empty(t, kds)
+}
+
+func TestResultStoreFile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	s, err := NewResultStoreFile(ctx, dir, true, NewRetention(nil, 2, 2))
+	assert.NoError(t, err)
+	testLocalResultStore(t, s)
+
+	// a read-only store on the same directory must see the results written by the other store
+	ro, err := NewResultStoreFromUrl(ctx, "file://"+dir, false, nil)
+	assert.NoError(t, err)
+	l, err := ro.ListCommandResultSummaries(ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, l, 1)
+	assert.Error(t, ro.WriteCommandResult(buildTestCommandResult("id4", time.Now())))
+}
+
+func TestResultStoreSqlite(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// characters that have a special meaning in URIs must not break the DSN
+	p := filepath.Join(t.TempDir(), "a b?c#d%e", "results.db")
+	err := os.MkdirAll(filepath.Dir(p), 0o700)
+	assert.NoError(t, err)
+
+	s, err := NewResultStoreSqlite(ctx, p, true, NewRetention(nil, 2, 2))
+	assert.NoError(t, err)
+	testLocalResultStore(t, s)
+	assert.FileExists(t, p)
+
+	ro, err := NewResultStoreFromUrl(ctx, "sqlite://"+p, false, nil)
+	assert.NoError(t, err)
+	l, err := ro.ListCommandResultSummaries(ListResultSummariesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, l, 1)
+	assert.Error(t, ro.WriteCommandResult(buildTestCommandResult("id4", time.Now())))
+}
+
+func testLocalSummaryPoller(t *testing.T, storage localResultStorage) {
+	poller := &localSummaryPoller{storage: storage, kind: localKindCommandResult}
+
+	changed, deleted, err := poller.poll()
+	assert.NoError(t, err)
+	assert.Empty(t, changed)
+	assert.Empty(t, deleted)
+
+	assert.NoError(t, storage.writeEntry(localKindCommandResult, "id1", []byte("s1"), map[string][]byte{"p": []byte("x")}))
+	assert.NoError(t, storage.writeEntry(localKindCommandResult, "id2", []byte("s2"), nil))
+	assert.NoError(t, storage.writeEntry(localKindValidateResult, "vr1", []byte("v1"), nil))
+
+	changed, deleted, err = poller.poll()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"id1": []byte("s1"), "id2": []byte("s2")}, changed)
+	assert.Empty(t, deleted)
+
+	// nothing changed, so nothing must be read
+	changed, deleted, err = poller.poll()
+	assert.NoError(t, err)
+	assert.Empty(t, changed)
+	assert.Empty(t, deleted)
+
+	assert.NoError(t, storage.writeEntry(localKindCommandResult, "id2", []byte("s2-modified"), nil))
+	assert.NoError(t, storage.deleteEntry(localKindCommandResult, "id1"))
+
+	changed, deleted, err = poller.poll()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"id2": []byte("s2-modified")}, changed)
+	assert.Equal(t, []string{"id1"}, deleted)
+
+	x, ok, err := storage.readSummary(localKindCommandResult, "id2")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("s2-modified"), x.summary)
+
+	_, ok, err = storage.readSummary(localKindCommandResult, "id1")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestLocalSummaryPollerFile(t *testing.T) {
+	testLocalSummaryPoller(t, &fileResultStorage{dir: t.TempDir()})
+}
+
+func TestLocalSummaryPollerSqlite(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s, err := NewResultStoreSqlite(ctx, filepath.Join(t.TempDir(), "results.db"), true, nil)
+	assert.NoError(t, err)
+	testLocalSummaryPoller(t, s.storage)
+}
+
+func TestBuildSqliteDsn(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses unix paths")
+	}
+
+	dsn, err := buildSqliteDsn("/tmp/a b?c#d.db", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "file:///tmp/a%20b%3Fc%23d.db?_pragma=busy_timeout%2810000%29&_pragma=journal_mode%28WAL%29", dsn)
+
+	dsn, err = buildSqliteDsn("/tmp/results.db", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "file:///tmp/results.db?_pragma=busy_timeout%2810000%29&mode=ro", dsn)
+}
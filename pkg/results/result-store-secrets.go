@@ -25,8 +25,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type ResultStoreSecrets struct {
@@ -37,15 +39,14 @@ type ResultStoreSecrets struct {
 	validateResultsCache cache.Cache
 	clusterId            string
 
-	allowWrite               bool
-	writeNamespace           string
-	keepCommandResultsCount  int
-	keepValidateResultsCount int
+	allowWrite     bool
+	writeNamespace string
+	retention      *Retention
 
 	mutex sync.Mutex
 }
 
-func NewResultStoreSecrets(ctx context.Context, config *rest.Config, client_ client.Client, allowWrite bool, writeNamespace string, keepCommandResultsCount int, keepValidateResultsCount int) (*ResultStoreSecrets, error) {
+func NewResultStoreSecrets(ctx context.Context, config *rest.Config, client_ client.Client, allowWrite bool, writeNamespace string, retention *Retention) (*ResultStoreSecrets, error) {
 	clusterId, err := k8s.GetClusterId(ctx, client_)
 	if err != nil {
 		return nil, err
@@ -73,14 +74,13 @@ func NewResultStoreSecrets(ctx context.Context, config *rest.Config, client_ cli
 	c1.WaitForCacheSync(ctx)
 
 	s := &ResultStoreSecrets{
-		ctx:                      ctx,
-		client:                   client_,
-		allowWrite:               allowWrite,
-		writeNamespace:           writeNamespace,
-		cache:                    c1,
-		clusterId:                clusterId,
-		keepCommandResultsCount:  keepCommandResultsCount,
-		keepValidateResultsCount: keepValidateResultsCount,
+		ctx:            ctx,
+		client:         client_,
+		allowWrite:     allowWrite,
+		writeNamespace: writeNamespace,
+		cache:          c1,
+		clusterId:      clusterId,
+		retention:      retention,
 	}
 
 	return s, nil
@@ -188,6 +188,7 @@ func (s *ResultStoreSecrets) WriteCommandResult(cr *result.CommandResult) error
 			},
 			Annotations: map[string]string{
 				"kluctl.io/command-result-summary": summaryJson,
+				"kluctl.io/result-size":            strconv.Itoa(len(compressedCr) + len(compressedObjects) + len(summaryJson)),
 			},
 		},
 		Data: map[string][]byte{
@@ -211,7 +212,7 @@ func (s *ResultStoreSecrets) WriteCommandResult(cr *result.CommandResult) error
 		return err
 	}
 
-	err = s.cleanupOldCommandResults(cr.ProjectKey, cr.TargetKey)
+	err = s.cleanupResults(cr.ProjectKey, cr.TargetKey)
 	if err != nil {
 		return err
 	}
@@ -239,6 +240,35 @@ func (s *ResultStoreSecrets) DeleteCommandResult(rsId string) error {
 	return nil
 }
 
+func (s *ResultStoreSecrets) DeleteValidateResult(vrId string) error {
+	if !s.allowWrite {
+		return fmt.Errorf("result store is read-only")
+	}
+	if vrId == "" {
+		return fmt.Errorf("empty vrId is not allowed")
+	}
+
+	// results are listed from all namespaces, so we must also search all namespaces when deleting
+	var l metav1.PartialObjectMetadataList
+	l.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("SecretList"))
+	err := s.client.List(s.ctx, &l, client.MatchingLabels{
+		"kluctl.io/validate-result-id": vrId,
+	})
+	if err != nil {
+		return err
+	}
+	if len(l.Items) == 0 {
+		return fmt.Errorf("validate result %s: %w", vrId, ErrResultNotFound)
+	}
+	for _, x := range l.Items {
+		err = s.client.Delete(s.ctx, &x)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *ResultStoreSecrets) WriteValidateResult(vr *result.ValidateResult) error {
 	if !s.allowWrite {
 		return fmt.Errorf("result store is read-only")
@@ -278,6 +308,7 @@ func (s *ResultStoreSecrets) WriteValidateResult(vr *result.ValidateResult) erro
 			},
 			Annotations: map[string]string{
 				"kluctl.io/validate-result-summary": summaryJson,
+				"kluctl.io/result-size":             strconv.Itoa(len(compressedVr) + len(summaryJson)),
 			},
 		},
 		Data: map[string][]byte{
@@ -300,7 +331,7 @@ func (s *ResultStoreSecrets) WriteValidateResult(vr *result.ValidateResult) erro
 		return err
 	}
 
-	err = s.cleanupValidateResults(vr.ProjectKey, vr.TargetKey)
+	err = s.cleanupResults(vr.ProjectKey, vr.TargetKey)
 	if err != nil {
 		return err
 	}
@@ -308,14 +339,24 @@ func (s *ResultStoreSecrets) WriteValidateResult(vr *result.ValidateResult) erro
 	return nil
 }
 
-func (s *ResultStoreSecrets) cleanupOldCommandResults(project gittypes.ProjectKey, target result.TargetKey) error {
+// cleanupResults deletes all results from the write namespace that are expired according to the retention policies
+// after a result for the given project and target was written
+func (s *ResultStoreSecrets) cleanupResults(projectKey gittypes.ProjectKey, targetKey result.TargetKey) error {
 	if !s.allowWrite {
 		return fmt.Errorf("result store is read-only")
 	}
+	if s.retention == nil {
+		return nil
+	}
 
-	results, err := s.ListCommandResultSummaries(ListResultSummariesOptions{
-		ProjectFilter: &project,
-	})
+	commandResults, err := s.doListCommandResultSummaries(ListResultSummariesOptions{})
+	if err != nil {
+		if errors.IsForbidden(err) {
+			return nil
+		}
+		return err
+	}
+	validateResults, err := s.doListValidateResultSummaries(ListResultSummariesOptions{})
 	if err != nil {
 		if errors.IsForbidden(err) {
 			return nil
@@ -323,22 +364,32 @@ func (s *ResultStoreSecrets) cleanupOldCommandResults(project gittypes.ProjectKe
 		return err
 	}
 
-	cnt := 0
-	for _, rs := range results {
-		if rs.TargetKey != target {
-			continue
+	var entries []retentionEntry
+	for _, e := range commandResults {
+		if e.name.Namespace == s.writeNamespace {
+			entries = append(entries, newCommandRetentionEntry(&e.summary, e.size))
+		}
+	}
+	for _, e := range validateResults {
+		if e.name.Namespace == s.writeNamespace {
+			entries = append(entries, newValidateRetentionEntry(&e.summary, e.size))
 		}
-		cnt++
+	}
 
-		if cnt > s.keepCommandResultsCount {
-			err := s.client.DeleteAllOf(s.ctx, &corev1.Secret{}, client.InNamespace(s.writeNamespace), client.MatchingLabels{
-				"kluctl.io/command-result-id": rs.Id,
-			})
-			if err != nil {
-				status.Warningf(s.ctx, "Failed to delete old command result %s: %s", rs.Id, err)
-			} else {
-				status.Infof(s.ctx, "Deleted old command result %s", rs.Id)
-			}
+	for _, e := range s.retention.findExpired(entries, projectKey, targetKey, time.Now()) {
+		idLabel := "kluctl.io/command-result-id"
+		t := "command result"
+		if e.validate {
+			idLabel = "kluctl.io/validate-result-id"
+			t = "validate result"
+		}
+		err := s.client.DeleteAllOf(s.ctx, &corev1.Secret{}, client.InNamespace(s.writeNamespace), client.MatchingLabels{
+			idLabel: e.id,
+		})
+		if err != nil {
+			status.Warningf(s.ctx, "Failed to delete old %s %s: %s", t, e.id, err)
+		} else {
+			status.Infof(s.ctx, "Deleted old %s %s", t, e.id)
 		}
 	}
 	return nil
@@ -428,43 +479,10 @@ func (s *ResultStoreSecrets) StartCleanupOrphans() error {
 	return nil
 }
 
-func (s *ResultStoreSecrets) cleanupValidateResults(project gittypes.ProjectKey, target result.TargetKey) error {
-	results, err := s.ListValidateResultSummaries(ListResultSummariesOptions{
-		ProjectFilter: &project,
-	})
-	if err != nil {
-		if errors.IsForbidden(err) {
-			return nil
-		}
-		return err
-	}
-
-	cnt := 0
-	for _, rs := range results {
-		rs := rs
-
-		if rs.TargetKey != target {
-			continue
-		}
-		cnt++
-
-		if cnt > s.keepValidateResultsCount {
-			err := s.client.DeleteAllOf(s.ctx, &corev1.Secret{}, client.InNamespace(s.writeNamespace), client.MatchingLabels{
-				"kluctl.io/validate-result-id": rs.Id,
-			})
-			if err != nil {
-				status.Warningf(s.ctx, "Failed to delete old validate result %s: %s", rs.Id, err)
-			} else {
-				status.Infof(s.ctx, "Deleted old validate result %s", rs.Id)
-			}
-		}
-	}
-	return nil
-}
-
 type commandResultSummaryAndName struct {
 	name    client.ObjectKey
 	summary result.CommandResultSummary
+	size    int64
 }
 
 func (s *ResultStoreSecrets) doListCommandResultSummaries(options ListResultSummariesOptions) ([]commandResultSummaryAndName, error) {
@@ -489,6 +507,7 @@ func (s *ResultStoreSecrets) doListCommandResultSummaries(options ListResultSumm
 		ret = append(ret, commandResultSummaryAndName{
 			name:    client.ObjectKeyFromObject(&x),
 			summary: *summary,
+			size:    parseResultSize(x.GetAnnotations()),
 		})
 	}
 
@@ -531,6 +550,16 @@ func (s *ResultStoreSecrets) parseCommandSummary(a map[string]string) (*result.C
 	return &summary, nil
 }
 
+// parseResultSize returns the size of the result as written by WriteCommandResult/WriteValidateResult. Results
+// written by older versions have no size annotation, in which case 0 is returned.
+func parseResultSize(a map[string]string) int64 {
+	size, err := strconv.ParseInt(a["kluctl.io/result-size"], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
 func (s *ResultStoreSecrets) parseValidateSummary(a map[string]string) (*result.ValidateResultSummary, error) {
 	if len(a) == 0 {
 		return nil, nil
@@ -687,6 +716,7 @@ func (s *ResultStoreSecrets) GetCommandResult(options GetCommandResultOptions) (
 type validateResultSummaryAndName struct {
 	name    client.ObjectKey
 	summary result.ValidateResultSummary
+	size    int64
 }
 
 func (s *ResultStoreSecrets) doListValidateResultSummaries(options ListResultSummariesOptions) ([]validateResultSummaryAndName, error) {
@@ -711,6 +741,7 @@ func (s *ResultStoreSecrets) doListValidateResultSummaries(options ListResultSum
 		ret = append(ret, validateResultSummaryAndName{
 			name:    client.ObjectKeyFromObject(&x),
 			summary: *summary,
+			size:    parseResultSize(x.GetAnnotations()),
 		})
 	}
 
@@ -0,0 +1,212 @@
+package results
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+
+	_ "modernc.org/sqlite"
+)
+
+// ResultStoreSqlite stores results in an embedded SQLite database. It uses a pure Go SQLite driver, so it also works
+// with builds that have cgo disabled.
+type ResultStoreSqlite struct {
+	*resultStoreLocal
+}
+
+type sqliteResultStorage struct {
+	db *sql.DB
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS results (
+	kind TEXT NOT NULL,
+	id TEXT NOT NULL,
+	summary BLOB NOT NULL,
+	version INTEGER NOT NULL,
+	PRIMARY KEY (kind, id)
+);
+CREATE TABLE IF NOT EXISTS result_parts (
+	kind TEXT NOT NULL,
+	id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	data BLOB NOT NULL,
+	PRIMARY KEY (kind, id, name)
+);
+`
+
+func NewResultStoreSqlite(ctx context.Context, path string, allowWrite bool, retention *Retention) (*ResultStoreSqlite, error) {
+	if path == "" {
+		return nil, fmt.Errorf("missing path for sqlite result store")
+	}
+
+	dsn, err := buildSqliteDsn(path, allowWrite)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	err = db.PingContext(ctx)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to open sqlite result store %s: %w", path, err)
+	}
+	if allowWrite {
+		_, err = db.ExecContext(ctx, sqliteSchema)
+		if err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
+	storage := &sqliteResultStorage{db: db}
+	return &ResultStoreSqlite{
+		resultStoreLocal: newResultStoreLocal(ctx, storage, allowWrite, retention),
+	}, nil
+}
+
+// buildSqliteDsn builds a SQLite URI for the given path. The path is escaped, so that characters like '?' and '#' are
+// not interpreted as part of the URI.
+func buildSqliteDsn(path string, allowWrite bool) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	p := filepath.ToSlash(absPath)
+	if filepath.VolumeName(absPath) != "" {
+		// Windows paths must look like /C:/...
+		p = "/" + p
+	}
+
+	q := url.Values{}
+	q.Add("_pragma", "busy_timeout(10000)")
+	if allowWrite {
+		q.Add("_pragma", "journal_mode(WAL)")
+	} else {
+		q.Set("mode", "ro")
+	}
+	u := url.URL{
+		Scheme:   "file",
+		Path:     p,
+		RawQuery: q.Encode(),
+	}
+	return u.String(), nil
+}
+
+func (s *sqliteResultStorage) writeEntry(kind string, id string, summary []byte, parts map[string][]byte) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM result_parts WHERE kind = ? AND id = ?`, kind, id)
+	if err != nil {
+		return err
+	}
+	// the version is increased on every write, so that watchers only need to re-read modified summaries
+	_, err = tx.Exec(`INSERT OR REPLACE INTO results (kind, id, summary, version) VALUES (?, ?, ?, (SELECT COALESCE(MAX(version), 0) + 1 FROM results))`, kind, id, summary)
+	if err != nil {
+		return err
+	}
+	for name, data := range parts {
+		_, err = tx.Exec(`INSERT INTO result_parts (kind, id, name, data) VALUES (?, ?, ?, ?)`, kind, id, name, data)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteResultStorage) deleteEntry(kind string, id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM result_parts WHERE kind = ? AND id = ?`, kind, id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`DELETE FROM results WHERE kind = ? AND id = ?`, kind, id)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteResultStorage) listSummaries(kind string) (map[string]localSummary, error) {
+	rows, err := s.db.Query(`
+SELECT r.id, r.summary, length(r.summary) + COALESCE((SELECT SUM(length(p.data)) FROM result_parts p WHERE p.kind = r.kind AND p.id = r.id), 0)
+FROM results r WHERE r.kind = ?`, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := map[string]localSummary{}
+	for rows.Next() {
+		var id string
+		var x localSummary
+		err = rows.Scan(&id, &x.summary, &x.size)
+		if err != nil {
+			return nil, err
+		}
+		ret[id] = x
+	}
+	return ret, rows.Err()
+}
+
+func (s *sqliteResultStorage) listVersions(kind string) (map[string]string, error) {
+	rows, err := s.db.Query(`SELECT id, version FROM results WHERE kind = ?`, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := map[string]string{}
+	for rows.Next() {
+		var id string
+		var version int64
+		err = rows.Scan(&id, &version)
+		if err != nil {
+			return nil, err
+		}
+		ret[id] = strconv.FormatInt(version, 10)
+	}
+	return ret, rows.Err()
+}
+
+func (s *sqliteResultStorage) readSummary(kind string, id string) (localSummary, bool, error) {
+	var x localSummary
+	err := s.db.QueryRow(`
+SELECT r.summary, length(r.summary) + COALESCE((SELECT SUM(length(p.data)) FROM result_parts p WHERE p.kind = r.kind AND p.id = r.id), 0)
+FROM results r WHERE r.kind = ? AND r.id = ?`, kind, id).Scan(&x.summary, &x.size)
+	if err == sql.ErrNoRows {
+		return x, false, nil
+	} else if err != nil {
+		return x, false, err
+	}
+	return x, true, nil
+}
+
+func (s *sqliteResultStorage) readPart(kind string, id string, part string) ([]byte, bool, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM result_parts WHERE kind = ? AND id = ? AND name = ?`, kind, id, part).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (s *sqliteResultStorage) close() error {
+	return s.db.Close()
+}
@@ -2,11 +2,15 @@ package results
 
 import (
 	"context"
+	"errors"
 	gittypes "github.com/kluctl/kluctl/lib/git/types"
 	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 )
 
+// ErrResultNotFound is returned when a result to be deleted does not exist
+var ErrResultNotFound = errors.New("result not found")
+
 type ListResultSummariesOptions struct {
 	ProjectFilter *gittypes.ProjectKey `json:"projectFilter,omitempty"`
 }
@@ -40,6 +44,7 @@ type ResultStore interface {
 	WriteCommandResult(cr *result.CommandResult) error
 	WriteValidateResult(vr *result.ValidateResult) error
 	DeleteCommandResult(rsId string) error
+	DeleteValidateResult(vrId string) error
 
 	ListCommandResultSummaries(options ListResultSummariesOptions) ([]result.CommandResultSummary, error)
 	WatchCommandResultSummaries(options ListResultSummariesOptions) (<-chan WatchCommandResultSummaryEvent, context.CancelFunc, error)
@@ -251,6 +251,10 @@ func (rc *ResultsCollector) DeleteCommandResult(rsId string) error {
 	return fmt.Errorf("DeleteCommandResult is not supported in ResultsCollector")
 }
 
+func (rc *ResultsCollector) DeleteValidateResult(vrId string) error {
+	return fmt.Errorf("DeleteValidateResult is not supported in ResultsCollector")
+}
+
 func (rc *ResultsCollector) ListCommandResultSummaries(options ListResultSummariesOptions) ([]result.CommandResultSummary, error) {
 	rc.mutex.Lock()
 	defer rc.mutex.Unlock()
@@ -0,0 +1,288 @@
+package results
+
+import (
+	"fmt"
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	"github.com/kluctl/kluctl/lib/yaml"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sort"
+	"sync"
+	"time"
+)
+
+const retentionValidateCommand = "validate"
+
+// RetentionRule is a retention policy that applies to all results of projects matching Project
+type RetentionRule struct {
+	Project *gittypes.ProjectKey `json:"project,omitempty"`
+
+	kluctlv1.ResultRetentionPolicy `json:",inline"`
+}
+
+// RetentionConfig is the content of the file passed via --result-retention-file
+type RetentionConfig struct {
+	Rules []RetentionRule `json:"rules,omitempty"`
+
+	// MaxTotalSize specifies the maximum total size of all results inside the result store (or namespace in case of
+	// the Secrets based store). The oldest results are deleted first.
+	MaxTotalSize *resource.Quantity `json:"maxTotalSize,omitempty"`
+}
+
+func LoadRetentionConfig(path string) (*RetentionConfig, error) {
+	var config RetentionConfig
+	err := yaml.ReadYamlFile(path, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load result retention config: %w", err)
+	}
+	return &config, nil
+}
+
+// Retention decides which results are to be deleted from a result store. Policies are resolved in the following order:
+// 1. Policies of the KluctlDeployment that produced the result (see SetKluctlDeploymentPolicies)
+// 2. Rules of the RetentionConfig
+// 3. The default keep counts
+// Inside 1. and 2., policies with a matching command take precedence over policies without a command.
+// Results of KluctlDeployments for which no policies were set are never deleted, as their policies are unknown to this
+// process (e.g. the CLI or another controller shard).
+type Retention struct {
+	config                   RetentionConfig
+	keepCommandResultsCount  int
+	keepValidateResultsCount int
+
+	mutex              sync.Mutex
+	deploymentPolicies map[types.NamespacedName][]kluctlv1.ResultRetentionPolicy
+}
+
+func NewRetention(config *RetentionConfig, keepCommandResultsCount int, keepValidateResultsCount int) *Retention {
+	r := &Retention{
+		keepCommandResultsCount:  keepCommandResultsCount,
+		keepValidateResultsCount: keepValidateResultsCount,
+		deploymentPolicies:       map[types.NamespacedName][]kluctlv1.ResultRetentionPolicy{},
+	}
+	if config != nil {
+		r.config = *config
+	}
+	return r
+}
+
+// SetKluctlDeploymentPolicies sets the policies used for all results of the given KluctlDeployment. Empty policies
+// mean that the rules of the RetentionConfig and the default keep counts apply.
+func (r *Retention) SetKluctlDeploymentPolicies(name string, namespace string, policies []kluctlv1.ResultRetentionPolicy) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	key := types.NamespacedName{Name: name, Namespace: namespace}
+	if policies == nil {
+		policies = []kluctlv1.ResultRetentionPolicy{}
+	}
+	r.deploymentPolicies[key] = policies
+}
+
+// ForgetKluctlDeployment removes the policies of the given KluctlDeployment, meaning that its results are not deleted
+// anymore by this process
+func (r *Retention) ForgetKluctlDeployment(name string, namespace string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.deploymentPolicies, types.NamespacedName{Name: name, Namespace: namespace})
+}
+
+// retentionEntry is the store independent representation of a single command or validate result
+type retentionEntry struct {
+	id               string
+	projectKey       gittypes.ProjectKey
+	targetKey        result.TargetKey
+	command          string
+	validate         bool
+	kluctlDeployment *result.KluctlDeploymentInfo
+	startTime        time.Time
+	failed           bool
+	size             int64
+}
+
+func newCommandRetentionEntry(s *result.CommandResultSummary, size int64) retentionEntry {
+	return retentionEntry{
+		id:               s.Id,
+		projectKey:       s.ProjectKey,
+		targetKey:        s.TargetKey,
+		command:          s.Command.Command,
+		kluctlDeployment: s.KluctlDeployment,
+		startTime:        s.Command.StartTime.Time,
+		failed:           len(s.Errors) != 0,
+		size:             size,
+	}
+}
+
+func newValidateRetentionEntry(s *result.ValidateResultSummary, size int64) retentionEntry {
+	return retentionEntry{
+		id:               s.Id,
+		projectKey:       s.ProjectKey,
+		targetKey:        s.TargetKey,
+		command:          retentionValidateCommand,
+		validate:         true,
+		kluctlDeployment: s.KluctlDeployment,
+		startTime:        s.StartTime.Time,
+		failed:           s.Errors != 0 || !s.Ready,
+		size:             size,
+	}
+}
+
+func findRetentionPolicy(policies []kluctlv1.ResultRetentionPolicy, command string) (int, bool) {
+	for i, p := range policies {
+		if p.Command == command {
+			return i, true
+		}
+	}
+	for i, p := range policies {
+		if p.Command == "" {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// findPolicy returns the policy for the given entry and a key that identifies the policy. Entries with the same
+// project, target and policy key are counted together. false is returned if the entry was produced by a
+// KluctlDeployment with unknown policies.
+func (r *Retention) findPolicy(e *retentionEntry) (kluctlv1.ResultRetentionPolicy, string, bool) {
+	if e.kluctlDeployment != nil {
+		key := types.NamespacedName{Name: e.kluctlDeployment.Name, Namespace: e.kluctlDeployment.Namespace}
+		policies, ok := r.deploymentPolicies[key]
+		if !ok {
+			return kluctlv1.ResultRetentionPolicy{}, "", false
+		}
+		if i, ok := findRetentionPolicy(policies, e.command); ok {
+			return policies[i], fmt.Sprintf("deployment/%s/%d", key.String(), i), true
+		}
+	}
+
+	var rulePolicies []kluctlv1.ResultRetentionPolicy
+	var ruleIndexes []int
+	for i, rule := range r.config.Rules {
+		if !FilterProject(e.projectKey, rule.Project) {
+			continue
+		}
+		rulePolicies = append(rulePolicies, rule.ResultRetentionPolicy)
+		ruleIndexes = append(ruleIndexes, i)
+	}
+	if i, ok := findRetentionPolicy(rulePolicies, e.command); ok {
+		return rulePolicies[i], fmt.Sprintf("rule/%d", ruleIndexes[i]), true
+	}
+
+	if e.validate {
+		return kluctlv1.ResultRetentionPolicy{KeepCount: &r.keepValidateResultsCount}, "default/validate", true
+	}
+	return kluctlv1.ResultRetentionPolicy{KeepCount: &r.keepCommandResultsCount}, "default/command", true
+}
+
+// findExpired returns all entries that must be deleted after a result for the given project and target was written.
+// Keep counts and max ages are only applied to the results of this project and target, so that writers never apply
+// their policies to results of other projects and targets. MaxTotalSize is applied to all entries.
+func (r *Retention) findExpired(entries []retentionEntry, projectKey gittypes.ProjectKey, targetKey result.TargetKey, now time.Time) []retentionEntry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// validate results are always counted independently of command results
+	type groupKey struct {
+		projectKey gittypes.ProjectKey
+		targetKey  result.TargetKey
+		policyKey  string
+		validate   bool
+	}
+	type group struct {
+		policy  kluctlv1.ResultRetentionPolicy
+		entries []*retentionEntry
+	}
+
+	groups := map[groupKey]*group{}
+	unknown := map[*retentionEntry]bool{}
+	for i := range entries {
+		e := &entries[i]
+		policy, policyKey, ok := r.findPolicy(e)
+		if !ok {
+			unknown[e] = true
+			continue
+		}
+		k := groupKey{projectKey: e.projectKey, targetKey: e.targetKey, policyKey: policyKey, validate: e.validate}
+		g, ok := groups[k]
+		if !ok {
+			g = &group{policy: policy}
+			groups[k] = g
+		}
+		g.entries = append(g.entries, e)
+	}
+
+	protected := map[*retentionEntry]bool{}
+	expired := map[*retentionEntry]bool{}
+	for k, g := range groups {
+		inScope := k.projectKey == projectKey && k.targetKey == targetKey
+		sort.SliceStable(g.entries, func(i, j int) bool {
+			return g.entries[i].startTime.After(g.entries[j].startTime)
+		})
+
+		foundSuccessful := false
+		foundFailed := false
+		for i, e := range g.entries {
+			if !e.failed && !foundSuccessful {
+				foundSuccessful = true
+				if g.policy.KeepLastSuccessful {
+					protected[e] = true
+					continue
+				}
+			}
+			if e.failed && !foundFailed {
+				foundFailed = true
+				if g.policy.KeepLastFailed {
+					protected[e] = true
+					continue
+				}
+			}
+
+			if !inScope {
+				continue
+			}
+			if g.policy.KeepCount != nil && i >= *g.policy.KeepCount {
+				expired[e] = true
+			} else if g.policy.MaxAge != nil && now.Sub(e.startTime) > g.policy.MaxAge.Duration {
+				expired[e] = true
+			}
+		}
+	}
+
+	if r.config.MaxTotalSize != nil {
+		var remaining []*retentionEntry
+		var totalSize int64
+		for i := range entries {
+			e := &entries[i]
+			if expired[e] {
+				continue
+			}
+			remaining = append(remaining, e)
+			totalSize += e.size
+		}
+		// oldest first
+		sort.SliceStable(remaining, func(i, j int) bool {
+			return remaining[i].startTime.Before(remaining[j].startTime)
+		})
+		maxTotalSize := r.config.MaxTotalSize.Value()
+		for _, e := range remaining {
+			if totalSize <= maxTotalSize {
+				break
+			}
+			if protected[e] || unknown[e] {
+				continue
+			}
+			expired[e] = true
+			totalSize -= e.size
+		}
+	}
+
+	var ret []retentionEntry
+	for i := range entries {
+		if expired[&entries[i]] {
+			ret = append(ret, entries[i])
+		}
+	}
+	return ret
+}
@@ -0,0 +1,155 @@
+package results
+
+import (
+	gittypes "github.com/kluctl/kluctl/lib/git/types"
+	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+	"testing"
+	"time"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func expiredIds(entries []retentionEntry) []string {
+	var ret []string
+	for _, e := range entries {
+		ret = append(ret, e.id)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func TestRetentionDefaultKeepCount(t *testing.T) {
+	now := time.Now()
+	r := NewRetention(nil, 2, 1)
+
+	entries := []retentionEntry{
+		{id: "c1", command: "deploy", startTime: now.Add(-3 * time.Hour)},
+		{id: "c2", command: "diff", startTime: now.Add(-2 * time.Hour)},
+		{id: "c3", command: "deploy", startTime: now.Add(-1 * time.Hour)},
+		{id: "v1", command: retentionValidateCommand, validate: true, startTime: now.Add(-2 * time.Hour)},
+		{id: "v2", command: retentionValidateCommand, validate: true, startTime: now.Add(-1 * time.Hour)},
+		// other target
+		{id: "o1", command: "deploy", startTime: now.Add(-5 * time.Hour), targetKey: result.TargetKey{TargetName: "other"}},
+	}
+	assert.Equal(t, []string{"c1", "v1"}, expiredIds(r.findExpired(entries, gittypes.ProjectKey{}, result.TargetKey{}, now)))
+	// the other target has only one result, which is still within the keep count
+	assert.Empty(t, expiredIds(r.findExpired(entries, gittypes.ProjectKey{}, result.TargetKey{TargetName: "other"}, now)))
+}
+
+func TestRetentionRules(t *testing.T) {
+	now := time.Now()
+	repoKey, err := gittypes.ParseRepoKey("github.com/example/repo", "git")
+	assert.NoError(t, err)
+	project := gittypes.ProjectKey{RepoKey: repoKey}
+
+	r := NewRetention(&RetentionConfig{
+		Rules: []RetentionRule{
+			{
+				Project: &project,
+				ResultRetentionPolicy: kluctlv1.ResultRetentionPolicy{
+					Command: "diff",
+					MaxAge:  &metav1.Duration{Duration: time.Hour},
+				},
+			},
+			{
+				Project: &project,
+				ResultRetentionPolicy: kluctlv1.ResultRetentionPolicy{
+					MaxAge:             &metav1.Duration{Duration: 24 * time.Hour},
+					KeepLastSuccessful: true,
+					KeepLastFailed:     true,
+				},
+			},
+		},
+	}, 1, 1)
+
+	entries := []retentionEntry{
+		{id: "diff-old", projectKey: project, command: "diff", startTime: now.Add(-2 * time.Hour)},
+		{id: "diff-new", projectKey: project, command: "diff", startTime: now.Add(-30 * time.Minute)},
+		{id: "deploy-ok-old", projectKey: project, command: "deploy", startTime: now.Add(-72 * time.Hour)},
+		{id: "deploy-ok-last", projectKey: project, command: "deploy", startTime: now.Add(-48 * time.Hour)},
+		{id: "deploy-failed-last", projectKey: project, command: "deploy", failed: true, startTime: now.Add(-47 * time.Hour)},
+		{id: "deploy-recent", projectKey: project, command: "deploy", failed: true, startTime: now.Add(-time.Hour)},
+		// not matched by any rule, so the default keep count of 1 applies
+		{id: "unmatched-1", command: "deploy", startTime: now.Add(-2 * time.Hour)},
+		{id: "unmatched-2", command: "deploy", startTime: now.Add(-1 * time.Hour)},
+	}
+
+	// deploy-recent is the newest failed one, so deploy-failed-last is not protected
+	assert.Equal(t, []string{"deploy-failed-last", "deploy-ok-old", "diff-old"}, expiredIds(r.findExpired(entries, project, result.TargetKey{}, now)))
+	assert.Equal(t, []string{"unmatched-1"}, expiredIds(r.findExpired(entries, gittypes.ProjectKey{}, result.TargetKey{}, now)))
+}
+
+func TestRetentionKluctlDeploymentPolicies(t *testing.T) {
+	now := time.Now()
+	r := NewRetention(nil, 1, 1)
+	r.SetKluctlDeploymentPolicies("kd", "ns", []kluctlv1.ResultRetentionPolicy{
+		{KeepCount: intPtr(3)},
+	})
+
+	kd := &result.KluctlDeploymentInfo{Name: "kd", Namespace: "ns"}
+	entries := []retentionEntry{
+		{id: "1", command: "deploy", kluctlDeployment: kd, startTime: now.Add(-4 * time.Hour)},
+		{id: "2", command: "deploy", kluctlDeployment: kd, startTime: now.Add(-3 * time.Hour)},
+		{id: "3", command: "deploy", kluctlDeployment: kd, startTime: now.Add(-2 * time.Hour)},
+		{id: "4", command: "deploy", kluctlDeployment: kd, startTime: now.Add(-1 * time.Hour)},
+	}
+	assert.Equal(t, []string{"1"}, expiredIds(r.findExpired(entries, gittypes.ProjectKey{}, result.TargetKey{}, now)))
+
+	// no policies means that the default keep count applies
+	r.SetKluctlDeploymentPolicies("kd", "ns", nil)
+	assert.Equal(t, []string{"1", "2", "3"}, expiredIds(r.findExpired(entries, gittypes.ProjectKey{}, result.TargetKey{}, now)))
+
+	// the policies of unknown deployments are unknown as well, so their results must not be touched
+	r.ForgetKluctlDeployment("kd", "ns")
+	assert.Empty(t, expiredIds(r.findExpired(entries, gittypes.ProjectKey{}, result.TargetKey{}, now)))
+}
+
+func TestRetentionUnknownKluctlDeployment(t *testing.T) {
+	now := time.Now()
+	r := NewRetention(nil, 1, 1)
+	r.SetKluctlDeploymentPolicies("kd1", "ns", nil)
+
+	// e.g. results written by another controller shard or by a controller that got restarted
+	kd1 := &result.KluctlDeploymentInfo{Name: "kd1", Namespace: "ns"}
+	kd2 := &result.KluctlDeploymentInfo{Name: "kd2", Namespace: "ns"}
+	entries := []retentionEntry{
+		{id: "kd1-1", command: "deploy", kluctlDeployment: kd1, startTime: now.Add(-4 * time.Hour)},
+		{id: "kd1-2", command: "deploy", kluctlDeployment: kd1, startTime: now.Add(-3 * time.Hour)},
+		{id: "kd2-1", command: "deploy", kluctlDeployment: kd2, startTime: now.Add(-4 * time.Hour)},
+		{id: "kd2-2", command: "deploy", kluctlDeployment: kd2, startTime: now.Add(-3 * time.Hour)},
+		{id: "cli-1", command: "deploy", startTime: now.Add(-2 * time.Hour)},
+		{id: "cli-2", command: "deploy", startTime: now.Add(-1 * time.Hour)},
+	}
+	// kd1 has no own policies, so its results are counted together with the CLI results
+	assert.Equal(t, []string{"cli-1", "kd1-1", "kd1-2"}, expiredIds(r.findExpired(entries, gittypes.ProjectKey{}, result.TargetKey{}, now)))
+}
+
+func TestRetentionMaxTotalSize(t *testing.T) {
+	now := time.Now()
+	maxSize := resource.MustParse("250")
+	r := NewRetention(&RetentionConfig{
+		Rules: []RetentionRule{
+			{ResultRetentionPolicy: kluctlv1.ResultRetentionPolicy{KeepLastFailed: true}},
+		},
+		MaxTotalSize: &maxSize,
+	}, 10, 10)
+
+	entries := []retentionEntry{
+		{id: "1", command: "deploy", failed: true, size: 100, startTime: now.Add(-4 * time.Hour)},
+		{id: "2", command: "deploy", size: 100, startTime: now.Add(-3 * time.Hour)},
+		{id: "3", command: "deploy", size: 100, startTime: now.Add(-2 * time.Hour)},
+		{id: "4", command: "deploy", size: 100, startTime: now.Add(-1 * time.Hour)},
+	}
+	// 1 is protected as it is the last failed one, so the oldest unprotected ones are deleted instead
+	assert.Equal(t, []string{"2", "3"}, expiredIds(r.findExpired(entries, gittypes.ProjectKey{}, result.TargetKey{}, now)))
+
+	// the total size is applied to the results of other targets as well
+	assert.Equal(t, []string{"2", "3"}, expiredIds(r.findExpired(entries, gittypes.ProjectKey{}, result.TargetKey{TargetName: "other"}, now)))
+}
@@ -0,0 +1,323 @@
+package schemas
+
+import (
+	"compress/gzip"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//go:embed k8s/*.json.gz
+var bundledSchemas embed.FS
+
+const objectMetaDefinition = "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"
+
+// intOrStringDefinitions are represented as plain strings in OpenAPI documents, while the API server also accepts
+// numbers
+var intOrStringDefinitions = []string{
+	"io.k8s.apimachinery.pkg.api.resource.Quantity",
+	"io.k8s.apimachinery.pkg.util.intstr.IntOrString",
+}
+
+var ErrNoBundledSchemas = errors.New("no bundled schemas available")
+
+// Schemas holds OpenAPI schemas for built-in Kubernetes types and CRDs and allows to validate objects against these
+// schemas without the need for a live cluster.
+type Schemas struct {
+	definitions map[string]*apiextensionsv1.JSONSchemaProps
+	gvks        map[schema.GroupVersionKind]*apiextensionsv1.JSONSchemaProps
+
+	// K8sVersion is the version of the bundled schemas that were loaded
+	K8sVersion string
+
+	// Lookup is called for GroupVersionKinds that are not known otherwise, e.g. to retrieve CRD schemas from a live
+	// cluster.
+	Lookup func(gvk schema.GroupVersionKind) (*uo.UnstructuredObject, error)
+}
+
+type ValidationResult struct {
+	Found    bool
+	Errors   []string
+	Warnings []string
+}
+
+func NewSchemas() *Schemas {
+	return &Schemas{
+		definitions: map[string]*apiextensionsv1.JSONSchemaProps{},
+		gvks:        map[schema.GroupVersionKind]*apiextensionsv1.JSONSchemaProps{},
+	}
+}
+
+// ListBundledVersions returns the Kubernetes versions for which schemas are bundled, sorted ascending
+func ListBundledVersions() ([]*semver.Version, error) {
+	entries, err := bundledSchemas.ReadDir("k8s")
+	if err != nil {
+		return nil, err
+	}
+	var ret []*semver.Version
+	for _, e := range entries {
+		v, err := semver.NewVersion(strings.TrimSuffix(e.Name(), ".json.gz"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundled schema file %s: %w", e.Name(), err)
+		}
+		ret = append(ret, v)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].LessThan(ret[j])
+	})
+	return ret, nil
+}
+
+// LoadBundled loads the bundled schemas that match the minor version of the given Kubernetes version. An error
+// wrapping ErrNoBundledSchemas is returned if no schemas are bundled for this version. If k8sVersion is empty, the
+// newest version is used.
+func (s *Schemas) LoadBundled(k8sVersion string) error {
+	versions, err := ListBundledVersions()
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("no bundled schemas found")
+	}
+
+	v := versions[len(versions)-1]
+	if k8sVersion != "" {
+		requested, err := semver.NewVersion(k8sVersion)
+		if err != nil {
+			return fmt.Errorf("invalid Kubernetes version %s: %w", k8sVersion, err)
+		}
+		requestedMinor, _ := semver.NewVersion(fmt.Sprintf("%d.%d", requested.Major(), requested.Minor()))
+
+		v = nil
+		var available []string
+		for _, x := range versions {
+			if x.Equal(requestedMinor) {
+				v = x
+			}
+			available = append(available, fmt.Sprintf("%d.%d", x.Major(), x.Minor()))
+		}
+		if v == nil {
+			return fmt.Errorf("%w for Kubernetes %s, available versions are %s", ErrNoBundledSchemas, k8sVersion, strings.Join(available, ", "))
+		}
+	}
+
+	f, err := bundledSchemas.Open(fmt.Sprintf("k8s/%s.json.gz", v.Original()))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	var doc map[string]any
+	err = json.NewDecoder(gz).Decode(&doc)
+	if err != nil {
+		return err
+	}
+	err = s.addOpenAPIDocument(uo.FromMap(doc))
+	if err != nil {
+		return err
+	}
+	s.K8sVersion = fmt.Sprintf("%d.%d", v.Major(), v.Minor())
+	return nil
+}
+
+// LoadDir loads all CRDs and OpenAPI documents (swagger.json or OpenAPI v3) found in the given directory
+func (s *Schemas) LoadDir(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			return nil
+		}
+		docs, err := yaml.ReadYamlAllFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		for _, doc := range docs {
+			m, ok := doc.(map[string]any)
+			if !ok {
+				continue
+			}
+			o := uo.FromMap(m)
+			if isCRD(o) {
+				err = s.AddCRD(o)
+			} else {
+				err = s.addOpenAPIDocument(o)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to load schemas from %s: %w", path, err)
+			}
+		}
+		return nil
+	})
+}
+
+// AddCRDs adds all CRDs found in the given list of objects
+func (s *Schemas) AddCRDs(objects []*uo.UnstructuredObject) error {
+	for _, o := range objects {
+		if !isCRD(o) {
+			continue
+		}
+		err := s.AddCRD(o)
+		if err != nil {
+			return fmt.Errorf("failed to load schemas from CRD %s: %w", o.GetK8sName(), err)
+		}
+	}
+	return nil
+}
+
+func (s *Schemas) AddCRD(o *uo.UnstructuredObject) error {
+	var crd apiextensionsv1.CustomResourceDefinition
+	err := o.ToStruct(&crd)
+	if err != nil {
+		return err
+	}
+	for _, v := range crd.Spec.Versions {
+		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: v.Name, Kind: crd.Spec.Names.Kind}
+		s.gvks[gvk] = s.prepareCRDSchema(v.Schema.OpenAPIV3Schema)
+	}
+	return nil
+}
+
+// prepareCRDSchema adds the implicit apiVersion, kind and metadata fields to the root of the given CRD schema. metadata
+// is validated against ObjectMeta if available, which is what the API server does as well.
+func (s *Schemas) prepareCRDSchema(js *apiextensionsv1.JSONSchemaProps) *apiextensionsv1.JSONSchemaProps {
+	js = js.DeepCopy()
+	if js.Properties == nil {
+		js.Properties = map[string]apiextensionsv1.JSONSchemaProps{}
+	}
+	js.Properties["apiVersion"] = apiextensionsv1.JSONSchemaProps{Type: "string"}
+	js.Properties["kind"] = apiextensionsv1.JSONSchemaProps{Type: "string"}
+	if _, ok := s.definitions[objectMetaDefinition]; ok {
+		ref := "#/definitions/" + objectMetaDefinition
+		js.Properties["metadata"] = apiextensionsv1.JSONSchemaProps{Ref: &ref}
+	} else {
+		js.Properties["metadata"] = apiextensionsv1.JSONSchemaProps{Type: "object"}
+	}
+	return js
+}
+
+// AddOpenAPIDocument adds all definitions of the given OpenAPI document, e.g. the one served by a live cluster
+func (s *Schemas) AddOpenAPIDocument(o *uo.UnstructuredObject) error {
+	return s.addOpenAPIDocument(o)
+}
+
+func (s *Schemas) addOpenAPIDocument(o *uo.UnstructuredObject) error {
+	defs, ok, _ := o.GetNestedObject("definitions")
+	if !ok {
+		defs, ok, _ = o.GetNestedObject("components", "schemas")
+	}
+	if !ok {
+		return nil
+	}
+
+	for name, v := range defs.Object {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var js apiextensionsv1.JSONSchemaProps
+		err = json.Unmarshal(b, &js)
+		if err != nil {
+			return fmt.Errorf("failed to parse definition %s: %w", name, err)
+		}
+		var gvks struct {
+			GVKs []schema.GroupVersionKind `json:"x-kubernetes-group-version-kind"`
+		}
+		err = json.Unmarshal(b, &gvks)
+		if err != nil {
+			return fmt.Errorf("failed to parse definition %s: %w", name, err)
+		}
+
+		s.definitions[name] = &js
+		for _, gvk := range gvks.GVKs {
+			s.gvks[gvk] = &js
+		}
+	}
+
+	for _, name := range intOrStringDefinitions {
+		if _, ok := s.definitions[name]; ok {
+			s.definitions[name] = &apiextensionsv1.JSONSchemaProps{XIntOrString: true}
+		}
+	}
+	return nil
+}
+
+func (s *Schemas) getSchema(gvk schema.GroupVersionKind) (*apiextensionsv1.JSONSchemaProps, error) {
+	if js, ok := s.gvks[gvk]; ok {
+		return js, nil
+	}
+	if s.Lookup == nil {
+		return nil, nil
+	}
+
+	o, err := s.Lookup(gvk)
+	if err != nil {
+		return nil, err
+	}
+	var js apiextensionsv1.JSONSchemaProps
+	err = o.ToStruct(&js)
+	if err != nil {
+		return nil, err
+	}
+	ret := s.prepareCRDSchema(&js)
+	s.gvks[gvk] = ret
+	return ret, nil
+}
+
+// ValidateObject validates the given object against the schema matching its GroupVersionKind.
+func (s *Schemas) ValidateObject(o *uo.UnstructuredObject) ValidationResult {
+	var ret ValidationResult
+
+	gvk := o.GetK8sGVK()
+	js, err := s.getSchema(gvk)
+	if err != nil {
+		ret.Warnings = append(ret.Warnings, fmt.Sprintf("failed to retrieve schema for %s: %s", gvk.String(), err.Error()))
+		return ret
+	}
+	if js == nil {
+		return ret
+	}
+	ret.Found = true
+
+	v := validator{
+		definitions: s.definitions,
+	}
+	v.validate(nil, js, o.Object)
+
+	for _, e := range v.errs {
+		ret.Errors = append(ret.Errors, e.Error())
+	}
+	for _, e := range v.unknownFields {
+		ret.Errors = append(ret.Errors, e.Error())
+	}
+	sort.Strings(ret.Errors)
+	return ret
+}
+
+func isCRD(o *uo.UnstructuredObject) bool {
+	gvk := o.GetK8sGVK()
+	return gvk.Group == apiextensionsv1.GroupName && gvk.Kind == "CustomResourceDefinition"
+}
@@ -0,0 +1,191 @@
+package schemas
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/lib/yaml"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func parseObject(t *testing.T, s string) *uo.UnstructuredObject {
+	var m map[string]any
+	err := yaml.ReadYamlString(s, &m)
+	assert.NoError(t, err)
+	return uo.FromMap(m)
+}
+
+const testDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test
+  namespace: default
+spec:
+  replicas: %s
+  selector:
+    matchLabels:
+      app: test
+  template:
+    metadata:
+      labels:
+        app: test
+    spec:
+      containers:
+      - name: c
+        image: nginx
+        imagePullPolicy: %s
+        resources:
+          requests:
+            cpu: 1
+            memory: 100Mi
+        ports:
+        - containerPort: 80
+          %s: 1
+`
+
+const testCRD = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: tests.example.com
+spec:
+  group: example.com
+  names:
+    kind: Test
+    plural: tests
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required: ["mode"]
+            properties:
+              mode:
+                type: string
+                enum: ["a", "b"]
+              port:
+                x-kubernetes-int-or-string: true
+              extra:
+                type: object
+                x-kubernetes-preserve-unknown-fields: true
+`
+
+func TestValidateBuiltin(t *testing.T) {
+	s := NewSchemas()
+	err := s.LoadBundled("")
+	assert.NoError(t, err)
+
+	r := s.ValidateObject(parseObject(t, fmt.Sprintf(testDeployment, "1", "Always", "hostPort")))
+	assert.True(t, r.Found)
+	assert.Empty(t, r.Errors)
+
+	r = s.ValidateObject(parseObject(t, fmt.Sprintf(testDeployment, `"x"`, "always", "hostPorrt")))
+	assert.True(t, r.Found)
+	assert.Equal(t, []string{
+		`spec.replicas: Invalid value: "x": must be of type integer`,
+		`spec.template.spec.containers[0].imagePullPolicy: Unsupported value: "always": supported values: "Always", "IfNotPresent", "Never"`,
+		`spec.template.spec.containers[0].ports[0].hostPorrt: Forbidden: unknown field`,
+	}, r.Errors)
+
+	r = s.ValidateObject(parseObject(t, `{"apiVersion": "example.com/v1", "kind": "Unknown"}`))
+	assert.False(t, r.Found)
+}
+
+func TestValidateCRD(t *testing.T) {
+	s := NewSchemas()
+	err := s.LoadBundled("")
+	assert.NoError(t, err)
+
+	err = s.AddCRDs([]*uo.UnstructuredObject{parseObject(t, testCRD)})
+	assert.NoError(t, err)
+
+	r := s.ValidateObject(parseObject(t, `{"apiVersion": "example.com/v1", "kind": "Test", "metadata": {"name": "x"}, "spec": {"mode": "a", "port": 1, "extra": {"x": 1}}}`))
+	assert.True(t, r.Found)
+	assert.Empty(t, r.Errors)
+
+	r = s.ValidateObject(parseObject(t, `{"apiVersion": "example.com/v1", "kind": "Test", "metadata": {"name": "x", "labelz": {}}, "spec": {"port": true}}`))
+	assert.Equal(t, []string{
+		`metadata.labelz: Forbidden: unknown field`,
+		`spec.mode: Required value`,
+		`spec.port: Invalid value: true: must be of type int-or-string`,
+	}, r.Errors)
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "crd.yaml"), []byte(testCRD), 0o600)
+	assert.NoError(t, err)
+
+	s := NewSchemas()
+	err = s.LoadDir(dir)
+	assert.NoError(t, err)
+
+	r := s.ValidateObject(parseObject(t, `{"apiVersion": "example.com/v1", "kind": "Test", "spec": {"mode": "c"}}`))
+	assert.Equal(t, []string{`spec.mode: Unsupported value: "c": supported values: "a", "b"`}, r.Errors)
+}
+
+func TestBundledVersionSelection(t *testing.T) {
+	versions, err := ListBundledVersions()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, versions)
+
+	oldest := versions[0]
+	oldestStr := fmt.Sprintf("%d.%d", oldest.Major(), oldest.Minor())
+	s := NewSchemas()
+	err = s.LoadBundled(oldestStr + ".5")
+	assert.NoError(t, err)
+	assert.Equal(t, oldestStr, s.K8sVersion)
+
+	s = NewSchemas()
+	err = s.LoadBundled("1.0.0")
+	assert.ErrorIs(t, err, ErrNoBundledSchemas)
+	assert.ErrorContains(t, err, "for Kubernetes 1.0.0, available versions are "+oldestStr)
+	assert.Empty(t, s.K8sVersion)
+
+	s = NewSchemas()
+	err = s.LoadBundled("invalid")
+	assert.ErrorContains(t, err, "invalid Kubernetes version invalid")
+	assert.NotErrorIs(t, err, ErrNoBundledSchemas)
+}
+
+func TestAddOpenAPIDocument(t *testing.T) {
+	// this is how live clusters serve these definitions
+	doc := parseObject(t, `
+definitions:
+  io.k8s.apimachinery.pkg.api.resource.Quantity:
+    type: string
+  com.example.v1.Test:
+    type: object
+    x-kubernetes-group-version-kind:
+    - group: example.com
+      version: v1
+      kind: Test
+    properties:
+      apiVersion:
+        type: string
+      kind:
+        type: string
+      size:
+        $ref: "#/definitions/io.k8s.apimachinery.pkg.api.resource.Quantity"
+`)
+
+	s := NewSchemas()
+	err := s.AddOpenAPIDocument(doc)
+	assert.NoError(t, err)
+
+	r := s.ValidateObject(parseObject(t, `{"apiVersion": "example.com/v1", "kind": "Test", "size": 1}`))
+	assert.True(t, r.Found)
+	assert.Empty(t, r.Errors)
+
+	r = s.ValidateObject(parseObject(t, `{"apiVersion": "example.com/v1", "kind": "Test", "size": true}`))
+	assert.Equal(t, []string{`size: Invalid value: true: must be of type int-or-string`}, r.Errors)
+}
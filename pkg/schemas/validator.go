@@ -0,0 +1,237 @@
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+type validator struct {
+	definitions map[string]*apiextensionsv1.JSONSchemaProps
+
+	errs          field.ErrorList
+	unknownFields field.ErrorList
+}
+
+func (v *validator) resolveRef(js *apiextensionsv1.JSONSchemaProps) *apiextensionsv1.JSONSchemaProps {
+	for js != nil && js.Ref != nil {
+		ref := *js.Ref
+		js = v.definitions[ref[strings.LastIndex(ref, "/")+1:]]
+	}
+	return js
+}
+
+func (v *validator) validate(path *field.Path, js *apiextensionsv1.JSONSchemaProps, value any) {
+	js = v.resolveRef(js)
+	if js == nil || value == nil {
+		return
+	}
+
+	for i := range js.AllOf {
+		v.validate(path, &js.AllOf[i], value)
+	}
+	if len(js.AnyOf) != 0 && v.countMatching(path, js.AnyOf, value) == 0 {
+		v.errs = append(v.errs, field.Invalid(path, printableValue(value), "must validate against any of the allowed schemas"))
+		return
+	}
+	if len(js.OneOf) != 0 && v.countMatching(path, js.OneOf, value) != 1 {
+		v.errs = append(v.errs, field.Invalid(path, printableValue(value), "must validate against exactly one of the allowed schemas"))
+		return
+	}
+
+	if js.XIntOrString || js.Format == "int-or-string" {
+		if _, ok := value.(string); !ok && !isInteger(value) {
+			v.errs = append(v.errs, field.Invalid(path, printableValue(value), "must be of type int-or-string"))
+		}
+		return
+	}
+
+	if js.Type != "" && !checkType(js.Type, value) {
+		v.errs = append(v.errs, field.Invalid(path, printableValue(value), fmt.Sprintf("must be of type %s", js.Type)))
+		return
+	}
+
+	if len(js.Enum) != 0 {
+		v.validateEnum(path, js, value)
+	}
+
+	switch x := value.(type) {
+	case string:
+		v.validateString(path, js, x)
+	case map[string]any:
+		v.validateObject(path, js, x)
+	case []any:
+		v.validateArray(path, js, x)
+	default:
+		if f, ok := toFloat(value); ok {
+			v.validateNumber(path, js, f)
+		}
+	}
+}
+
+func (v *validator) countMatching(path *field.Path, schemas []apiextensionsv1.JSONSchemaProps, value any) int {
+	cnt := 0
+	for i := range schemas {
+		v2 := validator{definitions: v.definitions}
+		v2.validate(path, &schemas[i], value)
+		if len(v2.errs) == 0 {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+func (v *validator) validateEnum(path *field.Path, js *apiextensionsv1.JSONSchemaProps, value any) {
+	var allowed []string
+	for _, e := range js.Enum {
+		var ev any
+		err := json.Unmarshal(e.Raw, &ev)
+		if err != nil {
+			return
+		}
+		if valuesEqual(ev, value) {
+			return
+		}
+		allowed = append(allowed, fmt.Sprint(ev))
+	}
+	v.errs = append(v.errs, field.NotSupported(path, printableValue(value), allowed))
+}
+
+func (v *validator) validateString(path *field.Path, js *apiextensionsv1.JSONSchemaProps, s string) {
+	l := int64(utf8.RuneCountInString(s))
+	if js.MaxLength != nil && l > *js.MaxLength {
+		v.errs = append(v.errs, field.TooLong(path, s, int(*js.MaxLength)))
+	}
+	if js.MinLength != nil && l < *js.MinLength {
+		v.errs = append(v.errs, field.Invalid(path, s, fmt.Sprintf("should be at least %d chars long", *js.MinLength)))
+	}
+	if js.Pattern != "" {
+		re, err := regexp.Compile(js.Pattern)
+		if err == nil && !re.MatchString(s) {
+			v.errs = append(v.errs, field.Invalid(path, s, fmt.Sprintf("should match '%s'", js.Pattern)))
+		}
+	}
+}
+
+func (v *validator) validateNumber(path *field.Path, js *apiextensionsv1.JSONSchemaProps, f float64) {
+	if js.Maximum != nil && (f > *js.Maximum || (js.ExclusiveMaximum && f == *js.Maximum)) {
+		v.errs = append(v.errs, field.Invalid(path, f, fmt.Sprintf("should be less than or equal to %v", *js.Maximum)))
+	}
+	if js.Minimum != nil && (f < *js.Minimum || (js.ExclusiveMinimum && f == *js.Minimum)) {
+		v.errs = append(v.errs, field.Invalid(path, f, fmt.Sprintf("should be greater than or equal to %v", *js.Minimum)))
+	}
+}
+
+func (v *validator) validateArray(path *field.Path, js *apiextensionsv1.JSONSchemaProps, l []any) {
+	if js.MaxItems != nil && int64(len(l)) > *js.MaxItems {
+		v.errs = append(v.errs, field.TooMany(path, len(l), int(*js.MaxItems)))
+	}
+	if js.MinItems != nil && int64(len(l)) < *js.MinItems {
+		v.errs = append(v.errs, field.Invalid(path, field.OmitValueType{}, fmt.Sprintf("should have at least %d items", *js.MinItems)))
+	}
+	if js.Items == nil {
+		return
+	}
+	for i, x := range l {
+		var itemSchema *apiextensionsv1.JSONSchemaProps
+		if js.Items.Schema != nil {
+			itemSchema = js.Items.Schema
+		} else if i < len(js.Items.JSONSchemas) {
+			itemSchema = &js.Items.JSONSchemas[i]
+		}
+		v.validate(path.Index(i), itemSchema, x)
+	}
+}
+
+func (v *validator) validateObject(path *field.Path, js *apiextensionsv1.JSONSchemaProps, m map[string]any) {
+	for _, r := range js.Required {
+		if _, ok := m[r]; !ok {
+			v.errs = append(v.errs, field.Required(path.Child(r), ""))
+		}
+	}
+
+	preserveUnknown := js.XPreserveUnknownFields != nil && *js.XPreserveUnknownFields
+	for k, x := range m {
+		if ps, ok := js.Properties[k]; ok {
+			v.validate(path.Child(k), &ps, x)
+			continue
+		}
+		if js.AdditionalProperties != nil {
+			if js.AdditionalProperties.Schema != nil {
+				v.validate(path.Key(k), js.AdditionalProperties.Schema, x)
+				continue
+			}
+			if js.AdditionalProperties.Allows {
+				continue
+			}
+		} else if len(js.Properties) == 0 || preserveUnknown {
+			continue
+		}
+		v.unknownFields = append(v.unknownFields, field.Forbidden(path.Child(k), "unknown field"))
+	}
+}
+
+func checkType(t string, value any) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		return isInteger(value)
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	}
+	return true
+}
+
+func toFloat(value any) (float64, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func isInteger(value any) bool {
+	f, ok := toFloat(value)
+	return ok && f == math.Trunc(f)
+}
+
+func valuesEqual(a any, b any) bool {
+	fa, ok1 := toFloat(a)
+	fb, ok2 := toFloat(b)
+	if ok1 && ok2 {
+		return fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// printableValue avoids dumping whole objects and lists into error messages
+func printableValue(value any) any {
+	switch value.(type) {
+	case map[string]any, []any:
+		return field.OmitValueType{}
+	}
+	return value
+}
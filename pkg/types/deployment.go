@@ -132,6 +132,14 @@ func ValidateIgnoreForDiffItemConfig(sl validator.StructLevel) {
 	}
 }
 
+type EmbeddedDiffItemConfig struct {
+	FieldPath SingleStringOrList `json:"fieldPath" validate:"required"`
+	Group     *string            `json:"group,omitempty"`
+	Kind      *string            `json:"kind,omitempty"`
+	Name      *string            `json:"name,omitempty"`
+	Namespace *string            `json:"namespace,omitempty"`
+}
+
 type ConflictResolutionAction string
 
 const (
@@ -157,6 +165,45 @@ func ValidateConflictResolutionConfig(sl validator.StructLevel) {
 	}
 }
 
+type ReadinessRuleConfig struct {
+	Group     string  `json:"group,omitempty"`
+	Kind      string  `json:"kind" validate:"required"`
+	Condition *string `json:"condition,omitempty"`
+	Cel       *string `json:"cel,omitempty"`
+	JsonPath  *string `json:"jsonPath,omitempty"`
+	Message   *string `json:"message,omitempty"`
+}
+
+func ValidateReadinessRuleConfig(sl validator.StructLevel) {
+	s := sl.Current().Interface().(ReadinessRuleConfig)
+	cnt := 0
+	for _, x := range []*string{s.Condition, s.Cel, s.JsonPath} {
+		if x != nil {
+			cnt++
+		}
+	}
+	if cnt != 1 {
+		sl.ReportError(s, "self", "self", "exactly one of condition, cel or jsonPath must be set", "")
+	}
+}
+
+type PolicyAction string
+
+const (
+	PolicyActionError PolicyAction = "error"
+	PolicyActionWarn  PolicyAction = "warn"
+)
+
+type PolicyConfig struct {
+	Name      string       `json:"name" validate:"required"`
+	Cel       string       `json:"cel" validate:"required"`
+	Message   *string      `json:"message,omitempty"`
+	Action    PolicyAction `json:"action,omitempty" validate:"omitempty,oneof=error warn"`
+	Group     *string      `json:"group,omitempty"`
+	Kind      *string      `json:"kind,omitempty"`
+	Namespace *string      `json:"namespace,omitempty"`
+}
+
 type DeploymentProjectConfig struct {
 	Vars []VarsSource `json:"vars,omitempty"`
 
@@ -170,7 +217,10 @@ type DeploymentProjectConfig struct {
 	Tags              []string          `json:"tags,omitempty"`
 
 	IgnoreForDiff      []IgnoreForDiffItemConfig  `json:"ignoreForDiff,omitempty"`
+	EmbeddedDiff       []EmbeddedDiffItemConfig   `json:"embeddedDiff,omitempty"`
 	ConflictResolution []ConflictResolutionConfig `json:"conflictResolution,omitempty"`
+	ReadinessRules     []ReadinessRuleConfig      `json:"readinessRules,omitempty"`
+	Policies           []PolicyConfig             `json:"policies,omitempty"`
 }
 
 func init() {
@@ -179,4 +229,5 @@ func init() {
 	yaml2.Validator.RegisterStructValidation(ValidateWaitReadinessObjectItemConfig, WaitReadinessObjectItemConfig{})
 	yaml2.Validator.RegisterStructValidation(ValidateIgnoreForDiffItemConfig, IgnoreForDiffItemConfig{})
 	yaml2.Validator.RegisterStructValidation(ValidateConflictResolutionConfig, ConflictResolutionConfig{})
+	yaml2.Validator.RegisterStructValidation(ValidateReadinessRuleConfig, ReadinessRuleConfig{})
 }
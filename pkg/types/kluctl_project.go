@@ -15,6 +15,15 @@ type AwsConfig struct {
 	ServiceAccount *ServiceAccountRef `json:"serviceAccount,omitempty"`
 }
 
+type TargetPolicies struct {
+	AllowDelete               *bool    `json:"allowDelete,omitempty"`
+	AllowPrune                *bool    `json:"allowPrune,omitempty"`
+	ForbidFlags               []string `json:"forbidFlags,omitempty" validate:"dive,oneof=force-apply replace-on-error force-replace-on-error abort-on-error no-wait prune allow-dangerous-prune"`
+	RequireConfirmationPhrase string   `json:"requireConfirmationPhrase,omitempty"`
+	AllowedClusterIds         []string `json:"allowedClusterIds,omitempty"`
+	DangerousPrune            string   `json:"dangerousPrune,omitempty" validate:"omitempty,oneof=warn fail skip allow"`
+}
+
 type Target struct {
 	Name          string                 `json:"name"`
 	Context       *string                `json:"context,omitempty"`
@@ -22,6 +31,7 @@ type Target struct {
 	Aws           *AwsConfig             `json:"aws,omitempty"`
 	Images        []FixedImage           `json:"images,omitempty"`
 	Discriminator string                 `json:"discriminator,omitempty"`
+	Policies      *TargetPolicies        `json:"policies,omitempty"`
 }
 
 type DeploymentArg struct {
@@ -30,10 +40,11 @@ type DeploymentArg struct {
 }
 
 type KluctlProject struct {
-	Targets       []Target        `json:"targets,omitempty"`
-	Args          []DeploymentArg `json:"args,omitempty"`
-	Discriminator string          `json:"discriminator,omitempty"`
-	Aws           *AwsConfig      `json:"aws,omitempty"`
+	Targets        []Target              `json:"targets,omitempty"`
+	Args           []DeploymentArg       `json:"args,omitempty"`
+	Discriminator  string                `json:"discriminator,omitempty"`
+	Aws            *AwsConfig            `json:"aws,omitempty"`
+	ReadinessRules []ReadinessRuleConfig `json:"readinessRules,omitempty"`
 }
 
 type KluctlLibraryProject struct {
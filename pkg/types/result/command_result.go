@@ -1,6 +1,7 @@
 package result
 
 import (
+	"fmt"
 	gittypes "github.com/kluctl/kluctl/lib/git/types"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
@@ -15,6 +16,17 @@ type Change struct {
 	OldValue    *apiextensionsv1.JSON `json:"oldValue,omitempty"`
 	NewValue    *apiextensionsv1.JSON `json:"newValue,omitempty"`
 	UnifiedDiff string                `json:"unifiedDiff,omitempty"`
+
+	// EmbeddedJsonPath is set when the change happened inside an embedded YAML/JSON document, which is stored as
+	// string at JsonPath. OldValue and NewValue then refer to the values inside the embedded document.
+	EmbeddedJsonPath string `json:"embeddedJsonPath,omitempty"`
+}
+
+func (c *Change) DisplayPath() string {
+	if c.EmbeddedJsonPath == "" {
+		return c.JsonPath
+	}
+	return fmt.Sprintf("%s#%s", c.JsonPath, c.EmbeddedJsonPath)
 }
 
 type ChangedObject struct {
@@ -271,6 +271,13 @@ func (in *DeploymentProjectConfig) DeepCopyInto(out *DeploymentProjectConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.EmbeddedDiff != nil {
+		in, out := &in.EmbeddedDiff, &out.EmbeddedDiff
+		*out = make([]EmbeddedDiffItemConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ConflictResolution != nil {
 		in, out := &in.ConflictResolution, &out.ConflictResolution
 		*out = make([]ConflictResolutionConfig, len(*in))
@@ -278,6 +285,20 @@ func (in *DeploymentProjectConfig) DeepCopyInto(out *DeploymentProjectConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ReadinessRules != nil {
+		in, out := &in.ReadinessRules, &out.ReadinessRules
+		*out = make([]ReadinessRuleConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]PolicyConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentProjectConfig.
@@ -290,6 +311,46 @@ func (in *DeploymentProjectConfig) DeepCopy() *DeploymentProjectConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmbeddedDiffItemConfig) DeepCopyInto(out *EmbeddedDiffItemConfig) {
+	*out = *in
+	if in.FieldPath != nil {
+		in, out := &in.FieldPath, &out.FieldPath
+		*out = make(SingleStringOrList, len(*in))
+		copy(*out, *in)
+	}
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmbeddedDiffItemConfig.
+func (in *EmbeddedDiffItemConfig) DeepCopy() *EmbeddedDiffItemConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EmbeddedDiffItemConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FixedImage) DeepCopyInto(out *FixedImage) {
 	*out = *in
@@ -613,6 +674,13 @@ func (in *KluctlProject) DeepCopyInto(out *KluctlProject) {
 		*out = new(AwsConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ReadinessRules != nil {
+		in, out := &in.ReadinessRules, &out.ReadinessRules
+		*out = make([]ReadinessRuleConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KluctlProject.
@@ -685,6 +753,76 @@ func (in *OciRef) DeepCopy() *OciRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyConfig) DeepCopyInto(out *PolicyConfig) {
+	*out = *in
+	if in.Message != nil {
+		in, out := &in.Message, &out.Message
+		*out = new(string)
+		**out = **in
+	}
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyConfig.
+func (in *PolicyConfig) DeepCopy() *PolicyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessRuleConfig) DeepCopyInto(out *ReadinessRuleConfig) {
+	*out = *in
+	if in.Condition != nil {
+		in, out := &in.Condition, &out.Condition
+		*out = new(string)
+		**out = **in
+	}
+	if in.Cel != nil {
+		in, out := &in.Cel, &out.Cel
+		*out = new(string)
+		**out = **in
+	}
+	if in.JsonPath != nil {
+		in, out := &in.JsonPath, &out.JsonPath
+		*out = new(string)
+		**out = **in
+	}
+	if in.Message != nil {
+		in, out := &in.Message, &out.Message
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessRuleConfig.
+func (in *ReadinessRuleConfig) DeepCopy() *ReadinessRuleConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessRuleConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceAccountRef) DeepCopyInto(out *ServiceAccountRef) {
 	*out = *in
@@ -743,6 +881,11 @@ func (in *Target) DeepCopyInto(out *Target) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = new(TargetPolicies)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Target.
@@ -755,6 +898,41 @@ func (in *Target) DeepCopy() *Target {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetPolicies) DeepCopyInto(out *TargetPolicies) {
+	*out = *in
+	if in.AllowDelete != nil {
+		in, out := &in.AllowDelete, &out.AllowDelete
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowPrune != nil {
+		in, out := &in.AllowPrune, &out.AllowPrune
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ForbidFlags != nil {
+		in, out := &in.ForbidFlags, &out.ForbidFlags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedClusterIds != nil {
+		in, out := &in.AllowedClusterIds, &out.AllowedClusterIds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetPolicies.
+func (in *TargetPolicies) DeepCopy() *TargetPolicies {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetPolicies)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VarSourceAzureKeyVault) DeepCopyInto(out *VarSourceAzureKeyVault) {
 	*out = *in
@@ -5,6 +5,7 @@ import (
 	"github.com/ohler55/ojg/jp"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -27,6 +28,39 @@ func keyPathFromJsonPath(e jp.Expr) (KeyPath, error) {
 	return ret, nil
 }
 
+// NewKeyPathFromJsonPath parses a simple json path (as returned by ToJsonPath) into a KeyPath
+func NewKeyPathFromJsonPath(p string) (KeyPath, error) {
+	e, err := jp.ParseString(p)
+	if err != nil {
+		return nil, err
+	}
+	if len(e) != 0 {
+		if _, ok := e[0].(jp.Root); ok {
+			e = e[1:]
+		}
+	}
+	return keyPathFromJsonPath(e)
+}
+
+// ToJsonPointer returns the RFC 6901 representation of the key path
+func (kl KeyPath) ToJsonPointer() string {
+	var sb strings.Builder
+	for _, k := range kl {
+		sb.WriteString("/")
+		switch v := k.(type) {
+		case int:
+			sb.WriteString(strconv.Itoa(v))
+		case string:
+			v = strings.ReplaceAll(v, "~", "~0")
+			v = strings.ReplaceAll(v, "/", "~1")
+			sb.WriteString(v)
+		default:
+			sb.WriteString(fmt.Sprintf("%v", v))
+		}
+	}
+	return sb.String()
+}
+
 func (kl KeyPath) ToJsonPath() string {
 	p := ""
 	for _, k := range kl {
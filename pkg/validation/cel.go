@@ -0,0 +1,66 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/google/cel-go/cel"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"sync"
+)
+
+var celEnvOnce sync.Once
+var celEnv *cel.Env
+var celEnvErr error
+
+// the top level fields of an object are made available as variables, so that expressions like
+// `status.phase == "Running"` are possible. The whole object is available via `object`.
+var celTopLevelFields = []string{"apiVersion", "kind", "metadata", "spec", "status", "data"}
+
+func getCelEnv() (*cel.Env, error) {
+	celEnvOnce.Do(func() {
+		opts := []cel.EnvOption{cel.Variable("object", cel.DynType)}
+		for _, f := range celTopLevelFields {
+			opts = append(opts, cel.Variable(f, cel.DynType))
+		}
+		celEnv, celEnvErr = cel.NewEnv(opts...)
+	})
+	return celEnv, celEnvErr
+}
+
+// compileCelBool compiles an expression that must evaluate to a bool
+func compileCelBool(expr string) (cel.Program, error) {
+	env, err := getCelEnv()
+	if err != nil {
+		return nil, err
+	}
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression must evaluate to a bool, got %s", ast.OutputType().String())
+	}
+	return env.Program(ast)
+}
+
+func evalCelBool(program cel.Program, o *uo.UnstructuredObject) (bool, error) {
+	vars := map[string]any{
+		"object": o.Object,
+	}
+	for _, f := range celTopLevelFields {
+		v, ok := o.Object[f]
+		if !ok {
+			// allows to use has() on non-existing top level fields, e.g. has(status.phase)
+			v = map[string]any{}
+		}
+		vars[f] = v
+	}
+	out, _, err := program.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression did not evaluate to a bool")
+	}
+	return b, nil
+}
@@ -0,0 +1,98 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/google/cel-go/cel"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+// Policies holds compiled policies, which are evaluated against rendered objects before anything gets applied
+type Policies struct {
+	policies []*policy
+}
+
+type policy struct {
+	config  types.PolicyConfig
+	program cel.Program
+}
+
+type PolicyViolation struct {
+	Policy  string
+	Action  types.PolicyAction
+	Message string
+}
+
+func NewPolicies(configs []types.PolicyConfig) (*Policies, error) {
+	ret := &Policies{}
+	for _, c := range configs {
+		p, err := compileCelBool(c.Cel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile policy %s: %w", c.Name, err)
+		}
+		ret.policies = append(ret.policies, &policy{
+			config:  c,
+			program: p,
+		})
+	}
+	return ret, nil
+}
+
+func (p *Policies) IsEmpty() bool {
+	return p == nil || len(p.policies) == 0
+}
+
+// Check evaluates all matching policies against the given object and returns all violations
+func (p *Policies) Check(o *uo.UnstructuredObject) []PolicyViolation {
+	if p == nil {
+		return nil
+	}
+
+	gvk := o.GetK8sGVK()
+	ns := o.GetK8sNamespace()
+
+	var ret []PolicyViolation
+	for _, x := range p.policies {
+		if !checkPolicySelector(gvk.Group, x.config.Group) ||
+			!checkPolicySelector(gvk.Kind, x.config.Kind) ||
+			!checkPolicySelector(ns, x.config.Namespace) {
+			continue
+		}
+
+		action := x.config.Action
+		if action == "" {
+			action = types.PolicyActionError
+		}
+
+		ok, err := evalCelBool(x.program, o)
+		if err != nil {
+			ret = append(ret, PolicyViolation{
+				Policy:  x.config.Name,
+				Action:  action,
+				Message: fmt.Sprintf("policy %s failed to evaluate: %s", x.config.Name, err.Error()),
+			})
+			continue
+		}
+		if ok {
+			continue
+		}
+
+		msg := fmt.Sprintf("policy %s is violated", x.config.Name)
+		if x.config.Message != nil {
+			msg = fmt.Sprintf("policy %s is violated: %s", x.config.Name, *x.config.Message)
+		}
+		ret = append(ret, PolicyViolation{
+			Policy:  x.config.Name,
+			Action:  action,
+			Message: msg,
+		})
+	}
+	return ret
+}
+
+func checkPolicySelector(v string, m *string) bool {
+	if m == nil {
+		return true
+	}
+	return v == *m
+}
@@ -0,0 +1,69 @@
+package validation
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func buildPolicyTestDeployment(image string, limits bool) *uo.UnstructuredObject {
+	c := map[string]any{
+		"name":  "c1",
+		"image": image,
+	}
+	if limits {
+		c["resources"] = map[string]any{"limits": map[string]any{"memory": "128Mi"}}
+	}
+	return uo.FromMap(map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"name":      "test",
+			"namespace": "default",
+		},
+		"spec": map[string]any{
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{c},
+				},
+			},
+		},
+	})
+}
+
+func TestPolicies(t *testing.T) {
+	p, err := NewPolicies([]types.PolicyConfig{
+		{
+			Name:    "no-latest",
+			Kind:    utils.Ptr("Deployment"),
+			Cel:     `spec.template.spec.containers.all(c, !c.image.endsWith(":latest"))`,
+			Message: utils.Ptr("latest tags are not allowed"),
+		},
+		{
+			Name:   "limits",
+			Kind:   utils.Ptr("Deployment"),
+			Cel:    `spec.template.spec.containers.all(c, has(c.resources) && has(c.resources.limits))`,
+			Action: types.PolicyActionWarn,
+		},
+		{
+			Name:      "other-namespace",
+			Namespace: utils.Ptr("other"),
+			Cel:       `false`,
+		},
+	})
+	assert.NoError(t, err)
+
+	v := p.Check(buildPolicyTestDeployment("nginx:1.25", true))
+	assert.Empty(t, v)
+
+	v = p.Check(buildPolicyTestDeployment("nginx:latest", false))
+	assert.Equal(t, []PolicyViolation{
+		{Policy: "no-latest", Action: types.PolicyActionError, Message: "policy no-latest is violated: latest tags are not allowed"},
+		{Policy: "limits", Action: types.PolicyActionWarn, Message: "policy limits is violated"},
+	}, v)
+
+	_, err = NewPolicies([]types.PolicyConfig{{Name: "invalid", Cel: `spec.x ==`}})
+	assert.ErrorContains(t, err, "failed to compile policy invalid")
+}
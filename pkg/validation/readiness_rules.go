@@ -0,0 +1,157 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/google/cel-go/cel"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sync"
+)
+
+// ReadinessRules holds the compiled readiness rules per GroupKind. Rules configured for a GroupKind completely replace
+// the built-in readiness checks and the built-in rules for that GroupKind.
+type ReadinessRules struct {
+	rules map[schema.GroupKind][]*readinessRule
+}
+
+type readinessRule struct {
+	config   types.ReadinessRuleConfig
+	program  cel.Program
+	jsonPath *uo.MyJsonPath
+}
+
+var defaultReadinessRulesOnce sync.Once
+var defaultReadinessRules *ReadinessRules
+
+func getDefaultReadinessRules() *ReadinessRules {
+	defaultReadinessRulesOnce.Do(func() {
+		var err error
+		defaultReadinessRules, err = NewReadinessRules(nil)
+		if err != nil {
+			panic(fmt.Sprintf("failed to compile built-in readiness rules: %v", err))
+		}
+	})
+	return defaultReadinessRules
+}
+
+// NewReadinessRules compiles the given readiness rules. The built-in rules are added for all GroupKinds that are not
+// configured by the given rules.
+func NewReadinessRules(configs []types.ReadinessRuleConfig) (*ReadinessRules, error) {
+	r := &ReadinessRules{
+		rules: map[schema.GroupKind][]*readinessRule{},
+	}
+	err := r.addRules(configs, false)
+	if err != nil {
+		return nil, err
+	}
+	err = r.addRules(builtinReadinessRules, true)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *ReadinessRules) addRules(configs []types.ReadinessRuleConfig, skipExisting bool) error {
+	added := map[schema.GroupKind]bool{}
+	for _, c := range configs {
+		gk := schema.GroupKind{Group: c.Group, Kind: c.Kind}
+		if skipExisting && !added[gk] {
+			if _, ok := r.rules[gk]; ok {
+				continue
+			}
+		}
+		rule, err := compileReadinessRule(c)
+		if err != nil {
+			return err
+		}
+		r.rules[gk] = append(r.rules[gk], rule)
+		added[gk] = true
+	}
+	return nil
+}
+
+func (r *ReadinessRules) getRules(gk schema.GroupKind) []*readinessRule {
+	if r == nil {
+		r = getDefaultReadinessRules()
+	}
+	return r.rules[gk]
+}
+
+func compileReadinessRule(c types.ReadinessRuleConfig) (*readinessRule, error) {
+	rule := &readinessRule{
+		config: c,
+	}
+	gk := schema.GroupKind{Group: c.Group, Kind: c.Kind}
+
+	switch {
+	case c.Cel != nil:
+		p, err := compileCelBool(*c.Cel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile readiness rule for %s: %w", gk.String(), err)
+		}
+		rule.program = p
+	case c.JsonPath != nil:
+		j, err := uo.NewMyJsonPath(*c.JsonPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse readiness rule for %s: %w", gk.String(), err)
+		}
+		rule.jsonPath = j
+	case c.Condition != nil:
+	default:
+		return nil, fmt.Errorf("readiness rule for %s has neither condition, cel nor jsonPath set", gk.String())
+	}
+	return rule, nil
+}
+
+// check evaluates the rule against the given object. If the object is not ready, a message is returned that explains
+// why.
+func (r *readinessRule) check(o *uo.UnstructuredObject) (bool, string) {
+	ready, msg := r.evaluate(o)
+	if ready {
+		return true, ""
+	}
+	if r.config.Message != nil {
+		return false, *r.config.Message
+	}
+	return false, msg
+}
+
+func (r *readinessRule) evaluate(o *uo.UnstructuredObject) (bool, string) {
+	switch {
+	case r.program != nil:
+		b, err := evalCelBool(r.program, o)
+		if err != nil {
+			return false, fmt.Sprintf("readiness rule '%s' failed: %s", *r.config.Cel, err.Error())
+		}
+		if !b {
+			return false, fmt.Sprintf("readiness rule '%s' is not satisfied", *r.config.Cel)
+		}
+		return true, ""
+	case r.jsonPath != nil:
+		for _, v := range r.jsonPath.Get(o) {
+			if v != nil && v != false && v != "" {
+				return true, ""
+			}
+		}
+		return false, fmt.Sprintf("readiness rule '%s' did not match", *r.config.JsonPath)
+	default:
+		l, _, _ := o.GetNestedObjectList("status", "conditions")
+		for _, c := range l {
+			t, _, _ := c.GetNestedString("type")
+			if t != *r.config.Condition {
+				continue
+			}
+			s, _, _ := c.GetNestedString("status")
+			if s == "True" {
+				return true, ""
+			}
+			message, _, _ := c.GetNestedString("message")
+			if message == "" {
+				message = fmt.Sprintf("%s condition is %s", t, s)
+			}
+			return false, message
+		}
+		return false, fmt.Sprintf("%s condition not in status", *r.config.Condition)
+	}
+}
@@ -0,0 +1,58 @@
+package validation
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+)
+
+func readyConditionRule(group string, kind string) types.ReadinessRuleConfig {
+	return types.ReadinessRuleConfig{Group: group, Kind: kind, Condition: utils.Ptr("Ready")}
+}
+
+func celRule(group string, kind string, expr string) types.ReadinessRuleConfig {
+	return types.ReadinessRuleConfig{Group: group, Kind: kind, Cel: utils.Ptr(expr)}
+}
+
+// builtinReadinessRules contains readiness rules for popular operators. These are used for all GroupKinds that are
+// not configured via readinessRules in the project.
+var builtinReadinessRules = []types.ReadinessRuleConfig{
+	// cert-manager
+	readyConditionRule("cert-manager.io", "Certificate"),
+	readyConditionRule("cert-manager.io", "Issuer"),
+	readyConditionRule("cert-manager.io", "ClusterIssuer"),
+
+	// Flux
+	readyConditionRule("source.toolkit.fluxcd.io", "GitRepository"),
+	readyConditionRule("source.toolkit.fluxcd.io", "OCIRepository"),
+	readyConditionRule("source.toolkit.fluxcd.io", "HelmRepository"),
+	readyConditionRule("source.toolkit.fluxcd.io", "HelmChart"),
+	readyConditionRule("source.toolkit.fluxcd.io", "Bucket"),
+	readyConditionRule("kustomize.toolkit.fluxcd.io", "Kustomization"),
+	readyConditionRule("helm.toolkit.fluxcd.io", "HelmRelease"),
+
+	// Kluctl
+	readyConditionRule("gitops.kluctl.io", "KluctlDeployment"),
+
+	// external-secrets
+	readyConditionRule("external-secrets.io", "ExternalSecret"),
+	readyConditionRule("external-secrets.io", "ClusterExternalSecret"),
+	readyConditionRule("external-secrets.io", "SecretStore"),
+	readyConditionRule("external-secrets.io", "ClusterSecretStore"),
+
+	// sealed-secrets
+	celRule("bitnami.com", "SealedSecret", `!has(status.conditions) || status.conditions.all(c, c.type != "Synced" || c.status == "True")`),
+
+	// KEDA
+	readyConditionRule("keda.sh", "ScaledObject"),
+	readyConditionRule("keda.sh", "ScaledJob"),
+
+	// Argo CD
+	celRule("argoproj.io", "Application", `status.health.status == "Healthy" && status.sync.status == "Synced"`),
+
+	// CloudNativePG
+	celRule("postgresql.cnpg.io", "Cluster", `status.phase == "Cluster in healthy state"`),
+
+	// Prometheus operator
+	types.ReadinessRuleConfig{Group: "monitoring.coreos.com", Kind: "Prometheus", Condition: utils.Ptr("Available")},
+	types.ReadinessRuleConfig{Group: "monitoring.coreos.com", Kind: "Alertmanager", Condition: utils.Ptr("Available")},
+}
@@ -0,0 +1,92 @@
+package validation
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func buildReadinessTestObject(apiVersion string, kind string, status map[string]any) *uo.UnstructuredObject {
+	o := uo.FromMap(map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]any{
+			"name":      "test",
+			"namespace": "default",
+		},
+	})
+	if status != nil {
+		_ = o.SetNestedField(status, "status")
+	}
+	return o
+}
+
+func TestReadinessRules(t *testing.T) {
+	rules, err := NewReadinessRules([]types.ReadinessRuleConfig{
+		{Group: "example.com", Kind: "CelKind", Cel: utils.Ptr(`status.phase == "Running"`)},
+		{Group: "example.com", Kind: "HasKind", Cel: utils.Ptr(`has(status.phase)`)},
+		{Group: "example.com", Kind: "JsonPathKind", JsonPath: utils.Ptr(`status.endpoints[*].ready`)},
+		{Group: "example.com", Kind: "ConditionKind", Condition: utils.Ptr("Available"), Message: utils.Ptr("custom message")},
+		{Group: "cert-manager.io", Kind: "Certificate", Cel: utils.Ptr(`true`)},
+	})
+	assert.NoError(t, err)
+
+	type testCase struct {
+		name    string
+		kind    string
+		status  map[string]any
+		ready   bool
+		message string
+	}
+	tests := []testCase{
+		{name: "cel-ready", kind: "CelKind", status: map[string]any{"phase": "Running"}, ready: true},
+		{name: "cel-not-ready", kind: "CelKind", status: map[string]any{"phase": "Pending"}, ready: false, message: `readiness rule 'status.phase == "Running"' is not satisfied`},
+		{name: "cel-no-status", kind: "CelKind", ready: false},
+		{name: "cel-has", kind: "HasKind", ready: false, message: `readiness rule 'has(status.phase)' is not satisfied`},
+		{name: "jsonpath-ready", kind: "JsonPathKind", status: map[string]any{"endpoints": []any{map[string]any{"ready": false}, map[string]any{"ready": true}}}, ready: true},
+		{name: "jsonpath-not-ready", kind: "JsonPathKind", status: map[string]any{"endpoints": []any{map[string]any{"ready": false}}}, ready: false},
+		{name: "condition-ready", kind: "ConditionKind", status: map[string]any{"conditions": []any{map[string]any{"type": "Available", "status": "True"}}}, ready: true},
+		{name: "condition-not-ready", kind: "ConditionKind", status: map[string]any{"conditions": []any{map[string]any{"type": "Available", "status": "False"}}}, ready: false, message: "custom message"},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			o := buildReadinessTestObject("example.com/v1", tc.kind, tc.status)
+			vr := ValidateObject(context.TODO(), nil, o, true, false, rules)
+			assert.Equal(t, tc.ready, vr.Ready)
+			if !tc.ready {
+				assert.Len(t, vr.Errors, 1)
+				if tc.message != "" {
+					assert.Equal(t, tc.message, vr.Errors[0].Message)
+				}
+			}
+		})
+	}
+
+	// configured rules replace the built-in rules
+	o := buildReadinessTestObject("cert-manager.io/v1", "Certificate", nil)
+	vr := ValidateObject(context.TODO(), nil, o, true, false, rules)
+	assert.True(t, vr.Ready)
+
+	// built-in rules are used when nothing is configured
+	vr = ValidateObject(context.TODO(), nil, o, true, false, nil)
+	assert.False(t, vr.Ready)
+	o = buildReadinessTestObject("cert-manager.io/v1", "Certificate", map[string]any{"conditions": []any{map[string]any{"type": "Ready", "status": "True"}}})
+	vr = ValidateObject(context.TODO(), nil, o, true, false, nil)
+	assert.True(t, vr.Ready)
+}
+
+func TestReadinessRulesInvalid(t *testing.T) {
+	_, err := NewReadinessRules([]types.ReadinessRuleConfig{
+		{Kind: "Pod", Cel: utils.Ptr(`status.phase ==`)},
+	})
+	assert.ErrorContains(t, err, "failed to compile readiness rule for Pod")
+
+	_, err = NewReadinessRules([]types.ReadinessRuleConfig{
+		{Kind: "Pod", Cel: utils.Ptr(`"x"`)},
+	})
+	assert.ErrorContains(t, err, "must evaluate to a bool")
+}
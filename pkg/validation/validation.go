@@ -48,7 +48,9 @@ const (
 	reactNotReady
 )
 
-func ValidateObject(ctx context.Context, k *k8s.K8sCluster, o *uo.UnstructuredObject, notReadyIsError bool, forceStatusRequired bool) (ret result.ValidateResult) {
+// ValidateObject checks the readiness of the given object. If readinessRules is nil, only the built-in readiness rules
+// are used.
+func ValidateObject(ctx context.Context, k *k8s.K8sCluster, o *uo.UnstructuredObject, notReadyIsError bool, forceStatusRequired bool, readinessRules *ReadinessRules) (ret result.ValidateResult) {
 	ref := o.GetK8sRef()
 
 	// We assume all is good in case no validation is performed
@@ -129,6 +131,20 @@ func ValidateObject(ctx context.Context, k *k8s.K8sCluster, o *uo.UnstructuredOb
 		return
 	}
 
+	if rules := readinessRules.getRules(o.GetK8sGVK().GroupKind()); len(rules) != 0 {
+		observedGeneration, ok, _ := o.GetNestedInt("status", "observedGeneration")
+		if ok && observedGeneration != o.GetK8sGeneration() {
+			addNotReady("Waiting for reconciliation")
+			return
+		}
+		for _, r := range rules {
+			if ready, msg := r.check(o); !ready {
+				addNotReady(msg)
+			}
+		}
+		return
+	}
+
 	status, _, _ := o.GetNestedObject("status")
 	if status == nil {
 		if forceStatusRequired {
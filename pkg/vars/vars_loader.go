@@ -25,9 +25,12 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	"os"
+	"reflect"
 	"sort"
 	"strings"
+	"sync"
 )
 
 type usernamePassword struct {
@@ -44,6 +47,20 @@ type VarsLoader struct {
 	gcp  gcp.GcpClientFactory
 
 	credentialsCache map[string]usernamePassword
+
+	clusterVarsRefsMutex sync.Mutex
+	clusterVarsRefs      []ClusterVarsRef
+}
+
+// ClusterVarsRef identifies the cluster objects read while loading clusterConfigMap, clusterSecret and clusterObject
+// vars. Either Name or Labels is set. Missing objects are recorded as well, as creating them changes the vars.
+// ResourceVersions maps the objects that were actually read to their resource versions.
+type ClusterVarsRef struct {
+	GVK              schema.GroupVersionKind
+	Namespace        string
+	Name             string
+	Labels           map[string]string
+	ResourceVersions map[k8stypes.NamespacedName]string
 }
 
 func NewVarsLoader(ctx context.Context, k *k8s.K8sCluster, sops *decryptor.Decryptor, rp *repocache.GitRepoCache, aws aws.AwsClientFactory, gcp gcp.GcpClientFactory) *VarsLoader {
@@ -58,6 +75,31 @@ func NewVarsLoader(ctx context.Context, k *k8s.K8sCluster, sops *decryptor.Decry
 	}
 }
 
+// GetClusterVarsRefs returns the cluster objects that were read while loading vars.
+func (v *VarsLoader) GetClusterVarsRefs() []ClusterVarsRef {
+	v.clusterVarsRefsMutex.Lock()
+	defer v.clusterVarsRefsMutex.Unlock()
+	return append([]ClusterVarsRef(nil), v.clusterVarsRefs...)
+}
+
+func (v *VarsLoader) addClusterVarsRef(ref ClusterVarsRef, objs []*uo.UnstructuredObject) {
+	for _, o := range objs {
+		if ref.ResourceVersions == nil {
+			ref.ResourceVersions = map[k8stypes.NamespacedName]string{}
+		}
+		ref.ResourceVersions[k8stypes.NamespacedName{Namespace: o.GetK8sNamespace(), Name: o.GetK8sName()}] = o.GetK8sResourceVersion()
+	}
+
+	v.clusterVarsRefsMutex.Lock()
+	defer v.clusterVarsRefsMutex.Unlock()
+	for _, x := range v.clusterVarsRefs {
+		if reflect.DeepEqual(x, ref) {
+			return
+		}
+	}
+	v.clusterVarsRefs = append(v.clusterVarsRefs, ref)
+}
+
 func (v *VarsLoader) LoadVarsList(ctx context.Context, varsCtx *VarsCtx, varsList []types.VarsSource, searchDirs []string, rootKey string) error {
 	for i, _ := range varsList {
 		source := &varsList[i]
@@ -371,14 +413,23 @@ func (v *VarsLoader) loadFromK8sConfigMapOrSecret(varsCtx *VarsCtx, varsSource t
 	var err error
 	var o *uo.UnstructuredObject
 
+	cvRef := ClusterVarsRef{
+		GVK:       schema.GroupVersionKind{Version: "v1", Kind: kind},
+		Namespace: varsSource.Namespace,
+		Name:      varsSource.Name,
+		Labels:    varsSource.Labels,
+	}
+
 	if varsSource.Name != "" {
 		o, _, err = v.k.GetSingleObject(k8s2.NewObjectRef("", "v1", kind, varsSource.Name, varsSource.Namespace))
 		if err != nil {
 			if ignoreMissing && errors.IsNotFound(err) {
+				v.addClusterVarsRef(cvRef, nil)
 				return uo.New(), nil
 			}
 			return nil, err
 		}
+		v.addClusterVarsRef(cvRef, []*uo.UnstructuredObject{o})
 	} else {
 		objs, _, err := v.k.ListObjects(schema.GroupVersionKind{
 			Group:   "",
@@ -388,6 +439,7 @@ func (v *VarsLoader) loadFromK8sConfigMapOrSecret(varsCtx *VarsCtx, varsSource t
 		if err != nil {
 			return nil, err
 		}
+		v.addClusterVarsRef(cvRef, objs)
 		if len(objs) == 0 {
 			if ignoreMissing {
 				return uo.New(), nil
@@ -509,6 +561,13 @@ func (v *VarsLoader) loadFromK8sObject(varsCtx *VarsCtx, varsSource types.VarsSo
 		}
 	}
 
+	v.addClusterVarsRef(ClusterVarsRef{
+		GVK:       gvk,
+		Namespace: varsSource.Namespace,
+		Name:      varsSource.Name,
+		Labels:    varsSource.Labels,
+	}, objs)
+
 	// we want stable sorting
 	sort.Slice(objs, func(i, j int) bool {
 		return objs[i].GetK8sRef().Less(objs[j].GetK8sRef())
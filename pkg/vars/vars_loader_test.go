@@ -33,6 +33,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 )
 
 type VarsLoaderTestSuite struct {
@@ -372,6 +374,16 @@ func (s *VarsLoaderTestSuite) TestClusterConfigMap() {
 
 		v, _, _ := vc.Vars.GetNestedInt("test1", "test2")
 		assert.Equal(s.T(), int64(42), v)
+
+		assert.Equal(s.T(), []ClusterVarsRef{{
+			GVK:       schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+			Namespace: s.namespace(),
+			Name:      "cm",
+			// the watcher compares the resource versions to detect changes that happened before it started watching
+			ResourceVersions: map[k8stypes.NamespacedName]string{
+				{Namespace: s.namespace(), Name: "cm"}: cm.ResourceVersion,
+			},
+		}}, vl.GetClusterVarsRefs())
 	})
 
 	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
@@ -405,6 +417,9 @@ func (s *VarsLoaderTestSuite) TestClusterConfigMap() {
 			},
 		}, nil, "")
 		assert.NoError(s.T(), err)
+
+		// missing objects must be recorded as well so that their creation can be watched
+		assert.Len(s.T(), vl.GetClusterVarsRefs(), 1)
 	})
 
 	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
@@ -8,6 +8,7 @@ import (
 	"github.com/kluctl/kluctl/lib/status"
 	"github.com/kluctl/kluctl/lib/yaml"
 	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
+	"github.com/kluctl/kluctl/v2/pkg/diff"
 	"github.com/kluctl/kluctl/v2/pkg/results"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
@@ -139,6 +140,7 @@ func (s *CommandResultsServer) Run(host string, port int, openBrowser bool) erro
 	api.GET("/getShortNames", s.getShortNames)
 	api.GET("/getCommandResult", s.getCommandResult)
 	api.GET("/getCommandResultObject", s.getCommandResultObject)
+	api.GET("/diffCommandResults", s.diffCommandResults)
 	api.GET("/getValidateResult", s.getValidateResult)
 	api.POST("/validateNow", s.validateNow)
 	api.POST("/reconcileNow", s.reconcileNow)
@@ -447,6 +449,66 @@ func (s *CommandResultsServer) getCommandResultObject(c *gin.Context) {
 	c.JSON(http.StatusOK, o2)
 }
 
+type diffCommandResultsParams struct {
+	ResultIdA string `form:"resultIdA"`
+	ResultIdB string `form:"resultIdB"`
+}
+
+func (s *CommandResultsServer) diffCommandResults(c *gin.Context) {
+	var params diffCommandResultsParams
+
+	err := c.Bind(&params)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	var crs []*result.CommandResult
+	for _, id := range []string{params.ResultIdA, params.ResultIdB} {
+		cr, err := s.store.GetCommandResult(results.GetCommandResultOptions{
+			Id:      id,
+			Reduced: false,
+		})
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		if cr == nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		crs = append(crs, cr)
+	}
+
+	dr, err := results.DiffCommandResults(crs[0], crs[1], nil)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	// secrets are always obfuscated, same as in the CLI
+	var obfuscator diff.Obfuscator
+	err = obfuscator.ObfuscateResult(dr)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	user := s.auth.getUser(c)
+	if !user.IsAdmin {
+		// non-admins are not allowed to see secrets
+		objects := make([]result.ResultObject, 0, len(dr.Objects))
+		for _, o := range dr.Objects {
+			if o.Ref.GroupKind() != (schema.GroupKind{Kind: "Secret"}) {
+				objects = append(objects, o)
+			}
+		}
+		dr.Objects = objects
+	}
+
+	c.JSON(http.StatusOK, dr)
+}
+
 func (s *CommandResultsServer) getValidateResult(c *gin.Context) {
 	var params resultIdParam
 
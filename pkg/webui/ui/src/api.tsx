@@ -49,6 +49,7 @@ export interface Api {
     listenEvents(filterProject: string | undefined, filterSubDir: string | undefined, handle: (msg: any) => void): Promise<() => void>
     getCommandResult(resultId: string): Promise<CommandResult>
     getCommandResultObject(resultId: string, ref: ObjectRef, objectType: string): Promise<any>
+    diffCommandResults(resultIdA: string, resultIdB: string): Promise<CommandResult>
     getValidateResult(resultId: string): Promise<ValidateResult>
     validateNow(cluster: string, name: string, namespace: string): Promise<Response>
     reconcileNow(cluster: string, name: string, namespace: string): Promise<Response>
@@ -209,6 +210,14 @@ export class RealApi implements Api {
         return await this.doGet("/api/getCommandResultObject", params)
     }
 
+    async diffCommandResults(resultIdA: string, resultIdB: string) {
+        const params = new URLSearchParams()
+        params.set("resultIdA", resultIdA)
+        params.set("resultIdB", resultIdB)
+        const json = await this.doGet("/api/diffCommandResults", params)
+        return new CommandResult(json)
+    }
+
     async getValidateResult(resultId: string) {
         const params = new URLSearchParams()
         params.set("resultId", resultId)
@@ -374,6 +383,10 @@ export class StaticApi implements Api {
         }
     }
 
+    async diffCommandResults(resultIdA: string, resultIdB: string): Promise<CommandResult> {
+        throw new Error("not implemented")
+    }
+
     async getValidateResult(resultId: string): Promise<ValidateResult> {
         throw new Error("not implemented")
     }
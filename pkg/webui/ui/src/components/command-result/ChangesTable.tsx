@@ -82,7 +82,7 @@ export function ChangesTable(props: { diffStatus: DiffStatus }) {
                                 <TableRow key={buildListKey(c)}>
                                     <TableCell>
                                         <Box minWidth={"100px"} sx={{ overflowWrap: "anywhere" }}>
-                                            <Typography>{c.jsonPath}</Typography>
+                                            <Typography>{c.embeddedJsonPath ? `${c.jsonPath}#${c.embeddedJsonPath}` : c.jsonPath}</Typography>
                                         </Box>
                                     </TableCell>
                                     <TableCell>
@@ -14,7 +14,7 @@ import { LogsViewer } from "../LogsViewer";
 import { K8sManifestViewer } from "../K8sManifestViewer";
 import { YamlViewer } from "../YamlViewer";
 import { gitRefToString } from "../../utils/git";
-import { AppContextProps, useAppContext } from "../App";
+import { AppContextProps, KluctlDeploymentWithClusterId, useAppContext } from "../App";
 import { ReconcilingIcon } from "../target-view/ReconcilingIcon";
 import { StatusIcon } from "../target-view/StatusIcon";
 import { TargetActionMenu } from "../target-view/TargetActionMenu";
@@ -154,6 +154,52 @@ export const TargetCard = React.memo(React.forwardRef((
 
 TargetCard.displayName = 'TargetItem';
 
+const findKluctlDeployment = (appCtx: AppContextProps, clusterId: string, name: string, namespace: string) => {
+    for (const ps of appCtx.projects) {
+        for (const ts of ps.targets) {
+            const kd = ts.kd
+            if (kd && kd.clusterId === clusterId && kd.deployment.metadata.name === name && kd.deployment.metadata.namespace === namespace) {
+                return kd
+            }
+        }
+    }
+    return undefined
+}
+
+// DependsOnChain shows the dependencies of a KluctlDeployment, including transitive dependencies
+const DependsOnChain = (props: { appCtx: AppContextProps, kd: KluctlDeploymentWithClusterId }) => {
+    const lines: React.ReactNode[] = []
+
+    const visit = (kd: KluctlDeploymentWithClusterId, depth: number, visited: Set<string>) => {
+        kd.deployment.spec.dependsOn?.forEach((d: any) => {
+            const namespace = d.namespace || kd.deployment.metadata.namespace
+            const key = `${namespace}/${d.name}`
+            const depKd = findKluctlDeployment(props.appCtx, kd.clusterId, d.name, namespace)
+
+            let status = "not found"
+            if (depKd) {
+                const readyCondition = depKd.deployment.status?.conditions?.find((c: any) => c.type === "Ready")
+                status = readyCondition?.status === "True" ? "ready" : "not ready"
+            }
+            lines.push(<Typography key={lines.length} variant={"body2"} pl={depth * 2}>
+                {depth ? "↳ " : ""}{key} ({status}{d.strict ? ", strict" : ""})
+            </Typography>)
+
+            // guard against dependency cycles
+            if (depKd && !visited.has(key)) {
+                const visited2 = new Set(visited)
+                visited2.add(key)
+                visit(depKd, depth + 1, visited2)
+            }
+        })
+    }
+    const visited = new Set<string>()
+    visited.add(`${props.kd.deployment.metadata.namespace}/${props.kd.deployment.metadata.name}`)
+    visit(props.kd, 0, visited)
+
+    return <Box>{lines}</Box>
+}
+
 class TargetItemCardProvider implements CardTabsProvider {
     private ts?: TargetSummary;
     private lastValidateResult?: ValidateResult
@@ -169,7 +215,7 @@ class TargetItemCardProvider implements CardTabsProvider {
         }
 
         const tabs = [
-            { label: "Summary", content: this.buildSummaryTab() }
+            { label: "Summary", content: this.buildSummaryTab(appCtx) }
         ]
 
         if (this.ts.kd?.deployment) {
@@ -240,7 +286,7 @@ class TargetItemCardProvider implements CardTabsProvider {
         return tabs
     }
 
-    buildSummaryTab(): React.ReactNode {
+    buildSummaryTab(appCtx: AppContextProps): React.ReactNode {
         const d = this.ts?.kd?.deployment
 
         const props: PropertiesEntry[] = [
@@ -281,6 +327,9 @@ class TargetItemCardProvider implements CardTabsProvider {
             pushProp(props, "Delete", d.spec.delete)
             pushProp(props, "Manual", d.spec.manual)
             pushProp(props, "Manual Objects Hash", d.spec.manualObjectsHash)
+            pushProp(props, "Depends On", d.spec.dependsOn?.length, () => <DependsOnChain appCtx={appCtx} kd={this.ts!.kd!}/>)
+            pushProp(props, "Deploy Window Closed", d.status?.conditions?.find((c: any) => c.type === "DeployWindowClosed")?.message)
+            pushProp(props, "Deploy Postponed Until", d.status?.deployPostponedUntil)
 
             pushProp(props, "Source Url", d.spec.source.url)
             pushProp(props, "Source Ref", gitRefToString(d.spec.source.ref))
@@ -36,6 +36,7 @@ export class Change {
     oldValue?: any;
     newValue?: any;
     unifiedDiff?: string;
+    embeddedJsonPath?: string;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
@@ -44,6 +45,7 @@ export class Change {
         this.oldValue = source["oldValue"];
         this.newValue = source["newValue"];
         this.unifiedDiff = source["unifiedDiff"];
+        this.embeddedJsonPath = source["embeddedJsonPath"];
     }
 }
 export class ResultObject {
@@ -88,6 +90,44 @@ export class ResultObject {
 	    return a;
 	}
 }
+export class PolicyConfig {
+    name: string;
+    cel: string;
+    message?: string;
+    action?: string;
+    group?: string;
+    kind?: string;
+    namespace?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.cel = source["cel"];
+        this.message = source["message"];
+        this.action = source["action"];
+        this.group = source["group"];
+        this.kind = source["kind"];
+        this.namespace = source["namespace"];
+    }
+}
+export class ReadinessRuleConfig {
+    group?: string;
+    kind: string;
+    condition?: string;
+    cel?: string;
+    jsonPath?: string;
+    message?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.group = source["group"];
+        this.kind = source["kind"];
+        this.condition = source["condition"];
+        this.cel = source["cel"];
+        this.jsonPath = source["jsonPath"];
+        this.message = source["message"];
+    }
+}
 export class ConflictResolutionConfig {
     fieldPath?: string[];
     fieldPathRegex?: string[];
@@ -110,6 +150,22 @@ export class ConflictResolutionConfig {
         this.action = source["action"];
     }
 }
+export class EmbeddedDiffItemConfig {
+    fieldPath: string[];
+    group?: string;
+    kind?: string;
+    name?: string;
+    namespace?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.fieldPath = source["fieldPath"];
+        this.group = source["group"];
+        this.kind = source["kind"];
+        this.name = source["name"];
+        this.namespace = source["namespace"];
+    }
+}
 export class IgnoreForDiffItemConfig {
     fieldPath?: string[];
     fieldPathRegex?: string[];
@@ -547,7 +603,10 @@ export class DeploymentProjectConfig {
     overrideNamespace?: string;
     tags?: string[];
     ignoreForDiff?: IgnoreForDiffItemConfig[];
+    embeddedDiff?: EmbeddedDiffItemConfig[];
     conflictResolution?: ConflictResolutionConfig[];
+    readinessRules?: ReadinessRuleConfig[];
+    policies?: PolicyConfig[];
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
@@ -559,7 +618,10 @@ export class DeploymentProjectConfig {
         this.overrideNamespace = source["overrideNamespace"];
         this.tags = source["tags"];
         this.ignoreForDiff = this.convertValues(source["ignoreForDiff"], IgnoreForDiffItemConfig);
+        this.embeddedDiff = this.convertValues(source["embeddedDiff"], EmbeddedDiffItemConfig);
         this.conflictResolution = this.convertValues(source["conflictResolution"], ConflictResolutionConfig);
+        this.readinessRules = this.convertValues(source["readinessRules"], ReadinessRuleConfig);
+        this.policies = this.convertValues(source["policies"], PolicyConfig);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -678,6 +740,24 @@ export class CommandInfo {
 	    return a;
 	}
 }
+export class TargetPolicies {
+    allowDelete?: boolean;
+    allowPrune?: boolean;
+    forbidFlags?: string[];
+    requireConfirmationPhrase?: string;
+    allowedClusterIds?: string[];
+    dangerousPrune?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.allowDelete = source["allowDelete"];
+        this.allowPrune = source["allowPrune"];
+        this.forbidFlags = source["forbidFlags"];
+        this.requireConfirmationPhrase = source["requireConfirmationPhrase"];
+        this.allowedClusterIds = source["allowedClusterIds"];
+        this.dangerousPrune = source["dangerousPrune"];
+    }
+}
 export class ObjectRef {
     group?: string;
     version?: string;
@@ -783,6 +863,7 @@ export class Target {
     aws?: AwsConfig;
     images?: FixedImage[];
     discriminator?: string;
+    policies?: TargetPolicies;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
@@ -792,6 +873,7 @@ export class Target {
         this.aws = this.convertValues(source["aws"], AwsConfig);
         this.images = this.convertValues(source["images"], FixedImage);
         this.discriminator = source["discriminator"];
+        this.policies = this.convertValues(source["policies"], TargetPolicies);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {